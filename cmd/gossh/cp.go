@@ -0,0 +1,230 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 23:34:20
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-20 00:01:00
+ * @FilePath: cp.go
+ * @Description: cp子命令：上传或下载文件/目录（支持多台主机及进度显示）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/duanztop/gossh"
+	"golang.org/x/term"
+)
+
+// cpUsage cp子命令的用法
+const cpUsage = `cp [选项] 源 目标
+
+源或目标中有且仅有一个为远端路径，格式为:路径（选择的全部主机）或主机:路径（主机格式同-H）。
+上传：
+  gossh cp -H web[01:03] ./app.tar.gz :/opt/app/
+  gossh cp -r ./conf deploy@web01:/etc/app
+下载（多台主机时保存到目标目录下以主机名称命名的子目录）：
+  gossh cp -i hosts.ini -l web :/var/log/app.log ./logs/
+目标以/结尾或为已存在的本地目录时，文件保存为其中的同名文件；-r时源目录的内容拷贝到目标目录`
+
+// runCp 执行cp子命令
+//
+//	@author duanzt
+//	@date 2026-10-19 23:34:50
+//	@param ctx context.Context 上下文
+//	@param args []string 参数
+//	@return error 执行异常或存在失败的主机时返回
+func runCp(ctx context.Context, args []string) error {
+	fs := newFlagSet("cp", cpUsage)
+	var hf hostFlags
+	var bf batchFlags
+	hf.register(fs)
+	bf.register(fs)
+	recursive := fs.Bool("r", false, "递归拷贝目录")
+	mode := fs.String("m", "", "文件权限（八进制如0644或符号格式如u+x，默认保留目标文件已有权限）")
+	preserve := fs.Bool("preserve", false, "保留源文件的权限及修改时间")
+	verify := fs.Bool("verify", false, "传输后校验sha256摘要")
+	progress := fs.Bool("progress", term.IsTerminal(int(os.Stderr.Fd())), "在错误输出中显示传输进度（默认在终端中显示）")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return usagef("需要源及目标两个参数")
+	}
+	srcHost, src, srcRemote := splitRemote(fs.Arg(0))
+	destHost, dest, destRemote := splitRemote(fs.Arg(1))
+	if srcRemote == destRemote {
+		return usagef("源和目标中有且仅有一个为远端路径（:路径或主机:路径）")
+	}
+	if spec := srcHost + destHost; spec != "" {
+		hf.hosts = strings.Trim(hf.hosts+","+spec, ",")
+	}
+	if src == "" || dest == "" {
+		return usagef("源及目标路径不能为空")
+	}
+	p, err := newPrinter(bf.output, os.Stdout, os.Stderr)
+	if err != nil {
+		return err
+	}
+	hosts, err := hf.resolve()
+	if err != nil {
+		return err
+	}
+	p.total = len(hosts)
+
+	var renderer *gossh.ProgressRenderer
+	if *progress {
+		renderer = gossh.NewProgressRenderer(os.Stderr)
+	}
+	// 每台主机使用独立的配置项切片（进度报告按主机区分，超时或中断时停止等待限速）
+	copyOptions := func(ctx context.Context, host gossh.Host) []gossh.CopyOption {
+		opts := []gossh.CopyOption{gossh.WithCopyContext(ctx)}
+		if *preserve {
+			opts = append(opts, gossh.WithPreserveMode(), gossh.WithPreserveTimes())
+		}
+		if *verify {
+			opts = append(opts, gossh.WithVerify(gossh.HashSHA256))
+		}
+		if renderer != nil {
+			opts = append(opts, gossh.WithProgress(renderer.WithLabel(host.DisplayName())))
+		}
+		return opts
+	}
+
+	var task gossh.HostTask
+	if destRemote {
+		info, err := os.Stat(src)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && !*recursive {
+			return usagef("%s是目录，需要使用-r", src)
+		}
+		task = func(ctx context.Context, host gossh.Host, conn gossh.IConnection) (*gossh.CommandResult, error) {
+			if info.IsDir() {
+				summary, err := conn.CopyDirLTR(src, dest, copyOptions(ctx, host)...)
+				return summaryResult(src, dest, summary), err
+			}
+			target := dest
+			if strings.HasSuffix(target, "/") {
+				target = path.Join(target, filepath.Base(src))
+			}
+			err := conn.CopyFileLTR(src, target, *mode, copyOptions(ctx, host)...)
+			return fileResult(src, target, info.Size()), err
+		}
+	} else {
+		multi := len(hosts) > 1
+		task = func(ctx context.Context, host gossh.Host, conn gossh.IConnection) (*gossh.CommandResult, error) {
+			dir := dest
+			if multi {
+				dir = filepath.Join(dest, safeName(host.DisplayName()))
+			}
+			if *recursive {
+				summary, err := conn.CopyDirRTL(src, dir, copyOptions(ctx, host)...)
+				return summaryResult(src, dir, summary), err
+			}
+			target := dir
+			if info, err := os.Stat(dest); multi || strings.HasSuffix(dest, "/") || err == nil && info.IsDir() {
+				target = filepath.Join(dir, path.Base(src))
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return nil, err
+			}
+			if err := conn.CopyFileRTL(src, target, *mode, copyOptions(ctx, host)...); err != nil {
+				return nil, err
+			}
+			info, err := os.Stat(target)
+			if err != nil {
+				return nil, err
+			}
+			return fileResult(src, target, info.Size()), nil
+		}
+	}
+	report, err := gossh.FanoutTask(ctx, hosts, task, bf.fanoutOptions(gossh.WithResultHandler(p.hostResult))...)
+	if renderer != nil {
+		renderer.Stop()
+	}
+	p.fanoutReport(report)
+	if err != nil {
+		return errHostsFailed
+	}
+	return nil
+}
+
+// splitRemote 拆分远端路径（:路径或主机:路径，主机中[]内的冒号为范围），
+// 冒号前包含路径分隔符或为单个字母（windows盘符）时视为本地路径
+//
+//	@author duanzt
+//	@date 2026-10-19 23:35:20
+//	@param arg string 参数
+//	@return string 主机（:路径时为空）
+//	@return string 路径
+//	@return bool 是否为远端路径
+func splitRemote(arg string) (string, string, bool) {
+	depth := 0
+	for i, r := range arg {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '/', '\\':
+			if depth == 0 {
+				return "", arg, false
+			}
+		case ':':
+			if depth > 0 {
+				continue
+			}
+			if i == 1 && len(arg) > 2 && (arg[2] == '\\' || arg[2] == '/') {
+				return "", arg, false
+			}
+			return arg[:i], arg[i+1:], true
+		}
+	}
+	return "", arg, false
+}
+
+// safeName 将主机名称转换为可用作目录名的字符串
+//
+//	@author duanzt
+//	@date 2026-10-19 23:35:50
+//	@param name string 主机名称
+//	@return string 目录名
+func safeName(name string) string {
+	return strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(name)
+}
+
+// fileResult 生成单个文件拷贝的结果
+//
+//	@author duanzt
+//	@date 2026-10-19 23:36:20
+//	@param src string 源路径
+//	@param dest string 目标路径
+//	@param size int64 文件大小
+//	@return *gossh.CommandResult 结果
+func fileResult(src, dest string, size int64) *gossh.CommandResult {
+	return &gossh.CommandResult{Stdout: fmt.Sprintf("%s -> %s (%d字节)\n", src, dest, size)}
+}
+
+// summaryResult 生成目录拷贝的结果
+//
+//	@author duanzt
+//	@date 2026-10-19 23:36:50
+//	@param src string 源目录
+//	@param dest string 目标目录
+//	@param summary *gossh.CopySummary 拷贝结果汇总（可为nil）
+//	@return *gossh.CommandResult 结果
+func summaryResult(src, dest string, summary *gossh.CopySummary) *gossh.CommandResult {
+	if summary == nil {
+		return nil
+	}
+	return &gossh.CommandResult{Stdout: fmt.Sprintf("%s -> %s (%d个文件，%d个目录，%d个符号链接，跳过%d个，%d字节，失败%d个)\n",
+		src, dest, summary.Files, summary.Dirs, summary.Symlinks, summary.Skipped, summary.Bytes, len(summary.Errors))}
+}
@@ -0,0 +1,63 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 23:33:20
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:33:20
+ * @FilePath: exec.go
+ * @Description: exec子命令：在一台或多台主机上执行命令
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/duanztop/gossh"
+)
+
+// execUsage exec子命令的用法
+const execUsage = `exec [选项] 命令...
+
+在选择的主机上并发执行命令，例如：
+  gossh exec -H web[01:03] -u deploy 'systemctl is-active nginx'
+  gossh exec -i hosts.ini -l web -o jsonl -c 50 -t 30s uptime`
+
+// runExec 执行exec子命令
+//
+//	@author duanzt
+//	@date 2026-10-19 23:33:50
+//	@param ctx context.Context 上下文
+//	@param args []string 参数
+//	@return error 执行异常或存在失败的主机时返回
+func runExec(ctx context.Context, args []string) error {
+	fs := newFlagSet("exec", execUsage)
+	var hf hostFlags
+	var bf batchFlags
+	hf.register(fs)
+	bf.register(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	command := strings.Join(fs.Args(), " ")
+	if strings.TrimSpace(command) == "" {
+		return usagef("缺少要执行的命令")
+	}
+	p, err := newPrinter(bf.output, os.Stdout, os.Stderr)
+	if err != nil {
+		return err
+	}
+	hosts, err := hf.resolve()
+	if err != nil {
+		return err
+	}
+	p.total = len(hosts)
+	report, err := gossh.Fanout(ctx, hosts, command, bf.fanoutOptions(gossh.WithResultHandler(p.hostResult))...)
+	p.fanoutReport(report)
+	if err != nil {
+		return errHostsFailed
+	}
+	return nil
+}
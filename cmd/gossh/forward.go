@@ -0,0 +1,141 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 23:40:50
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:40:50
+ * @FilePath: forward.go
+ * @Description: forward子命令：端口转发（-L本地转发、-R远程转发、-D socks5动态转发）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/duanztop/gossh"
+)
+
+// forwardUsage forward子命令的用法
+const forwardUsage = `forward [选项] [主机]
+
+连接单台主机并保持端口转发，直到收到中断信号或连接断开，-L、-R、-D可重复指定，例如：
+  gossh forward -L 13306:db.internal:3306 -L 18080:127.0.0.1:8080 bastion
+  gossh forward -R 9000:127.0.0.1:9000 web01
+  gossh forward -D 1080 -J bastion web01`
+
+// forwardSpecs 可重复指定的端口转发参数
+type forwardSpecs struct {
+	kind  gossh.ForwardKind
+	specs *[]gossh.ForwardSpec
+}
+
+// String 实现flag.Value
+//
+//	@author duanzt
+//	@date 2026-10-19 23:41:20
+//	@receiver f forwardSpecs
+//	@return string 已指定的参数
+func (f forwardSpecs) String() string {
+	if f.specs == nil {
+		return ""
+	}
+	var values []string
+	for _, spec := range *f.specs {
+		if spec.Kind == f.kind {
+			values = append(values, spec.String())
+		}
+	}
+	return strings.Join(values, " ")
+}
+
+// Set 实现flag.Value，解析并追加端口转发参数
+//
+//	@author duanzt
+//	@date 2026-10-19 23:41:50
+//	@receiver f forwardSpecs
+//	@param value string 参数
+//	@return error 格式不正确时返回
+func (f forwardSpecs) Set(value string) error {
+	spec, err := gossh.ParseForwardSpec(f.kind, value)
+	if err != nil {
+		return err
+	}
+	*f.specs = append(*f.specs, spec)
+	return nil
+}
+
+// runForward 执行forward子命令
+//
+//	@author duanzt
+//	@date 2026-10-19 23:42:20
+//	@param ctx context.Context 上下文（收到中断信号时停止转发）
+//	@param args []string 参数
+//	@return error 连接、监听异常或连接断开时返回
+func runForward(ctx context.Context, args []string) error {
+	fs := newFlagSet("forward", forwardUsage)
+	var hf hostFlags
+	hf.register(fs)
+	var specs []gossh.ForwardSpec
+	fs.Var(forwardSpecs{kind: gossh.ForwardLocal, specs: &specs}, "L", "本地转发，格式为[bind_address:]port:host:hostport（在本地监听，经主机连接host:hostport）")
+	fs.Var(forwardSpecs{kind: gossh.ForwardRemote, specs: &specs}, "R", "远程转发，格式为[bind_address:]port:host:hostport（在主机上监听，从本地连接host:hostport）")
+	fs.Var(forwardSpecs{kind: gossh.ForwardDynamic, specs: &specs}, "D", "socks5动态转发，格式为[bind_address:]port（在本地监听socks5代理）")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if len(specs) == 0 {
+		return usagef("需要通过-L、-R或-D指定端口转发")
+	}
+	host, rest, err := hf.resolveOne("forward", fs.Args())
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usagef("多余的参数%q", strings.Join(rest, " "))
+	}
+	conn, err := gossh.Dial(ctx, host)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	lost := make(chan struct{}, len(specs))
+	var forwarders []*gossh.Forwarder
+	for _, spec := range specs {
+		forwarder, err := gossh.Forward(ctx, conn, spec, func(err error) {
+			fmt.Fprintf(os.Stderr, "gossh forward: %s\n", err)
+		})
+		if err != nil {
+			cancel()
+			for _, f := range forwarders {
+				f.Close()
+			}
+			return err
+		}
+		forwarders = append(forwarders, forwarder)
+		fmt.Fprintf(os.Stderr, "%s 已开始转发（监听%s）\n", spec, forwarder.Addr())
+		go func() {
+			<-forwarder.Done()
+			lost <- struct{}{}
+		}()
+	}
+	select {
+	case <-ctx.Done():
+	case <-lost:
+	}
+	interrupted := ctx.Err() != nil
+	cancel()
+	for _, f := range forwarders {
+		f.Close()
+	}
+	if !interrupted {
+		return errors.New("连接已断开，端口转发停止")
+	}
+	return nil
+}
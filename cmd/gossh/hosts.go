@@ -0,0 +1,312 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 23:24:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:55:30
+ * @FilePath: hosts.go
+ * @Description: 子命令的公共参数：主机选择（-H、~/.ssh/config、主机清单）、验证方式及多主机执行配置
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/duanztop/gossh"
+	"golang.org/x/term"
+)
+
+// passwordEnv 读取密码的环境变量（避免密码出现在命令行及历史记录中）
+const passwordEnv = "GOSSH_PASSWORD"
+
+// newFlagSet 创建子命令的参数解析
+//
+//	@author duanzt
+//	@date 2026-10-19 23:24:40
+//	@param name string 子命令名称
+//	@param usage string 用法
+//	@return *flag.FlagSet 参数解析
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "用法: gossh %s\n\n选项:\n", usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags 解析参数（-h时输出帮助信息并以0退出，参数不正确时已由flag输出原因，以2退出）
+//
+//	@author duanzt
+//	@date 2026-10-19 23:25:10
+//	@param fs *flag.FlagSet 参数解析
+//	@param args []string 参数
+//	@return error 需要退出时返回
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return &exitError{code: exitOK}
+		}
+		return &exitError{code: exitUsage}
+	}
+	return nil
+}
+
+// hostFlags 主机选择及验证方式参数
+type hostFlags struct {
+	hosts     string // 主机列表
+	inventory string // 主机清单文件
+	limit     string // 主机清单中的分组/主机匹配规则
+	sshConfig string // ssh客户端配置文件
+	user      string // 默认用户名
+	password  string // 密码
+	askPass   bool   // 是否提示输入密码
+	key       string // 私钥文件
+	jump      string // 跳板机
+}
+
+// register 注册参数
+//
+//	@author duanzt
+//	@date 2026-10-19 23:25:40
+//	@receiver f *hostFlags
+//	@param fs *flag.FlagSet 参数解析
+func (f *hostFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.hosts, "H", "", "主机列表，逗号分隔，格式为[user@]host[:port]，支持web[01:03]范围、~/.ssh/config中的别名，@file从文件读取（每行一个）")
+	fs.StringVar(&f.inventory, "i", "", "主机清单文件（ansible风格的ini或yaml）")
+	fs.StringVar(&f.limit, "l", "", "主机清单中的分组或主机匹配规则（默认all，支持web:&prod、!db等）")
+	fs.StringVar(&f.sshConfig, "F", "", "ssh客户端配置文件（默认~/.ssh/config）")
+	fs.StringVar(&f.user, "u", "", "未指定用户名的主机使用的用户名（默认root）")
+	fs.StringVar(&f.password, "p", "", "密码，配置了私钥的主机先尝试私钥验证（建议使用环境变量"+passwordEnv+"或-A）")
+	fs.BoolVar(&f.askPass, "A", false, "提示输入密码")
+	fs.StringVar(&f.key, "k", "", "私钥文件（未指定时使用~/.ssh/config中的IdentityFile或默认私钥）")
+	fs.StringVar(&f.jump, "J", "", "跳板机，格式为[user@]host[:port]，多级以逗号分隔")
+}
+
+// resolve 获取选择的主机（-H及-i可同时使用），命令行的用户名、验证方式及跳板机作为未配置的主机的默认值
+//
+//	@author duanzt
+//	@date 2026-10-19 23:26:10
+//	@receiver f *hostFlags
+//	@return []gossh.Host 主机列表
+//	@return error 未指定主机、读取配置异常或没有匹配的主机时返回
+func (f *hostFlags) resolve() ([]gossh.Host, error) {
+	if f.hosts == "" && f.inventory == "" {
+		return nil, usagef("需要通过-H或-i指定主机")
+	}
+	if f.limit != "" && f.inventory == "" {
+		return nil, usagef("-l需要配合-i使用")
+	}
+	password, err := f.resolvePassword()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := gossh.LoadSSHConfig(f.sshConfig)
+	if err != nil {
+		return nil, fmt.Errorf("读取ssh配置失败: %w", err)
+	}
+	var hosts []gossh.Host
+	if f.inventory != "" {
+		inv, err := gossh.LoadInventory(f.inventory)
+		if err != nil {
+			return nil, err
+		}
+		limit := f.limit
+		if limit == "" {
+			limit = "all"
+		}
+		if hosts, err = inv.Hosts(limit); err != nil {
+			return nil, err
+		}
+	}
+	items, err := hostItems(f.hosts)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		names, err := gossh.ExpandHostPattern(item)
+		if err != nil {
+			return nil, usagef("%s", err)
+		}
+		for _, name := range names {
+			hosts = append(hosts, parseHost(cfg, name))
+		}
+	}
+	if len(hosts) == 0 {
+		return nil, errors.New("没有匹配的主机")
+	}
+	for i := range hosts {
+		if hosts[i].User == "" {
+			hosts[i].User = f.user
+		}
+		if hosts[i].Password == "" {
+			hosts[i].Password = password
+		}
+		if hosts[i].PrivateKey == "" {
+			hosts[i].PrivateKey = f.key
+		}
+		if hosts[i].Jump == "" {
+			hosts[i].Jump = f.jump
+		}
+	}
+	return hosts, nil
+}
+
+// resolveOne 获取选择的单台主机（shell、forward），未通过-H及-i指定主机时使用第一个参数
+//
+//	@author duanzt
+//	@date 2026-10-19 23:26:40
+//	@receiver f *hostFlags
+//	@param name string 子命令名称
+//	@param args []string 参数
+//	@return gossh.Host 主机
+//	@return []string 剩余参数
+//	@return error 主机数量不为1时返回
+func (f *hostFlags) resolveOne(name string, args []string) (gossh.Host, []string, error) {
+	if f.hosts == "" && f.inventory == "" && len(args) > 0 {
+		f.hosts, args = args[0], args[1:]
+	}
+	hosts, err := f.resolve()
+	if err != nil {
+		return gossh.Host{}, nil, err
+	}
+	if len(hosts) != 1 {
+		return gossh.Host{}, nil, usagef("%s只支持单台主机，当前匹配到%d台", name, len(hosts))
+	}
+	return hosts[0], args, nil
+}
+
+// resolvePassword 获取密码（-p、-A、环境变量GOSSH_PASSWORD）
+//
+//	@author duanzt
+//	@date 2026-10-19 23:27:10
+//	@receiver f *hostFlags
+//	@return string 密码
+//	@return error 无法读取输入时返回
+func (f *hostFlags) resolvePassword() (string, error) {
+	switch {
+	case f.password != "":
+		return f.password, nil
+	case f.askPass:
+		fmt.Fprint(os.Stderr, "密码: ")
+		password, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("读取密码失败: %w", err)
+		}
+		// 只提示一次，之后的调用（例如多次解析主机）直接使用
+		f.password, f.askPass = string(password), false
+		return f.password, nil
+	}
+	return os.Getenv(passwordEnv), nil
+}
+
+// hostItems 拆分主机列表（逗号及空白分隔，@开头时读取文件，文件中#开头的行为注释）
+//
+//	@author duanzt
+//	@date 2026-10-19 23:27:40
+//	@param value string 主机列表
+//	@return []string 主机
+//	@return error 读取文件异常时返回
+func hostItems(value string) ([]string, error) {
+	var items []string
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' || r == '\n' }) {
+		if !strings.HasPrefix(item, "@") {
+			items = append(items, item)
+			continue
+		}
+		file, err := os.Open(item[1:])
+		if err != nil {
+			return nil, fmt.Errorf("读取主机列表失败: %w", err)
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			items = append(items, strings.Fields(line)...)
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("读取主机列表失败: %w", err)
+		}
+	}
+	return items, nil
+}
+
+// parseHost 解析[user@]host[:port]，host为~/.ssh/config中的别名时使用其中的配置
+//
+//	@author duanzt
+//	@date 2026-10-19 23:28:10
+//	@param cfg *gossh.SSHConfig ssh客户端配置
+//	@param item string 主机
+//	@return gossh.Host 主机连接配置（名称为host[:port]）
+func parseHost(cfg *gossh.SSHConfig, item string) gossh.Host {
+	user, rest := "", item
+	if i := strings.LastIndex(item, "@"); i >= 0 {
+		user, rest = item[:i], item[i+1:]
+	}
+	name, port := rest, ""
+	if h, p, err := net.SplitHostPort(rest); err == nil {
+		name, port = h, p
+	}
+	host := cfg.Host(name)
+	host.Name = rest
+	if port != "" {
+		h, _, _ := net.SplitHostPort(host.Addr)
+		host.Addr = net.JoinHostPort(h, port)
+	}
+	if user != "" {
+		host.User = user
+	}
+	return host
+}
+
+// batchFlags 多主机执行参数
+type batchFlags struct {
+	output      string        // 输出格式
+	concurrency int           // 并发数
+	timeout     time.Duration // 单台主机超时
+	failFast    bool          // 任一主机失败时停止
+}
+
+// register 注册参数
+//
+//	@author duanzt
+//	@date 2026-10-19 23:28:40
+//	@receiver f *batchFlags
+//	@param fs *flag.FlagSet 参数解析
+func (f *batchFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.output, "o", formatText, "输出格式：text、json、jsonl（每行一个json）")
+	fs.IntVar(&f.concurrency, "c", 10, "同时执行的主机数")
+	fs.DurationVar(&f.timeout, "t", 0, "单台主机的超时时间（包含建立连接，例如30s、5m，默认不限制）")
+	fs.BoolVar(&f.failFast, "fail-fast", false, "任一主机失败时停止执行剩余主机")
+}
+
+// fanoutOptions 生成多主机执行配置
+//
+//	@author duanzt
+//	@date 2026-10-19 23:29:10
+//	@receiver f *batchFlags
+//	@param opts ...gossh.FanoutOption 追加的配置项
+//	@return []gossh.FanoutOption 多主机执行配置
+func (f *batchFlags) fanoutOptions(opts ...gossh.FanoutOption) []gossh.FanoutOption {
+	result := []gossh.FanoutOption{gossh.WithConcurrency(f.concurrency)}
+	if f.timeout > 0 {
+		result = append(result, gossh.WithHostTimeout(f.timeout))
+	}
+	if f.failFast {
+		result = append(result, gossh.WithFanoutFailFast())
+	}
+	return append(result, opts...)
+}
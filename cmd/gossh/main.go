@@ -0,0 +1,186 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 23:20:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:20:10
+ * @FilePath: main.go
+ * @Description: gossh命令行工具：exec、cp、shell、forward、run子命令
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+const (
+
+	// exitOK 执行成功
+	exitOK = 0
+
+	// exitFailed 存在失败的主机或执行异常
+	exitFailed = 1
+
+	// exitUsage 参数不正确
+	exitUsage = 2
+)
+
+// command 子命令
+type command struct {
+	name  string
+	usage string // 用法（第一行为参数格式）
+	run   func(ctx context.Context, args []string) error
+}
+
+// commands 全部子命令（按帮助信息中的顺序）
+var commands = []*command{
+	{name: "exec", usage: execUsage, run: runExec},
+	{name: "cp", usage: cpUsage, run: runCp},
+	{name: "shell", usage: shellUsage, run: runShell},
+	{name: "forward", usage: forwardUsage, run: runForward},
+	{name: "run", usage: runUsage, run: runPlaybook},
+}
+
+// usageError 参数不正确（退出码为2）
+type usageError struct {
+	msg string
+}
+
+// Error 实现error
+//
+//	@author duanzt
+//	@date 2026-10-19 23:20:40
+//	@receiver e *usageError
+//	@return string 异常信息
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+// usagef 生成参数不正确的异常
+//
+//	@author duanzt
+//	@date 2026-10-19 23:21:10
+//	@param format string 格式
+//	@param args ...any 参数
+//	@return error 参数不正确的异常
+func usagef(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// exitError 指定退出码且已输出结果的异常（例如存在失败的主机、远端命令的退出码）
+type exitError struct {
+	code int
+}
+
+// Error 实现error
+//
+//	@author duanzt
+//	@date 2026-10-19 23:21:40
+//	@receiver e *exitError
+//	@return string 异常信息
+func (e *exitError) Error() string {
+	return fmt.Sprintf("退出码%d", e.code)
+}
+
+// errHostsFailed 存在失败的主机（结果已输出）
+var errHostsFailed = &exitError{code: exitFailed}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run 执行子命令
+//
+//	@author duanzt
+//	@date 2026-10-19 23:22:10
+//	@param ctx context.Context 上下文（收到中断信号时取消）
+//	@param args []string 命令行参数（不含程序名）
+//	@param stderr io.Writer 异常及帮助信息的输出
+//	@return int 退出码
+func run(ctx context.Context, args []string, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" || args[0] == "help" {
+		if len(args) > 1 {
+			if cmd := findCommand(args[1]); cmd != nil {
+				return run(ctx, []string{cmd.name, "-h"}, stderr)
+			}
+		}
+		printUsage(stderr)
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOK
+	}
+	cmd := findCommand(args[0])
+	if cmd == nil {
+		fmt.Fprintf(stderr, "gossh: 未知的子命令%q\n\n", args[0])
+		printUsage(stderr)
+		return exitUsage
+	}
+	err := cmd.run(ctx, args[1:])
+	var usageErr *usageError
+	var exitErr *exitError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &exitErr):
+		return exitErr.code
+	case errors.As(err, &usageErr):
+		fmt.Fprintf(stderr, "gossh %s: %s\n用法: gossh %s\n详细参数见: gossh %s -h\n", cmd.name, usageErr.msg, cmd.usage, cmd.name)
+		return exitUsage
+	default:
+		fmt.Fprintf(stderr, "gossh %s: %s\n", cmd.name, err)
+		return exitFailed
+	}
+}
+
+// findCommand 按名称查找子命令
+//
+//	@author duanzt
+//	@date 2026-10-19 23:22:40
+//	@param name string 子命令名称
+//	@return *command 子命令（不存在时为nil）
+func findCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// printUsage 输出帮助信息
+//
+//	@author duanzt
+//	@date 2026-10-19 23:23:10
+//	@param w io.Writer 输出
+func printUsage(w io.Writer) {
+	fmt.Fprint(w, `gossh 基于ssh的批量运维工具
+
+用法:
+  gossh <子命令> [选项] [参数]
+
+子命令:
+  exec     在一台或多台主机上执行命令
+  cp       上传或下载文件/目录（支持多台主机及进度显示）
+  shell    打开交互式shell（伪终端）
+  forward  端口转发（-L本地转发、-R远程转发、-D socks5动态转发）
+  run      执行yaml任务文件
+
+主机可通过-H指定（逗号分隔，支持user@host:port、web[01:03]范围、~/.ssh/config中的别名及@文件），
+或通过-i指定主机清单并以-l选择分组/主机。exec、cp、run的结果可通过-o以text、json或jsonl格式输出。
+
+退出码: 0成功，1存在失败的主机或执行异常，2参数不正确（shell为远端命令的退出码）
+
+查看子命令的参数: gossh <子命令> -h
+`)
+}
@@ -0,0 +1,374 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 23:29:40
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:29:40
+ * @FilePath: output.go
+ * @Description: 多主机执行结果的输出（text、json、jsonl）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/duanztop/gossh"
+)
+
+const (
+
+	// formatText 便于阅读的文本（每台主机完成时输出，结束时在错误输出中输出汇总）
+	formatText = "text"
+
+	// formatJSON 结束时输出一个json对象（主机与列表顺序一致）
+	formatJSON = "json"
+
+	// formatJSONL 每条结果完成时输出一行json
+	formatJSONL = "jsonl"
+)
+
+// hostRecord 单台主机执行结果的json格式
+type hostRecord struct {
+	Host       string `json:"host"`
+	Addr       string `json:"addr"`
+	Status     string `json:"status"` // ok、failed、skipped
+	ExitCode   int    `json:"exit_code"`
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// fanoutRecord 多主机执行结果的json格式
+type fanoutRecord struct {
+	Results    []hostRecord `json:"results"`
+	Succeeded  int          `json:"succeeded"`
+	Failed     int          `json:"failed"`
+	Skipped    int          `json:"skipped"`
+	Aborted    bool         `json:"aborted"`
+	DurationMs int64        `json:"duration_ms"`
+}
+
+// taskRecord 任务执行结果的json格式
+type taskRecord struct {
+	Type       string `json:"type"` // task
+	Host       string `json:"host"`
+	Task       string `json:"task"`
+	Action     string `json:"action"`
+	Item       any    `json:"item,omitempty"`
+	Status     string `json:"status"` // ok、changed、skipped、failed
+	ExitCode   int    `json:"exit_code"`
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr"`
+	Error      string `json:"error,omitempty"`
+	Ignored    bool   `json:"ignored,omitempty"`
+	Handler    bool   `json:"handler,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// recapRecord 单台主机任务文件执行汇总的json格式
+type recapRecord struct {
+	Type        string       `json:"type"` // recap
+	Host        string       `json:"host"`
+	Addr        string       `json:"addr"`
+	Ok          int          `json:"ok"`
+	Changed     int          `json:"changed"`
+	Skipped     int          `json:"skipped"`
+	Failed      int          `json:"failed"`
+	Ignored     int          `json:"ignored"`
+	Unreachable bool         `json:"unreachable"`
+	Error       string       `json:"error,omitempty"`
+	DurationMs  int64        `json:"duration_ms"`
+	Results     []taskRecord `json:"results,omitempty"` // 仅json格式输出
+}
+
+// playbookRecord 任务文件执行结果的json格式
+type playbookRecord struct {
+	Recaps     []recapRecord `json:"recaps"`
+	DurationMs int64         `json:"duration_ms"`
+}
+
+// printer 结果输出
+type printer struct {
+	format string
+	out    io.Writer  // 结果的输出
+	errOut io.Writer  // 汇总及提示的输出（text）
+	lock   sync.Mutex // 保证并发输出的结果完整
+	done   int        // 已输出的主机数
+	total  int        // 主机总数
+}
+
+// newPrinter 创建结果输出
+//
+//	@author duanzt
+//	@date 2026-10-19 23:30:20
+//	@param format string 输出格式
+//	@param out io.Writer 结果的输出
+//	@param errOut io.Writer 汇总及提示的输出
+//	@return *printer 结果输出（获取主机后需设置total）
+//	@return error 输出格式不支持时返回
+func newPrinter(format string, out, errOut io.Writer) (*printer, error) {
+	switch format {
+	case formatText, formatJSON, formatJSONL:
+	default:
+		return nil, usagef("不支持的输出格式%q（text、json、jsonl）", format)
+	}
+	return &printer{format: format, out: out, errOut: errOut}, nil
+}
+
+// newHostRecord 转换单台主机的执行结果
+//
+//	@author duanzt
+//	@date 2026-10-19 23:30:50
+//	@param r *gossh.HostResult 执行结果
+//	@return hostRecord json格式
+func newHostRecord(r *gossh.HostResult) hostRecord {
+	record := hostRecord{
+		Host:       r.Host.DisplayName(),
+		Addr:       r.Host.Addr,
+		Status:     "ok",
+		ExitCode:   r.ExitCode,
+		Stdout:     r.Stdout,
+		Stderr:     r.Stderr,
+		DurationMs: r.Duration.Milliseconds(),
+	}
+	switch {
+	case r.Skipped:
+		record.Status = "skipped"
+	case r.Err != nil:
+		record.Status = "failed"
+	}
+	if r.Err != nil {
+		record.Error = r.Err.Error()
+	}
+	return record
+}
+
+// hostResult 输出单台主机的执行结果（用于WithResultHandler，json格式在结束时统一输出）
+//
+//	@author duanzt
+//	@date 2026-10-19 23:31:20
+//	@receiver p *printer
+//	@param r *gossh.HostResult 执行结果
+func (p *printer) hostResult(r *gossh.HostResult) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.done++
+	switch p.format {
+	case formatJSONL:
+		p.writeJSON(newHostRecord(r))
+	case formatText:
+		record := newHostRecord(r)
+		status := strings.ToUpper(map[string]string{"ok": "success", "failed": "failure", "skipped": "skipped"}[record.Status])
+		fmt.Fprintf(p.out, "[%d/%d] %s [%s] %s", p.done, p.total, time.Now().Format("15:04:05"), status, record.Host)
+		if record.Host != record.Addr {
+			fmt.Fprintf(p.out, " (%s)", record.Addr)
+		}
+		fmt.Fprintf(p.out, " exit=%d %s\n", record.ExitCode, r.Duration.Round(time.Millisecond))
+		if record.Error != "" && !r.Skipped {
+			fmt.Fprintf(p.out, "error: %s\n", record.Error)
+		}
+		writeBlock(p.out, "", record.Stdout)
+		writeBlock(p.out, "stderr: ", record.Stderr)
+	}
+}
+
+// fanoutReport 输出多主机执行结果：补充输出未执行的主机，json格式输出全部结果，text格式在错误输出中输出汇总
+//
+//	@author duanzt
+//	@date 2026-10-19 23:31:50
+//	@receiver p *printer
+//	@param report *gossh.FanoutReport 执行结果
+func (p *printer) fanoutReport(report *gossh.FanoutReport) {
+	if p.format == formatJSON {
+		record := fanoutRecord{
+			Results:    make([]hostRecord, 0, len(report.Results)),
+			Succeeded:  report.Succeeded,
+			Failed:     report.Failed,
+			Skipped:    report.Skipped,
+			Aborted:    report.Aborted,
+			DurationMs: report.Duration.Milliseconds(),
+		}
+		for _, r := range report.Results {
+			record.Results = append(record.Results, newHostRecord(r))
+		}
+		p.writeJSON(record)
+		return
+	}
+	for _, r := range report.Results {
+		if r.Skipped {
+			p.hostResult(r)
+		}
+	}
+	if p.format == formatText {
+		fmt.Fprintf(p.errOut, "共%d台主机：成功%d，失败%d，未执行%d，耗时%s\n", len(report.Results),
+			report.Succeeded, report.Failed, report.Skipped, report.Duration.Round(time.Millisecond))
+	}
+}
+
+// newTaskRecord 转换任务执行结果
+//
+//	@author duanzt
+//	@date 2026-10-19 23:43:20
+//	@param r *gossh.TaskResult 任务执行结果
+//	@return taskRecord json格式
+func newTaskRecord(r *gossh.TaskResult) taskRecord {
+	record := taskRecord{
+		Type:       "task",
+		Host:       r.Host,
+		Task:       r.Task.Name,
+		Action:     r.Task.Action(),
+		Item:       r.Item,
+		Status:     string(r.Status),
+		ExitCode:   r.ExitCode,
+		Stdout:     r.Stdout,
+		Stderr:     r.Stderr,
+		Ignored:    r.Ignored,
+		Handler:    r.Handler,
+		DurationMs: r.Duration.Milliseconds(),
+	}
+	if r.Err != nil {
+		record.Error = r.Err.Error()
+	}
+	return record
+}
+
+// newRecapRecord 转换单台主机的执行汇总
+//
+//	@author duanzt
+//	@date 2026-10-19 23:43:50
+//	@param recap *gossh.Recap 执行汇总
+//	@param results bool 是否包含全部任务执行结果
+//	@return recapRecord json格式
+func newRecapRecord(recap *gossh.Recap, results bool) recapRecord {
+	record := recapRecord{
+		Type:        "recap",
+		Host:        recap.Host.DisplayName(),
+		Addr:        recap.Host.Addr,
+		Ok:          recap.Ok,
+		Changed:     recap.Changed,
+		Skipped:     recap.Skipped,
+		Failed:      recap.Failed,
+		Ignored:     recap.Ignored,
+		Unreachable: recap.Unreachable,
+		DurationMs:  recap.Duration.Milliseconds(),
+	}
+	if recap.Err != nil {
+		record.Error = recap.Err.Error()
+	}
+	if results {
+		record.Results = make([]taskRecord, 0, len(recap.Results))
+		for _, r := range recap.Results {
+			record.Results = append(record.Results, newTaskRecord(r))
+		}
+	}
+	return record
+}
+
+// taskResult 输出任务执行结果（用于WithTaskHandler，json格式在结束时统一输出）
+//
+//	@author duanzt
+//	@date 2026-10-19 23:44:20
+//	@receiver p *printer
+//	@param r *gossh.TaskResult 任务执行结果
+func (p *printer) taskResult(r *gossh.TaskResult) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	switch p.format {
+	case formatJSONL:
+		p.writeJSON(newTaskRecord(r))
+	case formatText:
+		kind := "TASK"
+		if r.Handler {
+			kind = "HANDLER"
+		}
+		fmt.Fprintf(p.out, "%s: [%s] %s [%s]", r.Status, r.Host, kind, r.Task.Name)
+		if r.Item != nil {
+			fmt.Fprintf(p.out, " (item=%v)", r.Item)
+		}
+		if r.Ignored {
+			fmt.Fprint(p.out, " ...ignoring")
+		}
+		fmt.Fprintf(p.out, " %s\n", r.Duration.Round(time.Millisecond))
+		if r.Status == gossh.TaskFailed {
+			if r.Err != nil {
+				fmt.Fprintf(p.out, "error: %s\n", r.Err)
+			}
+			writeBlock(p.out, "", r.Stdout)
+			writeBlock(p.out, "stderr: ", r.Stderr)
+		}
+	}
+}
+
+// playbookReport 输出任务文件执行结果：text格式输出PLAY RECAP，json格式输出全部结果，jsonl格式输出各主机的汇总
+//
+//	@author duanzt
+//	@date 2026-10-19 23:44:50
+//	@receiver p *printer
+//	@param report *gossh.PlaybookReport 执行结果
+func (p *printer) playbookReport(report *gossh.PlaybookReport) {
+	switch p.format {
+	case formatText:
+		for _, recap := range report.Recaps {
+			if recap.Unreachable {
+				fmt.Fprintf(p.out, "unreachable: [%s] %s\n", recap.Host.DisplayName(), recap.Err)
+			}
+		}
+		fmt.Fprintln(p.out)
+		report.WriteTo(p.out)
+	case formatJSON:
+		record := playbookRecord{Recaps: make([]recapRecord, 0, len(report.Recaps)), DurationMs: report.Duration.Milliseconds()}
+		for _, recap := range report.Recaps {
+			record.Recaps = append(record.Recaps, newRecapRecord(recap, true))
+		}
+		p.writeJSON(record)
+	case formatJSONL:
+		for _, recap := range report.Recaps {
+			p.writeJSON(newRecapRecord(recap, false))
+		}
+	}
+}
+
+// writeJSON 输出一行json
+//
+//	@author duanzt
+//	@date 2026-10-19 23:32:20
+//	@receiver p *printer
+//	@param v any 内容
+func (p *printer) writeJSON(v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		fmt.Fprintf(p.errOut, "json序列化失败: %s\n", err)
+		return
+	}
+	p.out.Write(append(data, '\n'))
+}
+
+// writeBlock 输出多行内容，每行加前缀（内容为空时不输出，末尾没有换行符时补充）
+//
+//	@author duanzt
+//	@date 2026-10-19 23:32:50
+//	@param w io.Writer 输出
+//	@param prefix string 每行的前缀
+//	@param content string 内容
+func writeBlock(w io.Writer, prefix, content string) {
+	if content == "" {
+		return
+	}
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if line != "" {
+			b.WriteString(prefix + line)
+		}
+	}
+	if !strings.HasSuffix(content, "\n") {
+		b.WriteString("\n")
+	}
+	io.WriteString(w, b.String())
+}
@@ -0,0 +1,59 @@
+//go:build !windows
+
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 23:38:50
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:38:50
+ * @FilePath: resize_unix.go
+ * @Description: 监听终端窗口大小变化（SIGWINCH）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/duanztop/gossh"
+	"golang.org/x/term"
+)
+
+// watchResize 收到SIGWINCH时发送终端的当前大小
+//
+//	@author duanzt
+//	@date 2026-10-19 23:39:20
+//	@param fd int 终端的文件描述符
+//	@return <-chan gossh.WindowSize 窗口大小变化
+//	@return func() 停止监听
+func watchResize(fd int) (<-chan gossh.WindowSize, func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGWINCH)
+	resize := make(chan gossh.WindowSize, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(resize)
+		for {
+			select {
+			case <-signals:
+				width, height, err := term.GetSize(fd)
+				if err != nil {
+					continue
+				}
+				select {
+				case resize <- gossh.WindowSize{Width: width, Height: height}:
+				case <-done:
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return resize, func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
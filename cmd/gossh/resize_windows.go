@@ -0,0 +1,28 @@
+//go:build windows
+
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 23:39:50
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:39:50
+ * @FilePath: resize_windows.go
+ * @Description: 监听终端窗口大小变化（windows不支持SIGWINCH，不发送变化）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package main
+
+import (
+	"github.com/duanztop/gossh"
+)
+
+// watchResize windows不支持SIGWINCH，返回nil（不发送窗口大小变化）
+//
+//	@author duanzt
+//	@date 2026-10-19 23:40:20
+//	@param fd int 终端的文件描述符
+//	@return <-chan gossh.WindowSize 窗口大小变化（nil）
+//	@return func() 停止监听
+func watchResize(fd int) (<-chan gossh.WindowSize, func()) {
+	return nil, func() {}
+}
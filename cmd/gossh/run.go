@@ -0,0 +1,114 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 23:45:20
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:45:20
+ * @FilePath: run.go
+ * @Description: run子命令：在选择的主机（或本机）上执行yaml任务文件
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/duanztop/gossh"
+)
+
+// runUsage run子命令的用法
+const runUsage = `run [选项] 任务文件
+
+在选择的主机上并发执行yaml任务文件（shell、copy、template、fetch步骤），结束时输出各主机的执行汇总，例如：
+  gossh run -i hosts.ini -l web -e version=1.2.0 deploy.yml
+  gossh run -local -o jsonl setup.yml`
+
+// extraVars 可重复指定的额外变量（key=value）
+type extraVars map[string]any
+
+// String 实现flag.Value
+//
+//	@author duanzt
+//	@date 2026-10-19 23:45:50
+//	@receiver v extraVars
+//	@return string 已指定的变量
+func (v extraVars) String() string {
+	var items []string
+	for k, value := range v {
+		items = append(items, fmt.Sprintf("%s=%v", k, value))
+	}
+	sort.Strings(items)
+	return strings.Join(items, " ")
+}
+
+// Set 实现flag.Value，解析key=value
+//
+//	@author duanzt
+//	@date 2026-10-19 23:46:20
+//	@receiver v extraVars
+//	@param value string 参数
+//	@return error 格式不正确时返回
+func (v extraVars) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("变量%q格式不正确，应为key=value", value)
+	}
+	v[strings.TrimSpace(key)] = val
+	return nil
+}
+
+// runPlaybook 执行run子命令
+//
+//	@author duanzt
+//	@date 2026-10-19 23:46:50
+//	@param ctx context.Context 上下文（收到中断信号时结束执行中的命令并停止）
+//	@param args []string 参数
+//	@return error 读取任务文件异常或存在失败的主机时返回
+func runPlaybook(ctx context.Context, args []string) error {
+	fs := newFlagSet("run", runUsage)
+	var hf hostFlags
+	var bf batchFlags
+	hf.register(fs)
+	bf.register(fs)
+	vars := extraVars{}
+	fs.Var(vars, "e", "额外变量，格式为key=value，可重复指定（覆盖任务文件及主机清单中的同名变量）")
+	local := fs.Bool("local", false, "在本机执行（不连接主机）")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usagef("需要一个任务文件")
+	}
+	if *local && (hf.hosts != "" || hf.inventory != "") {
+		return usagef("-local不能与-H、-i同时使用")
+	}
+	p, err := newPrinter(bf.output, os.Stdout, os.Stderr)
+	if err != nil {
+		return err
+	}
+	pb, err := gossh.LoadPlaybook(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	opts := []gossh.PlaybookOption{gossh.WithExtraVars(vars), gossh.WithTaskHandler(p.taskResult)}
+	var report *gossh.PlaybookReport
+	if *local {
+		report, err = gossh.RunPlaybook(ctx, gossh.Local(), pb, opts...)
+	} else {
+		hosts, resolveErr := hf.resolve()
+		if resolveErr != nil {
+			return resolveErr
+		}
+		opts = append(opts, gossh.WithPlaybookFanout(bf.fanoutOptions()...))
+		report, err = gossh.RunPlaybookHosts(ctx, hosts, pb, opts...)
+	}
+	p.playbookReport(report)
+	if err != nil {
+		return errHostsFailed
+	}
+	return nil
+}
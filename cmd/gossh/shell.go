@@ -0,0 +1,107 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 23:37:20
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:37:20
+ * @FilePath: shell.go
+ * @Description: shell子命令：打开交互式shell（伪终端）或执行单条命令
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/duanztop/gossh"
+	"golang.org/x/term"
+)
+
+// shellUsage shell子命令的用法
+const shellUsage = `shell [选项] [主机] [命令...]
+
+连接单台主机并打开交互式shell，标准输入为终端时请求伪终端并同步窗口大小，例如：
+  gossh shell web01
+  gossh shell -J bastion deploy@10.0.0.5:2222
+  gossh shell -H web01 -- top -b -n 1
+指定命令时执行该命令后退出，退出码与远端命令一致`
+
+// runShell 执行shell子命令
+//
+//	@author duanzt
+//	@date 2026-10-19 23:37:50
+//	@param ctx context.Context 上下文
+//	@param args []string 参数
+//	@return error 连接异常或远端命令的退出码不为0时返回
+func runShell(ctx context.Context, args []string) error {
+	fs := newFlagSet("shell", shellUsage)
+	var hf hostFlags
+	hf.register(fs)
+	noPty := fs.Bool("T", false, "不请求伪终端")
+	forcePty := fs.Bool("tty", false, "标准输入不是终端时同样请求伪终端")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	host, rest, err := hf.resolveOne("shell", fs.Args())
+	if err != nil {
+		return err
+	}
+	// gossh shell web01 -- 命令：主机之后的--不会被flag处理
+	if len(rest) > 0 && rest[0] == "--" {
+		rest = rest[1:]
+	}
+	conn, err := gossh.Dial(ctx, host)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	opts := []gossh.ShellOption{gossh.WithShellCommand(strings.Join(rest, " "))}
+	fd := int(os.Stdin.Fd())
+	tty := term.IsTerminal(fd)
+	if !*noPty && (tty || *forcePty) {
+		size := gossh.WindowSize{}
+		if width, height, err := term.GetSize(fd); err == nil {
+			size = gossh.WindowSize{Width: width, Height: height}
+		}
+		opts = append(opts, gossh.WithPty(os.Getenv("TERM"), size))
+		if tty {
+			state, err := term.MakeRaw(fd)
+			if err != nil {
+				return fmt.Errorf("终端切换为raw模式失败: %w", err)
+			}
+			defer term.Restore(fd, state)
+			resize, stop := watchResize(fd)
+			defer stop()
+			opts = append(opts, gossh.WithWindowResize(resize))
+		}
+	}
+	err = conn.Shell(ctx, os.Stdin, os.Stdout, os.Stderr, opts...)
+	if code, ok := exitStatus(err); ok {
+		return &exitError{code: code}
+	}
+	return err
+}
+
+// exitStatus 获取远端（*ssh.ExitError）或本地（*exec.ExitError）命令的退出码
+//
+//	@author duanzt
+//	@date 2026-10-19 23:38:20
+//	@param err error 异常
+//	@return int 退出码
+//	@return bool 是否为命令的退出码
+func exitStatus(err error) (int, bool) {
+	var remote interface{ ExitStatus() int }
+	if errors.As(err, &remote) {
+		return remote.ExitStatus(), true
+	}
+	var local interface{ ExitCode() int }
+	if errors.As(err, &local) && local.ExitCode() >= 0 {
+		return local.ExitCode(), true
+	}
+	return 0, false
+}
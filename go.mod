@@ -1,10 +1,13 @@
 module github.com/duanztop/gossh
 
-go 1.18
+go 1.22
 
 require (
+	github.com/klauspost/compress v1.18.0 // zstd压缩工具包
 	github.com/pkg/sftp v1.13.5 // sftp连接工具包
 	golang.org/x/crypto v0.11.0 // ssh连接工具包
+	golang.org/x/term v0.10.0 // 终端检测工具包
+	gopkg.in/yaml.v3 v3.0.1 // yaml解析工具包
 )
 
 require (
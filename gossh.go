@@ -2,7 +2,7 @@
  * @Author: duanzt
  * @Date: 2023-07-14 10:26:52
  * @LastEditors: duanzt
- * @LastEditTime: 2023-07-17 18:54:52
+ * @LastEditTime: 2026-10-19 23:51:00
  * @FilePath: gossh.go
  * @Description: 暴露文件，提供使用的方法
  *
@@ -11,12 +11,23 @@
 package gossh
 
 import (
+	"context"
+	"io"
+	"io/fs"
 	"strings"
+	"time"
 
 	"github.com/duanztop/gossh/internal"
+	"github.com/duanztop/gossh/internal/batch"
+	"github.com/duanztop/gossh/internal/forward"
+	"github.com/duanztop/gossh/internal/inventory"
+	"github.com/duanztop/gossh/internal/iofs"
 	"github.com/duanztop/gossh/internal/local"
+	"github.com/duanztop/gossh/internal/playbook"
 	"github.com/duanztop/gossh/internal/remote"
+	"github.com/duanztop/gossh/internal/sshconfig"
 	"github.com/duanztop/gossh/internal/tools"
+	"github.com/duanztop/gossh/internal/transfer"
 )
 
 // Remote1 获取远程ssh连接（使用username+password验证方式）
@@ -86,6 +97,27 @@ func RemoteDefault(addr string) (internal.IConnection, error) {
 	return remote.NewConnectionDefault(addr)
 }
 
+// Dial 根据主机连接配置获取ssh连接（配置了密码时使用密码验证，否则使用私钥，均未配置时使用默认私钥），ctx取消或超时时停止连接
+//
+//	@author duanzt
+//	@date 2026-10-19 21:13:30
+//	@param ctx context.Context 上下文
+//	@param host Host 主机连接配置
+//	@return internal.IConnection ssh连接
+//	@return error 连接异常时返回
+func Dial(ctx context.Context, host Host) (internal.IConnection, error) {
+	// 判断addr，如果是ip增加默认后缀:22
+	rightAddr, err := tools.SshAddrTools.SetRightAddr(host.Addr)
+	if err != nil {
+		return nil, err
+	}
+	// 判断ip，如果是本机ip（或127.0.0.1，或localhost）且不经过跳板机，则直接使用本地ssh连接，降低远程ssh连接损耗
+	if host.Jump == "" && tools.IpTools.CheckIpIsLocal(strings.Split(rightAddr, tools.SshAddrTools.GetAddrSplit())[0]) {
+		return local.NewConnection2(rightAddr), nil
+	}
+	return remote.Dial(ctx, host)
+}
+
 // Local 获取本地ssh连接（）
 //
 //	@author duanzt
@@ -94,3 +126,251 @@ func RemoteDefault(addr string) (internal.IConnection, error) {
 func Local() internal.IConnection {
 	return local.NewConnection()
 }
+
+// DirFS 将连接适配为以dir为根目录的io/fs.FS（语义同os.DirFS），
+// 可直接用于fs.WalkDir、fs.Glob、template.ParseFS、http.FS等
+//
+//	@author duanzt
+//	@date 2026-10-19 10:53:36
+//	@param conn internal.IConnection 连接（远程连接基于sftp，本地连接基于os）
+//	@param dir string 根目录
+//	@return fs.FS 实现了fs.ReadDirFS、fs.StatFS、fs.ReadFileFS、fs.SubFS
+func DirFS(conn internal.IConnection, dir string) fs.FS {
+	return iofs.New(conn, dir)
+}
+
+// NewProgressRenderer 创建多进度条渲染，可通过WithProgress接入拷贝方法
+// w为终端时每个传输占一行原地刷新（适配终端宽度），否则定期输出日志行
+//
+//	@author duanzt
+//	@date 2026-10-19 15:22:10
+//	@param w io.Writer 输出目标，例如os.Stderr
+//	@return *ProgressRenderer 多进度条渲染，使用完毕后调用Stop
+func NewProgressRenderer(w io.Writer) *ProgressRenderer {
+	return tools.PorcessOnTools.NewRenderer(w)
+}
+
+// NewRateLimiter 创建令牌桶限速，可通过WithLimiter用于单次拷贝，或通过IConnection.SetRateLimiter用于连接，
+// 同一限速可被多个连接共享（例如批量发布时限制总带宽），并可通过SetRate在传输过程中调整速度
+//
+//	@author duanzt
+//	@date 2026-10-19 16:20:30
+//	@param rate int64 速度，单位：byte/s（不大于0表示不限速）
+//	@param burst int64 允许突发的字节数（不大于0时使用rate）
+//	@return *RateLimiter 限速
+func NewRateLimiter(rate, burst int64) *RateLimiter {
+	return tools.LimiterTools.NewRateLimiter(rate, burst)
+}
+
+// Diff 比较两个连接上的目录（或两个文件），连接可以是任意的本地/远程连接
+// 按摘要报告新增（仅b存在）、删除（仅a存在）及修改的文件，修改的文本文件附带unified差异
+//
+//	@author duanzt
+//	@date 2026-10-19 20:48:10
+//	@param a IConnection 原端连接
+//	@param aPath string 原端路径
+//	@param b IConnection 新端连接
+//	@param bPath string 新端路径
+//	@param opts ...DiffOption 比较配置（摘要算法、上下文行数、文本大小限制、排除）
+//	@return *DiffReport 比较结果，可通过WriteTo输出unified差异
+//	@return error 比较异常时返回
+func Diff(a internal.IConnection, aPath string, b internal.IConnection, bPath string, opts ...DiffOption) (*DiffReport, error) {
+	return transfer.Diff(a, b, aPath, bPath, internal.NewDiffOptions(opts...), nil)
+}
+
+// DiffTo 比较两个连接上的目录（或两个文件），边比较边将unified差异写入w（同diff -ruN，二进制文件输出"Binary files ... differ"）
+//
+//	@author duanzt
+//	@date 2026-10-19 20:48:50
+//	@param w io.Writer 输出目标，例如os.Stdout
+//	@param a IConnection 原端连接
+//	@param aPath string 原端路径
+//	@param b IConnection 新端连接
+//	@param bPath string 新端路径
+//	@param opts ...DiffOption 比较配置
+//	@return *DiffReport 比较结果
+//	@return error 比较或输出异常时返回
+func DiffTo(w io.Writer, a internal.IConnection, aPath string, b internal.IConnection, bPath string, opts ...DiffOption) (*DiffReport, error) {
+	return transfer.Diff(a, b, aPath, bPath, internal.NewDiffOptions(opts...), func(entry *internal.DiffEntry) error {
+		_, err := entry.WriteTo(w)
+		return err
+	})
+}
+
+// Fanout 并发地在多台主机上执行同一条shell命令，返回每台主机的输出、退出码、异常及耗时
+// 默认并发数为10、任一主机失败时继续执行，可配置单台及全局超时、FailFast及按失败百分比中止
+//
+//	@author duanzt
+//	@date 2026-10-19 21:14:10
+//	@param ctx context.Context 上下文（取消时结束执行中的命令，剩余主机不再执行）
+//	@param hosts []Host 主机列表
+//	@param command string shell命令
+//	@param opts ...FanoutOption 多主机执行配置
+//	@return *FanoutReport 执行结果（与主机列表顺序一致）
+//	@return error 存在失败或未执行的主机时返回
+func Fanout(ctx context.Context, hosts []Host, command string, opts ...FanoutOption) (*FanoutReport, error) {
+	o := internal.NewFanoutOptions(append([]FanoutOption{WithDialer(Dial)}, opts...)...)
+	return batch.Fanout(ctx, hosts, internal.ShellTask(command), o)
+}
+
+// FanoutTask 并发地在多台主机上执行自定义任务（例如拷贝文件），配置及结果同Fanout
+//
+//	@author duanzt
+//	@date 2026-10-19 23:16:40
+//	@param ctx context.Context 上下文（取消时结束执行中的任务，剩余主机不再执行）
+//	@param hosts []Host 主机列表
+//	@param task HostTask 在每台主机上执行的任务
+//	@param opts ...FanoutOption 多主机执行配置
+//	@return *FanoutReport 执行结果（与主机列表顺序一致）
+//	@return error 存在失败或未执行的主机时返回
+func FanoutTask(ctx context.Context, hosts []Host, task HostTask, opts ...FanoutOption) (*FanoutReport, error) {
+	o := internal.NewFanoutOptions(append([]FanoutOption{WithDialer(Dial)}, opts...)...)
+	return batch.Fanout(ctx, hosts, task, o)
+}
+
+// Rollout 分批滚动执行任务（例如发布）：每批并发执行前置任务、任务及后置任务，完成后执行健康检查，
+// 失败的主机超过错误预算时停止；配置状态记录文件后，中断的滚动执行可再次调用以从停止处继续
+//
+//	@author duanzt
+//	@date 2026-10-19 21:37:10
+//	@param ctx context.Context 上下文（取消时结束执行中的批次并停止）
+//	@param hosts []Host 主机列表（按顺序分批）
+//	@param task HostTask 在每台主机上执行的任务，执行命令时可使用ShellTask
+//	@param opts ...RolloutOption 滚动执行配置（批次大小、间隔、前置/后置任务、健康检查、错误预算、状态记录文件）
+//	@return *RolloutReport 执行结果
+//	@return error 存在失败主机或停止时返回
+func Rollout(ctx context.Context, hosts []Host, task HostTask, opts ...RolloutOption) (*RolloutReport, error) {
+	o := internal.NewRolloutOptions(append([]RolloutOption{WithRolloutFanout(WithDialer(Dial))}, opts...)...)
+	return batch.Rollout(ctx, hosts, task, o)
+}
+
+// LoadInventory 读取本地主机清单文件（兼容ansible风格的ini及yaml格式，扩展名为.yml、.yaml、.json时按yaml解析，否则按ini解析），
+// 通过Inventory.Hosts按分组或匹配规则获取主机连接配置，可直接用于Dial、Fanout、Rollout
+//
+//	@author duanzt
+//	@date 2026-10-19 22:11:10
+//	@param name string 文件路径
+//	@return *Inventory 主机清单
+//	@return error 读取或解析异常时返回
+func LoadInventory(name string) (*Inventory, error) {
+	return inventory.Load(name)
+}
+
+// ParseInventory 按格式解析主机清单
+//
+//	@author duanzt
+//	@date 2026-10-19 22:11:40
+//	@param data []byte 主机清单内容
+//	@param format InventoryFormat 格式（InventoryINI或InventoryYAML）
+//	@return *Inventory 主机清单
+//	@return error 格式不支持或解析异常时返回
+func ParseInventory(data []byte, format InventoryFormat) (*Inventory, error) {
+	return inventory.Parse(data, format)
+}
+
+// ExpandHostPattern 展开主机名中的范围，例如web[01:03]展开为web01、web02、web03，db[a:c]展开为dba、dbb、dbc，
+// 支持步长（[1:9:2]）及多个范围
+//
+//	@author duanzt
+//	@date 2026-10-19 23:17:10
+//	@param pattern string 主机名
+//	@return []string 展开后的主机名
+//	@return error 范围格式不正确时返回
+func ExpandHostPattern(pattern string) ([]string, error) {
+	return inventory.ExpandHostPattern(pattern)
+}
+
+// LoadPlaybook 读取本地yaml任务文件（shell、copy、template、fetch步骤，支持when、register、loop、notify及handlers），
+// 任务中本地文件的相对路径基于任务文件所在目录
+//
+//	@author duanzt
+//	@date 2026-10-19 22:41:10
+//	@param name string 文件路径
+//	@return *Playbook 任务文件
+//	@return error 读取或解析异常时返回
+func LoadPlaybook(name string) (*Playbook, error) {
+	return playbook.Load(name)
+}
+
+// ParsePlaybook 解析yaml任务文件
+//
+//	@author duanzt
+//	@date 2026-10-19 22:41:40
+//	@param data []byte 任务文件内容
+//	@param dir string 任务中本地文件相对路径的基准目录
+//	@return *Playbook 任务文件
+//	@return error 解析异常时返回
+func ParsePlaybook(data []byte, dir string) (*Playbook, error) {
+	return playbook.Parse(data, dir)
+}
+
+// RunPlaybook 在连接（远程连接或Local()）上执行任务文件，任一任务失败时停止，全部任务成功后执行被通知的handler
+//
+//	@author duanzt
+//	@date 2026-10-19 22:42:10
+//	@param ctx context.Context 上下文（取消时结束执行中的命令并停止）
+//	@param conn internal.IConnection 连接
+//	@param pb *Playbook 任务文件
+//	@param opts ...PlaybookOption 执行配置（额外变量、任务完成时的处理方法）
+//	@return *PlaybookReport 执行结果（包含该连接的执行汇总）
+//	@return error 任务失败时返回
+func RunPlaybook(ctx context.Context, conn internal.IConnection, pb *Playbook, opts ...PlaybookOption) (*PlaybookReport, error) {
+	start := time.Now()
+	host := Host{Name: conn.GetAddr(), Addr: conn.GetAddr()}
+	recap := playbook.Run(ctx, pb, conn, host, internal.NewPlaybookOptions(opts...))
+	report := &PlaybookReport{Recaps: []*Recap{recap}, Duration: time.Since(start)}
+	return report, report.Err()
+}
+
+// RunPlaybookHosts 并发地在多台主机上执行任务文件（主机变量，例如主机清单中的变量，可在任务中引用），
+// 返回各主机的执行汇总，可通过PlaybookReport.WriteTo输出
+//
+//	@author duanzt
+//	@date 2026-10-19 22:42:40
+//	@param ctx context.Context 上下文
+//	@param hosts []Host 主机列表
+//	@param pb *Playbook 任务文件
+//	@param opts ...PlaybookOption 执行配置（额外变量、任务完成时的处理方法、多主机执行配置）
+//	@return *PlaybookReport 执行结果（与主机列表顺序一致）
+//	@return error 存在失败的主机时返回
+func RunPlaybookHosts(ctx context.Context, hosts []Host, pb *Playbook, opts ...PlaybookOption) (*PlaybookReport, error) {
+	o := internal.NewPlaybookOptions(append([]PlaybookOption{WithPlaybookFanout(WithDialer(Dial))}, opts...)...)
+	return playbook.RunHosts(ctx, pb, hosts, o)
+}
+
+// Forward 在连接上开始端口转发（本地转发、远程转发或socks5动态转发），ctx取消或调用Forwarder.Close时停止
+//
+//	@author duanzt
+//	@date 2026-10-19 23:17:40
+//	@param ctx context.Context 上下文（取消时停止转发）
+//	@param conn internal.IConnection 连接
+//	@param spec ForwardSpec 端口转发配置（可通过ParseForwardSpec解析ssh格式的参数）
+//	@param handler func(error) 单个转发连接的异常处理方法（可为nil）
+//	@return *Forwarder 运行中的端口转发
+//	@return error 无法监听时返回
+func Forward(ctx context.Context, conn internal.IConnection, spec ForwardSpec, handler func(error)) (*Forwarder, error) {
+	return forward.Start(ctx, conn, spec, handler)
+}
+
+// LoadSSHConfig 读取openssh客户端配置（为空时读取~/.ssh/config，文件不存在时返回空配置），
+// 通过SSHConfig.Host按主机别名获取主机连接配置（HostName、Port、User、IdentityFile、ProxyJump）
+//
+//	@author duanzt
+//	@date 2026-10-19 23:18:10
+//	@param name string 配置文件路径
+//	@return *SSHConfig 配置
+//	@return error 读取或解析异常时返回
+func LoadSSHConfig(name string) (*SSHConfig, error) {
+	return sshconfig.Load(name)
+}
+
+// ParseSSHConfig 解析openssh客户端配置内容
+//
+//	@author duanzt
+//	@date 2026-10-19 23:18:40
+//	@param data []byte 配置内容
+//	@return *SSHConfig 配置
+//	@return error 解析异常时返回
+func ParseSSHConfig(data []byte) (*SSHConfig, error) {
+	return sshconfig.Parse(data)
+}
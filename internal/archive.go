@@ -0,0 +1,196 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 19:45:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 19:45:10
+ * @FilePath: archive.go
+ * @Description: 归档文件（tar、tar.gz、tar.zst、zip）的创建/解压配置及结果
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ArchiveFormat 归档格式
+type ArchiveFormat string
+
+const (
+
+	// ArchiveTar 不压缩的tar
+	ArchiveTar ArchiveFormat = "tar"
+
+	// ArchiveTarGz gzip压缩的tar（.tar.gz、.tgz）
+	ArchiveTarGz ArchiveFormat = "tar.gz"
+
+	// ArchiveTarZst zstd压缩的tar（.tar.zst、.tzst）
+	ArchiveTarZst ArchiveFormat = "tar.zst"
+
+	// ArchiveZip zip
+	ArchiveZip ArchiveFormat = "zip"
+)
+
+// archiveSuffixes 文件名后缀对应的归档格式（按后缀长度从长到短匹配）
+var archiveSuffixes = []struct {
+	suffix string
+	format ArchiveFormat
+}{
+	{".tar.gz", ArchiveTarGz},
+	{".tar.zst", ArchiveTarZst},
+	{".tgz", ArchiveTarGz},
+	{".tzst", ArchiveTarZst},
+	{".tar", ArchiveTar},
+	{".zip", ArchiveZip},
+}
+
+// DetectArchiveFormat 根据文件名后缀判断归档格式（不区分大小写）
+//
+//	@author duanzt
+//	@date 2026-10-19 19:45:50
+//	@param name string 归档文件路径
+//	@return ArchiveFormat 归档格式
+//	@return error 无法识别时返回
+func DetectArchiveFormat(name string) (ArchiveFormat, error) {
+	lower := strings.ToLower(name)
+	for _, s := range archiveSuffixes {
+		if strings.HasSuffix(lower, s.suffix) {
+			return s.format, nil
+		}
+	}
+	return "", fmt.Errorf("无法识别归档格式: %s", name)
+}
+
+// Compression tar格式对应的压缩算法
+//
+//	@author duanzt
+//	@date 2026-10-19 19:46:20
+//	@receiver f ArchiveFormat
+//	@return Compression 压缩算法（zip及未知格式返回CompressionNone）
+func (f ArchiveFormat) Compression() Compression {
+	switch f {
+	case ArchiveTarGz:
+		return CompressionGzip
+	case ArchiveTarZst:
+		return CompressionZstd
+	}
+	return CompressionNone
+}
+
+// ArchiveOptions 归档配置
+type ArchiveOptions struct {
+	Format          ArchiveFormat // 归档格式（为空时根据文件名后缀判断）
+	StripComponents int           // 解压时去除条目路径的前几级（同tar --strip-components）
+	SameOwner       bool          // 解压时恢复tar条目记录的所属用户及用户组（通常需要root权限）
+	Uid             int           // 解压时修改所属用户/创建时记录的所属用户，小于0时不修改
+	Gid             int           // 解压时修改所属用户组/创建时记录的所属用户组，小于0时不修改
+}
+
+// ArchiveOption 归档配置项
+type ArchiveOption func(*ArchiveOptions)
+
+// NewArchiveOptions 根据配置项生成归档配置，并确定归档格式
+//
+//	@author duanzt
+//	@date 2026-10-19 19:47:02
+//	@param archive string 归档文件路径（未配置格式时根据后缀判断）
+//	@param opts ...ArchiveOption 配置项
+//	@return *ArchiveOptions 归档配置
+//	@return error 无法识别归档格式时返回
+func NewArchiveOptions(archive string, opts ...ArchiveOption) (*ArchiveOptions, error) {
+	o := &ArchiveOptions{Uid: -1, Gid: -1}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	switch o.Format {
+	case "":
+		format, err := DetectArchiveFormat(archive)
+		if err != nil {
+			return nil, err
+		}
+		o.Format = format
+	case ArchiveTar, ArchiveTarGz, ArchiveTarZst, ArchiveZip:
+	default:
+		return nil, fmt.Errorf("不支持的归档格式: %s", o.Format)
+	}
+	if o.StripComponents < 0 {
+		o.StripComponents = 0
+	}
+	return o, nil
+}
+
+// WithArchiveFormat 指定归档格式（文件名后缀无法识别时使用）
+//
+//	@author duanzt
+//	@date 2026-10-19 19:47:40
+//	@param format ArchiveFormat 归档格式
+//	@return ArchiveOption 配置项
+func WithArchiveFormat(format ArchiveFormat) ArchiveOption {
+	return func(o *ArchiveOptions) {
+		o.Format = format
+	}
+}
+
+// WithStripComponents 解压时去除条目路径的前n级（例如去除app-1.0/前缀），去除后为空的条目被忽略
+//
+//	@author duanzt
+//	@date 2026-10-19 19:48:02
+//	@param n int 去除的级数
+//	@return ArchiveOption 配置项
+func WithStripComponents(n int) ArchiveOption {
+	return func(o *ArchiveOptions) {
+		o.StripComponents = n
+	}
+}
+
+// WithSameOwner 解压时恢复tar条目记录的所属用户及用户组（zip不记录所属用户，不生效）
+//
+//	@author duanzt
+//	@date 2026-10-19 19:48:30
+//	@return ArchiveOption 配置项
+func WithSameOwner() ArchiveOption {
+	return func(o *ArchiveOptions) {
+		o.SameOwner = true
+	}
+}
+
+// WithArchiveOwner 解压时将解压出的文件修改为指定的所属用户及用户组；创建tar时作为条目记录的所属用户及用户组
+//
+//	@author duanzt
+//	@date 2026-10-19 19:49:02
+//	@param uid int 用户id，小于0时不修改
+//	@param gid int 用户组id，小于0时不修改
+//	@return ArchiveOption 配置项
+func WithArchiveOwner(uid, gid int) ArchiveOption {
+	return func(o *ArchiveOptions) {
+		o.Uid, o.Gid = uid, gid
+	}
+}
+
+// ArchiveManifest 归档的条目清单
+type ArchiveManifest struct {
+	Paths []string // 条目路径（相对于解压目标目录/创建时的源目录，使用/分隔，目录以/结尾）
+	Files int      // 文件数（包含符号链接及硬链接）
+	Dirs  int      // 目录数
+}
+
+// Add 记录条目
+//
+//	@author duanzt
+//	@date 2026-10-19 19:49:40
+//	@receiver m *ArchiveManifest
+//	@param rel string 相对路径（使用/分隔）
+//	@param dir bool 是否为目录
+func (m *ArchiveManifest) Add(rel string, dir bool) {
+	if dir {
+		m.Paths = append(m.Paths, rel+"/")
+		m.Dirs++
+		return
+	}
+	m.Paths = append(m.Paths, rel)
+	m.Files++
+}
@@ -0,0 +1,177 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 21:09:20
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 21:40:10
+ * @FilePath: fanout.go
+ * @Description: 多主机并发执行（限制并发数、单台及全局超时、失败中止）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package batch
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/duanztop/gossh/internal"
+)
+
+// fanout 多主机执行过程的上下文
+type fanout struct {
+	hosts   []internal.Host
+	task    internal.HostTask
+	o       *internal.FanoutOptions
+	report  *internal.FanoutReport
+	lock    sync.Mutex
+	aborted chan struct{} // 达到中止条件时关闭
+}
+
+// Fanout 并发地在多台主机上建立连接并执行任务，执行完成后关闭连接
+// 任一主机失败且开启FailFast、或失败主机的百分比达到AbortThreshold时，不再执行剩余主机（执行中的主机不受影响）；
+// 全局超时时结束执行中的任务，剩余主机标记为未执行
+//
+//	@author duanzt
+//	@date 2026-10-19 21:10:10
+//	@param ctx context.Context 上下文（取消时同全局超时）
+//	@param hosts []internal.Host 主机列表
+//	@param task internal.HostTask 在每台主机上执行的任务
+//	@param o *internal.FanoutOptions 多主机执行配置
+//	@return *internal.FanoutReport 执行结果（包含全部主机）
+//	@return error 存在失败或未执行的主机时返回
+func Fanout(ctx context.Context, hosts []internal.Host, task internal.HostTask, o *internal.FanoutOptions) (*internal.FanoutReport, error) {
+	start := time.Now()
+	if o.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.Timeout)
+		defer cancel()
+	}
+	f := &fanout{
+		hosts:   hosts,
+		task:    task,
+		o:       o,
+		report:  &internal.FanoutReport{Results: make([]*internal.HostResult, len(hosts))},
+		aborted: make(chan struct{}),
+	}
+	concurrency := o.Concurrency
+	if concurrency <= 0 {
+		concurrency = internal.DefaultConcurrency
+	}
+	if concurrency > len(hosts) {
+		concurrency = len(hosts)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				// 分发与中止同时发生时，已分发的主机同样不再执行
+				if f.isAborted() || ctx.Err() != nil {
+					continue
+				}
+				f.finish(idx, f.run(ctx, hosts[idx]))
+			}
+		}()
+	}
+dispatch:
+	for i := range hosts {
+		select {
+		case jobs <- i:
+		case <-f.aborted:
+			break dispatch
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i, result := range f.report.Results {
+		if result != nil {
+			continue
+		}
+		reason := ctx.Err()
+		if f.isAborted() {
+			reason = internal.ErrFanoutAborted
+		}
+		f.report.Results[i] = &internal.HostResult{Host: hosts[i], ExitCode: -1, Err: reason, Skipped: true}
+		f.report.Skipped++
+	}
+	f.report.Duration = time.Since(start)
+	return f.report, f.report.Err()
+}
+
+// run 在单台主机上建立连接并执行任务
+//
+//	@author duanzt
+//	@date 2026-10-19 21:11:10
+//	@receiver f *fanout
+//	@param ctx context.Context 上下文
+//	@param host internal.Host 主机连接配置
+//	@return *internal.HostResult 执行结果
+func (f *fanout) run(ctx context.Context, host internal.Host) *internal.HostResult {
+	if f.o.HostTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.o.HostTimeout)
+		defer cancel()
+	}
+	start := time.Now()
+	result := &internal.HostResult{Host: host, ExitCode: -1}
+	conn, err := f.o.Dial(ctx, host)
+	if err == nil {
+		var output *internal.CommandResult
+		output, err = f.task(ctx, host, conn)
+		conn.Close()
+		if output != nil {
+			result.Stdout, result.Stderr, result.ExitCode = output.Stdout, output.Stderr, output.ExitCode
+		}
+	}
+	result.Err = err
+	result.Duration = time.Since(start)
+	return result
+}
+
+// finish 记录单台主机的执行结果，失败时检查中止条件
+//
+//	@author duanzt
+//	@date 2026-10-19 21:12:02
+//	@receiver f *fanout
+//	@param idx int 主机在列表中的位置
+//	@param result *internal.HostResult 执行结果
+func (f *fanout) finish(idx int, result *internal.HostResult) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.report.Results[idx] = result
+	if result.Err == nil {
+		f.report.Succeeded++
+	} else {
+		f.report.Failed++
+		threshold := f.o.AbortThreshold > 0 && float64(f.report.Failed)*100 >= f.o.AbortThreshold*float64(len(f.hosts))
+		if (f.o.FailFast || threshold) && !f.report.Aborted {
+			f.report.Aborted = true
+			close(f.aborted)
+		}
+	}
+	if f.o.Handler != nil {
+		f.o.Handler(result)
+	}
+}
+
+// isAborted 是否已达到中止条件
+//
+//	@author duanzt
+//	@date 2026-10-19 21:12:40
+//	@receiver f *fanout
+//	@return bool 是否已中止
+func (f *fanout) isAborted() bool {
+	select {
+	case <-f.aborted:
+		return true
+	default:
+		return false
+	}
+}
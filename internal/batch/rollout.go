@@ -0,0 +1,299 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 21:30:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-20 00:02:40
+ * @FilePath: rollout.go
+ * @Description: 分批滚动执行（按数量或百分比分批、批次间隔、前置/后置任务、健康检查、错误预算及可恢复的状态记录）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/duanztop/gossh/internal"
+)
+
+// Rollout 将主机分批执行任务：每批并发执行（前置任务、任务、后置任务），完成后执行健康检查，
+// 失败的主机数（包含状态记录中此前失败且尚未重试成功的主机）超过错误预算或批次被中断时停止，未超过时间隔Pause后执行下一批；
+// 配置了状态记录文件时每批完成后更新，再次执行时跳过其中已成功的主机
+//
+//	@author duanzt
+//	@date 2026-10-19 21:31:02
+//	@param ctx context.Context 上下文（取消时结束执行中的批次并停止）
+//	@param hosts []internal.Host 主机列表（按顺序分批）
+//	@param task internal.HostTask 在每台主机上执行的任务
+//	@param o *internal.RolloutOptions 滚动执行配置
+//	@return *internal.RolloutReport 执行结果
+//	@return error 读取/保存状态记录异常、存在失败主机或停止时返回
+func Rollout(ctx context.Context, hosts []internal.Host, task internal.HostTask, o *internal.RolloutOptions) (*internal.RolloutReport, error) {
+	start := time.Now()
+	report := &internal.RolloutReport{}
+	state, err := loadRolloutState(o.StateFile)
+	if err != nil {
+		return report, err
+	}
+	report.State = state
+	done := make(map[string]bool, len(state.Done))
+	for _, name := range state.Done {
+		done[name] = true
+	}
+	var pending []internal.Host
+	for _, host := range hosts {
+		if done[host.DisplayName()] {
+			report.Resumed++
+			continue
+		}
+		pending = append(pending, host)
+	}
+	state.Halted = false
+	size := batchSize(len(hosts), o)
+	budget := o.ErrorBudget
+	if o.ErrorBudgetPercent > 0 {
+		budget = int(math.Floor(o.ErrorBudgetPercent * float64(len(hosts)) / 100))
+	}
+
+	for begin := 0; begin < len(pending); begin += size {
+		end := begin + size
+		if end > len(pending) {
+			end = len(pending)
+		}
+		result := runBatch(ctx, pending[begin:end], task, o, state.Batches+1)
+		report.Batches = append(report.Batches, result)
+		report.Failed += result.Failed
+		report.Succeeded += end - begin - result.Failed - result.Skipped
+		updateRolloutState(state, result)
+		// 错误预算按状态记录中失败的主机计算，恢复执行时此前失败且尚未重试成功的主机同样计入
+		if result.Skipped > 0 || len(state.Failed) > budget {
+			report.Halted, state.Halted = true, true
+			report.Remaining = len(pending) - end + result.Skipped
+		}
+		if err := saveRolloutState(o.StateFile, state); err != nil {
+			return report, err
+		}
+		if o.BatchHandler != nil {
+			o.BatchHandler(result)
+		}
+		if report.Halted {
+			break
+		}
+		if end < len(pending) && o.Pause > 0 {
+			select {
+			case <-time.After(o.Pause):
+			case <-ctx.Done():
+				report.Halted, state.Halted = true, true
+				report.Remaining = len(pending) - end
+				if err := saveRolloutState(o.StateFile, state); err != nil {
+					return report, err
+				}
+			}
+			if report.Halted {
+				break
+			}
+		}
+	}
+	report.Duration = time.Since(start)
+	return report, report.Err()
+}
+
+// runBatch 执行一个批次：并发执行任务，再对成功的主机执行健康检查
+//
+//	@author duanzt
+//	@date 2026-10-19 21:32:10
+//	@param ctx context.Context 上下文
+//	@param hosts []internal.Host 该批的主机
+//	@param task internal.HostTask 任务
+//	@param o *internal.RolloutOptions 滚动执行配置
+//	@param index int 批次序号
+//	@return *internal.BatchResult 批次执行结果
+func runBatch(ctx context.Context, hosts []internal.Host, task internal.HostTask, o *internal.RolloutOptions, index int) *internal.BatchResult {
+	fo := *o.Fanout
+	if fo.Concurrency <= 0 {
+		fo.Concurrency = len(hosts)
+	}
+	result := &internal.BatchResult{Index: index}
+	taskReport, _ := Fanout(ctx, hosts, hookedTask(task, o), &fo)
+	result.Results = taskReport.Results
+	result.Failed, result.Skipped = taskReport.Failed, taskReport.Skipped
+	if o.HealthCheck == nil || taskReport.Succeeded == 0 {
+		return result
+	}
+	var healthy []internal.Host
+	for _, r := range taskReport.Results {
+		if r.Err == nil {
+			healthy = append(healthy, r.Host)
+		}
+	}
+	// 批次已被中断时不再检查，成功的主机视为未完成
+	if taskReport.Skipped > 0 {
+		result.Skipped += len(healthy)
+		for _, host := range healthy {
+			result.Health = append(result.Health, &internal.HostResult{Host: host, ExitCode: -1, Err: taskReport.Err(), Skipped: true})
+		}
+		return result
+	}
+	healthReport, _ := Fanout(ctx, healthy, o.HealthCheck, &fo)
+	result.Health = healthReport.Results
+	result.Failed += healthReport.Failed
+	result.Skipped += healthReport.Skipped
+	return result
+}
+
+// hookedTask 组合前置任务、任务及后置任务（输出依次拼接，退出码为最后执行的任务的退出码）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:33:02
+//	@param task internal.HostTask 任务
+//	@param o *internal.RolloutOptions 滚动执行配置
+//	@return internal.HostTask 组合后的任务
+func hookedTask(task internal.HostTask, o *internal.RolloutOptions) internal.HostTask {
+	steps := []struct {
+		name string
+		task internal.HostTask
+	}{{"前置任务", o.PreHook}, {"", task}, {"后置任务", o.PostHook}}
+	return func(ctx context.Context, host internal.Host, conn internal.IConnection) (*internal.CommandResult, error) {
+		combined := &internal.CommandResult{ExitCode: -1}
+		for _, step := range steps {
+			if step.task == nil {
+				continue
+			}
+			output, err := step.task(ctx, host, conn)
+			if output != nil {
+				combined.Stdout += output.Stdout
+				combined.Stderr += output.Stderr
+				combined.ExitCode = output.ExitCode
+			}
+			if err != nil {
+				if step.name != "" {
+					err = fmt.Errorf("%s失败: %w", step.name, err)
+				}
+				return combined, err
+			}
+		}
+		if combined.ExitCode < 0 {
+			combined.ExitCode = 0
+		}
+		return combined, nil
+	}
+}
+
+// batchSize 计算每批的主机数
+//
+//	@author duanzt
+//	@date 2026-10-19 21:33:40
+//	@param total int 全部主机数
+//	@param o *internal.RolloutOptions 滚动执行配置
+//	@return int 每批的主机数（至少为1）
+func batchSize(total int, o *internal.RolloutOptions) int {
+	size := o.BatchSize
+	if o.BatchPercent > 0 {
+		size = int(math.Ceil(o.BatchPercent * float64(total) / 100))
+	}
+	if size < 1 {
+		size = 1
+	}
+	return size
+}
+
+// updateRolloutState 根据批次执行结果更新状态记录（健康检查失败的主机视为失败，未完成的主机保持原状态）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:34:10
+//	@param state *internal.RolloutState 状态记录
+//	@param result *internal.BatchResult 批次执行结果
+func updateRolloutState(state *internal.RolloutState, result *internal.BatchResult) {
+	health := make(map[string]*internal.HostResult, len(result.Health))
+	for _, r := range result.Health {
+		health[r.Host.DisplayName()] = r
+	}
+	failed := make(map[string]bool, len(state.Failed))
+	for _, name := range state.Failed {
+		failed[name] = true
+	}
+	for _, r := range result.Results {
+		name := r.Host.DisplayName()
+		check := health[name]
+		switch {
+		case r.Skipped || (check != nil && check.Skipped):
+		case r.Err != nil || (check != nil && check.Err != nil):
+			failed[name] = true
+		default:
+			delete(failed, name)
+			state.Done = append(state.Done, name)
+		}
+	}
+	state.Failed = state.Failed[:0]
+	for name := range failed {
+		state.Failed = append(state.Failed, name)
+	}
+	sort.Strings(state.Failed)
+	state.Batches = result.Index
+}
+
+// loadRolloutState 读取状态记录文件（未配置或文件不存在时返回空的状态记录）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:34:50
+//	@param name string 状态记录文件路径
+//	@return *internal.RolloutState 状态记录
+//	@return error 读取或解析异常时返回
+func loadRolloutState(name string) (*internal.RolloutState, error) {
+	state := &internal.RolloutState{}
+	if name == "" {
+		return state, nil
+	}
+	data, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("状态记录文件%s解析失败: %w", name, err)
+	}
+	return state, nil
+}
+
+// saveRolloutState 保存状态记录文件（先写入临时文件再重命名，避免中断时文件不完整）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:35:30
+//	@param name string 状态记录文件路径（为空时不保存）
+//	@param state *internal.RolloutState 状态记录
+//	@return error 保存异常时返回
+func saveRolloutState(name string, state *internal.RolloutState) error {
+	state.Updated = time.Now()
+	if name == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
@@ -0,0 +1,72 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 13:30:18
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 13:30:18
+ * @FilePath: checksum.go
+ * @Description: 传输文件的摘要算法及校验异常
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package internal
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+)
+
+// HashAlgorithm 摘要算法
+type HashAlgorithm string
+
+const (
+
+	// HashSHA256 sha256摘要
+	HashSHA256 HashAlgorithm = "sha256"
+
+	// HashSHA512 sha512摘要
+	HashSHA512 HashAlgorithm = "sha512"
+
+	// HashMD5 md5摘要（兼容老旧主机）
+	HashMD5 HashAlgorithm = "md5"
+)
+
+// New 创建摘要计算对象
+//
+//	@author duanzt
+//	@date 2026-10-19 13:31:02
+//	@receiver a HashAlgorithm
+//	@return hash.Hash 摘要计算对象
+//	@return error 不支持该算法时返回
+func (a HashAlgorithm) New() (hash.Hash, error) {
+	switch a {
+	case HashSHA256:
+		return sha256.New(), nil
+	case HashSHA512:
+		return sha512.New(), nil
+	case HashMD5:
+		return md5.New(), nil
+	default:
+		return nil, fmt.Errorf("不支持的摘要算法: %s", string(a))
+	}
+}
+
+// ChecksumError 传输后目标文件的摘要与发送内容不一致
+type ChecksumError struct {
+	Path      string        // 目标文件路径
+	Algorithm HashAlgorithm // 摘要算法
+	Expected  string        // 发送内容的摘要（十六进制）
+	Actual    string        // 目标文件的摘要（十六进制）
+}
+
+// Error 实现error接口
+//
+//	@author duanzt
+//	@date 2026-10-19 13:32:15
+//	@receiver e *ChecksumError
+//	@return string 异常描述
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("%s: %s校验失败，发送内容为%s，目标文件为%s", e.Path, string(e.Algorithm), e.Expected, e.Actual)
+}
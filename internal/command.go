@@ -0,0 +1,18 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 20:56:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 20:56:10
+ * @FilePath: command.go
+ * @Description: 命令执行结果
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package internal
+
+// CommandResult 命令执行结果
+type CommandResult struct {
+	Stdout   string // 标准输出
+	Stderr   string // 错误输出
+	ExitCode int    // 退出码（未能执行、被终止或无法获取时为-1）
+}
@@ -0,0 +1,392 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 11:08:27
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-20 00:01:00
+ * @FilePath: copyoption.go
+ * @Description: 文件/目录拷贝的可选配置
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package internal
+
+import "context"
+
+const (
+
+	// DefaultParallel 并行分块传输的默认并发数
+	DefaultParallel = 4
+
+	// DefaultChunkSize 并行分块传输的默认分块大小
+	DefaultChunkSize int64 = 4 << 20
+)
+
+// SymlinkPolicy 目录拷贝时符号链接的处理策略
+type SymlinkPolicy int
+
+const (
+
+	// SymlinkFollow 跟随符号链接，拷贝链接指向的文件/目录（默认）
+	SymlinkFollow SymlinkPolicy = iota
+
+	// SymlinkPreserve 在目标端重建相同的符号链接
+	SymlinkPreserve
+
+	// SymlinkSkip 跳过符号链接
+	SymlinkSkip
+)
+
+// Protocol 远程连接的文件传输协议
+type Protocol string
+
+const (
+
+	// ProtocolAuto 优先使用sftp，远端未开启sftp子系统时使用scp（默认）
+	ProtocolAuto Protocol = ""
+
+	// ProtocolSFTP 仅使用sftp
+	ProtocolSFTP Protocol = "sftp"
+
+	// ProtocolSCP 仅使用scp（通过exec会话运行远端scp命令）
+	ProtocolSCP Protocol = "scp"
+)
+
+// Compression tar流传输的压缩算法
+type Compression string
+
+const (
+
+	// CompressionNone 不压缩
+	CompressionNone Compression = ""
+
+	// CompressionGzip gzip压缩（远端使用tar -z）
+	CompressionGzip Compression = "gzip"
+
+	// CompressionZstd zstd压缩（远端使用tar --zstd，需要GNU tar 1.31及以上）
+	CompressionZstd Compression = "zstd"
+)
+
+// CopyOptions 拷贝配置
+type CopyOptions struct {
+	Includes      []string      // 只拷贝匹配任一glob的文件（为空表示全部拷贝）
+	Excludes      []string      // 跳过匹配任一glob的文件/目录
+	Symlink       SymlinkPolicy // 符号链接处理策略
+	PreserveMode  bool          // 保留源文件权限
+	Owner         string        // 目标文件所属用户（用户名或uid，为空表示不修改）
+	Group         string        // 目标文件所属用户组（组名或gid，为空表示不修改）
+	PreserveTimes bool          // 保留源文件修改时间
+	FailFast      bool          // 任一文件失败时立即终止（默认继续拷贝其余文件）
+	Resume        bool          // 断点续传（目标文件已有部分内容时从其末尾继续写入）
+	ResumeVerify  int64         // 断点续传前校验已有内容末尾的字节数（0表示仅按大小判断）
+	Verify        HashAlgorithm // 传输后校验目标文件摘要的算法（为空表示不校验）
+	Atomic        bool          // 先写入同目录下的临时文件，完成后再重命名为目标文件
+	Backup        string        // 覆盖前将原目标文件备份为"目标文件+Backup"（为空表示不备份）
+	Progress      IProgress     // 传输进度报告（为nil表示不报告）
+	Parallel      int           // 并行分块传输的并发数（小于2表示顺序传输）
+	ChunkSize     int64         // 并行分块传输的分块大小
+	Limiters      []ILimiter    // 传输限速（同时满足全部限速）
+	Protocol      Protocol      // 远程连接的文件传输协议（本地连接忽略）
+	Tar           bool          // 目录拷贝时以tar流整体传输（本地连接忽略）
+	Compression   Compression   // tar流的压缩算法
+	Checksum      HashAlgorithm // 同步时大小相同的文件再按摘要比较（为空时按大小及修改时间比较）
+	Delete        bool          // 同步时删除目标端多余的文件/目录
+	DryRun        bool          // 同步时仅报告计划的操作，不修改目标端
+
+	Context context.Context // 传输的上下文（取消时停止等待限速，为nil时使用context.Background()）
+}
+
+// CopyOption 拷贝配置项
+type CopyOption func(*CopyOptions)
+
+// NewCopyOptions 根据配置项生成拷贝配置
+//
+//	@author duanzt
+//	@date 2026-10-19 11:09:40
+//	@param opts ...CopyOption 配置项
+//	@return *CopyOptions 拷贝配置
+func NewCopyOptions(opts ...CopyOption) *CopyOptions {
+	o := &CopyOptions{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return o
+}
+
+// Ctx 获取传输的上下文（未配置时为context.Background()）
+//
+//	@author duanzt
+//	@date 2026-10-20 00:00:20
+//	@receiver o *CopyOptions
+//	@return context.Context 上下文
+func (o *CopyOptions) Ctx() context.Context {
+	if o.Context == nil {
+		return context.Background()
+	}
+	return o.Context
+}
+
+// WithInclude 只拷贝匹配任一glob的文件
+// 不含"/"的glob匹配文件名，含"/"的glob匹配相对源目录的路径，"**"匹配任意层目录
+//
+//	@author duanzt
+//	@date 2026-10-19 11:10:22
+//	@param patterns ...string glob列表，例如：*.conf、conf/**/*.yaml
+//	@return CopyOption 配置项
+func WithInclude(patterns ...string) CopyOption {
+	return func(o *CopyOptions) {
+		o.Includes = append(o.Includes, patterns...)
+	}
+}
+
+// WithExclude 跳过匹配任一glob的文件/目录（目录被跳过时不再遍历其子文件），glob规则同WithInclude
+//
+//	@author duanzt
+//	@date 2026-10-19 11:10:58
+//	@param patterns ...string glob列表，例如：.git、*.log、logs/**
+//	@return CopyOption 配置项
+func WithExclude(patterns ...string) CopyOption {
+	return func(o *CopyOptions) {
+		o.Excludes = append(o.Excludes, patterns...)
+	}
+}
+
+// WithSymlinkPolicy 设置符号链接处理策略
+//
+//	@author duanzt
+//	@date 2026-10-19 11:11:31
+//	@param policy SymlinkPolicy 符号链接处理策略
+//	@return CopyOption 配置项
+func WithSymlinkPolicy(policy SymlinkPolicy) CopyOption {
+	return func(o *CopyOptions) {
+		o.Symlink = policy
+	}
+}
+
+// WithPreserveMode 保留源文件权限（拷贝流时需要流实现Stat方法，例如*os.File），mode参数为符号格式时在源文件权限的基础上计算
+//
+//	@author duanzt
+//	@date 2026-10-19 11:12:03
+//	@return CopyOption 配置项
+func WithPreserveMode() CopyOption {
+	return func(o *CopyOptions) {
+		o.PreserveMode = true
+	}
+}
+
+// WithOwner 设置目标文件的所属用户及用户组（需要目标端有相应权限）
+//
+//	@author duanzt
+//	@date 2026-10-19 12:16:25
+//	@param owner string 用户名或uid，为空表示不修改
+//	@param group string 组名或gid，为空表示不修改
+//	@return CopyOption 配置项
+func WithOwner(owner, group string) CopyOption {
+	return func(o *CopyOptions) {
+		o.Owner = owner
+		o.Group = group
+	}
+}
+
+// WithPreserveTimes 保留源文件修改时间
+//
+//	@author duanzt
+//	@date 2026-10-19 11:12:27
+//	@return CopyOption 配置项
+func WithPreserveTimes() CopyOption {
+	return func(o *CopyOptions) {
+		o.PreserveTimes = true
+	}
+}
+
+// WithFailFast 任一文件拷贝失败时立即终止
+//
+//	@author duanzt
+//	@date 2026-10-19 11:12:50
+//	@return CopyOption 配置项
+func WithFailFast() CopyOption {
+	return func(o *CopyOptions) {
+		o.FailFast = true
+	}
+}
+
+// WithResume 开启断点续传：目标文件已存在且不大于源文件时，校验已有内容后从其末尾继续写入，校验失败时从头写入
+// 源流需支持Seek（例如*os.File），否则从头写入；目录同步（SyncDir）时不生效
+//
+//	@author duanzt
+//	@date 2026-10-19 13:10:12
+//	@param verifyBytes int64 校验已有内容末尾的字节数（比对两端sha256，0表示仅按大小判断）
+//	@return CopyOption 配置项
+func WithResume(verifyBytes int64) CopyOption {
+	return func(o *CopyOptions) {
+		o.Resume = true
+		o.ResumeVerify = verifyBytes
+	}
+}
+
+// WithVerify 传输后校验目标文件：发送时计算内容摘要，再计算目标文件摘要进行比对，不一致时返回*ChecksumError
+// 远端文件优先使用sftp的check-file扩展计算摘要，服务端不支持时使用sha256sum等命令
+//
+//	@author duanzt
+//	@date 2026-10-19 13:33:40
+//	@param algo HashAlgorithm 摘要算法（HashSHA256、HashSHA512、HashMD5）
+//	@return CopyOption 配置项
+func WithVerify(algo HashAlgorithm) CopyOption {
+	return func(o *CopyOptions) {
+		o.Verify = algo
+	}
+}
+
+// WithAtomic 原子写入：先写入目标文件同目录下的临时文件，fsync并设置权限及所属用户后再重命名为目标文件，失败时删除临时文件
+// 同时开启断点续传时临时文件名固定且失败时保留，以便下次续传
+//
+//	@author duanzt
+//	@date 2026-10-19 14:10:26
+//	@return CopyOption 配置项
+func WithAtomic() CopyOption {
+	return func(o *CopyOptions) {
+		o.Atomic = true
+	}
+}
+
+// WithBackup 原子写入，并在覆盖前将原目标文件备份（优先使用硬链接，不支持时重命名），同时开启WithAtomic
+//
+//	@author duanzt
+//	@date 2026-10-19 14:11:05
+//	@param suffix string 备份文件后缀，例如.bak（为空时使用.bak）
+//	@return CopyOption 配置项
+func WithBackup(suffix string) CopyOption {
+	return func(o *CopyOptions) {
+		if suffix == "" {
+			suffix = ".bak"
+		}
+		o.Atomic = true
+		o.Backup = suffix
+	}
+}
+
+// WithProgress 报告传输进度（目录拷贝时每个文件分别报告）
+//
+//	@author duanzt
+//	@date 2026-10-19 14:55:18
+//	@param p IProgress 进度报告，例如ProgressFunc(func(info ProgressInfo) {...})
+//	@return CopyOption 配置项
+func WithProgress(p IProgress) CopyOption {
+	return func(o *CopyOptions) {
+		o.Progress = p
+	}
+}
+
+// WithParallel 并行分块传输：大于一个分块的文件按分块拆分后并发读取及写入（远端文件为同一sftp会话上的多个并发请求）
+// 需要源支持随机读取（例如*os.File、*bytes.Reader）且大小已知，否则顺序传输；开启断点续传时顺序传输（并发写入中断后文件中可能存在空洞）
+//
+//	@author duanzt
+//	@date 2026-10-19 15:40:12
+//	@param concurrency int 并发数（小于1时使用DefaultParallel）
+//	@param chunkSize int64 分块大小（小于1时使用DefaultChunkSize）
+//	@return CopyOption 配置项
+func WithParallel(concurrency int, chunkSize int64) CopyOption {
+	return func(o *CopyOptions) {
+		if concurrency < 1 {
+			concurrency = DefaultParallel
+		}
+		if chunkSize < 1 {
+			chunkSize = DefaultChunkSize
+		}
+		o.Parallel = concurrency
+		o.ChunkSize = chunkSize
+	}
+}
+
+// WithLimiter 传输限速（可多次使用，同时满足全部限速），上传及下载均生效
+// 每次拷贝使用新的限速时仅限制本次传输，多个连接共享同一限速时限制总速度
+//
+//	@author duanzt
+//	@date 2026-10-19 16:10:30
+//	@param limiter ILimiter 限速，例如gossh.NewRateLimiter(10<<20, 1<<20)
+//	@return CopyOption 配置项
+func WithLimiter(limiter ILimiter) CopyOption {
+	return func(o *CopyOptions) {
+		if limiter != nil {
+			o.Limiters = append(o.Limiters, limiter)
+		}
+	}
+}
+
+// WithCopyContext 传输的上下文，取消或超时时限速等待立即结束并返回ctx.Err()（例如多主机执行时的单台超时）
+//
+//	@author duanzt
+//	@date 2026-10-20 00:00:40
+//	@param ctx context.Context 上下文
+//	@return CopyOption 配置项
+func WithCopyContext(ctx context.Context) CopyOption {
+	return func(o *CopyOptions) {
+		o.Context = ctx
+	}
+}
+
+// WithProtocol 指定远程连接的文件传输协议（默认优先sftp，sftp子系统不可用时使用scp）
+// 使用scp时不支持断点续传及并行分块传输（从头顺序传输）；scp无法表示符号链接，
+// 上传目录时SymlinkPreserve按SymlinkSkip处理，下载目录时由远端scp跟随符号链接；
+// 上传目录时不支持Owner、Verify及Atomic，未开启WithPreserveMode时已存在的远端文件保持原权限
+//
+//	@author duanzt
+//	@date 2026-10-19 16:42:30
+//	@param protocol Protocol 传输协议（ProtocolAuto、ProtocolSFTP、ProtocolSCP）
+//	@return CopyOption 配置项
+func WithProtocol(protocol Protocol) CopyOption {
+	return func(o *CopyOptions) {
+		o.Protocol = protocol
+	}
+}
+
+// WithTar 目录拷贝时以tar流整体传输，远端运行tar命令打包或解包，本地使用纯Go实现（无需本地tar命令），适合大量小文件
+// 过滤规则、符号链接策略、权限、修改时间、进度（按文件内容字节数报告，每次拷贝报告一次）及限速（按压缩后字节数）均生效；
+// 不支持Owner、Verify、Atomic及断点续传；开启WithPreserveMode或WithPreserveTimes时上传的目录条目会覆盖已存在的远端目录的权限；
+// 源为文件时按普通方式拷贝；下载时由远端打包整个目录，过滤在本地解包时进行
+//
+//	@author duanzt
+//	@date 2026-10-19 17:40:12
+//	@param compression Compression 压缩算法（CompressionNone、CompressionGzip、CompressionZstd）
+//	@return CopyOption 配置项
+func WithTar(compression Compression) CopyOption {
+	return func(o *CopyOptions) {
+		o.Tar = true
+		o.Compression = compression
+	}
+}
+
+// WithChecksum 目录同步时大小相同的文件再按摘要比较（默认按大小及修改时间比较），远端优先使用sftp的check-file扩展
+//
+//	@author duanzt
+//	@date 2026-10-19 18:24:10
+//	@param algo HashAlgorithm 摘要算法
+//	@return CopyOption 配置项
+func WithChecksum(algo HashAlgorithm) CopyOption {
+	return func(o *CopyOptions) {
+		o.Checksum = algo
+	}
+}
+
+// WithDelete 目录同步时删除目标端多余的文件/目录（被排除的文件不会被删除）
+//
+//	@author duanzt
+//	@date 2026-10-19 18:24:40
+//	@return CopyOption 配置项
+func WithDelete() CopyOption {
+	return func(o *CopyOptions) {
+		o.Delete = true
+	}
+}
+
+// WithDryRun 目录同步时仅报告计划的操作（SyncReport.Changes），不修改目标端
+//
+//	@author duanzt
+//	@date 2026-10-19 18:25:05
+//	@return CopyOption 配置项
+func WithDryRun() CopyOption {
+	return func(o *CopyOptions) {
+		o.DryRun = true
+	}
+}
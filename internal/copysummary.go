@@ -0,0 +1,68 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 11:14:05
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 11:14:05
+ * @FilePath: copysummary.go
+ * @Description: 目录拷贝结果汇总
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package internal
+
+import (
+	"fmt"
+)
+
+// CopySummary 目录拷贝结果汇总
+type CopySummary struct {
+	Files    int          // 拷贝成功的文件数
+	Dirs     int          // 创建的目录数
+	Symlinks int          // 重建的符号链接数
+	Skipped  int          // 被过滤或跳过的文件数
+	Bytes    int64        // 拷贝成功的字节数
+	Errors   []*CopyError // 拷贝失败的文件
+}
+
+// CopyError 单个文件的拷贝异常
+type CopyError struct {
+	Path string // 源文件路径
+	Err  error  // 异常信息
+}
+
+// Error 实现error接口
+//
+//	@author duanzt
+//	@date 2026-10-19 11:15:12
+//	@receiver e *CopyError
+//	@return string 异常描述
+func (e *CopyError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+// Unwrap 获取原始异常
+//
+//	@author duanzt
+//	@date 2026-10-19 11:15:30
+//	@receiver e *CopyError
+//	@return error 原始异常
+func (e *CopyError) Unwrap() error {
+	return e.Err
+}
+
+// Err 汇总拷贝异常，全部成功时返回nil
+//
+//	@author duanzt
+//	@date 2026-10-19 11:16:02
+//	@receiver s *CopySummary
+//	@return error 拷贝异常（包装首个失败文件的异常）
+func (s *CopySummary) Err() error {
+	switch len(s.Errors) {
+	case 0:
+		return nil
+	case 1:
+		return s.Errors[0]
+	default:
+		return fmt.Errorf("%d个文件拷贝失败，首个异常：%w", len(s.Errors), s.Errors[0])
+	}
+}
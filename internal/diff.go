@@ -0,0 +1,197 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 20:33:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 20:33:10
+ * @FilePath: diff.go
+ * @Description: 两个连接之间的目录/文件比较结果及配置
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package internal
+
+import (
+	"io"
+)
+
+const (
+
+	// DefaultDiffContext unified差异默认保留的上下文行数
+	DefaultDiffContext = 3
+
+	// DefaultDiffMaxTextSize 默认生成文本差异的最大文件大小（超过时仅按摘要比较）
+	DefaultDiffMaxTextSize = 1 << 20
+)
+
+// DiffStatus 文件的比较结果
+type DiffStatus string
+
+const (
+
+	// DiffAdded 仅新端存在
+	DiffAdded DiffStatus = "added"
+
+	// DiffRemoved 仅原端存在
+	DiffRemoved DiffStatus = "removed"
+
+	// DiffModified 两端均存在但摘要不同
+	DiffModified DiffStatus = "modified"
+)
+
+// DiffEntry 单个有差异的文件
+type DiffEntry struct {
+	Status  DiffStatus // 比较结果
+	Path    string     // 相对于比较根目录的路径（使用/分隔，比较单个文件时为文件名）
+	OldSize int64      // 原端文件大小（不存在时为0）
+	NewSize int64      // 新端文件大小（不存在时为0）
+	OldSum  string     // 原端文件摘要（不存在时为空）
+	NewSum  string     // 新端文件摘要（不存在时为空）
+	Binary  bool       // 二进制文件或超过文本差异的大小限制（不生成文本差异）
+	Unified string     // unified格式的文本差异（Binary时为空）
+}
+
+// WriteTo 输出unified格式的差异（二进制文件输出"Binary files ... differ"）
+//
+//	@author duanzt
+//	@date 2026-10-19 20:34:02
+//	@receiver e *DiffEntry
+//	@param w io.Writer 输出
+//	@return int64 输出的字节数
+//	@return error 输出异常时返回
+func (e *DiffEntry) WriteTo(w io.Writer) (int64, error) {
+	text := e.Unified
+	if e.Binary {
+		oldName, newName := "a/"+e.Path, "b/"+e.Path
+		switch e.Status {
+		case DiffAdded:
+			oldName = "/dev/null"
+		case DiffRemoved:
+			newName = "/dev/null"
+		}
+		text = "Binary files " + oldName + " and " + newName + " differ\n"
+	}
+	n, err := io.WriteString(w, text)
+	return int64(n), err
+}
+
+// DiffReport 比较结果
+type DiffReport struct {
+	OldAddr   string       // 原端连接地址
+	OldRoot   string       // 原端比较的路径
+	NewAddr   string       // 新端连接地址
+	NewRoot   string       // 新端比较的路径
+	Entries   []*DiffEntry // 有差异的文件（按遍历顺序，同一目录下按名称排序）
+	Unchanged int          // 两端相同的文件数
+}
+
+// Count 统计指定比较结果的文件数
+//
+//	@author duanzt
+//	@date 2026-10-19 20:34:40
+//	@receiver r *DiffReport
+//	@param status DiffStatus 比较结果
+//	@return int 文件数
+func (r *DiffReport) Count(status DiffStatus) int {
+	n := 0
+	for _, entry := range r.Entries {
+		if entry.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteTo 依次输出全部文件的unified格式差异
+//
+//	@author duanzt
+//	@date 2026-10-19 20:35:10
+//	@receiver r *DiffReport
+//	@param w io.Writer 输出
+//	@return int64 输出的字节数
+//	@return error 输出异常时返回
+func (r *DiffReport) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	for _, entry := range r.Entries {
+		n, err := entry.WriteTo(w)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// DiffOptions 比较配置
+type DiffOptions struct {
+	Hash        HashAlgorithm // 摘要算法（默认sha256）
+	Context     int           // unified差异保留的上下文行数
+	MaxTextSize int64         // 生成文本差异的最大文件大小，单位：byte
+	Excludes    []string      // 排除的文件/目录（glob，规则同拷贝的exclude）
+}
+
+// DiffOption 比较配置项
+type DiffOption func(*DiffOptions)
+
+// NewDiffOptions 根据配置项生成比较配置
+//
+//	@author duanzt
+//	@date 2026-10-19 20:35:40
+//	@param opts ...DiffOption 配置项
+//	@return *DiffOptions 比较配置
+func NewDiffOptions(opts ...DiffOption) *DiffOptions {
+	o := &DiffOptions{Hash: HashSHA256, Context: DefaultDiffContext, MaxTextSize: DefaultDiffMaxTextSize}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return o
+}
+
+// WithDiffHash 设置比较文件内容使用的摘要算法
+//
+//	@author duanzt
+//	@date 2026-10-19 20:36:10
+//	@param algo HashAlgorithm 摘要算法
+//	@return DiffOption 配置项
+func WithDiffHash(algo HashAlgorithm) DiffOption {
+	return func(o *DiffOptions) {
+		o.Hash = algo
+	}
+}
+
+// WithDiffContext 设置unified差异保留的上下文行数（小于0时为0）
+//
+//	@author duanzt
+//	@date 2026-10-19 20:36:40
+//	@param lines int 上下文行数
+//	@return DiffOption 配置项
+func WithDiffContext(lines int) DiffOption {
+	return func(o *DiffOptions) {
+		o.Context = lines
+	}
+}
+
+// WithDiffMaxTextSize 设置生成文本差异的最大文件大小（超过时仅报告摘要不同）
+//
+//	@author duanzt
+//	@date 2026-10-19 20:37:10
+//	@param size int64 文件大小，单位：byte
+//	@return DiffOption 配置项
+func WithDiffMaxTextSize(size int64) DiffOption {
+	return func(o *DiffOptions) {
+		o.MaxTextSize = size
+	}
+}
+
+// WithDiffExclude 排除匹配glob的文件/目录（可多次配置）
+//
+//	@author duanzt
+//	@date 2026-10-19 20:37:40
+//	@param patterns ...string glob
+//	@return DiffOption 配置项
+func WithDiffExclude(patterns ...string) DiffOption {
+	return func(o *DiffOptions) {
+		o.Excludes = append(o.Excludes, patterns...)
+	}
+}
@@ -0,0 +1,216 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 21:03:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 21:40:10
+ * @FilePath: fanout.go
+ * @Description: 多主机并发执行的结果及配置
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultConcurrency 多主机执行默认的并发数
+const DefaultConcurrency = 10
+
+// ErrFanoutAborted 失败的主机达到中止条件，剩余主机未执行
+var ErrFanoutAborted = errors.New("失败主机达到中止条件，未执行")
+
+// HostTask 在单台主机上执行的任务
+//
+//	@param ctx context.Context 上下文（单台主机超时、全局超时时取消）
+//	@param host Host 主机连接配置
+//	@param conn IConnection 主机的连接
+//	@return *CommandResult 执行结果（可为nil）
+//	@return error 执行异常时返回
+type HostTask func(ctx context.Context, host Host, conn IConnection) (*CommandResult, error)
+
+// ShellTask 生成执行shell命令的任务
+//
+//	@author duanzt
+//	@date 2026-10-19 21:20:10
+//	@param command string shell命令
+//	@return HostTask 任务
+func ShellTask(command string) HostTask {
+	return func(ctx context.Context, host Host, conn IConnection) (*CommandResult, error) {
+		return conn.Run(ctx, command)
+	}
+}
+
+// HostResult 单台主机的执行结果
+type HostResult struct {
+	Host     Host          // 主机连接配置
+	Stdout   string        // 标准输出
+	Stderr   string        // 错误输出
+	ExitCode int           // 退出码（未能执行、被终止或无法获取时为-1）
+	Err      error         // 连接或执行异常（退出码不为0时为*ssh.ExitError或*exec.ExitError）
+	Duration time.Duration // 耗时（包含建立连接）
+	Skipped  bool          // 因中止或全局超时未执行
+}
+
+// Failed 主机是否执行失败（未执行的主机不算失败）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:03:50
+//	@receiver r *HostResult
+//	@return bool 是否执行失败
+func (r *HostResult) Failed() bool {
+	return r.Err != nil && !r.Skipped
+}
+
+// FanoutReport 多主机执行结果
+type FanoutReport struct {
+	Results   []*HostResult // 各主机的执行结果（与主机列表顺序一致）
+	Succeeded int           // 执行成功的主机数
+	Failed    int           // 执行失败的主机数
+	Skipped   int           // 未执行的主机数
+	Aborted   bool          // 是否因失败主机达到中止条件而停止
+	Duration  time.Duration // 总耗时
+}
+
+// Err 汇总执行异常，全部成功时返回nil
+//
+//	@author duanzt
+//	@date 2026-10-19 21:04:30
+//	@receiver r *FanoutReport
+//	@return error 执行异常（包装首个失败主机的异常，没有失败主机时为首个未执行主机的原因）
+func (r *FanoutReport) Err() error {
+	if r.Failed == 0 && r.Skipped == 0 {
+		return nil
+	}
+	var first *HostResult
+	for _, result := range r.Results {
+		if result.Failed() {
+			first = result
+			break
+		}
+		if first == nil && result.Skipped {
+			first = result
+		}
+	}
+	return fmt.Errorf("%d台主机执行失败，%d台未执行，首个异常：%s: %w", r.Failed, r.Skipped, first.Host.DisplayName(), first.Err)
+}
+
+// FanoutOptions 多主机执行配置
+type FanoutOptions struct {
+	Concurrency    int               // 同时执行的主机数
+	HostTimeout    time.Duration     // 单台主机的超时时间（包含建立连接，不大于0表示不限制）
+	Timeout        time.Duration     // 全局超时时间（超时时结束执行中的命令，剩余主机不再执行）
+	FailFast       bool              // 任一主机失败时停止执行剩余主机（执行中的主机不受影响）
+	AbortThreshold float64           // 失败主机数占全部主机的百分比达到该值时停止执行剩余主机（不大于0表示不限制）
+	Dial           DialFunc          // 建立连接的方法
+	Handler        func(*HostResult) // 每台主机执行完成时调用（串行调用，可用于实时输出）
+}
+
+// FanoutOption 多主机执行配置项
+type FanoutOption func(*FanoutOptions)
+
+// NewFanoutOptions 根据配置项生成多主机执行配置
+//
+//	@author duanzt
+//	@date 2026-10-19 21:05:10
+//	@param opts ...FanoutOption 配置项
+//	@return *FanoutOptions 多主机执行配置
+func NewFanoutOptions(opts ...FanoutOption) *FanoutOptions {
+	o := &FanoutOptions{Concurrency: DefaultConcurrency}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return o
+}
+
+// WithConcurrency 设置同时执行的主机数（不大于0时使用默认值）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:05:40
+//	@param n int 并发数
+//	@return FanoutOption 配置项
+func WithConcurrency(n int) FanoutOption {
+	return func(o *FanoutOptions) {
+		if n > 0 {
+			o.Concurrency = n
+		}
+	}
+}
+
+// WithHostTimeout 设置单台主机的超时时间（包含建立连接）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:06:10
+//	@param timeout time.Duration 超时时间
+//	@return FanoutOption 配置项
+func WithHostTimeout(timeout time.Duration) FanoutOption {
+	return func(o *FanoutOptions) {
+		o.HostTimeout = timeout
+	}
+}
+
+// WithFanoutTimeout 设置全局超时时间
+//
+//	@author duanzt
+//	@date 2026-10-19 21:06:40
+//	@param timeout time.Duration 超时时间
+//	@return FanoutOption 配置项
+func WithFanoutTimeout(timeout time.Duration) FanoutOption {
+	return func(o *FanoutOptions) {
+		o.Timeout = timeout
+	}
+}
+
+// WithFanoutFailFast 任一主机失败时停止执行剩余主机（默认继续执行）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:07:10
+//	@return FanoutOption 配置项
+func WithFanoutFailFast() FanoutOption {
+	return func(o *FanoutOptions) {
+		o.FailFast = true
+	}
+}
+
+// WithAbortThreshold 失败主机数占全部主机的百分比达到percent时停止执行剩余主机
+//
+//	@author duanzt
+//	@date 2026-10-19 21:07:40
+//	@param percent float64 百分比，例如10表示10%
+//	@return FanoutOption 配置项
+func WithAbortThreshold(percent float64) FanoutOption {
+	return func(o *FanoutOptions) {
+		o.AbortThreshold = percent
+	}
+}
+
+// WithDialer 设置建立连接的方法（默认根据主机连接配置建立ssh连接）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:08:10
+//	@param dial DialFunc 建立连接的方法
+//	@return FanoutOption 配置项
+func WithDialer(dial DialFunc) FanoutOption {
+	return func(o *FanoutOptions) {
+		if dial != nil {
+			o.Dial = dial
+		}
+	}
+}
+
+// WithResultHandler 设置每台主机执行完成时的处理方法
+//
+//	@author duanzt
+//	@date 2026-10-19 21:08:40
+//	@param handler func(*HostResult) 处理方法
+//	@return FanoutOption 配置项
+func WithResultHandler(handler func(*HostResult)) FanoutOption {
+	return func(o *FanoutOptions) {
+		o.Handler = handler
+	}
+}
@@ -0,0 +1,207 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 19:20:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 19:20:10
+ * @FilePath: find.go
+ * @Description: 查找文件（类似find命令）的结果及过滤条件
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package internal
+
+import (
+	"io/fs"
+	"path"
+	"time"
+)
+
+// FindType 查找的文件类型
+type FindType string
+
+const (
+
+	// FindAny 不限类型（默认）
+	FindAny FindType = ""
+
+	// FindFile 普通文件
+	FindFile FindType = "f"
+
+	// FindDir 目录
+	FindDir FindType = "d"
+
+	// FindSymlink 符号链接
+	FindSymlink FindType = "l"
+)
+
+// FindEntry 查找到的文件
+type FindEntry struct {
+	Path        string // 完整路径（查找根目录 + 相对路径，使用/分隔）
+	Depth       int    // 相对于查找根目录的深度（根目录为0）
+	fs.FileInfo        // 文件信息（不跟随符号链接）
+}
+
+// FindOptions 查找条件（多个条件同时满足时才返回）
+type FindOptions struct {
+	Names          []string  // 文件名通配符（匹配任意一个即可，语法同path.Match）
+	Type           FindType  // 文件类型
+	MinSize        int64     // 最小文件大小（包含），单位：byte，小于等于0时不限制
+	MaxSize        int64     // 最大文件大小（包含），单位：byte，小于0时不限制
+	ModifiedAfter  time.Time // 修改时间晚于该时间，零值时不限制
+	ModifiedBefore time.Time // 修改时间早于该时间，零值时不限制
+	MinDepth       int       // 最小深度（根目录为0）
+	MaxDepth       int       // 最大深度，小于0时不限制
+}
+
+// FindOption 查找条件配置项
+type FindOption func(*FindOptions)
+
+// NewFindOptions 根据配置项生成查找条件
+//
+//	@author duanzt
+//	@date 2026-10-19 19:20:50
+//	@param opts ...FindOption 配置项
+//	@return *FindOptions 查找条件
+func NewFindOptions(opts ...FindOption) *FindOptions {
+	o := &FindOptions{MaxSize: -1, MaxDepth: -1}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return o
+}
+
+// Match 判断文件是否满足查找条件（深度之外的条件）
+//
+//	@author duanzt
+//	@date 2026-10-19 19:21:30
+//	@receiver o *FindOptions
+//	@param info fs.FileInfo 文件信息（不跟随符号链接）
+//	@return bool 是否满足
+func (o *FindOptions) Match(info fs.FileInfo) bool {
+	if len(o.Names) > 0 {
+		matched := false
+		for _, pattern := range o.Names {
+			if ok, _ := path.Match(pattern, info.Name()); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	mode := info.Mode()
+	switch o.Type {
+	case FindFile:
+		if !mode.IsRegular() {
+			return false
+		}
+	case FindDir:
+		if !mode.IsDir() {
+			return false
+		}
+	case FindSymlink:
+		if mode&fs.ModeSymlink == 0 {
+			return false
+		}
+	}
+	if o.MinSize > 0 && info.Size() < o.MinSize {
+		return false
+	}
+	if o.MaxSize >= 0 && info.Size() > o.MaxSize {
+		return false
+	}
+	if !o.ModifiedAfter.IsZero() && !info.ModTime().After(o.ModifiedAfter) {
+		return false
+	}
+	if !o.ModifiedBefore.IsZero() && !info.ModTime().Before(o.ModifiedBefore) {
+		return false
+	}
+	return true
+}
+
+// WithName 按文件名通配符过滤（例如*.log，可多次配置，匹配任意一个即可）
+//
+//	@author duanzt
+//	@date 2026-10-19 19:22:10
+//	@param patterns ...string 文件名通配符
+//	@return FindOption 配置项
+func WithName(patterns ...string) FindOption {
+	return func(o *FindOptions) {
+		o.Names = append(o.Names, patterns...)
+	}
+}
+
+// WithType 按文件类型过滤
+//
+//	@author duanzt
+//	@date 2026-10-19 19:22:30
+//	@param typ FindType 文件类型
+//	@return FindOption 配置项
+func WithType(typ FindType) FindOption {
+	return func(o *FindOptions) {
+		o.Type = typ
+	}
+}
+
+// WithSize 按文件大小过滤（包含边界）
+//
+//	@author duanzt
+//	@date 2026-10-19 19:22:50
+//	@param minSize int64 最小文件大小，单位：byte，小于等于0时不限制
+//	@param maxSize int64 最大文件大小，单位：byte，小于0时不限制
+//	@return FindOption 配置项
+func WithSize(minSize, maxSize int64) FindOption {
+	return func(o *FindOptions) {
+		o.MinSize, o.MaxSize = minSize, maxSize
+	}
+}
+
+// WithModifiedAfter 仅查找修改时间晚于指定时间的文件
+//
+//	@author duanzt
+//	@date 2026-10-19 19:23:10
+//	@param t time.Time 时间
+//	@return FindOption 配置项
+func WithModifiedAfter(t time.Time) FindOption {
+	return func(o *FindOptions) {
+		o.ModifiedAfter = t
+	}
+}
+
+// WithModifiedBefore 仅查找修改时间早于指定时间的文件
+//
+//	@author duanzt
+//	@date 2026-10-19 19:23:30
+//	@param t time.Time 时间
+//	@return FindOption 配置项
+func WithModifiedBefore(t time.Time) FindOption {
+	return func(o *FindOptions) {
+		o.ModifiedBefore = t
+	}
+}
+
+// WithOlderThan 仅查找修改时间在指定时长之前的文件（例如7*24*time.Hour，相对于配置时的当前时间）
+//
+//	@author duanzt
+//	@date 2026-10-19 19:23:50
+//	@param d time.Duration 时长
+//	@return FindOption 配置项
+func WithOlderThan(d time.Duration) FindOption {
+	return WithModifiedBefore(time.Now().Add(-d))
+}
+
+// WithDepth 按相对于查找根目录的深度过滤（根目录为0，直接子项为1）
+//
+//	@author duanzt
+//	@date 2026-10-19 19:24:10
+//	@param minDepth int 最小深度
+//	@param maxDepth int 最大深度，小于0时不限制
+//	@return FindOption 配置项
+func WithDepth(minDepth, maxDepth int) FindOption {
+	return func(o *FindOptions) {
+		o.MinDepth, o.MaxDepth = minDepth, maxDepth
+	}
+}
@@ -0,0 +1,79 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 18:40:05
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 18:40:05
+ * @FilePath: follow.go
+ * @Description: 跟踪文件新增内容（类似tail -F）的结果及可选配置
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package internal
+
+import (
+	"time"
+)
+
+// DefaultFollowInterval 本地连接检查文件新增内容、轮转及截断的默认间隔
+const DefaultFollowInterval = 250 * time.Millisecond
+
+// FollowLine 跟踪到的一行内容
+type FollowLine struct {
+	Host string    // 连接地址（GetAddr）
+	Path string    // 文件路径
+	Text string    // 行内容（不含换行符）
+	Time time.Time // 读取到该行的时间
+}
+
+// FollowFunc 处理跟踪到的行（在跟踪的goroutine中同步调用）
+type FollowFunc func(line *FollowLine)
+
+// FollowOptions 跟踪配置
+type FollowOptions struct {
+	FromStart bool          // 从文件开头读取（默认只读取开始跟踪后新增的行）
+	Interval  time.Duration // 本地连接的检查间隔（远程连接由tail命令处理）
+}
+
+// FollowOption 跟踪配置项
+type FollowOption func(*FollowOptions)
+
+// NewFollowOptions 根据配置项生成跟踪配置
+//
+//	@author duanzt
+//	@date 2026-10-19 18:40:50
+//	@param opts ...FollowOption 配置项
+//	@return *FollowOptions 跟踪配置
+func NewFollowOptions(opts ...FollowOption) *FollowOptions {
+	o := &FollowOptions{Interval: DefaultFollowInterval}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return o
+}
+
+// WithFromStart 从文件开头读取已有内容后继续跟踪
+//
+//	@author duanzt
+//	@date 2026-10-19 18:41:20
+//	@return FollowOption 配置项
+func WithFromStart() FollowOption {
+	return func(o *FollowOptions) {
+		o.FromStart = true
+	}
+}
+
+// WithFollowInterval 设置本地连接检查文件新增内容、轮转及截断的间隔（小于等于0时使用默认值）
+//
+//	@author duanzt
+//	@date 2026-10-19 18:41:50
+//	@param interval time.Duration 检查间隔
+//	@return FollowOption 配置项
+func WithFollowInterval(interval time.Duration) FollowOption {
+	return func(o *FollowOptions) {
+		if interval > 0 {
+			o.Interval = interval
+		}
+	}
+}
@@ -0,0 +1,146 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 22:58:40
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 22:58:40
+ * @FilePath: forward.go
+ * @Description: 端口转发配置（同ssh的-L、-R、-D参数）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package internal
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ForwardKind 端口转发类型
+type ForwardKind string
+
+const (
+
+	// ForwardLocal 本地转发（-L）：在本地监听，经连接访问目标地址
+	ForwardLocal ForwardKind = "L"
+
+	// ForwardRemote 远程转发（-R）：在连接所在主机上监听，从本地访问目标地址
+	ForwardRemote ForwardKind = "R"
+
+	// ForwardDynamic 动态转发（-D）：在本地监听socks5代理，经连接访问客户端请求的地址
+	ForwardDynamic ForwardKind = "D"
+
+	// defaultForwardBind 未指定监听地址时默认的监听地址
+	defaultForwardBind = "127.0.0.1"
+)
+
+// ForwardSpec 端口转发配置
+type ForwardSpec struct {
+	Kind   ForwardKind // 转发类型
+	Listen string      // 监听地址，例如：127.0.0.1:8080（远程转发时为连接所在主机上的地址）
+	Target string      // 目标地址，例如：10.0.0.5:3306（动态转发时为空）
+}
+
+// String 转换为ssh参数格式，例如：-L 127.0.0.1:8080:10.0.0.5:80
+//
+//	@author duanzt
+//	@date 2026-10-19 22:59:10
+//	@receiver s ForwardSpec
+//	@return string ssh参数格式
+func (s ForwardSpec) String() string {
+	if s.Target == "" {
+		return "-" + string(s.Kind) + " " + s.Listen
+	}
+	return "-" + string(s.Kind) + " " + s.Listen + ":" + s.Target
+}
+
+// ParseForwardSpec 解析ssh格式的端口转发参数：
+// -L、-R为[bind_address:]port:host:hostport，-D为[bind_address:]port，ipv6地址使用[]包裹，
+// 未指定bind_address时监听127.0.0.1，bind_address为*时监听全部地址
+//
+//	@author duanzt
+//	@date 2026-10-19 22:59:50
+//	@param kind ForwardKind 转发类型
+//	@param spec string 转发参数
+//	@return ForwardSpec 端口转发配置
+//	@return error 格式不正确时返回
+func ParseForwardSpec(kind ForwardKind, spec string) (ForwardSpec, error) {
+	fields := splitForwardSpec(spec)
+	result := ForwardSpec{Kind: kind}
+	switch kind {
+	case ForwardLocal, ForwardRemote:
+		if len(fields) != 3 && len(fields) != 4 {
+			return result, fmt.Errorf("-%s参数%q格式不正确，应为[bind_address:]port:host:hostport", kind, spec)
+		}
+		host, port := fields[len(fields)-2], fields[len(fields)-1]
+		if host == "" || !validPort(port, false) {
+			return result, fmt.Errorf("-%s参数%q的目标地址不正确", kind, spec)
+		}
+		result.Target = net.JoinHostPort(host, port)
+		fields = fields[:len(fields)-2]
+	case ForwardDynamic:
+		if len(fields) != 1 && len(fields) != 2 {
+			return result, fmt.Errorf("-D参数%q格式不正确，应为[bind_address:]port", spec)
+		}
+	default:
+		return result, fmt.Errorf("不支持的转发类型%q", kind)
+	}
+	bind, port := defaultForwardBind, fields[len(fields)-1]
+	if len(fields) == 2 {
+		bind = fields[0]
+	}
+	switch bind {
+	case "*":
+		bind = ""
+	case "localhost":
+		bind = defaultForwardBind
+	}
+	// 远程转发允许端口为0（由sshd分配）
+	if !validPort(port, kind == ForwardRemote) {
+		return result, fmt.Errorf("-%s参数%q的监听端口不正确", kind, spec)
+	}
+	result.Listen = net.JoinHostPort(bind, port)
+	return result, nil
+}
+
+// splitForwardSpec 以冒号拆分转发参数（[]内的冒号不拆分，并去掉[]）
+//
+//	@author duanzt
+//	@date 2026-10-19 23:00:30
+//	@param spec string 转发参数
+//	@return []string 拆分结果
+func splitForwardSpec(spec string) []string {
+	var fields []string
+	var b strings.Builder
+	bracket := false
+	for _, r := range spec {
+		switch {
+		case r == '[' && !bracket:
+			bracket = true
+		case r == ']' && bracket:
+			bracket = false
+		case r == ':' && !bracket:
+			fields = append(fields, b.String())
+			b.Reset()
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return append(fields, b.String())
+}
+
+// validPort 校验端口
+//
+//	@author duanzt
+//	@date 2026-10-19 23:01:00
+//	@param port string 端口
+//	@param allowZero bool 是否允许0
+//	@return bool 是否合法
+func validPort(port string, allowZero bool) bool {
+	n, err := strconv.Atoi(port)
+	if err != nil {
+		return false
+	}
+	return n > 0 && n <= 65535 || allowZero && n == 0
+}
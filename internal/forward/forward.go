@@ -0,0 +1,250 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 23:01:40
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:56:30
+ * @FilePath: forward.go
+ * @Description: 端口转发（本地转发、远程转发、socks5动态转发）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package forward
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"github.com/duanztop/gossh/internal"
+)
+
+// Forwarder 运行中的端口转发
+type Forwarder struct {
+	spec     internal.ForwardSpec
+	conn     internal.IConnection
+	listener net.Listener
+	handler  func(error)        // 单个转发连接的异常处理方法（可为nil）
+	accepted atomic.Int64       // 已接受的连接数
+	lock     sync.Mutex         // 保护active、closed
+	active   map[net.Conn]bool  // 转发中的连接（停止时关闭）
+	closed   bool               // 是否已停止
+	wg       sync.WaitGroup     // 等待转发中的连接结束
+	done     chan struct{}      // 停止监听时关闭
+	stop     context.CancelFunc // 停止转发
+}
+
+// Start 开始端口转发：本地转发、动态转发在本地监听，远程转发通过conn.Listen在连接所在主机上监听；
+// ctx取消、调用Close或连接断开时停止
+//
+//	@author duanzt
+//	@date 2026-10-19 23:02:20
+//	@param ctx context.Context 上下文（取消时停止转发）
+//	@param conn internal.IConnection 连接
+//	@param spec internal.ForwardSpec 端口转发配置
+//	@param handler func(error) 单个转发连接的异常处理方法（例如目标地址无法连接，可为nil）
+//	@return *Forwarder 运行中的端口转发
+//	@return error 无法监听时返回
+func Start(ctx context.Context, conn internal.IConnection, spec internal.ForwardSpec, handler func(error)) (*Forwarder, error) {
+	var listener net.Listener
+	var err error
+	switch spec.Kind {
+	case internal.ForwardLocal, internal.ForwardDynamic:
+		listener, err = net.Listen("tcp", spec.Listen)
+	case internal.ForwardRemote:
+		listener, err = conn.Listen("tcp", spec.Listen)
+	default:
+		return nil, fmt.Errorf("不支持的转发类型%q", spec.Kind)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s监听失败: %w", spec, err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	f := &Forwarder{
+		spec:     spec,
+		conn:     conn,
+		listener: listener,
+		handler:  handler,
+		active:   map[net.Conn]bool{},
+		done:     make(chan struct{}),
+		stop:     cancel,
+	}
+	go func() {
+		// 连接断开后本地监听无法再转发，同样停止
+		select {
+		case <-ctx.Done():
+		case <-conn.Done():
+			cancel()
+		}
+		_ = listener.Close()
+		f.lock.Lock()
+		f.closed = true
+		for c := range f.active {
+			_ = c.Close()
+		}
+		f.lock.Unlock()
+	}()
+	go f.serve()
+	return f, nil
+}
+
+// Spec 获取端口转发配置
+//
+//	@author duanzt
+//	@date 2026-10-19 23:03:00
+//	@receiver f *Forwarder
+//	@return internal.ForwardSpec 端口转发配置
+func (f *Forwarder) Spec() internal.ForwardSpec {
+	return f.spec
+}
+
+// Addr 获取实际监听的地址（监听端口为0时可获取分配的端口）
+//
+//	@author duanzt
+//	@date 2026-10-19 23:03:30
+//	@receiver f *Forwarder
+//	@return net.Addr 监听地址
+func (f *Forwarder) Addr() net.Addr {
+	return f.listener.Addr()
+}
+
+// Accepted 获取已接受的连接数
+//
+//	@author duanzt
+//	@date 2026-10-19 23:04:00
+//	@receiver f *Forwarder
+//	@return int64 连接数
+func (f *Forwarder) Accepted() int64 {
+	return f.accepted.Load()
+}
+
+// Done 停止监听时关闭的通道（连接断开时同样关闭）
+//
+//	@author duanzt
+//	@date 2026-10-19 23:04:30
+//	@receiver f *Forwarder
+//	@return <-chan struct{} 通道
+func (f *Forwarder) Done() <-chan struct{} {
+	return f.done
+}
+
+// Close 停止监听并关闭转发中的连接，等待全部连接结束后返回
+//
+//	@author duanzt
+//	@date 2026-10-19 23:05:00
+//	@receiver f *Forwarder
+//	@return error 始终为nil
+func (f *Forwarder) Close() error {
+	f.stop()
+	<-f.done
+	f.wg.Wait()
+	return nil
+}
+
+// serve 接受连接并转发
+//
+//	@author duanzt
+//	@date 2026-10-19 23:05:30
+//	@receiver f *Forwarder
+func (f *Forwarder) serve() {
+	defer close(f.done)
+	defer f.stop()
+	for {
+		c, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		f.accepted.Add(1)
+		if !f.track(c, true) {
+			_ = c.Close()
+			return
+		}
+		f.wg.Add(1)
+		go func() {
+			defer f.wg.Done()
+			defer f.track(c, false)
+			if err := f.handle(c); err != nil && f.handler != nil {
+				f.handler(fmt.Errorf("%s: %w", f.spec, err))
+			}
+		}()
+	}
+}
+
+// track 记录或移除转发中的连接
+//
+//	@author duanzt
+//	@date 2026-10-19 23:06:00
+//	@receiver f *Forwarder
+//	@param c net.Conn 连接
+//	@param add bool 是否为记录
+//	@return bool 记录时转发已停止返回false
+func (f *Forwarder) track(c net.Conn, add bool) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if !add {
+		delete(f.active, c)
+		return true
+	}
+	if f.closed {
+		return false
+	}
+	f.active[c] = true
+	return true
+}
+
+// handle 转发单个连接
+//
+//	@author duanzt
+//	@date 2026-10-19 23:06:30
+//	@receiver f *Forwarder
+//	@param c net.Conn 接受的连接
+//	@return error 无法连接目标地址时返回
+func (f *Forwarder) handle(c net.Conn) error {
+	defer c.Close()
+	var target net.Conn
+	var err error
+	switch f.spec.Kind {
+	case internal.ForwardLocal:
+		target, err = f.conn.Dial("tcp", f.spec.Target)
+	case internal.ForwardRemote:
+		target, err = net.Dial("tcp", f.spec.Target)
+	case internal.ForwardDynamic:
+		target, err = socks5(c, f.conn.Dial)
+	}
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+	if !f.track(target, true) {
+		return nil
+	}
+	defer f.track(target, false)
+	return pipe(c, target)
+}
+
+// pipe 双向拷贝数据，一个方向结束时关闭另一端的写入，两个方向都结束后返回
+//
+//	@author duanzt
+//	@date 2026-10-19 23:07:00
+//	@param a net.Conn 连接
+//	@param b net.Conn 连接
+//	@return error 始终为nil（连接被关闭属于正常结束）
+func pipe(a, b net.Conn) error {
+	var wg sync.WaitGroup
+	copyHalf := func(dst, src net.Conn) {
+		defer wg.Done()
+		_, _ = io.Copy(dst, src)
+		if cw, ok := dst.(interface{ CloseWrite() error }); ok {
+			_ = cw.CloseWrite()
+		} else {
+			_ = dst.Close()
+		}
+	}
+	wg.Add(2)
+	go copyHalf(a, b)
+	go copyHalf(b, a)
+	wg.Wait()
+	return nil
+}
@@ -0,0 +1,143 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 23:07:40
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:07:40
+ * @FilePath: socks5.go
+ * @Description: socks5代理握手（无认证，仅支持CONNECT）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package forward
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+
+	// socks5协议常量（RFC 1928）
+	socksVersion        = 5
+	socksNoAuth         = 0
+	socksNoAcceptable   = 0xff
+	socksConnect        = 1
+	socksAtypIPv4       = 1
+	socksAtypDomain     = 3
+	socksAtypIPv6       = 4
+	socksSucceeded      = 0
+	socksGeneralFail    = 1
+	socksCmdNotSupport  = 7
+	socksAtypNotSupport = 8
+
+	// socksHandshakeTimeout 握手的超时时间
+	socksHandshakeTimeout = 30 * time.Second
+)
+
+// errSocks socks5握手失败
+var errSocks = errors.New("socks5握手失败")
+
+// socks5 完成socks5握手，通过dial连接客户端请求的地址
+//
+//	@author duanzt
+//	@date 2026-10-19 23:08:20
+//	@param c net.Conn 客户端连接
+//	@param dial func(network, addr string) (net.Conn, error) 建立连接的方法
+//	@return net.Conn 目标连接
+//	@return error 握手失败或无法连接目标地址时返回
+func socks5(c net.Conn, dial func(network, addr string) (net.Conn, error)) (net.Conn, error) {
+	_ = c.SetDeadline(time.Now().Add(socksHandshakeTimeout))
+	defer c.SetDeadline(time.Time{})
+
+	// 协商认证方式：版本、方法数、方法列表
+	head := make([]byte, 2)
+	if _, err := io.ReadFull(c, head); err != nil {
+		return nil, fmt.Errorf("%w: %v", errSocks, err)
+	}
+	if head[0] != socksVersion {
+		return nil, fmt.Errorf("%w: 不支持的版本%d", errSocks, head[0])
+	}
+	methods := make([]byte, head[1])
+	if _, err := io.ReadFull(c, methods); err != nil {
+		return nil, fmt.Errorf("%w: %v", errSocks, err)
+	}
+	method := byte(socksNoAcceptable)
+	for _, m := range methods {
+		if m == socksNoAuth {
+			method = socksNoAuth
+		}
+	}
+	if _, err := c.Write([]byte{socksVersion, method}); err != nil {
+		return nil, fmt.Errorf("%w: %v", errSocks, err)
+	}
+	if method == socksNoAcceptable {
+		return nil, fmt.Errorf("%w: 客户端不支持无认证方式", errSocks)
+	}
+
+	// 请求：版本、命令、保留、地址类型、地址、端口
+	req := make([]byte, 4)
+	if _, err := io.ReadFull(c, req); err != nil {
+		return nil, fmt.Errorf("%w: %v", errSocks, err)
+	}
+	if req[1] != socksConnect {
+		_ = socksReply(c, socksCmdNotSupport)
+		return nil, fmt.Errorf("%w: 不支持的命令%d", errSocks, req[1])
+	}
+	var host string
+	switch req[3] {
+	case socksAtypIPv4, socksAtypIPv6:
+		ip := make(net.IP, net.IPv4len)
+		if req[3] == socksAtypIPv6 {
+			ip = make(net.IP, net.IPv6len)
+		}
+		if _, err := io.ReadFull(c, ip); err != nil {
+			return nil, fmt.Errorf("%w: %v", errSocks, err)
+		}
+		host = ip.String()
+	case socksAtypDomain:
+		n := make([]byte, 1)
+		if _, err := io.ReadFull(c, n); err != nil {
+			return nil, fmt.Errorf("%w: %v", errSocks, err)
+		}
+		domain := make([]byte, n[0])
+		if _, err := io.ReadFull(c, domain); err != nil {
+			return nil, fmt.Errorf("%w: %v", errSocks, err)
+		}
+		host = string(domain)
+	default:
+		_ = socksReply(c, socksAtypNotSupport)
+		return nil, fmt.Errorf("%w: 不支持的地址类型%d", errSocks, req[3])
+	}
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(c, port); err != nil {
+		return nil, fmt.Errorf("%w: %v", errSocks, err)
+	}
+	addr := net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port))))
+	target, err := dial("tcp", addr)
+	if err != nil {
+		_ = socksReply(c, socksGeneralFail)
+		return nil, fmt.Errorf("连接%s失败: %w", addr, err)
+	}
+	if err := socksReply(c, socksSucceeded); err != nil {
+		_ = target.Close()
+		return nil, fmt.Errorf("%w: %v", errSocks, err)
+	}
+	return target, nil
+}
+
+// socksReply 回复请求结果（绑定地址固定为0.0.0.0:0）
+//
+//	@author duanzt
+//	@date 2026-10-19 23:09:00
+//	@param c net.Conn 客户端连接
+//	@param code byte 结果
+//	@return error 写入异常时返回
+func socksReply(c net.Conn, code byte) error {
+	_, err := c.Write([]byte{socksVersion, code, 0, socksAtypIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
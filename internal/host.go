@@ -0,0 +1,48 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 20:55:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:55:30
+ * @FilePath: host.go
+ * @Description: 主机连接配置
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package internal
+
+import (
+	"context"
+)
+
+// Host 主机连接配置
+type Host struct {
+	Name       string            // 主机名称（用于展示，为空时使用Addr）
+	Addr       string            // ssh连接地址，例如：192.168.10.100:22（只传入ip的情况会默认使用22端口）
+	User       string            // 用户名（为空时使用root）
+	Password   string            // 密码（与私钥同时配置时先尝试私钥验证）
+	PrivateKey string            // 私钥文件路径（密码及私钥均为空时使用默认私钥）
+	Jump       string            // 跳板机，格式为[user@]host[:port]，多级跳板机以逗号分隔（用户名为空时使用User）
+	Vars       map[string]string // 主机变量（例如从主机清单中读取的变量）
+	Groups     []string          // 所属的分组
+}
+
+// DisplayName 获取主机名称（未设置Name时为Addr）
+//
+//	@author duanzt
+//	@date 2026-10-19 20:55:40
+//	@receiver h Host
+//	@return string 主机名称
+func (h Host) DisplayName() string {
+	if h.Name != "" {
+		return h.Name
+	}
+	return h.Addr
+}
+
+// DialFunc 根据主机连接配置建立连接
+//
+//	@param ctx context.Context 上下文（取消或超时时停止连接）
+//	@param host Host 主机连接配置
+//	@return IConnection 连接
+//	@return error 连接异常时返回
+type DialFunc func(ctx context.Context, host Host) (IConnection, error)
@@ -2,7 +2,7 @@
  * @Author: duanzt
  * @Date: 2023-07-14 09:41:38
  * @LastEditors: duanzt
- * @LastEditTime: 2023-07-17 13:10:00
+ * @LastEditTime: 2026-10-19 23:56:30
  * @FilePath: iconnection.go
  * @Description: 定义connection interface
  *
@@ -13,11 +13,15 @@ package internal
 import (
 	"context"
 	"io"
+	"net"
 )
 
 // IConnection connection interface
 type IConnection interface {
 
+	// IFileSystem 文件系统操作（远程连接基于sftp实现，本地连接基于os实现）
+	IFileSystem
+
 	// Close 关闭连接
 	//  @author duanzt
 	//  @date 2023-07-14 09:48:57
@@ -42,62 +46,212 @@ type IConnection interface {
 	//  @return error ssh异常时返回
 	ExecShell(context.Context, string) (string, error)
 
-	// CopyFileLTR 拷贝文件流到远端
+	// Run 执行shell命令，分别获取标准输出、错误输出及退出码（ctx取消或超时时结束命令）
+	//  @author duanzt
+	//  @date 2026-10-19 20:56:40
+	//  @param ctx context.Context 上下文（取消时结束命令）
+	//  @param shell string shell命令
+	//  @return *CommandResult 执行结果（始终不为nil，异常时包含已获取的输出）
+	//  @return error 无法执行、退出码不为0或ctx取消时返回
+	Run(ctx context.Context, shell string) (*CommandResult, error)
+
+	// Shell 启动交互式shell（或执行命令），输入输出与stdin、stdout、stderr连接，结束时返回
+	//  @author duanzt
+	//  @date 2026-10-19 22:52:50
+	//  @param ctx context.Context 上下文（取消时结束shell）
+	//  @param stdin io.Reader 输入
+	//  @param stdout io.Writer 输出
+	//  @param stderr io.Writer 错误输出（请求伪终端时与输出合并）
+	//  @param opts ...ShellOption 配置（伪终端、窗口大小变化、执行的命令）
+	//  @return error 无法启动、退出码不为0或ctx取消时返回
+	Shell(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, opts ...ShellOption) error
+
+	// Dial 从连接所在的主机建立网络连接（远程连接通过ssh direct-tcpip通道）
+	//  @author duanzt
+	//  @date 2026-10-19 22:53:20
+	//  @param network string 网络类型（tcp、tcp4、tcp6）
+	//  @param addr string 目标地址，例如：127.0.0.1:3306
+	//  @return net.Conn 网络连接
+	//  @return error 连接异常时返回
+	Dial(network, addr string) (net.Conn, error)
+
+	// Listen 在连接所在的主机上监听（远程连接通过ssh tcpip-forward请求，需要sshd允许端口转发）
+	//  @author duanzt
+	//  @date 2026-10-19 22:53:50
+	//  @param network string 网络类型（tcp、tcp4、tcp6）
+	//  @param addr string 监听地址，例如：127.0.0.1:8080
+	//  @return net.Listener 监听
+	//  @return error 监听异常时返回
+	Listen(network, addr string) (net.Listener, error)
+
+	// Done 连接断开（或被关闭）时关闭的通道，本地连接不会断开（返回nil）
+	//  @author duanzt
+	//  @date 2026-10-19 23:56:00
+	//  @return <-chan struct{} 通道
+	Done() <-chan struct{}
+
+	// CopyFileITR 拷贝文件流到远端
 	//  @author duanzt
 	//  @date 2023-07-14 09:56:42
 	//  @param src io.Reader 流
 	//  @param dest string 远端目标文件地址
-	//  @param mode string 文件权限
+	//  @param mode string 文件权限（八进制如0755，或符号格式如u+x,go-w；为空时新文件为0644、已有文件保持原权限）
+	//  @param opts ...CopyOption 拷贝配置（保留源文件权限、所属用户等）
 	//  @return error ssh异常时返回
-	CopyFileITR(src io.Reader, dest, mode string) error
+	CopyFileITR(src io.Reader, dest, mode string, opts ...CopyOption) error
 
 	// CopyFileITRMon 拷贝文件流到远端（监控远端目标文件大小）
 	//  @author duanzt
 	//  @date 2023-07-14 10:02:16
 	//  @param src io.Reader 流
 	//  @param dest string 远端目标文件地址
-	//  @param mode string 文件权限
+	//  @param mode string 文件权限（八进制如0755，或符号格式如u+x,go-w；为空时新文件为0644、已有文件保持原权限）
 	//  @param destSizeChan chan int64 返回远端目标文件大小，单位：byte
+	//  @param opts ...CopyOption 拷贝配置（保留源文件权限、所属用户等）
 	//  @return error ssh异常时返回
-	CopyFileITRMon(src io.Reader, dest, mode string, destSizeChan chan int64) (err error)
+	CopyFileITRMon(src io.Reader, dest, mode string, destSizeChan chan int64, opts ...CopyOption) (err error)
 
 	// CopyFileLTR 拷贝本地文件到远端
 	//  @author duanzt
 	//  @date 2023-07-14 10:00:05
 	//  @param  src dest 本地文件地址
 	//  @param dest string 远端目标文件地址
-	//  @param mode string 文件权限
+	//  @param mode string 文件权限（八进制如0755，或符号格式如u+x,go-w；为空时新文件为0644、已有文件保持原权限）
+	//  @param opts ...CopyOption 拷贝配置（保留源文件权限、所属用户等）
 	//  @return error ssh异常时返回
-	CopyFileLTR(src, dest, mode string) error
+	CopyFileLTR(src, dest, mode string, opts ...CopyOption) error
 
 	// CopyFileLTRMon 拷贝本地文件到远端（监控远端目标文件大小）
 	//  @author duanzt
 	//  @date 2023-07-14 10:00:05
 	//  @param src string 本地文件地址
 	//  @param dest string 远端目标文件地址
-	//  @param mode string 文件权限
+	//  @param mode string 文件权限（八进制如0755，或符号格式如u+x,go-w；为空时新文件为0644、已有文件保持原权限）
 	//  @param destSizeChan chan int64 返回远端目标文件大小，单位：byte
+	//  @param opts ...CopyOption 拷贝配置（保留源文件权限、所属用户等）
 	//  @return error ssh异常时返回
-	CopyFileLTRMon(src, dest, mode string, destSizeChan chan int64) (err error)
+	CopyFileLTRMon(src, dest, mode string, destSizeChan chan int64, opts ...CopyOption) (err error)
 
 	// CopyFileRTL 拷贝远端文件到本地
 	//  @author duanzt
 	//  @date 2023-07-14 09:59:07
 	//  @param src string 远端文件地址
 	//  @param dest string 本地目标文件地址
-	//  @param mode string 文件权限
+	//  @param mode string 文件权限（八进制如0755，或符号格式如u+x,go-w；为空时新文件为0644、已有文件保持原权限）
+	//  @param opts ...CopyOption 拷贝配置（保留源文件权限、所属用户等）
 	//  @return error ssh异常时返回
-	CopyFileRTL(src string, dest, mode string) error
+	CopyFileRTL(src string, dest, mode string, opts ...CopyOption) error
 
 	// CopyFileRTLMon 拷贝远端文件到本地（监控本地目标文件大小）
 	//  @author duanzt
 	//  @date 2023-07-14 09:59:07
 	//  @param src string 远端文件地址
 	//  @param dest string 本地目标文件地址
-	//  @param mode string 文件权限
+	//  @param mode string 文件权限（八进制如0755，或符号格式如u+x,go-w；为空时新文件为0644、已有文件保持原权限）
 	//  @param destSizeChan chan int64 返回本地目标文件大小，单位：byte
+	//  @param opts ...CopyOption 拷贝配置（保留源文件权限、所属用户等）
 	//  @return error ssh异常时返回
-	CopyFileRTLMon(src string, dest, mode string, destSizeChan chan int64) (err error)
+	CopyFileRTLMon(src string, dest, mode string, destSizeChan chan int64, opts ...CopyOption) (err error)
+
+	// CopyDirLTR 递归拷贝本地目录到远端
+	//  @author duanzt
+	//  @date 2026-10-19 11:40:16
+	//  @param src string 本地目录（为文件时仅拷贝该文件）
+	//  @param dest string 远端目标目录
+	//  @param opts ...CopyOption 拷贝配置（过滤、符号链接策略、保留权限及修改时间、失败即终止等）
+	//  @return *CopySummary 拷贝结果汇总
+	//  @return error 拷贝异常时返回（未开启FailFast时汇总全部失败文件）
+	CopyDirLTR(src, dest string, opts ...CopyOption) (*CopySummary, error)
+
+	// CopyDirRTL 递归拷贝远端目录到本地
+	//  @author duanzt
+	//  @date 2026-10-19 11:40:52
+	//  @param src string 远端目录（为文件时仅拷贝该文件）
+	//  @param dest string 本地目标目录
+	//  @param opts ...CopyOption 拷贝配置（过滤、符号链接策略、保留权限及修改时间、失败即终止等）
+	//  @return *CopySummary 拷贝结果汇总
+	//  @return error 拷贝异常时返回（未开启FailFast时汇总全部失败文件）
+	CopyDirRTL(src, dest string, opts ...CopyOption) (*CopySummary, error)
+
+	// SyncDirLTR 同步本地目录到远端（仅拷贝有变化的文件，可删除远端多余的文件，可演练）
+	//  @author duanzt
+	//  @date 2026-10-19 18:26:02
+	//  @param src string 本地目录
+	//  @param dest string 远端目标目录
+	//  @param opts ...CopyOption 拷贝配置（比较方式、删除、演练、过滤、符号链接策略等）
+	//  @return *SyncReport 同步结果
+	//  @return error 同步异常时返回（未开启FailFast时汇总全部失败文件）
+	SyncDirLTR(src, dest string, opts ...CopyOption) (*SyncReport, error)
+
+	// SyncDirRTL 同步远端目录到本地（仅拷贝有变化的文件，可删除本地多余的文件，可演练）
+	//  @author duanzt
+	//  @date 2026-10-19 18:26:30
+	//  @param src string 远端目录
+	//  @param dest string 本地目标目录
+	//  @param opts ...CopyOption 拷贝配置（比较方式、删除、演练、过滤、符号链接策略等）
+	//  @return *SyncReport 同步结果
+	//  @return error 同步异常时返回（未开启FailFast时汇总全部失败文件）
+	SyncDirRTL(src, dest string, opts ...CopyOption) (*SyncReport, error)
+
+	// Follow 跟踪文件新增的行（类似tail -F，处理文件轮转及截断，文件不存在时等待其被创建），阻塞直到ctx取消
+	// 远程连接在远端运行tail -F，本地连接定期检查文件
+	//  @author duanzt
+	//  @date 2026-10-19 18:53:10
+	//  @param ctx context.Context 上下文（取消时停止跟踪）
+	//  @param name string 文件路径
+	//  @param handler FollowFunc 处理跟踪到的行（包含连接地址、文件路径及读取时间）
+	//  @param opts ...FollowOption 跟踪配置（从开头读取、检查间隔）
+	//  @return error ctx取消时返回nil，跟踪异常时返回
+	Follow(ctx context.Context, name string, handler FollowFunc, opts ...FollowOption) error
+
+	// Find 查找根目录下满足条件的文件（类似find -H），结果按路径排序，无法读取的子目录会被跳过
+	// 远程连接优先执行一次find命令获取结果，远端不支持时通过sftp遍历
+	//  @author duanzt
+	//  @date 2026-10-19 19:34:20
+	//  @param ctx context.Context 上下文（取消时停止遍历）
+	//  @param root string 查找的根目录
+	//  @param opts ...FindOption 查找条件（文件名、类型、大小、修改时间、深度）
+	//  @return []*FindEntry 查找到的文件（包含路径、深度及文件信息）
+	//  @return error 根目录不存在或查找异常时返回
+	Find(ctx context.Context, root string, opts ...FindOption) ([]*FindEntry, error)
+
+	// ExtractArchive 将连接上的归档文件（tar、tar.gz、tar.zst、zip）解压到目标目录
+	// 条目路径为绝对路径、包含..或上级路径为符号链接时拒绝解压；远程连接优先使用GNU tar/unzip命令，缺少命令时通过sftp流式解压
+	//  @author duanzt
+	//  @date 2026-10-19 20:13:02
+	//  @param archive string 归档文件路径（未指定格式时根据后缀判断）
+	//  @param dest string 目标目录（不存在时创建）
+	//  @param opts ...ArchiveOption 归档配置（格式、去除路径前缀、所属用户）
+	//  @return *ArchiveManifest 已解压的条目清单
+	//  @return error 解压异常时返回
+	ExtractArchive(archive, dest string, opts ...ArchiveOption) (*ArchiveManifest, error)
+
+	// CreateArchive 将连接上的目录打包为归档文件（条目路径相对于源目录）
+	// 远程连接优先使用GNU tar/zip命令，缺少命令时通过sftp流式打包
+	//  @author duanzt
+	//  @date 2026-10-19 20:13:40
+	//  @param src string 源目录
+	//  @param archive string 归档文件路径（未指定格式时根据后缀判断，已存在时覆盖）
+	//  @param opts ...ArchiveOption 归档配置（格式、条目记录的所属用户）
+	//  @return *ArchiveManifest 已打包的条目清单
+	//  @return error 打包异常时返回
+	CreateArchive(src, archive string, opts ...ArchiveOption) (*ArchiveManifest, error)
+
+	// Checksum 计算连接上文件的摘要（远程连接优先使用sftp check-file扩展，不支持时执行sha256sum等命令）
+	//  @author duanzt
+	//  @date 2026-10-19 20:38:20
+	//  @param name string 文件路径
+	//  @param algo HashAlgorithm 摘要算法（HashSHA256、HashSHA512、HashMD5）
+	//  @return string 摘要（十六进制）
+	//  @return error 文件不存在或计算异常时返回
+	Checksum(name string, algo HashAlgorithm) (string, error)
+
+	// SetRateLimiter 设置连接级别的传输限速（上传及下载均生效，对之后开始的拷贝生效，与WithLimiter同时满足）
+	// 多个连接设置同一限速时限制总速度
+	//  @author duanzt
+	//  @date 2026-10-19 16:18:40
+	//  @param limiter ILimiter 限速（为nil表示不限速）
+	SetRateLimiter(limiter ILimiter)
 
 	// GetAddr 获取ssh连接地址（例127.0.0.1:22）
 	//  @author duanzt
@@ -0,0 +1,46 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 19:02:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 19:02:10
+ * @FilePath: ifile.go
+ * @Description: 定义可读写文件interface（远程连接为sftp文件句柄，本地连接为*os.File）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package internal
+
+import (
+	"io"
+	"io/fs"
+)
+
+// IFile 可读写的文件句柄（支持顺序及随机读写），使用完毕后需要关闭
+type IFile interface {
+	io.Reader
+	io.Writer
+	io.Seeker
+	io.ReaderAt
+	io.WriterAt
+	io.Closer
+
+	// Name 获取打开时的文件路径
+	//  @author duanzt
+	//  @date 2026-10-19 19:02:40
+	//  @return string 文件路径
+	Name() string
+
+	// Stat 获取文件信息
+	//  @author duanzt
+	//  @date 2026-10-19 19:03:02
+	//  @return fs.FileInfo 文件信息
+	//  @return error 获取异常时返回
+	Stat() (fs.FileInfo, error)
+
+	// Truncate 修改文件大小
+	//  @author duanzt
+	//  @date 2026-10-19 19:03:25
+	//  @param size int64 文件大小，单位：byte
+	//  @return error 修改异常时返回
+	Truncate(size int64) error
+}
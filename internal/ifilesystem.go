@@ -0,0 +1,185 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 09:41:26
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 20:20:00
+ * @FilePath: ifilesystem.go
+ * @Description: 定义filesystem interface（远程连接基于sftp实现，本地连接基于os实现）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package internal
+
+import (
+	"io/fs"
+	"time"
+)
+
+// IFileSystem filesystem interface
+// 所有方法返回的错误均为*fs.PathError（Rename/Symlink为*os.LinkError），
+// 可通过errors.Is(err, fs.ErrNotExist)、errors.Is(err, fs.ErrPermission)等判断错误类型
+type IFileSystem interface {
+
+	// Open 以只读方式打开文件
+	//  @author duanzt
+	//  @date 2026-10-19 10:41:15
+	//  @param name string 文件路径
+	//  @return fs.File 文件对象，使用完毕后需要关闭
+	//  @return error 文件不存在等异常时返回
+	Open(name string) (fs.File, error)
+
+	// OpenFile 按标志打开文件（标志同os.OpenFile，例如os.O_RDWR|os.O_CREATE）
+	//  @author duanzt
+	//  @date 2026-10-19 19:04:02
+	//  @param name string 文件路径
+	//  @param flag int 打开标志
+	//  @param perm fs.FileMode 新建文件的权限（远程连接不受umask影响，本地连接同os.OpenFile）
+	//  @return IFile 文件句柄，使用完毕后需要关闭
+	//  @return error 文件不存在等异常时返回
+	OpenFile(name string, flag int, perm fs.FileMode) (IFile, error)
+
+	// Create 创建文件（已存在时清空），以读写方式打开
+	//  @author duanzt
+	//  @date 2026-10-19 19:04:30
+	//  @param name string 文件路径
+	//  @return IFile 文件句柄，使用完毕后需要关闭
+	//  @return error 创建异常时返回
+	Create(name string) (IFile, error)
+
+	// ReadFile 读取文件全部内容
+	//  @author duanzt
+	//  @date 2026-10-19 19:04:58
+	//  @param name string 文件路径
+	//  @return []byte 文件内容
+	//  @return error 文件不存在等异常时返回
+	ReadFile(name string) ([]byte, error)
+
+	// WriteFile 将内容写入文件（不存在时以perm创建，已存在时清空后写入并保持原权限）
+	//  @author duanzt
+	//  @date 2026-10-19 19:05:25
+	//  @param name string 文件路径
+	//  @param data []byte 文件内容
+	//  @param perm fs.FileMode 新建文件的权限
+	//  @return error 写入异常时返回
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+
+	// Stat 获取文件信息（跟随符号链接）
+	//  @author duanzt
+	//  @date 2026-10-19 09:42:10
+	//  @param name string 文件路径
+	//  @return fs.FileInfo 文件信息
+	//  @return error 文件不存在等异常时返回
+	Stat(name string) (fs.FileInfo, error)
+
+	// Lstat 获取文件信息（不跟随符号链接）
+	//  @author duanzt
+	//  @date 2026-10-19 09:42:35
+	//  @param name string 文件路径
+	//  @return fs.FileInfo 文件信息
+	//  @return error 文件不存在等异常时返回
+	Lstat(name string) (fs.FileInfo, error)
+
+	// ReadDir 读取目录下的文件信息（按文件名排序）
+	//  @author duanzt
+	//  @date 2026-10-19 09:43:02
+	//  @param name string 目录路径
+	//  @return []fs.FileInfo 目录下的文件信息
+	//  @return error 目录不存在等异常时返回
+	ReadDir(name string) ([]fs.FileInfo, error)
+
+	// Remove 删除文件或空目录
+	//  @author duanzt
+	//  @date 2026-10-19 09:43:29
+	//  @param name string 文件/目录路径
+	//  @return error 删除异常时返回
+	Remove(name string) error
+
+	// RemoveAll 递归删除文件或目录（路径不存在时不返回异常）
+	//  @author duanzt
+	//  @date 2026-10-19 09:43:51
+	//  @param name string 文件/目录路径
+	//  @return error 删除异常时返回
+	RemoveAll(name string) error
+
+	// Rename 重命名（目标文件存在时覆盖）
+	//  @author duanzt
+	//  @date 2026-10-19 09:44:16
+	//  @param oldname string 原路径
+	//  @param newname string 新路径
+	//  @return error 重命名异常时返回
+	Rename(oldname, newname string) error
+
+	// Mkdir 创建目录
+	//  @author duanzt
+	//  @date 2026-10-19 09:44:38
+	//  @param name string 目录路径
+	//  @param perm fs.FileMode 目录权限
+	//  @return error 创建异常时返回
+	Mkdir(name string, perm fs.FileMode) error
+
+	// MkdirAll 递归创建目录（目录已存在时不返回异常）
+	//  @author duanzt
+	//  @date 2026-10-19 09:45:02
+	//  @param name string 目录路径
+	//  @param perm fs.FileMode 新建目录的权限
+	//  @return error 创建异常时返回
+	MkdirAll(name string, perm fs.FileMode) error
+
+	// Chmod 修改文件权限
+	//  @author duanzt
+	//  @date 2026-10-19 09:45:25
+	//  @param name string 文件路径
+	//  @param mode fs.FileMode 文件权限
+	//  @return error 修改异常时返回
+	Chmod(name string, mode fs.FileMode) error
+
+	// Chown 修改文件所属用户及用户组
+	//  @author duanzt
+	//  @date 2026-10-19 09:45:49
+	//  @param name string 文件路径
+	//  @param uid int 用户id（小于0时不修改）
+	//  @param gid int 用户组id（小于0时不修改）
+	//  @return error 修改异常时返回
+	Chown(name string, uid, gid int) error
+
+	// Chtimes 修改文件访问时间及修改时间
+	//  @author duanzt
+	//  @date 2026-10-19 09:46:13
+	//  @param name string 文件路径
+	//  @param atime time.Time 访问时间
+	//  @param mtime time.Time 修改时间
+	//  @return error 修改异常时返回
+	Chtimes(name string, atime, mtime time.Time) error
+
+	// Symlink 创建符号链接newname，指向oldname
+	//  @author duanzt
+	//  @date 2026-10-19 09:46:40
+	//  @param oldname string 链接指向的路径
+	//  @param newname string 符号链接路径
+	//  @return error 创建异常时返回
+	Symlink(oldname, newname string) error
+
+	// Readlink 读取符号链接指向的路径
+	//  @author duanzt
+	//  @date 2026-10-19 09:47:02
+	//  @param name string 符号链接路径
+	//  @return string 链接指向的路径
+	//  @return error 读取异常时返回
+	Readlink(name string) (string, error)
+
+	// Truncate 修改文件大小
+	//  @author duanzt
+	//  @date 2026-10-19 09:47:25
+	//  @param name string 文件路径
+	//  @param size int64 文件大小，单位：byte
+	//  @return error 修改异常时返回
+	Truncate(name string, size int64) error
+
+	// Glob 查找匹配通配符的文件（语法同path.Match，每一级路径均可使用通配符，例如/var/log/*/*.log）
+	//  @author duanzt
+	//  @date 2026-10-19 19:34:50
+	//  @param pattern string 通配符
+	//  @return []string 匹配的文件路径（没有匹配时为空）
+	//  @return error 通配符不合法时返回path.ErrBadPattern
+	Glob(pattern string) ([]string, error)
+}
@@ -0,0 +1,25 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 16:02:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-20 00:01:00
+ * @FilePath: ilimiter.go
+ * @Description: 定义传输限速interface
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package internal
+
+import "context"
+
+// ILimiter 传输限速interface（需并发安全，同一限速可被多个传输、多个连接共享，共享时总速度受限）
+type ILimiter interface {
+
+	// WaitN 阻塞直到允许传输n个字节，ctx取消时立即返回
+	//  @author duanzt
+	//  @date 2026-10-19 16:02:40
+	//  @param ctx context.Context 上下文（取消时停止等待）
+	//  @param n int 字节数
+	//  @return error ctx取消时返回ctx.Err()
+	WaitN(ctx context.Context, n int) error
+}
@@ -0,0 +1,189 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 22:02:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-20 00:02:00
+ * @FilePath: ini.go
+ * @Description: 解析ini格式的主机清单（[group]、[group:vars]、[group:children]）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package inventory
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseINI 解析ini格式的主机清单：[group]下每行为主机名（或主机范围）及key=value格式的主机变量，
+// [group:vars]下每行为分组变量，[group:children]下每行为子分组；第一个分组前的主机属于ungrouped，
+// 主机名可带端口（例如db01:2222），#或;开头的行为注释
+//
+//	@author duanzt
+//	@date 2026-10-19 22:02:40
+//	@param data []byte 主机清单内容
+//	@return *Inventory 主机清单
+//	@return error 格式不正确时返回（包含行号）
+func ParseINI(data []byte) (*Inventory, error) {
+	inv := New()
+	group, kind := UngroupedGroup, ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("第%d行格式不正确: %s", lineNo, line)
+			}
+			group, kind = line[1:len(line)-1], ""
+			if name, suffix, ok := strings.Cut(group, ":"); ok {
+				if suffix != "vars" && suffix != "children" {
+					return nil, fmt.Errorf("第%d行分组类型%s不支持", lineNo, suffix)
+				}
+				group, kind = name, suffix
+			}
+			if group == "" {
+				return nil, fmt.Errorf("第%d行分组名称为空", lineNo)
+			}
+			inv.Group(group)
+			continue
+		}
+
+		var err error
+		switch kind {
+		case "vars":
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				return nil, fmt.Errorf("第%d行变量格式应为key=value: %s", lineNo, line)
+			}
+			inv.Group(group).Vars[strings.TrimSpace(key)] = unquote(strings.TrimSpace(value))
+		case "children":
+			inv.AddChild(group, strings.TrimSpace(cutComment(line)))
+		default:
+			err = parseHostLine(inv, group, line)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("第%d行%w", lineNo, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// parseHostLine 解析主机行（主机名及key=value格式的主机变量，值可使用引号包含空格）
+//
+//	@author duanzt
+//	@date 2026-10-19 22:03:40
+//	@param inv *Inventory 主机清单
+//	@param group string 分组名称
+//	@param line string 主机行
+//	@return error 格式不正确时返回
+func parseHostLine(inv *Inventory, group, line string) error {
+	fields, err := splitFields(cutComment(line))
+	if err != nil {
+		return err
+	}
+	name := fields[0]
+	vars := map[string]string{}
+	// 主机名带端口（端口在最后一个]之后）
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "]") {
+		if _, err := strconv.Atoi(name[i+1:]); err == nil {
+			name, vars["ansible_port"] = name[:i], name[i+1:]
+		}
+	}
+	for _, field := range fields[1:] {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return fmt.Errorf("主机变量格式应为key=value: %s", field)
+		}
+		vars[key] = value
+	}
+	return inv.AddHost(group, name, vars)
+}
+
+// cutComment 去除行内注释（引号外、位于行首或空白之后的#及其后的内容）
+//
+//	@author duanzt
+//	@date 2026-10-20 00:02:00
+//	@param line string 行内容
+//	@return string 去除注释后的内容
+func cutComment(line string) string {
+	var quote rune
+	prev := ' '
+	for i, c := range line {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (prev == ' ' || prev == '\t'):
+			return line[:i]
+		}
+		prev = c
+	}
+	return line
+}
+
+// splitFields 按空白拆分，引号内的空白不拆分（去除引号）
+//
+//	@author duanzt
+//	@date 2026-10-19 22:04:30
+//	@param line string 行内容
+//	@return []string 拆分后的字段
+//	@return error 引号未闭合时返回
+func splitFields(line string) ([]string, error) {
+	var fields []string
+	var current strings.Builder
+	var quote rune
+	inField := false
+	for _, c := range line {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				current.WriteRune(c)
+			}
+		case c == '"' || c == '\'':
+			quote, inField = c, true
+		case c == ' ' || c == '\t':
+			if inField {
+				fields = append(fields, current.String())
+				current.Reset()
+				inField = false
+			}
+		default:
+			current.WriteRune(c)
+			inField = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("引号未闭合: %s", line)
+	}
+	if inField {
+		fields = append(fields, current.String())
+	}
+	return fields, nil
+}
+
+// unquote 去除值两端成对的引号
+//
+//	@author duanzt
+//	@date 2026-10-19 22:05:10
+//	@param value string 值
+//	@return string 去除引号后的值
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,679 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 21:48:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 21:48:10
+ * @FilePath: inventory.go
+ * @Description: 主机清单（兼容ansible风格的分组、嵌套分组、主机及分组变量），生成可直接建立连接的主机连接配置
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package inventory
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/duanztop/gossh/internal"
+)
+
+const (
+
+	// AllGroup 包含全部主机的分组
+	AllGroup = "all"
+
+	// UngroupedGroup 不属于all以外任何分组的主机所在的分组
+	UngroupedGroup = "ungrouped"
+)
+
+// Format 主机清单格式
+type Format string
+
+const (
+
+	// FormatINI ini格式（ansible的hosts文件）
+	FormatINI Format = "ini"
+
+	// FormatYAML yaml格式（json格式同样按yaml解析）
+	FormatYAML Format = "yaml"
+)
+
+// Group 主机分组
+type Group struct {
+	Name     string            // 分组名称
+	Hosts    []string          // 直接属于该分组的主机（按定义顺序）
+	Children []string          // 子分组
+	Vars     map[string]string // 分组变量
+}
+
+// Inventory 主机清单
+type Inventory struct {
+	groups   map[string]*Group
+	hostVars map[string]map[string]string // 主机变量
+	hosts    []string                     // 全部主机（按定义顺序）
+}
+
+// New 新建空的主机清单（包含all及ungrouped分组）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:48:40
+//	@return *Inventory 主机清单
+func New() *Inventory {
+	inv := &Inventory{groups: map[string]*Group{}, hostVars: map[string]map[string]string{}}
+	inv.Group(AllGroup)
+	inv.Group(UngroupedGroup)
+	return inv
+}
+
+// Parse 按格式解析主机清单
+//
+//	@author duanzt
+//	@date 2026-10-19 22:09:40
+//	@param data []byte 主机清单内容
+//	@param format Format 格式
+//	@return *Inventory 主机清单
+//	@return error 格式不支持或解析异常时返回
+func Parse(data []byte, format Format) (*Inventory, error) {
+	switch format {
+	case FormatINI:
+		return ParseINI(data)
+	case FormatYAML:
+		return ParseYAML(data)
+	}
+	return nil, fmt.Errorf("主机清单格式%s不支持", format)
+}
+
+// Load 读取本地主机清单文件，扩展名为.yml、.yaml、.json时按yaml解析，否则按ini解析
+//
+//	@author duanzt
+//	@date 2026-10-19 22:10:10
+//	@param name string 文件路径
+//	@return *Inventory 主机清单
+//	@return error 读取或解析异常时返回
+func Load(name string) (*Inventory, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	format := FormatINI
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yml", ".yaml", ".json":
+		format = FormatYAML
+	}
+	inv, err := Parse(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("主机清单%s解析失败: %w", name, err)
+	}
+	return inv, nil
+}
+
+// Group 获取分组，不存在时新建
+//
+//	@author duanzt
+//	@date 2026-10-19 21:49:10
+//	@receiver inv *Inventory
+//	@param name string 分组名称
+//	@return *Group 分组
+func (inv *Inventory) Group(name string) *Group {
+	group, ok := inv.groups[name]
+	if !ok {
+		group = &Group{Name: name, Vars: map[string]string{}}
+		inv.groups[name] = group
+	}
+	return group
+}
+
+// AddHost 向分组中添加主机（支持主机范围，例如web[01:20].dc1），vars合并到主机变量中
+//
+//	@author duanzt
+//	@date 2026-10-19 21:49:40
+//	@receiver inv *Inventory
+//	@param group string 分组名称
+//	@param pattern string 主机名或主机范围
+//	@param vars map[string]string 主机变量（可为nil）
+//	@return error 主机范围格式不正确时返回
+func (inv *Inventory) AddHost(group, pattern string, vars map[string]string) error {
+	names, err := ExpandHostPattern(pattern)
+	if err != nil {
+		return err
+	}
+	g := inv.Group(group)
+	for _, name := range names {
+		hostVars, ok := inv.hostVars[name]
+		if !ok {
+			hostVars = map[string]string{}
+			inv.hostVars[name] = hostVars
+			inv.hosts = append(inv.hosts, name)
+		}
+		for k, v := range vars {
+			hostVars[k] = v
+		}
+		if !contains(g.Hosts, name) {
+			g.Hosts = append(g.Hosts, name)
+		}
+	}
+	return nil
+}
+
+// AddChild 将child设置为group的子分组
+//
+//	@author duanzt
+//	@date 2026-10-19 21:50:10
+//	@receiver inv *Inventory
+//	@param group string 分组名称
+//	@param child string 子分组名称
+func (inv *Inventory) AddChild(group, child string) {
+	g := inv.Group(group)
+	inv.Group(child)
+	if !contains(g.Children, child) {
+		g.Children = append(g.Children, child)
+	}
+}
+
+// GroupNames 获取全部分组名称（按名称排序）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:50:40
+//	@receiver inv *Inventory
+//	@return []string 分组名称
+func (inv *Inventory) GroupNames() []string {
+	names := make([]string, 0, len(inv.groups))
+	for name := range inv.groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HostNames 获取全部主机名称（按定义顺序）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:51:10
+//	@receiver inv *Inventory
+//	@return []string 主机名称
+func (inv *Inventory) HostNames() []string {
+	return append([]string(nil), inv.hosts...)
+}
+
+// GroupHosts 获取分组（包含子分组）中的全部主机名称（按定义顺序）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:51:40
+//	@receiver inv *Inventory
+//	@param name string 分组名称
+//	@return []string 主机名称
+//	@return bool 分组是否存在
+func (inv *Inventory) GroupHosts(name string) ([]string, bool) {
+	if _, ok := inv.groups[name]; !ok {
+		return nil, false
+	}
+	if name == AllGroup {
+		return inv.HostNames(), true
+	}
+	set := map[string]bool{}
+	inv.collectHosts(name, set, map[string]bool{})
+	return inv.ordered(set), true
+}
+
+// collectHosts 递归收集分组中的主机（忽略循环引用，ungrouped分组只包含不属于其它分组的主机）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:52:10
+//	@receiver inv *Inventory
+//	@param name string 分组名称
+//	@param set map[string]bool 收集到的主机
+//	@param visited map[string]bool 已访问的分组
+func (inv *Inventory) collectHosts(name string, set, visited map[string]bool) {
+	group, ok := inv.groups[name]
+	if !ok || visited[name] {
+		return
+	}
+	visited[name] = true
+	if name == UngroupedGroup {
+		for _, host := range inv.hosts {
+			if inv.isUngrouped(host) {
+				set[host] = true
+			}
+		}
+	}
+	for _, host := range group.Hosts {
+		if name != UngroupedGroup {
+			set[host] = true
+		}
+	}
+	for _, child := range group.Children {
+		inv.collectHosts(child, set, visited)
+	}
+}
+
+// isUngrouped 主机是否不属于all及ungrouped以外的任何分组
+//
+//	@author duanzt
+//	@date 2026-10-19 21:52:25
+//	@receiver inv *Inventory
+//	@param host string 主机名称
+//	@return bool 不属于其它分组则返回true
+func (inv *Inventory) isUngrouped(host string) bool {
+	for name := range inv.groups {
+		if name == AllGroup || name == UngroupedGroup {
+			continue
+		}
+		set := map[string]bool{}
+		inv.collectHosts(name, set, map[string]bool{UngroupedGroup: true})
+		if set[host] {
+			return false
+		}
+	}
+	return true
+}
+
+// HostGroups 获取主机所属的全部分组（包含上级分组，不包含all，按名称排序；不属于任何分组时为ungrouped）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:52:40
+//	@receiver inv *Inventory
+//	@param host string 主机名称
+//	@return []string 分组名称
+func (inv *Inventory) HostGroups(host string) []string {
+	if _, ok := inv.hostVars[host]; !ok {
+		return nil
+	}
+	var groups []string
+	for _, name := range inv.GroupNames() {
+		if name == AllGroup || name == UngroupedGroup {
+			continue
+		}
+		set := map[string]bool{}
+		inv.collectHosts(name, set, map[string]bool{UngroupedGroup: true})
+		if set[host] {
+			groups = append(groups, name)
+		}
+	}
+	if len(groups) == 0 {
+		groups = []string{UngroupedGroup}
+	}
+	return groups
+}
+
+// Vars 获取主机合并后的变量，优先级从低到高为：all分组、所属分组（按层级由浅到深，同层级按名称）、主机变量
+//
+//	@author duanzt
+//	@date 2026-10-19 21:53:10
+//	@receiver inv *Inventory
+//	@param host string 主机名称
+//	@return map[string]string 变量
+//	@return bool 主机是否存在
+func (inv *Inventory) Vars(host string) (map[string]string, bool) {
+	hostVars, ok := inv.hostVars[host]
+	if !ok {
+		return nil, false
+	}
+	groups := inv.HostGroups(host)
+	depths := inv.depths()
+	sort.SliceStable(groups, func(i, j int) bool {
+		return depths[groups[i]] < depths[groups[j]]
+	})
+	vars := map[string]string{}
+	for _, name := range append([]string{AllGroup}, groups...) {
+		for k, v := range inv.groups[name].Vars {
+			vars[k] = v
+		}
+	}
+	for k, v := range hostVars {
+		vars[k] = v
+	}
+	return vars, true
+}
+
+// depths 计算各分组的层级（all为0，其余为到all的最长路径，没有上级分组的分组视为all的子分组）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:53:50
+//	@receiver inv *Inventory
+//	@return map[string]int 分组层级
+func (inv *Inventory) depths() map[string]int {
+	parents := map[string][]string{}
+	for name, group := range inv.groups {
+		if name == AllGroup {
+			continue
+		}
+		for _, child := range group.Children {
+			parents[child] = append(parents[child], name)
+		}
+	}
+	depths := map[string]int{AllGroup: 0}
+	var depth func(name string, path map[string]bool) int
+	depth = func(name string, path map[string]bool) int {
+		if d, ok := depths[name]; ok {
+			return d
+		}
+		path[name] = true
+		d := 1
+		for _, parent := range parents[name] {
+			// 忽略循环引用
+			if !path[parent] {
+				if pd := depth(parent, path) + 1; pd > d {
+					d = pd
+				}
+			}
+		}
+		delete(path, name)
+		depths[name] = d
+		return d
+	}
+	for name := range inv.groups {
+		depth(name, map[string]bool{})
+	}
+	return depths
+}
+
+// Host 获取主机连接配置，根据变量设置连接地址（ansible_host、ansible_port）、用户名（ansible_user）、
+// 密码（ansible_password）、私钥（ansible_ssh_private_key_file，支持~）及跳板机（ansible_ssh_common_args或
+// ansible_ssh_extra_args中的-J或-o ProxyJump）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:55:10
+//	@receiver inv *Inventory
+//	@param name string 主机名称
+//	@return internal.Host 主机连接配置
+//	@return error 主机不存在或私钥路径异常时返回
+func (inv *Inventory) Host(name string) (internal.Host, error) {
+	vars, ok := inv.Vars(name)
+	if !ok {
+		return internal.Host{}, fmt.Errorf("主机%s不存在", name)
+	}
+	host := internal.Host{
+		Name:     name,
+		User:     first(vars, "ansible_user", "ansible_ssh_user"),
+		Password: first(vars, "ansible_password", "ansible_ssh_pass", "ansible_ssh_password"),
+		Vars:     vars,
+	}
+	for _, group := range inv.HostGroups(name) {
+		if group != UngroupedGroup {
+			host.Groups = append(host.Groups, group)
+		}
+	}
+	addr := first(vars, "ansible_host", "ansible_ssh_host")
+	if addr == "" {
+		addr = name
+	}
+	if port := first(vars, "ansible_port", "ansible_ssh_port"); port != "" {
+		addr = net.JoinHostPort(addr, port)
+	}
+	host.Addr = addr
+	if key := first(vars, "ansible_ssh_private_key_file", "ansible_private_key_file"); key != "" {
+		key, err := expandHome(key)
+		if err != nil {
+			return host, err
+		}
+		host.PrivateKey = key
+	}
+	host.Jump = proxyJump(vars["ansible_ssh_common_args"])
+	if jump := proxyJump(vars["ansible_ssh_extra_args"]); jump != "" {
+		host.Jump = jump
+	}
+	return host, nil
+}
+
+// Hosts 根据匹配规则获取主机连接配置（按定义顺序），规则兼容ansible：all或*、分组名、主机名、通配符（例如web*）、
+// ~开头的正则表达式，多个规则以:或,分隔，&开头表示取交集，!开头表示排除，例如web:db:&dc1:!web03
+//
+//	@author duanzt
+//	@date 2026-10-19 21:56:10
+//	@receiver inv *Inventory
+//	@param pattern string 匹配规则（为空时同all）
+//	@return []internal.Host 主机连接配置
+//	@return error 规则未匹配到主机或分组、或主机配置异常时返回
+func (inv *Inventory) Hosts(pattern string) ([]internal.Host, error) {
+	names, err := inv.Match(pattern)
+	if err != nil {
+		return nil, err
+	}
+	hosts := make([]internal.Host, 0, len(names))
+	for _, name := range names {
+		host, err := inv.Host(name)
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
+
+// Match 根据匹配规则获取主机名称（按定义顺序），先合并全部普通规则，再依次取交集、排除
+//
+//	@author duanzt
+//	@date 2026-10-19 21:57:02
+//	@receiver inv *Inventory
+//	@param pattern string 匹配规则（为空时同all）
+//	@return []string 主机名称
+//	@return error 规则未匹配到主机或分组时返回
+func (inv *Inventory) Match(pattern string) ([]string, error) {
+	if strings.TrimSpace(pattern) == "" {
+		pattern = AllGroup
+	}
+	union := map[string]bool{}
+	var intersections, exclusions []map[string]bool
+	for _, term := range splitPattern(pattern) {
+		var target *[]map[string]bool
+		switch {
+		case strings.HasPrefix(term, "&"):
+			target, term = &intersections, term[1:]
+		case strings.HasPrefix(term, "!"):
+			target, term = &exclusions, term[1:]
+		}
+		set, err := inv.matchTerm(term)
+		if err != nil {
+			return nil, err
+		}
+		if target == nil {
+			for name := range set {
+				union[name] = true
+			}
+		} else {
+			*target = append(*target, set)
+		}
+	}
+	for _, set := range intersections {
+		for name := range union {
+			if !set[name] {
+				delete(union, name)
+			}
+		}
+	}
+	for _, set := range exclusions {
+		for name := range set {
+			delete(union, name)
+		}
+	}
+	return inv.ordered(union), nil
+}
+
+// matchTerm 获取单个规则匹配的主机
+//
+//	@author duanzt
+//	@date 2026-10-19 21:57:40
+//	@receiver inv *Inventory
+//	@param term string 单个规则
+//	@return map[string]bool 匹配的主机
+//	@return error 未匹配到主机或分组、或规则不合法时返回
+func (inv *Inventory) matchTerm(term string) (map[string]bool, error) {
+	set := map[string]bool{}
+	if term == AllGroup || term == "*" {
+		for _, name := range inv.hosts {
+			set[name] = true
+		}
+		return set, nil
+	}
+	if _, ok := inv.groups[term]; ok {
+		inv.collectHosts(term, set, map[string]bool{})
+		return set, nil
+	}
+	if _, ok := inv.hostVars[term]; ok {
+		set[term] = true
+		return set, nil
+	}
+
+	var match func(string) bool
+	switch {
+	case strings.HasPrefix(term, "~"):
+		re, err := regexp.Compile(term[1:])
+		if err != nil {
+			return nil, fmt.Errorf("匹配规则%s不合法: %w", term, err)
+		}
+		match = re.MatchString
+	case strings.ContainsAny(term, "*?["):
+		if _, err := filepath.Match(term, ""); err != nil {
+			return nil, fmt.Errorf("匹配规则%s不合法: %w", term, err)
+		}
+		match = func(name string) bool {
+			ok, _ := filepath.Match(term, name)
+			return ok
+		}
+	default:
+		return nil, fmt.Errorf("匹配规则%s未匹配到主机或分组", term)
+	}
+	for name := range inv.groups {
+		if match(name) {
+			inv.collectHosts(name, set, map[string]bool{})
+		}
+	}
+	for _, name := range inv.hosts {
+		if match(name) {
+			set[name] = true
+		}
+	}
+	return set, nil
+}
+
+// ordered 按定义顺序输出主机
+//
+//	@author duanzt
+//	@date 2026-10-19 21:58:10
+//	@receiver inv *Inventory
+//	@param set map[string]bool 主机
+//	@return []string 主机名称
+func (inv *Inventory) ordered(set map[string]bool) []string {
+	names := make([]string, 0, len(set))
+	for _, name := range inv.hosts {
+		if set[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// splitPattern 拆分匹配规则（以:或,分隔，方括号内的:不拆分）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:58:40
+//	@param pattern string 匹配规则
+//	@return []string 单个规则
+func splitPattern(pattern string) []string {
+	var terms []string
+	depth, begin := 0, 0
+	add := func(term string) {
+		if term = strings.TrimSpace(term); term != "" {
+			terms = append(terms, term)
+		}
+	}
+	for i, c := range pattern {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ':', ',':
+			if depth == 0 && !strings.HasPrefix(pattern[begin:], "~") {
+				add(pattern[begin:i])
+				begin = i + 1
+			}
+		}
+	}
+	add(pattern[begin:])
+	return terms
+}
+
+// proxyJump 从ssh参数中解析跳板机（-J host、-Jhost、-o ProxyJump=host、-o 'ProxyJump host'）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:59:20
+//	@param args string ssh参数
+//	@return string 跳板机（未配置时为空）
+func proxyJump(args string) string {
+	fields := strings.Fields(strings.NewReplacer(`"`, " ", `'`, " ").Replace(args))
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		switch {
+		case field == "-J" && i+1 < len(fields):
+			return fields[i+1]
+		case strings.HasPrefix(field, "-J"):
+			return field[2:]
+		case field == "-o" && i+1 < len(fields):
+			i++
+			option := fields[i]
+			if strings.EqualFold(option, "ProxyJump") && i+1 < len(fields) {
+				return fields[i+1]
+			}
+			if key, value, ok := strings.Cut(option, "="); ok && strings.EqualFold(key, "ProxyJump") {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// first 获取第一个不为空的变量
+//
+//	@author duanzt
+//	@date 2026-10-19 22:00:02
+//	@param vars map[string]string 变量
+//	@param keys ...string 变量名
+//	@return string 变量值
+func first(vars map[string]string, keys ...string) string {
+	for _, key := range keys {
+		if v := vars[key]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// expandHome 将路径开头的~替换为当前用户的home目录
+//
+//	@author duanzt
+//	@date 2026-10-19 22:00:40
+//	@param name string 路径
+//	@return string 替换后的路径
+//	@return error 获取home目录异常时返回
+func expandHome(name string) (string, error) {
+	if name != "~" && !strings.HasPrefix(name, "~/") {
+		return name, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("私钥路径%s无法展开: %w", name, err)
+	}
+	return filepath.Join(home, name[1:]), nil
+}
+
+// contains 切片中是否包含s
+//
+//	@author duanzt
+//	@date 2026-10-19 22:01:10
+//	@param list []string 切片
+//	@param s string 字符串
+//	@return bool 包含则返回true
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 21:45:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 21:45:10
+ * @FilePath: pattern.go
+ * @Description: 主机范围展开（例如web[01:20].dc1、db-[a:c]）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package inventory
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ExpandHostPattern 展开主机范围，支持数字范围（起始值以0开头时按起始值的长度补0）、单个字母范围及步长，
+// 可包含多个范围，例如web[01:20].dc1、db-[a:c]、node[0:10:2]、rack[1:2]-[01:04]；不包含范围时原样返回
+//
+//	@author duanzt
+//	@date 2026-10-19 21:45:40
+//	@param pattern string 主机范围
+//	@return []string 展开后的主机名（按范围顺序）
+//	@return error 范围格式不正确时返回
+func ExpandHostPattern(pattern string) ([]string, error) {
+	begin := strings.Index(pattern, "[")
+	if begin < 0 {
+		return []string{pattern}, nil
+	}
+	end := strings.Index(pattern[begin:], "]")
+	if end < 0 {
+		return nil, fmt.Errorf("主机范围%s格式不正确: 缺少]", pattern)
+	}
+	end += begin
+	items, err := expandRange(pattern[begin+1 : end])
+	if err != nil {
+		return nil, fmt.Errorf("主机范围%s格式不正确: %w", pattern, err)
+	}
+	rests, err := ExpandHostPattern(pattern[end+1:])
+	if err != nil {
+		return nil, err
+	}
+	prefix := pattern[:begin]
+	result := make([]string, 0, len(items)*len(rests))
+	for _, item := range items {
+		for _, rest := range rests {
+			result = append(result, prefix+item+rest)
+		}
+	}
+	return result, nil
+}
+
+// expandRange 展开单个范围（不含方括号），格式为start:end[:step]，start为空时从0开始
+//
+//	@author duanzt
+//	@date 2026-10-19 21:46:30
+//	@param r string 范围
+//	@return []string 展开后的值
+//	@return error 范围格式不正确时返回
+func expandRange(r string) ([]string, error) {
+	parts := strings.Split(r, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("范围[%s]应为start:end[:step]", r)
+	}
+	start, stop := parts[0], parts[1]
+	step := 1
+	if len(parts) == 3 {
+		n, err := strconv.Atoi(parts[2])
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("步长%s不合法", parts[2])
+		}
+		step = n
+	}
+	if start == "" {
+		start = "0"
+	}
+
+	// 字母范围
+	if isLetter(start) || isLetter(stop) {
+		if !isLetter(start) || !isLetter(stop) || start[0] > stop[0] {
+			return nil, fmt.Errorf("字母范围[%s]不合法", r)
+		}
+		var result []string
+		for c := int(start[0]); c <= int(stop[0]); c += step {
+			result = append(result, string(rune(c)))
+		}
+		return result, nil
+	}
+
+	// 数字范围
+	from, err := strconv.Atoi(start)
+	if err != nil || from < 0 {
+		return nil, fmt.Errorf("起始值%s不合法", start)
+	}
+	to, err := strconv.Atoi(stop)
+	if err != nil || to < from {
+		return nil, fmt.Errorf("结束值%s不合法", stop)
+	}
+	format := "%d"
+	if len(start) > 1 && start[0] == '0' {
+		format = "%0" + strconv.Itoa(len(start)) + "d"
+	}
+	result := make([]string, 0, (to-from)/step+1)
+	for i := from; i <= to; i += step {
+		result = append(result, fmt.Sprintf(format, i))
+	}
+	return result, nil
+}
+
+// isLetter 是否为单个字母
+//
+//	@author duanzt
+//	@date 2026-10-19 21:47:02
+//	@param s string 字符串
+//	@return bool 是单个字母则返回true
+func isLetter(s string) bool {
+	return len(s) == 1 && (s[0] >= 'a' && s[0] <= 'z' || s[0] >= 'A' && s[0] <= 'Z')
+}
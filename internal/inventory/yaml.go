@@ -0,0 +1,132 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 22:06:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 22:06:10
+ * @FilePath: yaml.go
+ * @Description: 解析yaml格式的主机清单（分组下的hosts、vars、children）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlGroup yaml格式的分组（hosts及children使用yaml.Node以保留定义顺序）
+type yamlGroup struct {
+	Hosts    yaml.Node      `yaml:"hosts"`
+	Vars     map[string]any `yaml:"vars"`
+	Children yaml.Node      `yaml:"children"`
+}
+
+// ParseYAML 解析yaml格式的主机清单（json格式同样适用），顶层为分组（通常为all），
+// 分组下hosts为主机名（或主机范围）到主机变量的映射，vars为分组变量，children为子分组
+//
+//	@author duanzt
+//	@date 2026-10-19 22:06:40
+//	@param data []byte 主机清单内容
+//	@return *Inventory 主机清单
+//	@return error 格式不正确时返回
+func ParseYAML(data []byte) (*Inventory, error) {
+	inv := New()
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("yaml解析失败: %w", err)
+	}
+	if len(root.Content) == 0 {
+		return inv, nil
+	}
+	err := eachPair(root.Content[0], "顶层", func(name string, value *yaml.Node) error {
+		return parseYAMLGroup(inv, name, value)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// parseYAMLGroup 解析分组及其子分组
+//
+//	@author duanzt
+//	@date 2026-10-19 22:07:30
+//	@param inv *Inventory 主机清单
+//	@param name string 分组名称
+//	@param node *yaml.Node 分组内容（可为空）
+//	@return error 格式不正确时返回
+func parseYAMLGroup(inv *Inventory, name string, node *yaml.Node) error {
+	group := inv.Group(name)
+	var g yamlGroup
+	if err := node.Decode(&g); err != nil {
+		return fmt.Errorf("分组%s格式不正确: %w", name, err)
+	}
+	for k, v := range g.Vars {
+		group.Vars[k] = varString(v)
+	}
+	err := eachPair(&g.Hosts, "分组"+name+"的hosts", func(host string, value *yaml.Node) error {
+		var vars map[string]any
+		if err := value.Decode(&vars); err != nil {
+			return fmt.Errorf("主机%s的变量格式不正确: %w", host, err)
+		}
+		hostVars := make(map[string]string, len(vars))
+		for k, v := range vars {
+			hostVars[k] = varString(v)
+		}
+		return inv.AddHost(name, host, hostVars)
+	})
+	if err != nil {
+		return err
+	}
+	return eachPair(&g.Children, "分组"+name+"的children", func(child string, value *yaml.Node) error {
+		inv.AddChild(name, child)
+		return parseYAMLGroup(inv, child, value)
+	})
+}
+
+// eachPair 按顺序遍历映射节点（空节点不遍历）
+//
+//	@author duanzt
+//	@date 2026-10-19 22:08:20
+//	@param node *yaml.Node 映射节点
+//	@param desc string 节点描述（用于异常信息）
+//	@param fn func(key string, value *yaml.Node) error 处理方法
+//	@return error 节点不是映射或处理异常时返回
+func eachPair(node *yaml.Node, desc string, fn func(key string, value *yaml.Node) error) error {
+	if node.Kind == 0 || node.Tag == "!!null" {
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s应为映射", desc)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if err := fn(node.Content[i].Value, node.Content[i+1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// varString 将变量值转换为字符串（列表及映射转换为json）
+//
+//	@author duanzt
+//	@date 2026-10-19 22:09:02
+//	@param v any 变量值
+//	@return string 字符串
+func varString(v any) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case []any, map[string]any:
+		data, err := json.Marshal(value)
+		if err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,258 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 10:45:12
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 10:45:12
+ * @FilePath: iofs.go
+ * @Description: 将连接适配为标准库io/fs.FS（可直接用于fs.WalkDir、fs.Glob、template.ParseFS、http.FS等）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package iofs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+
+	"github.com/duanztop/gossh/internal"
+)
+
+// connFS 基于连接文件系统的fs.FS实现
+type connFS struct {
+	fsys internal.IFileSystem // 连接的文件系统
+	dir  string               // 根目录
+}
+
+// New 新建一个以dir为根目录的fs.FS（语义同os.DirFS）
+//
+//	@author duanzt
+//	@date 2026-10-19 10:46:03
+//	@param fsys internal.IFileSystem 连接的文件系统
+//	@param dir string 根目录
+//	@return fs.FS 实现了fs.ReadDirFS、fs.StatFS、fs.ReadFileFS、fs.SubFS
+func New(fsys internal.IFileSystem, dir string) fs.FS {
+	return &connFS{fsys: fsys, dir: dir}
+}
+
+// Open 打开文件（目录返回fs.ReadDirFile）
+//
+//	@author duanzt
+//	@date 2026-10-19 10:46:40
+//	@receiver f *connFS
+//	@param name string 相对根目录的路径（需满足fs.ValidPath）
+//	@return fs.File 文件对象
+//	@return error 打开异常时返回
+func (f *connFS) Open(name string) (fs.File, error) {
+	fullName, err := f.join("open", name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.fsys.Stat(fullName)
+	if err != nil {
+		return nil, pathError("open", name, err)
+	}
+	if info.IsDir() {
+		return &dirFile{fsys: f.fsys, fullName: fullName, name: name, info: info}, nil
+	}
+	file, err := f.fsys.Open(fullName)
+	if err != nil {
+		return nil, pathError("open", name, err)
+	}
+	return file, nil
+}
+
+// Stat 获取文件信息
+//
+//	@author duanzt
+//	@date 2026-10-19 10:47:18
+//	@receiver f *connFS
+//	@param name string 相对根目录的路径
+//	@return fs.FileInfo 文件信息
+//	@return error 获取异常时返回
+func (f *connFS) Stat(name string) (fs.FileInfo, error) {
+	fullName, err := f.join("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.fsys.Stat(fullName)
+	if err != nil {
+		return nil, pathError("stat", name, err)
+	}
+	return info, nil
+}
+
+// ReadDir 读取目录（按文件名排序）
+//
+//	@author duanzt
+//	@date 2026-10-19 10:47:52
+//	@receiver f *connFS
+//	@param name string 相对根目录的路径
+//	@return []fs.DirEntry 目录下的文件
+//	@return error 读取异常时返回
+func (f *connFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	fullName, err := f.join("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	infos, err := f.fsys.ReadDir(fullName)
+	if err != nil {
+		return nil, pathError("readdir", name, err)
+	}
+	return toDirEntries(infos), nil
+}
+
+// ReadFile 读取文件全部内容
+//
+//	@author duanzt
+//	@date 2026-10-19 10:48:25
+//	@receiver f *connFS
+//	@param name string 相对根目录的路径
+//	@return []byte 文件内容
+//	@return error 读取异常时返回
+func (f *connFS) ReadFile(name string) ([]byte, error) {
+	fullName, err := f.join("readfile", name)
+	if err != nil {
+		return nil, err
+	}
+	file, err := f.fsys.Open(fullName)
+	if err != nil {
+		return nil, pathError("readfile", name, err)
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, pathError("readfile", name, err)
+	}
+	return data, nil
+}
+
+// Sub 获取以dir为根目录的子文件系统
+//
+//	@author duanzt
+//	@date 2026-10-19 10:48:58
+//	@receiver f *connFS
+//	@param dir string 相对根目录的路径
+//	@return fs.FS 子文件系统
+//	@return error dir不合法时返回
+func (f *connFS) Sub(dir string) (fs.FS, error) {
+	fullName, err := f.join("sub", dir)
+	if err != nil {
+		return nil, err
+	}
+	if dir == "." {
+		return f, nil
+	}
+	return &connFS{fsys: f.fsys, dir: fullName}, nil
+}
+
+// join 校验name并拼接为连接上的完整路径
+//
+//	@author duanzt
+//	@date 2026-10-19 10:49:31
+//	@receiver f *connFS
+//	@param op string 操作名称
+//	@param name string 相对根目录的路径
+//	@return string 完整路径
+//	@return error name不满足fs.ValidPath时返回
+func (f *connFS) join(op, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if f.dir == "" {
+		return name, nil
+	}
+	if name == "." {
+		return f.dir, nil
+	}
+	return path.Join(f.dir, name), nil
+}
+
+// dirFile 目录文件（实现fs.ReadDirFile）
+type dirFile struct {
+	fsys     internal.IFileSystem
+	fullName string        // 连接上的完整路径
+	name     string        // 相对根目录的路径
+	info     fs.FileInfo   // 目录信息
+	entries  []fs.DirEntry // 目录下的文件（首次ReadDir时加载）
+	offset   int           // 已读取的文件数
+	loaded   bool          // entries是否已加载
+}
+
+// Stat 获取目录信息
+func (d *dirFile) Stat() (fs.FileInfo, error) {
+	return d.info, nil
+}
+
+// Read 目录不可读取
+func (d *dirFile) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errors.New("is a directory")}
+}
+
+// Close 关闭目录
+func (d *dirFile) Close() error {
+	return nil
+}
+
+// ReadDir 读取目录，语义同fs.ReadDirFile
+//
+//	@author duanzt
+//	@date 2026-10-19 10:50:44
+//	@receiver d *dirFile
+//	@param n int n<=0时返回剩余全部文件，否则最多返回n个
+//	@return []fs.DirEntry 目录下的文件
+//	@return error n>0且已读取完毕时返回io.EOF
+func (d *dirFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.loaded {
+		infos, err := d.fsys.ReadDir(d.fullName)
+		if err != nil {
+			return nil, pathError("readdir", d.name, err)
+		}
+		d.entries = toDirEntries(infos)
+		d.loaded = true
+	}
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(rest) {
+		n = len(rest)
+	}
+	d.offset += n
+	return rest[:n], nil
+}
+
+// toDirEntries 将文件信息转换为fs.DirEntry
+//
+//	@author duanzt
+//	@date 2026-10-19 10:51:20
+//	@param infos []fs.FileInfo 文件信息
+//	@return []fs.DirEntry 目录项
+func toDirEntries(infos []fs.FileInfo) []fs.DirEntry {
+	entries := make([]fs.DirEntry, len(infos))
+	for i := range infos {
+		entries[i] = fs.FileInfoToDirEntry(infos[i])
+	}
+	return entries
+}
+
+// pathError 将连接返回的异常转换为以相对路径描述的*fs.PathError
+//
+//	@author duanzt
+//	@date 2026-10-19 10:51:58
+//	@param op string 操作名称
+//	@param name string 相对根目录的路径
+//	@param err error 连接返回的异常
+//	@return error 转换后的异常
+func pathError(op, name string, err error) error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
@@ -0,0 +1,67 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 14:40:12
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 14:40:12
+ * @FilePath: iprogress.go
+ * @Description: 定义传输进度报告interface
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package internal
+
+import (
+	"time"
+)
+
+// ProgressInfo 单个文件的传输进度
+type ProgressInfo struct {
+	Name     string        // 目标文件路径
+	Done     int64         // 目标文件已写入的字节数（断点续传时包含已有部分）
+	Total    int64         // 总字节数（未知时为-1）
+	Rate     float64       // 本次传输的平均速度，单位：byte/s
+	ETA      time.Duration // 预计剩余时间（未知时为-1）
+	Finished bool          // 传输是否已结束
+	Err      error         // 传输结束时的异常（成功时为nil）
+}
+
+// Percent 获取传输百分比
+//
+//	@author duanzt
+//	@date 2026-10-19 14:41:05
+//	@receiver p ProgressInfo
+//	@return float64 0~100，总字节数未知时返回-1
+func (p ProgressInfo) Percent() float64 {
+	if p.Total < 0 {
+		return -1
+	}
+	if p.Total == 0 {
+		return 100
+	}
+	return float64(p.Done) * 100 / float64(p.Total)
+}
+
+// IProgress 传输进度报告interface
+// 由写入目标文件的字节数驱动，在写入的goroutine中同步调用（默认间隔100ms），开始及结束时各调用一次；
+// 目录拷贝时每个文件分别报告（通过Name区分）
+type IProgress interface {
+
+	// Report 报告传输进度
+	//  @author duanzt
+	//  @date 2026-10-19 14:42:20
+	//  @param info ProgressInfo 传输进度（Finished为true时表示传输结束）
+	Report(info ProgressInfo)
+}
+
+// ProgressFunc 使用函数实现IProgress
+type ProgressFunc func(info ProgressInfo)
+
+// Report 报告传输进度
+//
+//	@author duanzt
+//	@date 2026-10-19 14:43:02
+//	@receiver f ProgressFunc
+//	@param info ProgressInfo 传输进度
+func (f ProgressFunc) Report(info ProgressInfo) {
+	f(info)
+}
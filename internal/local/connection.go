@@ -2,7 +2,7 @@
  * @Author: duanzt
  * @Date: 2023-07-14 10:27:45
  * @LastEditors: duanzt
- * @LastEditTime: 2023-07-17 18:53:14
+ * @LastEditTime: 2026-10-19 23:58:30
  * @FilePath: connection.go
  * @Description: 本地连接（逻辑上，并没有建立任何连接）
  *
@@ -11,16 +11,19 @@
 package local
 
 import (
+	"bytes"
 	"context"
 	"io"
+	"io/fs"
 	"os"
-	"strconv"
+	"os/exec"
+	"runtime"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/duanztop/gossh/internal"
 	"github.com/duanztop/gossh/internal/tools"
+	"github.com/duanztop/gossh/internal/transfer"
 )
 
 const (
@@ -31,7 +34,8 @@ const (
 
 // connection 本地连接（逻辑上，并没有建立任何连接）
 type connection struct {
-	addr string // 地址信息
+	addr                 string // 地址信息
+	transfer.ConnLimiter        // 连接级别的限速
 }
 
 // Close 关闭连接
@@ -76,137 +80,273 @@ func (c *connection) ExecShell(cxt context.Context, shell string) (string, error
 	})
 }
 
-// CopyFileLTR 拷贝文件流到远端
+// Run 执行shell命令，分别获取标准输出、错误输出及退出码，ctx取消时结束命令
 //
 //	@author duanzt
-//	@date 2023-07-14 09:56:42
-//	@param src io.Reader 流
-//	@param dest string 远端目标文件地址
-//	@param mode string 文件权限
-//	@return error ssh异常时返回
-func (c *connection) CopyFileITR(src io.Reader, dest string, mode string) error {
-	modeInt, err := strconv.ParseInt(mode, 8, 32)
-	if err != nil {
-		return err
+//	@date 2026-10-19 21:02:10
+//	@receiver c *connection
+//	@param ctx context.Context 上下文（取消时结束命令）
+//	@param shell string shell命令
+//	@return *internal.CommandResult 执行结果
+//	@return error 无法执行、退出码不为0或ctx取消时返回
+func (c *connection) Run(ctx context.Context, shell string) (*internal.CommandResult, error) {
+	result := &internal.CommandResult{ExitCode: -1}
+	cmd := exec.CommandContext(ctx, "sh", "-c", shell)
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/c", shell)
 	}
-	if _, err = tools.FileTools.CreateFile(dest); err != nil {
-		return err
+	// 命令被结束后不等待仍持有输出管道的子进程
+	cmd.WaitDelay = time.Second
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	result.Stdout, result.Stderr = stdout.String(), stderr.String()
+	if cmd.ProcessState != nil {
+		result.ExitCode = cmd.ProcessState.ExitCode()
 	}
-	dstFile, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE, os.FileMode(modeInt))
-	if err != nil {
-		return err
+	if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+		return result, ctxErr
 	}
-	defer dstFile.Close()
-	_, err = io.Copy(dstFile, src)
-	return err
+	return result, err
+}
+
+// CopyFileITR 拷贝文件流到本地文件
+//
+//	@author duanzt
+//	@date 2023-07-14 09:56:42
+//	@param src io.Reader 流
+//	@param dest string 目标文件地址
+//	@param mode string 文件权限（八进制或符号格式）
+//	@param opts ...internal.CopyOption 拷贝配置
+//	@return error 拷贝异常时返回
+func (c *connection) CopyFileITR(src io.Reader, dest string, mode string, opts ...internal.CopyOption) error {
+	return c.copyStream(src, dest, mode, c.CopyOptions(opts))
 }
 
-// CopyFileITRMon 拷贝文件流到远端（监控远端目标文件大小）
+// CopyFileITRMon 拷贝文件流到本地文件（监控目标文件大小）
 //
 //	@author duanzt
 //	@date 2023-07-14 10:02:16
 //	@param src io.Reader 流
-//	@param dest string 远端目标文件地址
-//	@param mode string 文件权限
-//	@param destSizeChan chan int64 返回远端目标文件大小，单位：byte
-//	@return error ssh异常时返回
-func (c *connection) CopyFileITRMon(src io.Reader, dest string, mode string, destSizeChan chan int64) (err error) {
-	modeInt, err := strconv.ParseInt(mode, 8, 32)
+//	@param dest string 目标文件地址
+//	@param mode string 文件权限（八进制或符号格式）
+//	@param destSizeChan chan int64 返回目标文件大小，单位：byte
+//	@param opts ...internal.CopyOption 拷贝配置
+//	@return error 拷贝异常时返回
+func (c *connection) CopyFileITRMon(src io.Reader, dest string, mode string, destSizeChan chan int64, opts ...internal.CopyOption) (err error) {
+	progress := transfer.NewChanProgress(destSizeChan)
+	defer progress.Close()
+	return c.CopyFileITR(src, dest, mode, append(opts[:len(opts):len(opts)], internal.WithProgress(progress))...)
+}
+
+// CopyFileLTR 拷贝本地文件
+//
+//	@author duanzt
+//	@date 2023-07-14 10:00:05
+//	@param src string 源文件地址
+//	@param dest string 目标文件地址
+//	@param mode string 文件权限（八进制或符号格式）
+//	@param opts ...internal.CopyOption 拷贝配置
+//	@return error 拷贝异常时返回
+func (c *connection) CopyFileLTR(src string, dest string, mode string, opts ...internal.CopyOption) error {
+	file, err := os.Open(src)
 	if err != nil {
 		return err
 	}
+	defer file.Close()
+	return c.CopyFileITR(file, dest, mode, opts...)
+}
 
-	if _, err = tools.FileTools.CreateFile(dest); err != nil {
-		return err
-	}
+// CopyFileLTRMon 拷贝本地文件（监控目标文件大小）
+//
+//	@author duanzt
+//	@date 2023-07-14 10:00:05
+//	@param src string 源文件地址
+//	@param dest string 目标文件地址
+//	@param mode string 文件权限（八进制或符号格式）
+//	@param destSizeChan chan int64 返回目标文件大小，单位：byte
+//	@param opts ...internal.CopyOption 拷贝配置
+//	@return error 拷贝异常时返回
+func (c *connection) CopyFileLTRMon(src string, dest string, mode string, destSizeChan chan int64, opts ...internal.CopyOption) (err error) {
+	progress := transfer.NewChanProgress(destSizeChan)
+	defer progress.Close()
+	return c.CopyFileLTR(src, dest, mode, append(opts[:len(opts):len(opts)], internal.WithProgress(progress))...)
+}
+
+// CopyFileRTL 拷贝本地文件（本地连接的源端与目标端均为本机）
+//
+//	@author duanzt
+//	@date 2023-07-14 09:59:07
+//	@param src string 源文件地址
+//	@param dest string 目标文件地址
+//	@param mode string 文件权限（八进制或符号格式）
+//	@param opts ...internal.CopyOption 拷贝配置
+//	@return error 拷贝异常时返回
+func (c *connection) CopyFileRTL(src string, dest string, mode string, opts ...internal.CopyOption) error {
+	return c.CopyFileLTR(src, dest, mode, opts...)
+}
+
+// CopyFileRTLMon 拷贝本地文件（监控目标文件大小，本地连接的源端与目标端均为本机）
+//
+//	@author duanzt
+//	@date 2023-07-14 09:59:07
+//	@param src string 源文件地址
+//	@param dest string 目标文件地址
+//	@param mode string 文件权限（八进制或符号格式）
+//	@param destSizeChan chan int64 返回目标文件大小，单位：byte
+//	@param opts ...internal.CopyOption 拷贝配置
+//	@return error 拷贝异常时返回
+func (c *connection) CopyFileRTLMon(src string, dest string, mode string, destSizeChan chan int64, opts ...internal.CopyOption) (err error) {
+	return c.CopyFileLTRMon(src, dest, mode, destSizeChan, opts...)
+}
 
-	dstFile, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE, os.FileMode(modeInt))
+// copyStream 将流写入本地文件，并按配置校验摘要、设置权限及所属用户
+//
+//	@author duanzt
+//	@date 2026-10-19 12:40:18
+//	@receiver c *connection
+//	@param src io.Reader 流
+//	@param dest string 目标文件地址
+//	@param mode string 文件权限（八进制或符号格式）
+//	@param o *internal.CopyOptions 拷贝配置
+//	@return error 拷贝异常时返回
+func (c *connection) copyStream(src io.Reader, dest, mode string, o *internal.CopyOptions) (err error) {
+	tracker := transfer.NewTracker(dest, o)
+	defer func() {
+		tracker.Finish(err)
+	}()
+	perm, err := tools.ModeTools.Resolve(mode, tools.FileTools.StatReader(src), o.PreserveMode, func() (fs.FileInfo, error) {
+		return os.Stat(dest)
+	})
 	if err != nil {
 		return err
 	}
-	defer dstFile.Close()
-	waitGroup := &sync.WaitGroup{}
-	waitGroup.Add(1)
-	go func() {
-		_, err = io.Copy(dstFile, src)
-		waitGroup.Done()
-	}()
+	return transfer.WriteLocal(src, dest, perm, o, tracker)
+}
 
-	ticker := time.NewTicker(time.Millisecond * 100)
-	defer ticker.Stop()
-	go func() {
-		defer func() {
-			_ = recover()
-		}()
-		for range ticker.C {
-			stat, _ := dstFile.Stat()
-			destSizeChan <- stat.Size()
-		}
-	}()
+// CopyDirLTR 递归拷贝目录（本地连接的源端与目标端均为本机）
+//
+//	@author duanzt
+//	@date 2026-10-19 11:44:10
+//	@receiver c *connection
+//	@param src string 源目录（为文件时仅拷贝该文件）
+//	@param dest string 目标目录
+//	@param opts ...internal.CopyOption 拷贝配置
+//	@return *internal.CopySummary 拷贝结果汇总
+//	@return error 拷贝异常时返回（未开启FailFast时汇总全部失败文件）
+func (c *connection) CopyDirLTR(src, dest string, opts ...internal.CopyOption) (*internal.CopySummary, error) {
+	return transfer.CopyDir(c, c, src, dest, func(src, dest, mode string) error {
+		return c.CopyFileLTR(src, dest, mode, opts...)
+	}, internal.NewCopyOptions(opts...))
+}
 
-	waitGroup.Wait()
-	stat, _ := dstFile.Stat()
-	destSizeChan <- stat.Size()
-	close(destSizeChan)
-	return err
+// CopyDirRTL 递归拷贝目录（本地连接的源端与目标端均为本机）
+//
+//	@author duanzt
+//	@date 2026-10-19 11:44:36
+//	@receiver c *connection
+//	@param src string 源目录（为文件时仅拷贝该文件）
+//	@param dest string 目标目录
+//	@param opts ...internal.CopyOption 拷贝配置
+//	@return *internal.CopySummary 拷贝结果汇总
+//	@return error 拷贝异常时返回（未开启FailFast时汇总全部失败文件）
+func (c *connection) CopyDirRTL(src, dest string, opts ...internal.CopyOption) (*internal.CopySummary, error) {
+	return c.CopyDirLTR(src, dest, opts...)
 }
 
-// CopyFileLTR 拷贝本地文件到远端
+// SyncDirLTR 同步目录（本地连接的源端与目标端均为本机）
 //
 //	@author duanzt
-//	@date 2023-07-14 10:00:05
-//	@param  src dest 本地文件地址
-//	@param dest string 远端目标文件地址
-//	@param mode string 文件权限
-//	@return error ssh异常时返回
-func (c *connection) CopyFileLTR(src string, dest string, mode string) error {
-	file, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	return c.CopyFileITR(file, dest, mode)
+//	@date 2026-10-19 18:27:10
+//	@receiver c *connection
+//	@param src string 源目录
+//	@param dest string 目标目录
+//	@param opts ...internal.CopyOption 拷贝配置
+//	@return *internal.SyncReport 同步结果
+//	@return error 同步异常时返回（未开启FailFast时汇总全部失败文件）
+func (c *connection) SyncDirLTR(src, dest string, opts ...internal.CopyOption) (*internal.SyncReport, error) {
+	fileOpts := transfer.SyncFileOptions(opts)
+	side := transfer.SyncSide{FS: c, Sum: transfer.FileSum}
+	return transfer.Sync(side, side, src, dest, func(src, dest, mode string) error {
+		return c.CopyFileLTR(src, dest, mode, fileOpts...)
+	}, internal.NewCopyOptions(opts...))
 }
 
-// CopyFileLTRMon 拷贝本地文件到远端（监控远端目标文件大小）
+// SyncDirRTL 同步目录（本地连接的源端与目标端均为本机）
 //
 //	@author duanzt
-//	@date 2023-07-14 10:00:05
-//	@param src string 本地文件地址
-//	@param dest string 远端目标文件地址
-//	@param mode string 文件权限
-//	@param destSizeChan chan int64 返回远端目标文件大小，单位：byte
-//	@return error ssh异常时返回
-func (c *connection) CopyFileLTRMon(src string, dest string, mode string, destSizeChan chan int64) (err error) {
-	file, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	return c.CopyFileITRMon(file, dest, mode, destSizeChan)
+//	@date 2026-10-19 18:27:35
+//	@receiver c *connection
+//	@param src string 源目录
+//	@param dest string 目标目录
+//	@param opts ...internal.CopyOption 拷贝配置
+//	@return *internal.SyncReport 同步结果
+//	@return error 同步异常时返回（未开启FailFast时汇总全部失败文件）
+func (c *connection) SyncDirRTL(src, dest string, opts ...internal.CopyOption) (*internal.SyncReport, error) {
+	return c.SyncDirLTR(src, dest, opts...)
 }
 
-// CopyFileRTL 拷贝远端文件到本地
+// Find 查找根目录下满足条件的文件
 //
 //	@author duanzt
-//	@date 2023-07-14 09:59:07
-//	@param src string 远端文件地址
-//	@param dest string 本地目标文件地址
-//	@param mode string 文件权限
-//	@return error ssh异常时返回
-func (c *connection) CopyFileRTL(src string, dest string, mode string) error {
-	return c.CopyFileLTR(src, dest, mode)
+//	@date 2026-10-19 19:36:10
+//	@receiver c *connection
+//	@param ctx context.Context 上下文（取消时停止遍历）
+//	@param root string 查找的根目录
+//	@param opts ...internal.FindOption 查找条件
+//	@return []*internal.FindEntry 查找到的文件
+//	@return error 根目录不存在或查找异常时返回
+func (c *connection) Find(ctx context.Context, root string, opts ...internal.FindOption) ([]*internal.FindEntry, error) {
+	return transfer.Find(ctx, c, root, internal.NewFindOptions(opts...))
 }
 
-// CopyFileRTLMon 拷贝远端文件到本地（监控本地目标文件大小）
+// Checksum 计算本地文件的摘要
 //
 //	@author duanzt
-//	@date 2023-07-14 09:59:07
-//	@param src string 远端文件地址
-//	@param dest string 本地目标文件地址
-//	@param mode string 文件权限
-//	@param destSizeChan chan int64 返回本地目标文件大小，单位：byte
-//	@return error ssh异常时返回
-func (c *connection) CopyFileRTLMon(src string, dest string, mode string, destSizeChan chan int64) (err error) {
-	return c.CopyFileLTRMon(src, dest, mode, destSizeChan)
+//	@date 2026-10-19 20:39:20
+//	@receiver c *connection
+//	@param name string 文件路径
+//	@param algo internal.HashAlgorithm 摘要算法
+//	@return string 摘要（十六进制）
+//	@return error 文件不存在或计算异常时返回
+func (c *connection) Checksum(name string, algo internal.HashAlgorithm) (string, error) {
+	return transfer.FileSum(name, algo)
+}
+
+// ExtractArchive 将本地归档文件解压到本地目标目录（纯Go实现，不依赖tar/unzip命令）
+//
+//	@author duanzt
+//	@date 2026-10-19 20:14:20
+//	@receiver c *connection
+//	@param archive string 归档文件路径
+//	@param dest string 目标目录
+//	@param opts ...internal.ArchiveOption 归档配置
+//	@return *internal.ArchiveManifest 已解压的条目清单
+//	@return error 解压异常时返回
+func (c *connection) ExtractArchive(archive, dest string, opts ...internal.ArchiveOption) (*internal.ArchiveManifest, error) {
+	o, err := internal.NewArchiveOptions(archive, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return transfer.ExtractArchive(c, archive, dest, o)
+}
+
+// CreateArchive 将本地目录打包为本地归档文件（纯Go实现，不依赖tar/zip命令）
+//
+//	@author duanzt
+//	@date 2026-10-19 20:14:50
+//	@receiver c *connection
+//	@param src string 源目录
+//	@param archive string 归档文件路径
+//	@param opts ...internal.ArchiveOption 归档配置
+//	@return *internal.ArchiveManifest 已打包的条目清单
+//	@return error 打包异常时返回
+func (c *connection) CreateArchive(src, archive string, opts ...internal.ArchiveOption) (*internal.ArchiveManifest, error) {
+	o, err := internal.NewArchiveOptions(archive, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return transfer.CreateArchive(c, src, archive, o)
 }
 
 // GetAddr 获取ssh连接地址（例127.0.0.1:22）
@@ -0,0 +1,283 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 10:05:37
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 19:40:00
+ * @FilePath: filesystem.go
+ * @Description: 本地连接的文件系统操作（基于os实现）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package local
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/duanztop/gossh/internal"
+)
+
+// Open 以只读方式打开文件
+//
+//	@author duanzt
+//	@date 2026-10-19 10:42:40
+//	@receiver c *connection
+//	@param name string 文件路径
+//	@return fs.File 文件对象，使用完毕后需要关闭
+//	@return error 文件不存在等异常时返回
+func (c *connection) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+// OpenFile 按标志打开文件
+//
+//	@author duanzt
+//	@date 2026-10-19 19:06:02
+//	@receiver c *connection
+//	@param name string 文件路径
+//	@param flag int 打开标志（同os.OpenFile）
+//	@param perm fs.FileMode 新建文件的权限（受umask影响）
+//	@return internal.IFile 文件句柄（*os.File）
+//	@return error 文件不存在等异常时返回
+func (c *connection) OpenFile(name string, flag int, perm fs.FileMode) (internal.IFile, error) {
+	file, err := os.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// Create 创建文件（已存在时清空），以读写方式打开
+//
+//	@author duanzt
+//	@date 2026-10-19 19:06:30
+//	@receiver c *connection
+//	@param name string 文件路径
+//	@return internal.IFile 文件句柄（*os.File）
+//	@return error 创建异常时返回
+func (c *connection) Create(name string) (internal.IFile, error) {
+	return c.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+}
+
+// ReadFile 读取文件全部内容
+//
+//	@author duanzt
+//	@date 2026-10-19 19:06:58
+//	@receiver c *connection
+//	@param name string 文件路径
+//	@return []byte 文件内容
+//	@return error 文件不存在等异常时返回
+func (c *connection) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+// WriteFile 将内容写入文件
+//
+//	@author duanzt
+//	@date 2026-10-19 19:07:25
+//	@receiver c *connection
+//	@param name string 文件路径
+//	@param data []byte 文件内容
+//	@param perm fs.FileMode 新建文件的权限（受umask影响）
+//	@return error 写入异常时返回
+func (c *connection) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(name, data, perm)
+}
+
+// Stat 获取文件信息（跟随符号链接）
+//
+//	@author duanzt
+//	@date 2026-10-19 10:06:02
+//	@receiver c *connection
+//	@param name string 文件路径
+//	@return fs.FileInfo 文件信息
+//	@return error 文件不存在等异常时返回
+func (c *connection) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+// Lstat 获取文件信息（不跟随符号链接）
+//
+//	@author duanzt
+//	@date 2026-10-19 10:06:25
+//	@receiver c *connection
+//	@param name string 文件路径
+//	@return fs.FileInfo 文件信息
+//	@return error 文件不存在等异常时返回
+func (c *connection) Lstat(name string) (fs.FileInfo, error) {
+	return os.Lstat(name)
+}
+
+// ReadDir 读取目录下的文件信息（按文件名排序）
+//
+//	@author duanzt
+//	@date 2026-10-19 10:06:51
+//	@receiver c *connection
+//	@param name string 目录路径
+//	@return []fs.FileInfo 目录下的文件信息
+//	@return error 目录不存在等异常时返回
+func (c *connection) ReadDir(name string) ([]fs.FileInfo, error) {
+	entries, err := os.ReadDir(name)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]fs.FileInfo, 0, len(entries))
+	for i := range entries {
+		info, err := entries[i].Info()
+		if err != nil {
+			// 读取目录与获取文件信息之间文件被删除，忽略该文件
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// Remove 删除文件或空目录
+//
+//	@author duanzt
+//	@date 2026-10-19 10:07:20
+//	@receiver c *connection
+//	@param name string 文件/目录路径
+//	@return error 删除异常时返回
+func (c *connection) Remove(name string) error {
+	return os.Remove(name)
+}
+
+// RemoveAll 递归删除文件或目录（路径不存在时不返回异常）
+//
+//	@author duanzt
+//	@date 2026-10-19 10:07:44
+//	@receiver c *connection
+//	@param name string 文件/目录路径
+//	@return error 删除异常时返回
+func (c *connection) RemoveAll(name string) error {
+	return os.RemoveAll(name)
+}
+
+// Rename 重命名（目标文件存在时覆盖）
+//
+//	@author duanzt
+//	@date 2026-10-19 10:08:09
+//	@receiver c *connection
+//	@param oldname string 原路径
+//	@param newname string 新路径
+//	@return error 重命名异常时返回
+func (c *connection) Rename(oldname, newname string) error {
+	return os.Rename(oldname, newname)
+}
+
+// Mkdir 创建目录
+//
+//	@author duanzt
+//	@date 2026-10-19 10:08:33
+//	@receiver c *connection
+//	@param name string 目录路径
+//	@param perm fs.FileMode 目录权限
+//	@return error 创建异常时返回
+func (c *connection) Mkdir(name string, perm fs.FileMode) error {
+	return os.Mkdir(name, perm)
+}
+
+// MkdirAll 递归创建目录（目录已存在时不返回异常）
+//
+//	@author duanzt
+//	@date 2026-10-19 10:08:58
+//	@receiver c *connection
+//	@param name string 目录路径
+//	@param perm fs.FileMode 新建目录的权限
+//	@return error 创建异常时返回
+func (c *connection) MkdirAll(name string, perm fs.FileMode) error {
+	return os.MkdirAll(name, perm)
+}
+
+// Chmod 修改文件权限
+//
+//	@author duanzt
+//	@date 2026-10-19 10:09:21
+//	@receiver c *connection
+//	@param name string 文件路径
+//	@param mode fs.FileMode 文件权限
+//	@return error 修改异常时返回
+func (c *connection) Chmod(name string, mode fs.FileMode) error {
+	return os.Chmod(name, mode)
+}
+
+// Chown 修改文件所属用户及用户组
+//
+//	@author duanzt
+//	@date 2026-10-19 10:09:47
+//	@receiver c *connection
+//	@param name string 文件路径
+//	@param uid int 用户id
+//	@param gid int 用户组id
+//	@return error 修改异常时返回
+func (c *connection) Chown(name string, uid, gid int) error {
+	return os.Chown(name, uid, gid)
+}
+
+// Chtimes 修改文件访问时间及修改时间
+//
+//	@author duanzt
+//	@date 2026-10-19 10:10:12
+//	@receiver c *connection
+//	@param name string 文件路径
+//	@param atime time.Time 访问时间
+//	@param mtime time.Time 修改时间
+//	@return error 修改异常时返回
+func (c *connection) Chtimes(name string, atime, mtime time.Time) error {
+	return os.Chtimes(name, atime, mtime)
+}
+
+// Symlink 创建符号链接newname，指向oldname
+//
+//	@author duanzt
+//	@date 2026-10-19 10:10:38
+//	@receiver c *connection
+//	@param oldname string 链接指向的路径
+//	@param newname string 符号链接路径
+//	@return error 创建异常时返回
+func (c *connection) Symlink(oldname, newname string) error {
+	return os.Symlink(oldname, newname)
+}
+
+// Readlink 读取符号链接指向的路径
+//
+//	@author duanzt
+//	@date 2026-10-19 10:11:03
+//	@receiver c *connection
+//	@param name string 符号链接路径
+//	@return string 链接指向的路径
+//	@return error 读取异常时返回
+func (c *connection) Readlink(name string) (string, error) {
+	return os.Readlink(name)
+}
+
+// Truncate 修改文件大小
+//
+//	@author duanzt
+//	@date 2026-10-19 10:11:29
+//	@receiver c *connection
+//	@param name string 文件路径
+//	@param size int64 文件大小，单位：byte
+//	@return error 修改异常时返回
+func (c *connection) Truncate(name string, size int64) error {
+	return os.Truncate(name, size)
+}
+
+// Glob 查找匹配通配符的文件
+//
+//	@author duanzt
+//	@date 2026-10-19 19:35:20
+//	@receiver c *connection
+//	@param pattern string 通配符
+//	@return []string 匹配的文件路径
+//	@return error 通配符不合法时返回
+func (c *connection) Glob(pattern string) ([]string, error) {
+	return filepath.Glob(pattern)
+}
@@ -0,0 +1,223 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 18:43:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 18:43:10
+ * @FilePath: follow.go
+ * @Description: 本地文件跟踪（定期检查新增内容，按inode识别轮转，按大小识别截断）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package local
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/duanztop/gossh/internal"
+)
+
+// maxFollowLine 单行的最大长度（超过时按该长度拆分，避免没有换行符的内容占用过多内存）
+const maxFollowLine = 1 << 20
+
+// follower 本地文件跟踪的上下文
+type follower struct {
+	host    string
+	name    string
+	handler internal.FollowFunc
+	file    *os.File
+	info    fs.FileInfo // 已打开文件的信息
+	offset  int64       // 已读取的位置
+	partial []byte      // 尚未遇到换行符的内容
+	buf     []byte
+}
+
+// Follow 跟踪文件新增的行（类似tail -F），文件被轮转（重命名后新建）或截断时从新文件开头继续读取，
+// 文件不存在时等待其被创建；阻塞直到ctx取消
+//
+//	@author duanzt
+//	@date 2026-10-19 18:44:02
+//	@receiver c *connection
+//	@param ctx context.Context 上下文（取消时停止跟踪）
+//	@param name string 文件路径
+//	@param handler internal.FollowFunc 处理跟踪到的行
+//	@param opts ...internal.FollowOption 跟踪配置
+//	@return error ctx取消时返回nil，读取异常时返回
+func (c *connection) Follow(ctx context.Context, name string, handler internal.FollowFunc, opts ...internal.FollowOption) error {
+	o := internal.NewFollowOptions(opts...)
+	f := &follower{host: c.GetAddr(), name: name, handler: handler, buf: make([]byte, 32<<10)}
+	defer f.close()
+	if err := f.open(!o.FromStart); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	ticker := time.NewTicker(o.Interval)
+	defer ticker.Stop()
+	for {
+		if err := f.poll(); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// open 打开文件
+//
+//	@author duanzt
+//	@date 2026-10-19 18:45:12
+//	@receiver f *follower
+//	@param atEnd bool 是否从文件末尾开始读取
+//	@return error 打开异常时返回（文件不存在时返回fs.ErrNotExist）
+func (f *follower) open(atEnd bool) error {
+	file, err := os.Open(f.name)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.info, f.offset, f.partial = file, info, 0, f.partial[:0]
+	if atEnd {
+		f.offset = info.Size()
+	}
+	return nil
+}
+
+// poll 读取新增内容，并检查文件是否被轮转或截断
+//
+//	@author duanzt
+//	@date 2026-10-19 18:46:02
+//	@receiver f *follower
+//	@return error 读取异常时返回
+func (f *follower) poll() error {
+	if f.file == nil {
+		// 文件尚未创建或轮转后尚未新建，新建的文件从开头读取
+		if err := f.open(false); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+	}
+	if err := f.read(); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(f.name)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		// 已被重命名或删除，旧文件读取完毕后等待新文件
+		if err := f.read(); err != nil {
+			return err
+		}
+		f.flush()
+		f.close()
+		return nil
+	case err != nil:
+		return err
+	case !os.SameFile(info, f.info):
+		// 轮转：读取旧文件剩余内容后切换到新文件开头
+		if err := f.read(); err != nil {
+			return err
+		}
+		f.flush()
+		f.close()
+		if err := f.open(false); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		if f.file != nil {
+			return f.read()
+		}
+	case info.Size() < f.offset:
+		// 截断：从开头重新读取
+		f.offset, f.partial = 0, f.partial[:0]
+		return f.read()
+	}
+	return nil
+}
+
+// read 读取当前位置之后的内容并按行处理
+//
+//	@author duanzt
+//	@date 2026-10-19 18:47:10
+//	@receiver f *follower
+//	@return error 读取异常时返回
+func (f *follower) read() error {
+	for {
+		n, err := f.file.ReadAt(f.buf, f.offset)
+		f.offset += int64(n)
+		f.emit(f.buf[:n])
+		if err == io.EOF || (err == nil && n == 0) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// emit 按换行符拆分内容，完整的行交给处理方法
+//
+//	@author duanzt
+//	@date 2026-10-19 18:47:50
+//	@receiver f *follower
+//	@param b []byte 新读取的内容
+func (f *follower) emit(b []byte) {
+	for len(b) > 0 {
+		i := bytes.IndexByte(b, '\n')
+		if i < 0 {
+			f.partial = append(f.partial, b...)
+			if len(f.partial) >= maxFollowLine {
+				f.flush()
+			}
+			return
+		}
+		f.partial = append(f.partial, b[:i]...)
+		f.line()
+		b = b[i+1:]
+	}
+}
+
+// flush 将尚未遇到换行符的内容作为一行交给处理方法
+//
+//	@author duanzt
+//	@date 2026-10-19 18:48:30
+//	@receiver f *follower
+func (f *follower) flush() {
+	if len(f.partial) > 0 {
+		f.line()
+	}
+}
+
+// line 将已缓存的内容作为一行交给处理方法（可能为空行）
+//
+//	@author duanzt
+//	@date 2026-10-19 18:48:45
+//	@receiver f *follower
+func (f *follower) line() {
+	text := string(bytes.TrimSuffix(f.partial, []byte{'\r'}))
+	f.partial = f.partial[:0]
+	f.handler(&internal.FollowLine{Host: f.host, Path: f.name, Text: text, Time: time.Now()})
+}
+
+// close 关闭已打开的文件
+//
+//	@author duanzt
+//	@date 2026-10-19 18:49:02
+//	@receiver f *follower
+func (f *follower) close() {
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+}
@@ -0,0 +1,92 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 22:56:40
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:56:30
+ * @FilePath: shell.go
+ * @Description: 本地交互式shell及网络连接
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package local
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"github.com/duanztop/gossh/internal"
+)
+
+// Shell 启动本地shell（$SHELL，未设置时为sh，windows为cmd），不分配伪终端，窗口大小配置被忽略
+//
+//	@author duanzt
+//	@date 2026-10-19 22:57:10
+//	@receiver c *connection
+//	@param ctx context.Context 上下文（取消时结束shell）
+//	@param stdin io.Reader 输入
+//	@param stdout io.Writer 输出
+//	@param stderr io.Writer 错误输出
+//	@param opts ...internal.ShellOption 配置
+//	@return error 无法启动、退出码不为0（*exec.ExitError）或ctx取消时返回
+func (c *connection) Shell(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, opts ...internal.ShellOption) error {
+	o := internal.NewShellOptions(opts...)
+	shell, flag := os.Getenv("SHELL"), "-c"
+	if shell == "" {
+		shell = "sh"
+	}
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/c"
+	}
+	cmd := exec.CommandContext(ctx, shell)
+	if o.Command != "" {
+		cmd = exec.CommandContext(ctx, shell, flag, o.Command)
+	}
+	cmd.WaitDelay = time.Second
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = stdin, stdout, stderr
+	err := cmd.Run()
+	if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+// Dial 从本地建立网络连接
+//
+//	@author duanzt
+//	@date 2026-10-19 22:57:40
+//	@receiver c *connection
+//	@param network string 网络类型
+//	@param addr string 目标地址
+//	@return net.Conn 网络连接
+//	@return error 连接异常时返回
+func (c *connection) Dial(network, addr string) (net.Conn, error) {
+	return net.Dial(network, addr)
+}
+
+// Listen 在本地监听
+//
+//	@author duanzt
+//	@date 2026-10-19 22:58:10
+//	@receiver c *connection
+//	@param network string 网络类型
+//	@param addr string 监听地址
+//	@return net.Listener 监听
+//	@return error 监听异常时返回
+func (c *connection) Listen(network, addr string) (net.Listener, error) {
+	return net.Listen(network, addr)
+}
+
+// Done 本地连接不会断开
+//
+//	@author duanzt
+//	@date 2026-10-19 23:56:10
+//	@receiver c *connection
+//	@return <-chan struct{} nil（永不关闭）
+func (c *connection) Done() <-chan struct{} {
+	return nil
+}
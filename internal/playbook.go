@@ -0,0 +1,261 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 22:20:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:59:30
+ * @FilePath: playbook.go
+ * @Description: 任务文件（shell、copy、template、fetch步骤）、执行结果及配置
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package internal
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Playbook 任务文件
+type Playbook struct {
+	Name     string         // 名称
+	Vars     map[string]any // 变量（优先级高于主机变量）
+	Tasks    []*Task        // 任务（按顺序执行）
+	Handlers []*Task        // 由任务通知、在全部任务完成后执行的任务（按定义顺序，每个最多执行一次）
+	Dir      string         // 本地文件的相对路径基于该目录（读取任务文件时为其所在目录）
+}
+
+// Task 任务，Shell、Copy、Template、Fetch中有且仅有一个
+// 字符串字段均为text/template模板，可引用变量、注册的结果及循环的item，例如{{ .version }}、{{ .current.stdout }}
+type Task struct {
+	Name         string        // 名称
+	Shell        string        // 执行shell命令（默认视为有变更，退出码不为0时失败）
+	Copy         *CopyStep     // 拷贝本地文件/目录或内容到目标端
+	Template     *TemplateStep // 渲染本地模板文件后写入目标端
+	Fetch        *FetchStep    // 拷贝目标端文件到本地
+	When         string        // 执行条件（模板表达式，例如eq .current.rc 0，为false时跳过）
+	Register     string        // 将执行结果注册为变量，供之后的任务使用
+	Loop         any           // 循环执行的列表，或引用列表变量的模板（例如{{ .packages }}），当前值为{{ .item }}
+	Notify       []string      // 有变更时通知的handler名称
+	ChangedWhen  string        // 是否有变更的条件（模板表达式，可通过.result引用当前结果）
+	FailedWhen   string        // 是否失败的条件（模板表达式，设置时忽略shell的退出码）
+	IgnoreErrors bool          // 失败时是否继续执行之后的任务
+}
+
+// Action 获取任务的步骤类型
+//
+//	@author duanzt
+//	@date 2026-10-19 22:20:50
+//	@receiver t *Task
+//	@return string shell、copy、template、fetch，未设置时为空
+func (t *Task) Action() string {
+	switch {
+	case t.Shell != "":
+		return "shell"
+	case t.Copy != nil:
+		return "copy"
+	case t.Template != nil:
+		return "template"
+	case t.Fetch != nil:
+		return "fetch"
+	}
+	return ""
+}
+
+// CopyStep 拷贝步骤（内容与目标端一致时无变更）
+type CopyStep struct {
+	Src     string // 本地文件或目录（目录按摘要同步）
+	Content string // 写入的内容（未设置Src时使用）
+	Dest    string // 目标端路径
+	Mode    string // 文件权限（八进制如0755，或符号格式如u+x,go-w；Src为目录时作用于其中的文件，内容一致但权限不同时同样修正）
+}
+
+// TemplateStep 模板步骤（渲染结果与目标端一致时无变更）
+type TemplateStep struct {
+	Src  string // 本地模板文件（text/template）
+	Dest string // 目标端路径
+	Mode string // 文件权限
+}
+
+// FetchStep 拉取步骤（内容与本地文件一致时无变更）
+type FetchStep struct {
+	Src  string // 目标端文件
+	Dest string // 本地路径
+}
+
+// TaskStatus 任务执行状态
+type TaskStatus string
+
+const (
+
+	// TaskOk 执行成功且无变更
+	TaskOk TaskStatus = "ok"
+
+	// TaskChanged 执行成功且有变更
+	TaskChanged TaskStatus = "changed"
+
+	// TaskSkipped 不满足执行条件，已跳过
+	TaskSkipped TaskStatus = "skipped"
+
+	// TaskFailed 执行失败
+	TaskFailed TaskStatus = "failed"
+)
+
+// TaskResult 任务在单台主机上的执行结果（循环时每个item一个结果）
+type TaskResult struct {
+	Task     *Task         // 任务
+	Host     string        // 主机名称
+	Item     any           // 循环的当前值（未循环时为nil）
+	Status   TaskStatus    // 执行状态
+	Stdout   string        // 标准输出（shell）
+	Stderr   string        // 错误输出（shell）
+	ExitCode int           // 退出码（shell，其它步骤为0）
+	Err      error         // 执行异常
+	Ignored  bool          // 失败但设置了IgnoreErrors
+	Handler  bool          // 是否为handler
+	Duration time.Duration // 耗时
+}
+
+// Recap 单台主机的执行汇总
+type Recap struct {
+	Host        Host          // 主机
+	Ok          int           // 成功且无变更的任务数
+	Changed     int           // 有变更的任务数
+	Skipped     int           // 跳过的任务数
+	Failed      int           // 失败的任务数
+	Ignored     int           // 失败但忽略的任务数
+	Unreachable bool          // 是否无法建立连接
+	Err         error         // 导致停止执行的异常
+	Results     []*TaskResult // 全部执行结果（按执行顺序）
+	Duration    time.Duration // 耗时
+}
+
+// PlaybookReport 任务文件的执行结果
+type PlaybookReport struct {
+	Recaps   []*Recap      // 各主机的执行汇总（与主机列表顺序一致）
+	Duration time.Duration // 总耗时
+}
+
+// Err 汇总执行异常，全部主机成功时返回nil
+//
+//	@author duanzt
+//	@date 2026-10-19 22:21:30
+//	@receiver r *PlaybookReport
+//	@return error 执行异常（包装首个失败主机的异常）
+func (r *PlaybookReport) Err() error {
+	var first *Recap
+	failed := 0
+	for _, recap := range r.Recaps {
+		if recap.Err != nil {
+			failed++
+			if first == nil {
+				first = recap
+			}
+		}
+	}
+	if first == nil {
+		return nil
+	}
+	return fmt.Errorf("%d台主机执行失败，首个异常：%s: %w", failed, first.Host.DisplayName(), first.Err)
+}
+
+// WriteTo 输出各主机的执行汇总，格式同ansible的PLAY RECAP
+//
+//	@author duanzt
+//	@date 2026-10-19 22:22:10
+//	@receiver r *PlaybookReport
+//	@param w io.Writer 输出
+//	@return int64 输出的字节数
+//	@return error 输出异常时返回
+func (r *PlaybookReport) WriteTo(w io.Writer) (int64, error) {
+	width := 0
+	for _, recap := range r.Recaps {
+		if n := len(recap.Host.DisplayName()); n > width {
+			width = n
+		}
+	}
+	var b strings.Builder
+	b.WriteString("PLAY RECAP " + strings.Repeat("*", 60) + "\n")
+	for _, recap := range r.Recaps {
+		unreachable := 0
+		if recap.Unreachable {
+			unreachable = 1
+		}
+		fmt.Fprintf(&b, "%-*s : ok=%-4d changed=%-4d unreachable=%-4d failed=%-4d skipped=%-4d ignored=%d\n", width, recap.Host.DisplayName(),
+			recap.Ok, recap.Changed, unreachable, recap.Failed, recap.Skipped, recap.Ignored)
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// PlaybookOptions 任务文件执行配置
+type PlaybookOptions struct {
+	Vars    map[string]any    // 额外变量（优先级最高）
+	Handler func(*TaskResult) // 每个任务（循环时每个item）执行完成时调用（多主机时串行调用）
+	Fanout  *FanoutOptions    // 多主机执行配置
+}
+
+// PlaybookOption 任务文件执行配置项
+type PlaybookOption func(*PlaybookOptions)
+
+// NewPlaybookOptions 根据配置项生成任务文件执行配置
+//
+//	@author duanzt
+//	@date 2026-10-19 22:22:50
+//	@param opts ...PlaybookOption 配置项
+//	@return *PlaybookOptions 任务文件执行配置
+func NewPlaybookOptions(opts ...PlaybookOption) *PlaybookOptions {
+	o := &PlaybookOptions{Fanout: NewFanoutOptions()}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return o
+}
+
+// WithExtraVars 设置额外变量（覆盖任务文件及主机清单中的同名变量）
+//
+//	@author duanzt
+//	@date 2026-10-19 22:23:20
+//	@param vars map[string]any 变量
+//	@return PlaybookOption 配置项
+func WithExtraVars(vars map[string]any) PlaybookOption {
+	return func(o *PlaybookOptions) {
+		if o.Vars == nil {
+			o.Vars = map[string]any{}
+		}
+		for k, v := range vars {
+			o.Vars[k] = v
+		}
+	}
+}
+
+// WithTaskHandler 设置每个任务执行完成时的处理方法（可用于实时输出）
+//
+//	@author duanzt
+//	@date 2026-10-19 22:23:50
+//	@param handler func(*TaskResult) 处理方法
+//	@return PlaybookOption 配置项
+func WithTaskHandler(handler func(*TaskResult)) PlaybookOption {
+	return func(o *PlaybookOptions) {
+		o.Handler = handler
+	}
+}
+
+// WithPlaybookFanout 设置多主机执行配置（并发数、单台主机超时、建立连接的方法等）
+//
+//	@author duanzt
+//	@date 2026-10-19 22:24:20
+//	@param opts ...FanoutOption 多主机执行配置项
+//	@return PlaybookOption 配置项
+func WithPlaybookFanout(opts ...FanoutOption) PlaybookOption {
+	return func(o *PlaybookOptions) {
+		for _, opt := range opts {
+			if opt != nil {
+				opt(o.Fanout)
+			}
+		}
+	}
+}
@@ -0,0 +1,202 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 22:25:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 22:25:10
+ * @FilePath: parse.go
+ * @Description: 解析yaml格式的任务文件
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package playbook
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/duanztop/gossh/internal"
+	"gopkg.in/yaml.v3"
+)
+
+// stringList 字符串或字符串列表
+type stringList []string
+
+// UnmarshalYAML 支持单个字符串或字符串列表
+//
+//	@author duanzt
+//	@date 2026-10-19 22:25:40
+//	@receiver l *stringList
+//	@param node *yaml.Node yaml节点
+//	@return error 格式不正确时返回
+func (l *stringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*l = stringList{node.Value}
+		return nil
+	}
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// rawTask yaml格式的任务
+type rawTask struct {
+	Name         string                 `yaml:"name"`
+	Shell        string                 `yaml:"shell"`
+	Copy         *internal.CopyStep     `yaml:"copy"`
+	Template     *internal.TemplateStep `yaml:"template"`
+	Fetch        *internal.FetchStep    `yaml:"fetch"`
+	When         string                 `yaml:"when"`
+	Register     string                 `yaml:"register"`
+	Loop         any                    `yaml:"loop"`
+	Notify       stringList             `yaml:"notify"`
+	ChangedWhen  string                 `yaml:"changed_when"`
+	FailedWhen   string                 `yaml:"failed_when"`
+	IgnoreErrors bool                   `yaml:"ignore_errors"`
+}
+
+// rawPlaybook yaml格式的任务文件
+type rawPlaybook struct {
+	Name     string         `yaml:"name"`
+	Vars     map[string]any `yaml:"vars"`
+	Tasks    []*rawTask     `yaml:"tasks"`
+	Handlers []*rawTask     `yaml:"handlers"`
+}
+
+// Parse 解析yaml格式的任务文件，顶层为包含name、vars、tasks、handlers的映射，或直接为任务列表；
+// 步骤的字段名为小写（src、content、dest、mode），不支持的字段视为错误
+//
+//	@author duanzt
+//	@date 2026-10-19 22:26:20
+//	@param data []byte 任务文件内容
+//	@param dir string 本地文件相对路径的基准目录
+//	@return *internal.Playbook 任务文件
+//	@return error 格式不正确时返回
+func Parse(data []byte, dir string) (*internal.Playbook, error) {
+	var raw rawPlaybook
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("yaml解析失败: %w", err)
+	}
+	var target any = &raw
+	if len(node.Content) > 0 && node.Content[0].Kind == yaml.SequenceNode {
+		target = &raw.Tasks
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(target); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("任务文件格式不正确: %w", err)
+	}
+
+	pb := &internal.Playbook{Name: raw.Name, Vars: raw.Vars, Dir: dir}
+	if pb.Vars == nil {
+		pb.Vars = map[string]any{}
+	}
+	handlers := map[string]bool{}
+	for i, r := range raw.Handlers {
+		if r != nil && r.Name == "" {
+			return nil, fmt.Errorf("第%d个handler缺少name", i+1)
+		}
+		task, err := convertTask(r, fmt.Sprintf("第%d个handler", i+1))
+		if err != nil {
+			return nil, err
+		}
+		handlers[task.Name] = true
+		pb.Handlers = append(pb.Handlers, task)
+	}
+	for i, r := range raw.Tasks {
+		task, err := convertTask(r, fmt.Sprintf("第%d个任务", i+1))
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range task.Notify {
+			if !handlers[name] {
+				return nil, fmt.Errorf("第%d个任务通知的handler %s不存在", i+1, name)
+			}
+		}
+		pb.Tasks = append(pb.Tasks, task)
+	}
+	return pb, nil
+}
+
+// Load 读取本地任务文件，本地文件的相对路径基于任务文件所在目录
+//
+//	@author duanzt
+//	@date 2026-10-19 22:27:02
+//	@param name string 任务文件路径
+//	@return *internal.Playbook 任务文件
+//	@return error 读取或解析异常时返回
+func Load(name string) (*internal.Playbook, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	pb, err := Parse(data, filepath.Dir(name))
+	if err != nil {
+		return nil, fmt.Errorf("任务文件%s解析失败: %w", name, err)
+	}
+	return pb, nil
+}
+
+// convertTask 转换并校验任务
+//
+//	@author duanzt
+//	@date 2026-10-19 22:27:40
+//	@param r *rawTask yaml格式的任务
+//	@param desc string 任务描述（用于异常信息）
+//	@return *internal.Task 任务
+//	@return error 步骤数量不为1或步骤缺少必要字段时返回
+func convertTask(r *rawTask, desc string) (*internal.Task, error) {
+	if r == nil {
+		return nil, fmt.Errorf("%s为空", desc)
+	}
+	if r.Name != "" {
+		desc += "(" + r.Name + ")"
+	}
+	task := &internal.Task{
+		Name:         r.Name,
+		Shell:        r.Shell,
+		Copy:         r.Copy,
+		Template:     r.Template,
+		Fetch:        r.Fetch,
+		When:         r.When,
+		Register:     r.Register,
+		Loop:         r.Loop,
+		Notify:       r.Notify,
+		ChangedWhen:  r.ChangedWhen,
+		FailedWhen:   r.FailedWhen,
+		IgnoreErrors: r.IgnoreErrors,
+	}
+	steps := 0
+	for _, set := range []bool{task.Shell != "", task.Copy != nil, task.Template != nil, task.Fetch != nil} {
+		if set {
+			steps++
+		}
+	}
+	if steps != 1 {
+		return nil, fmt.Errorf("%s应有且仅有一个步骤（shell、copy、template、fetch）", desc)
+	}
+	switch task.Loop.(type) {
+	case nil, []any, string:
+	default:
+		return nil, fmt.Errorf("%s的loop应为列表或引用列表变量的模板", desc)
+	}
+	switch {
+	case task.Copy != nil && (task.Copy.Dest == "" || task.Copy.Src == "" && task.Copy.Content == ""):
+		return nil, fmt.Errorf("%s的copy需要dest及src或content", desc)
+	case task.Template != nil && (task.Template.Src == "" || task.Template.Dest == ""):
+		return nil, fmt.Errorf("%s的template需要src及dest", desc)
+	case task.Fetch != nil && (task.Fetch.Src == "" || task.Fetch.Dest == ""):
+		return nil, fmt.Errorf("%s的fetch需要src及dest", desc)
+	}
+	if task.Name == "" {
+		task.Name = task.Action()
+	}
+	return task, nil
+}
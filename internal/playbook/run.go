@@ -0,0 +1,381 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 22:28:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 22:28:10
+ * @FilePath: run.go
+ * @Description: 在连接上执行任务文件（条件、注册结果、循环、handler及执行汇总）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package playbook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/duanztop/gossh/internal"
+	"github.com/duanztop/gossh/internal/batch"
+)
+
+// runner 单台主机上任务文件的执行过程
+type runner struct {
+	pb       *internal.Playbook
+	conn     internal.IConnection
+	host     internal.Host
+	o        *internal.PlaybookOptions
+	recap    *internal.Recap
+	vars     map[string]any  // 模板变量（主机变量、任务文件变量、额外变量及注册的结果）
+	notified map[string]bool // 已通知的handler
+}
+
+// Run 在连接上依次执行任务文件中的任务，任一任务失败（未设置IgnoreErrors）时停止，
+// 全部任务成功后执行被通知的handler
+//
+//	@author duanzt
+//	@date 2026-10-19 22:28:40
+//	@param ctx context.Context 上下文（取消时结束执行中的命令并停止）
+//	@param pb *internal.Playbook 任务文件
+//	@param conn internal.IConnection 连接（远程或本地）
+//	@param host internal.Host 主机（Vars作为变量，Name用于展示）
+//	@param o *internal.PlaybookOptions 执行配置
+//	@return *internal.Recap 执行汇总
+func Run(ctx context.Context, pb *internal.Playbook, conn internal.IConnection, host internal.Host, o *internal.PlaybookOptions) *internal.Recap {
+	start := time.Now()
+	r := &runner{
+		pb:       pb,
+		conn:     conn,
+		host:     host,
+		o:        o,
+		recap:    &internal.Recap{Host: host},
+		vars:     map[string]any{},
+		notified: map[string]bool{},
+	}
+	for k, v := range host.Vars {
+		r.vars[k] = v
+	}
+	for k, v := range pb.Vars {
+		r.vars[k] = v
+	}
+	for k, v := range o.Vars {
+		r.vars[k] = v
+	}
+	r.vars["inventory_hostname"] = host.DisplayName()
+	r.vars["host"] = host
+
+	if r.runTasks(ctx, pb.Tasks, false) {
+		var handlers []*internal.Task
+		for _, handler := range pb.Handlers {
+			if r.notified[handler.Name] {
+				handlers = append(handlers, handler)
+			}
+		}
+		r.runTasks(ctx, handlers, true)
+	}
+	r.recap.Duration = time.Since(start)
+	return r.recap
+}
+
+// RunHosts 并发地在多台主机上建立连接并执行任务文件，执行完成后关闭连接
+//
+//	@author duanzt
+//	@date 2026-10-19 22:29:20
+//	@param ctx context.Context 上下文
+//	@param pb *internal.Playbook 任务文件
+//	@param hosts []internal.Host 主机列表
+//	@param o *internal.PlaybookOptions 执行配置（Fanout.Dial为建立连接的方法）
+//	@return *internal.PlaybookReport 执行结果（与主机列表顺序一致）
+//	@return error 存在失败的主机时返回
+func RunHosts(ctx context.Context, pb *internal.Playbook, hosts []internal.Host, o *internal.PlaybookOptions) (*internal.PlaybookReport, error) {
+	start := time.Now()
+	var lock sync.Mutex
+	recaps := make(map[string]*internal.Recap, len(hosts))
+	ho := *o
+	if o.Handler != nil {
+		// 多主机并发执行时串行调用
+		ho.Handler = func(result *internal.TaskResult) {
+			lock.Lock()
+			defer lock.Unlock()
+			o.Handler(result)
+		}
+	}
+	task := func(ctx context.Context, host internal.Host, conn internal.IConnection) (*internal.CommandResult, error) {
+		recap := Run(ctx, pb, conn, host, &ho)
+		lock.Lock()
+		recaps[host.DisplayName()] = recap
+		lock.Unlock()
+		return nil, recap.Err
+	}
+	fanoutReport, _ := batch.Fanout(ctx, hosts, task, o.Fanout)
+
+	report := &internal.PlaybookReport{}
+	for _, result := range fanoutReport.Results {
+		recap := recaps[result.Host.DisplayName()]
+		if recap == nil {
+			// 未能建立连接或未执行
+			recap = &internal.Recap{Host: result.Host, Unreachable: !result.Skipped, Err: result.Err, Duration: result.Duration}
+		}
+		report.Recaps = append(report.Recaps, recap)
+	}
+	report.Duration = time.Since(start)
+	return report, report.Err()
+}
+
+// runTasks 依次执行任务
+//
+//	@author duanzt
+//	@date 2026-10-19 22:30:02
+//	@receiver r *runner
+//	@param ctx context.Context 上下文
+//	@param tasks []*internal.Task 任务
+//	@param handler bool 是否为handler
+//	@return bool 是否全部成功（或已忽略失败）
+func (r *runner) runTasks(ctx context.Context, tasks []*internal.Task, handler bool) bool {
+	for _, task := range tasks {
+		if err := ctx.Err(); err != nil {
+			r.recap.Err = err
+			return false
+		}
+		status, err := r.runTask(ctx, task, handler)
+		switch status {
+		case internal.TaskOk:
+			r.recap.Ok++
+		case internal.TaskChanged:
+			r.recap.Changed++
+			for _, name := range task.Notify {
+				r.notified[name] = true
+			}
+		case internal.TaskSkipped:
+			r.recap.Skipped++
+		case internal.TaskFailed:
+			if task.IgnoreErrors {
+				r.recap.Ignored++
+				continue
+			}
+			r.recap.Failed++
+			r.recap.Err = fmt.Errorf("任务%s失败: %w", task.Name, err)
+			return false
+		}
+	}
+	return true
+}
+
+// runTask 执行单个任务（循环时对每个item执行），注册结果并返回汇总的状态
+//
+//	@author duanzt
+//	@date 2026-10-19 22:30:40
+//	@receiver r *runner
+//	@param ctx context.Context 上下文
+//	@param task *internal.Task 任务
+//	@param handler bool 是否为handler
+//	@return internal.TaskStatus 状态（任一item失败时为失败，任一item有变更时为有变更，全部跳过时为跳过）
+//	@return error 首个失败的异常
+func (r *runner) runTask(ctx context.Context, task *internal.Task, handler bool) (internal.TaskStatus, error) {
+	if task.Loop == nil {
+		result := r.runItem(ctx, task, nil, handler)
+		if task.Register != "" {
+			r.vars[task.Register] = registered(result)
+		}
+		return result.Status, result.Err
+	}
+
+	items, err := loopItems(task.Loop, r.vars)
+	if err != nil {
+		result := &internal.TaskResult{Task: task, Host: r.host.DisplayName(), Status: internal.TaskFailed, Err: err, Handler: handler}
+		r.recap.Results = append(r.recap.Results, result)
+		if r.o.Handler != nil {
+			r.o.Handler(result)
+		}
+		return internal.TaskFailed, err
+	}
+	var firstErr error
+	changed, failed, skipped := false, false, true
+	results := make([]any, 0, len(items))
+	for _, item := range items {
+		result := r.runItem(ctx, task, item, handler)
+		results = append(results, registered(result))
+		switch result.Status {
+		case internal.TaskChanged:
+			changed = true
+		case internal.TaskFailed:
+			failed = true
+			if firstErr == nil {
+				firstErr = result.Err
+			}
+		}
+		if result.Status != internal.TaskSkipped {
+			skipped = false
+		}
+		// 失败时不再执行剩余的item
+		if result.Status == internal.TaskFailed && !task.IgnoreErrors {
+			break
+		}
+	}
+	delete(r.vars, "item")
+	if task.Register != "" {
+		r.vars[task.Register] = map[string]any{"results": results, "changed": changed, "failed": failed, "skipped": skipped}
+	}
+	switch {
+	case failed:
+		return internal.TaskFailed, firstErr
+	case changed:
+		return internal.TaskChanged, nil
+	case skipped:
+		return internal.TaskSkipped, nil
+	}
+	return internal.TaskOk, nil
+}
+
+// loopItems 获取循环的列表（模板时计算引用的变量，变量应为列表）
+//
+//	@author duanzt
+//	@date 2026-10-19 22:31:02
+//	@param loop any 列表或模板
+//	@param vars map[string]any 变量
+//	@return []any 列表
+//	@return error 模板异常或变量不是列表时返回
+func loopItems(loop any, vars map[string]any) ([]any, error) {
+	expr, ok := loop.(string)
+	if !ok {
+		return loop.([]any), nil
+	}
+	value, err := evalValue(expr, vars)
+	if err != nil {
+		return nil, fmt.Errorf("loop: %w", err)
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("loop: %s的值不是列表", expr)
+	}
+	items := make([]any, v.Len())
+	for i := range items {
+		items[i] = v.Index(i).Interface()
+	}
+	return items, nil
+}
+
+// runItem 执行任务的一次（判断条件、执行步骤、判断变更及失败条件）
+//
+//	@author duanzt
+//	@date 2026-10-19 22:31:20
+//	@receiver r *runner
+//	@param ctx context.Context 上下文
+//	@param task *internal.Task 任务
+//	@param item any 循环的当前值
+//	@param handler bool 是否为handler
+//	@return *internal.TaskResult 执行结果
+func (r *runner) runItem(ctx context.Context, task *internal.Task, item any, handler bool) *internal.TaskResult {
+	start := time.Now()
+	result := &internal.TaskResult{Task: task, Host: r.host.DisplayName(), Item: item, Handler: handler}
+	defer func() {
+		result.Duration = time.Since(start)
+		r.recap.Results = append(r.recap.Results, result)
+		if r.o.Handler != nil {
+			r.o.Handler(result)
+		}
+	}()
+	if task.Loop != nil {
+		r.vars["item"] = item
+	}
+
+	if task.When != "" {
+		ok, err := evalCondition(task.When, r.vars)
+		if err != nil {
+			result.Status, result.Err = internal.TaskFailed, fmt.Errorf("when: %w", err)
+			return result
+		}
+		if !ok {
+			result.Status = internal.TaskSkipped
+			return result
+		}
+	}
+
+	changed, err := r.runStep(ctx, task, result)
+	// 设置了FailedWhen时忽略退出码（连接等异常除外）
+	if task.FailedWhen != "" && (err == nil || result.ExitCode > 0) {
+		err = nil
+		failed, evalErr := r.evalWithResult(task.FailedWhen, result, changed)
+		switch {
+		case evalErr != nil:
+			err = fmt.Errorf("failed_when: %w", evalErr)
+		case failed:
+			err = errors.New("满足failed_when条件")
+		}
+	}
+	if err == nil && task.ChangedWhen != "" {
+		changed, err = r.evalWithResult(task.ChangedWhen, result, changed)
+		if err != nil {
+			err = fmt.Errorf("changed_when: %w", err)
+		}
+	}
+	switch {
+	case err != nil:
+		result.Status, result.Err = internal.TaskFailed, err
+		result.Ignored = task.IgnoreErrors
+	case changed:
+		result.Status = internal.TaskChanged
+	default:
+		result.Status = internal.TaskOk
+	}
+	return result
+}
+
+// evalWithResult 使用当前结果（.result，及注册的变量名）计算条件
+//
+//	@author duanzt
+//	@date 2026-10-19 22:32:02
+//	@receiver r *runner
+//	@param expr string 条件
+//	@param result *internal.TaskResult 当前结果
+//	@param changed bool 步骤判断的是否有变更
+//	@return bool 条件是否成立
+//	@return error 条件异常时返回
+func (r *runner) evalWithResult(expr string, result *internal.TaskResult, changed bool) (bool, error) {
+	current := registered(result)
+	current["changed"] = changed
+	vars := make(map[string]any, len(r.vars)+2)
+	for k, v := range r.vars {
+		vars[k] = v
+	}
+	vars["result"] = current
+	if result.Task.Register != "" {
+		vars[result.Task.Register] = current
+	}
+	return evalCondition(expr, vars)
+}
+
+// registered 将执行结果转换为注册的变量（stdout、stdout_lines、stderr、rc、changed、failed、skipped、msg）
+//
+//	@author duanzt
+//	@date 2026-10-19 22:32:40
+//	@param result *internal.TaskResult 执行结果
+//	@return map[string]any 注册的变量
+func registered(result *internal.TaskResult) map[string]any {
+	msg := ""
+	if result.Err != nil {
+		msg = result.Err.Error()
+	}
+	lines := []string{}
+	if stdout := strings.TrimRight(result.Stdout, "\n"); stdout != "" {
+		lines = strings.Split(stdout, "\n")
+	}
+	vars := map[string]any{
+		"stdout":       strings.TrimRight(result.Stdout, "\n"),
+		"stdout_lines": lines,
+		"stderr":       strings.TrimRight(result.Stderr, "\n"),
+		"rc":           result.ExitCode,
+		"changed":      result.Status == internal.TaskChanged,
+		"failed":       result.Status == internal.TaskFailed,
+		"skipped":      result.Status == internal.TaskSkipped,
+		"msg":          msg,
+	}
+	if result.Item != nil {
+		vars["item"] = result.Item
+	}
+	return vars
+}
@@ -0,0 +1,424 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 22:33:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:59:30
+ * @FilePath: step.go
+ * @Description: 任务步骤（shell、copy、template、fetch）的执行及模板渲染
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package playbook
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/duanztop/gossh/internal"
+	"github.com/duanztop/gossh/internal/tools"
+	"github.com/duanztop/gossh/internal/transfer"
+)
+
+// funcs 模板可使用的函数
+var funcs = template.FuncMap{
+	"contains":  strings.Contains,
+	"hasPrefix": strings.HasPrefix,
+	"hasSuffix": strings.HasSuffix,
+	"trim":      strings.TrimSpace,
+	"lower":     strings.ToLower,
+	"upper":     strings.ToUpper,
+	"split":     strings.Split,
+	"join":      strings.Join,
+	"replace":   strings.ReplaceAll,
+	"toJson": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// runStep 执行任务的步骤
+//
+//	@author duanzt
+//	@date 2026-10-19 22:33:40
+//	@receiver r *runner
+//	@param ctx context.Context 上下文
+//	@param task *internal.Task 任务
+//	@param result *internal.TaskResult 执行结果（写入输出及退出码）
+//	@return bool 是否有变更
+//	@return error 执行异常时返回
+func (r *runner) runStep(ctx context.Context, task *internal.Task, result *internal.TaskResult) (bool, error) {
+	switch {
+	case task.Shell != "":
+		return r.runShell(ctx, task.Shell, result)
+	case task.Copy != nil:
+		return r.runCopy(task.Copy)
+	case task.Template != nil:
+		return r.runTemplate(task.Template)
+	case task.Fetch != nil:
+		return r.runFetch(task.Fetch)
+	}
+	return false, errors.New("任务没有步骤")
+}
+
+// runShell 执行shell命令（视为有变更）
+//
+//	@author duanzt
+//	@date 2026-10-19 22:34:20
+//	@receiver r *runner
+//	@param ctx context.Context 上下文
+//	@param shell string shell命令模板
+//	@param result *internal.TaskResult 执行结果
+//	@return bool 是否有变更（执行后均视为有变更）
+//	@return error 执行异常或退出码不为0时返回
+func (r *runner) runShell(ctx context.Context, shell string, result *internal.TaskResult) (bool, error) {
+	command, err := render("shell", shell, r.vars)
+	if err != nil {
+		return false, err
+	}
+	output, err := r.conn.Run(ctx, command)
+	if output != nil {
+		result.Stdout, result.Stderr, result.ExitCode = output.Stdout, output.Stderr, output.ExitCode
+	} else {
+		result.ExitCode = -1
+	}
+	return true, err
+}
+
+// runCopy 拷贝本地文件/目录或内容到目标端（文件内容一致时无变更，目录按摘要同步）
+//
+//	@author duanzt
+//	@date 2026-10-19 22:35:02
+//	@receiver r *runner
+//	@param step *internal.CopyStep 拷贝步骤
+//	@return bool 是否有变更
+//	@return error 执行异常时返回
+func (r *runner) runCopy(step *internal.CopyStep) (bool, error) {
+	dest, mode, err := r.renderDest(step.Dest, step.Mode)
+	if err != nil {
+		return false, err
+	}
+	if step.Src == "" {
+		content, err := render("content", step.Content, r.vars)
+		if err != nil {
+			return false, err
+		}
+		return r.writeContent([]byte(content), dest, mode)
+	}
+
+	src, err := r.localPath(step.Src)
+	if err != nil {
+		return false, err
+	}
+	info, err := os.Stat(src)
+	if err != nil {
+		return false, err
+	}
+	if info.IsDir() {
+		report, err := r.conn.SyncDirLTR(src, dest, internal.WithChecksum(internal.HashSHA256))
+		if err != nil {
+			return false, err
+		}
+		changed, err := r.ensureDirMode(src, dest, mode)
+		return changed || len(report.Changes) > 0, err
+	}
+	sum, err := transfer.FileSum(src, internal.HashSHA256)
+	if err != nil {
+		return false, err
+	}
+	if same, err := r.sameSum(dest, sum); err != nil || same {
+		if err != nil {
+			return false, err
+		}
+		return r.ensureMode(dest, mode)
+	}
+	return true, r.conn.CopyFileLTR(src, dest, mode, internal.WithAtomic())
+}
+
+// runTemplate 渲染本地模板文件后写入目标端（渲染结果一致时无变更）
+//
+//	@author duanzt
+//	@date 2026-10-19 22:35:40
+//	@receiver r *runner
+//	@param step *internal.TemplateStep 模板步骤
+//	@return bool 是否有变更
+//	@return error 执行异常时返回
+func (r *runner) runTemplate(step *internal.TemplateStep) (bool, error) {
+	dest, mode, err := r.renderDest(step.Dest, step.Mode)
+	if err != nil {
+		return false, err
+	}
+	src, err := r.localPath(step.Src)
+	if err != nil {
+		return false, err
+	}
+	text, err := os.ReadFile(src)
+	if err != nil {
+		return false, err
+	}
+	content, err := render(filepath.Base(src), string(text), r.vars)
+	if err != nil {
+		return false, err
+	}
+	return r.writeContent([]byte(content), dest, mode)
+}
+
+// runFetch 拷贝目标端文件到本地（内容一致时无变更）
+//
+//	@author duanzt
+//	@date 2026-10-19 22:36:20
+//	@receiver r *runner
+//	@param step *internal.FetchStep 拉取步骤
+//	@return bool 是否有变更
+//	@return error 执行异常时返回
+func (r *runner) runFetch(step *internal.FetchStep) (bool, error) {
+	src, err := render("src", step.Src, r.vars)
+	if err != nil {
+		return false, err
+	}
+	dest, err := r.localPath(step.Dest)
+	if err != nil {
+		return false, err
+	}
+	sum, err := r.conn.Checksum(src, internal.HashSHA256)
+	if err != nil {
+		return false, err
+	}
+	if local, err := transfer.FileSum(dest, internal.HashSHA256); err == nil && local == sum {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return false, err
+	}
+	return true, r.conn.CopyFileRTL(src, dest, "", internal.WithAtomic())
+}
+
+// writeContent 将内容写入目标端（内容一致时不写入）
+//
+//	@author duanzt
+//	@date 2026-10-19 22:37:02
+//	@receiver r *runner
+//	@param content []byte 内容
+//	@param dest string 目标端路径
+//	@param mode string 文件权限
+//	@return bool 是否有变更
+//	@return error 执行异常时返回
+func (r *runner) writeContent(content []byte, dest, mode string) (bool, error) {
+	sum := sha256.Sum256(content)
+	if same, err := r.sameSum(dest, hex.EncodeToString(sum[:])); err != nil || same {
+		if err != nil {
+			return false, err
+		}
+		return r.ensureMode(dest, mode)
+	}
+	return true, r.conn.CopyFileITR(bytes.NewReader(content), dest, mode, internal.WithAtomic())
+}
+
+// ensureMode 内容一致时按需修正目标端文件的权限
+//
+//	@author duanzt
+//	@date 2026-10-19 23:59:00
+//	@receiver r *runner
+//	@param dest string 目标端路径
+//	@param mode string 文件权限（为空时不修改）
+//	@return bool 是否修改了权限
+//	@return error 获取或修改权限异常时返回
+func (r *runner) ensureMode(dest, mode string) (bool, error) {
+	if mode == "" {
+		return false, nil
+	}
+	info, err := r.conn.Stat(dest)
+	if err != nil {
+		return false, err
+	}
+	perm, err := tools.ModeTools.Resolve(mode, nil, false, func() (fs.FileInfo, error) {
+		return info, nil
+	})
+	if err != nil {
+		return false, err
+	}
+	if info.Mode().Perm() == perm.Perm() {
+		return false, nil
+	}
+	return true, r.conn.Chmod(dest, perm.Perm())
+}
+
+// ensureDirMode 目录拷贝时按需修正目标端文件的权限（mode作用于源目录中的每个文件，不修改目录本身）
+//
+//	@author duanzt
+//	@date 2026-10-19 23:59:20
+//	@receiver r *runner
+//	@param src string 本地源目录
+//	@param dest string 目标端目录
+//	@param mode string 文件权限（为空时不修改）
+//	@return bool 是否修改了权限
+//	@return error 遍历、获取或修改权限异常时返回
+func (r *runner) ensureDirMode(src, dest, mode string) (bool, error) {
+	if mode == "" {
+		return false, nil
+	}
+	changed := false
+	err := filepath.WalkDir(src, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(src, name)
+		if err != nil {
+			return err
+		}
+		modified, err := r.ensureMode(path.Join(dest, filepath.ToSlash(rel)), mode)
+		changed = changed || modified
+		return err
+	})
+	return changed, err
+}
+
+// sameSum 目标端文件的摘要是否与sum一致（文件不存在时不一致）
+//
+//	@author duanzt
+//	@date 2026-10-19 22:37:40
+//	@receiver r *runner
+//	@param dest string 目标端路径
+//	@param sum string sha256摘要
+//	@return bool 是否一致
+//	@return error 获取摘要异常（文件不存在除外）时返回
+func (r *runner) sameSum(dest, sum string) (bool, error) {
+	if _, err := r.conn.Stat(dest); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	remote, err := r.conn.Checksum(dest, internal.HashSHA256)
+	if err != nil {
+		return false, err
+	}
+	return remote == sum, nil
+}
+
+// renderDest 渲染目标端路径及文件权限
+//
+//	@author duanzt
+//	@date 2026-10-19 22:38:20
+//	@receiver r *runner
+//	@param dest string 目标端路径模板
+//	@param mode string 文件权限模板
+//	@return string 目标端路径
+//	@return string 文件权限
+//	@return error 渲染异常时返回
+func (r *runner) renderDest(dest, mode string) (string, string, error) {
+	dest, err := render("dest", dest, r.vars)
+	if err != nil {
+		return "", "", err
+	}
+	mode, err = render("mode", mode, r.vars)
+	return dest, mode, err
+}
+
+// localPath 渲染本地路径，相对路径基于任务文件所在目录
+//
+//	@author duanzt
+//	@date 2026-10-19 22:39:02
+//	@receiver r *runner
+//	@param name string 本地路径模板
+//	@return string 本地路径
+//	@return error 渲染异常时返回
+func (r *runner) localPath(name string) (string, error) {
+	name, err := render("path", name, r.vars)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(name) && r.pb.Dir != "" {
+		name = filepath.Join(r.pb.Dir, name)
+	}
+	return name, nil
+}
+
+// render 渲染text/template模板（引用不存在的变量时返回异常）
+//
+//	@author duanzt
+//	@date 2026-10-19 22:39:40
+//	@param name string 模板名称（用于异常信息）
+//	@param text string 模板内容
+//	@param vars map[string]any 变量
+//	@return string 渲染结果
+//	@return error 模板或渲染异常时返回
+func render(name, text string, vars map[string]any) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, vars); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// evalValue 计算模板表达式的值（不转换为字符串），例如{{ .packages }}或.packages
+//
+//	@author duanzt
+//	@date 2026-10-19 22:40:02
+//	@param expr string 模板表达式
+//	@param vars map[string]any 变量
+//	@return any 表达式的值
+//	@return error 表达式异常时返回
+func evalValue(expr string, vars map[string]any) (any, error) {
+	inner := strings.TrimSpace(expr)
+	if strings.HasPrefix(inner, "{{") && strings.HasSuffix(inner, "}}") {
+		inner = strings.TrimSpace(inner[2 : len(inner)-2])
+	}
+	var value any
+	tmpl, err := template.New("value").Funcs(funcs).Funcs(template.FuncMap{
+		"capture": func(v any) string {
+			value = v
+			return ""
+		},
+	}).Option("missingkey=error").Parse("{{ capture (" + inner + ") }}")
+	if err != nil {
+		return nil, err
+	}
+	if err := tmpl.Execute(io.Discard, vars); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// evalCondition 计算条件：模板表达式（例如eq .current.rc 0、.deploy.changed），或包含{{的模板（渲染结果
+// 为空、false、0时不成立）
+//
+//	@author duanzt
+//	@date 2026-10-19 22:40:20
+//	@param expr string 条件
+//	@param vars map[string]any 变量
+//	@return bool 条件是否成立
+//	@return error 条件异常时返回
+func evalCondition(expr string, vars map[string]any) (bool, error) {
+	text := expr
+	if !strings.Contains(expr, "{{") {
+		text = "{{ if " + expr + " }}true{{ else }}false{{ end }}"
+	}
+	value, err := render("when", text, vars)
+	if err != nil {
+		return false, fmt.Errorf("条件%s异常: %w", expr, err)
+	}
+	switch strings.TrimSpace(value) {
+	case "", "false", "0":
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,293 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 20:05:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 20:05:10
+ * @FilePath: archive.go
+ * @Description: 远端归档文件的创建及解压（优先使用GNU tar、zip/unzip命令，缺少命令时通过sftp流式处理）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package remote
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/duanztop/gossh/internal"
+	"github.com/duanztop/gossh/internal/tools"
+	"github.com/duanztop/gossh/internal/transfer"
+	"golang.org/x/crypto/ssh"
+)
+
+// errArchiveUnsupported 远端缺少处理该归档格式的命令
+var errArchiveUnsupported = errors.New("远端缺少归档命令")
+
+// ExtractArchive 将远端归档文件解压到远端目标目录
+// 先列出条目校验路径（绝对路径或包含..时不解压），再由远端命令解压；远端缺少命令（或zip需要去除前缀）时通过sftp流式解压
+//
+//	@author duanzt
+//	@date 2026-10-19 20:06:02
+//	@receiver c *connection
+//	@param archive string 远端归档文件路径
+//	@param dest string 远端目标目录（不存在时创建）
+//	@param opts ...internal.ArchiveOption 归档配置
+//	@return *internal.ArchiveManifest 已解压的条目清单
+//	@return error 解压异常时返回
+func (c *connection) ExtractArchive(archive, dest string, opts ...internal.ArchiveOption) (*internal.ArchiveManifest, error) {
+	o, err := internal.NewArchiveOptions(archive, opts...)
+	if err != nil {
+		return nil, err
+	}
+	// unzip不支持去除路径前缀
+	if o.Format != internal.ArchiveZip || o.StripComponents == 0 {
+		manifest, err := c.extractByCommand(archive, dest, o)
+		if !errors.Is(err, errArchiveUnsupported) {
+			return manifest, err
+		}
+	}
+	return transfer.ExtractArchive(c, archive, dest, o)
+}
+
+// CreateArchive 将远端目录打包为远端归档文件，远端缺少命令时通过sftp流式打包
+//
+//	@author duanzt
+//	@date 2026-10-19 20:06:40
+//	@receiver c *connection
+//	@param src string 远端源目录
+//	@param archive string 远端归档文件路径（已存在时覆盖）
+//	@param opts ...internal.ArchiveOption 归档配置
+//	@return *internal.ArchiveManifest 已打包的条目清单
+//	@return error 打包异常时返回
+func (c *connection) CreateArchive(src, archive string, opts ...internal.ArchiveOption) (*internal.ArchiveManifest, error) {
+	o, err := internal.NewArchiveOptions(archive, opts...)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := c.createByCommand(src, archive, o)
+	if !errors.Is(err, errArchiveUnsupported) {
+		return manifest, err
+	}
+	return transfer.CreateArchive(c, src, archive, o)
+}
+
+// extractByCommand 通过远端命令解压
+//
+//	@author duanzt
+//	@date 2026-10-19 20:07:20
+//	@receiver c *connection
+//	@param archive string 远端归档文件路径
+//	@param dest string 远端目标目录
+//	@param o *internal.ArchiveOptions 归档配置
+//	@return *internal.ArchiveManifest 已解压的条目清单
+//	@return error 远端缺少命令时返回errArchiveUnsupported
+func (c *connection) extractByCommand(archive, dest string, o *internal.ArchiveOptions) (*internal.ArchiveManifest, error) {
+	manifest, err := c.listArchive(archive, o, o.StripComponents)
+	if err != nil {
+		return nil, err
+	}
+	quotedDest := tools.ShellTools.Quote(dest)
+	command := "mkdir -p -- " + quotedDest + " && "
+	if o.Format == internal.ArchiveZip {
+		command += "unzip -o -qq " + archiveArg(archive) + " -d " + quotedDest
+	} else {
+		// -p与条目权限一致，未开启SameOwner时-o不恢复条目记录的所属用户
+		command += "tar -x -p" + archiveTarFlags(o.Format) + " -f " + archiveArg(archive) + " -C " + quotedDest
+		if o.StripComponents > 0 {
+			command += " --strip-components=" + strconv.Itoa(o.StripComponents)
+		}
+		if o.SameOwner {
+			command += " --same-owner"
+		} else {
+			command += " -o"
+		}
+	}
+	// 解压失败时无法确定已解压的条目，不返回清单
+	if _, err := c.runArchiveCommand(command, nil); err != nil {
+		return nil, err
+	}
+	return manifest, c.chownManifest(dest, manifest, o)
+}
+
+// createByCommand 通过远端命令打包
+//
+//	@author duanzt
+//	@date 2026-10-19 20:08:10
+//	@receiver c *connection
+//	@param src string 远端源目录
+//	@param archive string 远端归档文件路径
+//	@param o *internal.ArchiveOptions 归档配置
+//	@return *internal.ArchiveManifest 已打包的条目清单
+//	@return error 远端缺少命令时返回errArchiveUnsupported
+func (c *connection) createByCommand(src, archive string, o *internal.ArchiveOptions) (*internal.ArchiveManifest, error) {
+	var command string
+	if o.Format == internal.ArchiveZip {
+		// zip需要在源目录中执行，归档文件路径转换为绝对路径
+		command = archiveProbe(o.Format) + "command -v zip >/dev/null 2>&1 || exit 127; " +
+			"A=" + archiveArg(archive) + "; case $A in /*) ;; *) A=$PWD/$A;; esac; " +
+			"rm -f -- \"$A\" && cd -- " + tools.ShellTools.Quote(src) + " && zip -q -r -y \"$A\" ."
+	} else {
+		// 去除条目路径的./前缀，与zip及sftp流式打包的条目路径一致
+		command = archiveProbe(o.Format) + "tar -c" + archiveTarFlags(o.Format) + " -f " + archiveArg(archive) +
+			" -C " + tools.ShellTools.Quote(src) + ` --transform='s,^\./,,'`
+		if o.Uid >= 0 {
+			command += " --owner=+" + strconv.Itoa(o.Uid)
+		}
+		if o.Gid >= 0 {
+			command += " --group=+" + strconv.Itoa(o.Gid)
+		}
+		command += " ."
+	}
+	if _, err := c.runArchiveCommand(command, nil); err != nil {
+		return nil, err
+	}
+	return c.listArchive(archive, o, 0)
+}
+
+// listArchive 列出归档的条目并校验路径
+//
+//	@author duanzt
+//	@date 2026-10-19 20:09:02
+//	@receiver c *connection
+//	@param archive string 远端归档文件路径
+//	@param o *internal.ArchiveOptions 归档配置
+//	@param strip int 去除条目路径的前几级
+//	@return *internal.ArchiveManifest 条目清单
+//	@return error 远端缺少命令时返回errArchiveUnsupported，条目路径不合法时返回
+func (c *connection) listArchive(archive string, o *internal.ArchiveOptions, strip int) (*internal.ArchiveManifest, error) {
+	command := archiveProbe(o.Format)
+	if o.Format == internal.ArchiveZip {
+		command += "unzip -Z1 " + archiveArg(archive)
+	} else {
+		command += "tar -t" + archiveTarFlags(o.Format) + " -f " + archiveArg(archive)
+	}
+	output, err := c.runArchiveCommand(command, nil)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &internal.ArchiveManifest{}
+	for _, name := range strings.Split(output, "\n") {
+		if name == "" {
+			continue
+		}
+		rel, ok, err := transfer.ArchiveEntryPath(name, strip)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			manifest.Add(rel, strings.HasSuffix(name, "/"))
+		}
+	}
+	return manifest, nil
+}
+
+// chownManifest 修改已解压条目的所属用户及用户组（通过标准输入传递路径，不跟随符号链接）
+//
+//	@author duanzt
+//	@date 2026-10-19 20:09:50
+//	@receiver c *connection
+//	@param dest string 远端目标目录
+//	@param manifest *internal.ArchiveManifest 已解压的条目清单
+//	@param o *internal.ArchiveOptions 归档配置
+//	@return error 修改异常时返回
+func (c *connection) chownManifest(dest string, manifest *internal.ArchiveManifest, o *internal.ArchiveOptions) error {
+	if (o.Uid < 0 && o.Gid < 0) || len(manifest.Paths) == 0 {
+		return nil
+	}
+	spec := ""
+	if o.Uid >= 0 {
+		spec = strconv.Itoa(o.Uid)
+	}
+	if o.Gid >= 0 {
+		spec += ":" + strconv.Itoa(o.Gid)
+	}
+	var paths bytes.Buffer
+	for _, rel := range manifest.Paths {
+		paths.WriteString(strings.TrimSuffix(rel, "/"))
+		paths.WriteByte(0)
+	}
+	_, err := c.runArchiveCommand("cd -- "+tools.ShellTools.Quote(dest)+" && xargs -0 chown -h -- "+spec, &paths)
+	return err
+}
+
+// runArchiveCommand 执行归档命令
+//
+//	@author duanzt
+//	@date 2026-10-19 20:10:30
+//	@receiver c *connection
+//	@param command string shell命令
+//	@param stdin *bytes.Buffer 标准输入（可为nil）
+//	@return string 标准输出
+//	@return error 无法执行或退出码为127（命令不存在）时返回errArchiveUnsupported，其他异常附加错误输出
+func (c *connection) runArchiveCommand(command string, stdin *bytes.Buffer) (string, error) {
+	sess, err := c.client.NewSession()
+	if err != nil {
+		return "", err
+	}
+	defer sess.Close()
+	var stdout, stderr bytes.Buffer
+	sess.Stdout, sess.Stderr = &stdout, &stderr
+	if stdin != nil {
+		sess.Stdin = stdin
+	}
+	// 无法执行命令（例如服务端仅开启sftp）时同样视为缺少命令
+	if err := sess.Start(command); err != nil {
+		return "", errArchiveUnsupported
+	}
+	if err := sess.Wait(); err != nil {
+		var exitErr *ssh.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitStatus() == 127 {
+			return "", errArchiveUnsupported
+		}
+		if output := strings.TrimSpace(stderr.String()); output != "" {
+			return "", fmt.Errorf("%w, %s", err, output)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// archiveProbe 检查远端是否有处理该格式的命令（GNU tar及对应的压缩命令、unzip），缺少时以127退出
+//
+//	@author duanzt
+//	@date 2026-10-19 20:11:10
+//	@param format internal.ArchiveFormat 归档格式
+//	@return string shell命令（以"; "结尾）
+func archiveProbe(format internal.ArchiveFormat) string {
+	switch format {
+	case internal.ArchiveZip:
+		return "command -v unzip >/dev/null 2>&1 || exit 127; "
+	case internal.ArchiveTarGz:
+		return "tar --version 2>/dev/null | grep -q 'GNU tar' && command -v gzip >/dev/null 2>&1 || exit 127; "
+	case internal.ArchiveTarZst:
+		return "tar --version 2>/dev/null | grep -q 'GNU tar' && command -v zstd >/dev/null 2>&1 || exit 127; "
+	default:
+		return "tar --version 2>/dev/null | grep -q 'GNU tar' || exit 127; "
+	}
+}
+
+// archiveTarFlags 归档格式对应的tar压缩参数
+//
+//	@author duanzt
+//	@date 2026-10-19 20:11:40
+//	@param format internal.ArchiveFormat 归档格式
+//	@return string tar参数
+func archiveTarFlags(format internal.ArchiveFormat) string {
+	return tarFlags(&internal.CopyOptions{Compression: format.Compression()})
+}
+
+// archiveArg 转义归档文件路径（以-开头时添加./前缀，避免被解析为参数）
+//
+//	@author duanzt
+//	@date 2026-10-19 20:12:02
+//	@param name string 文件路径
+//	@return string 转义后的shell参数
+func archiveArg(name string) string {
+	if strings.HasPrefix(name, "-") {
+		name = "./" + name
+	}
+	return tools.ShellTools.Quote(name)
+}
@@ -0,0 +1,280 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 13:46:50
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 20:51:30
+ * @FilePath: checksum.go
+ * @Description: 远端文件摘要计算（sftp check-file扩展，不支持时使用sha256sum等命令）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package remote
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/duanztop/gossh/internal"
+	"github.com/duanztop/gossh/internal/tools"
+)
+
+const (
+
+	// sftp协议报文类型
+	sshFxpInit          = 1
+	sshFxpVersion       = 2
+	sshFxpStatus        = 101
+	sshFxpExtended      = 200
+	sshFxpExtendedReply = 201
+
+	// checkFileExtension 计算文件摘要的sftp扩展（draft-ietf-secsh-filexfer-extensions）
+	checkFileExtension = "check-file"
+
+	// maxPacketSize 允许读取的最大报文长度
+	maxPacketSize = 256 * 1024
+)
+
+// sumCommands 各摘要算法对应的命令
+var sumCommands = map[internal.HashAlgorithm]string{
+	internal.HashSHA256: "sha256sum",
+	internal.HashSHA512: "sha512sum",
+	internal.HashMD5:    "md5sum",
+}
+
+// Checksum 计算远端文件的摘要，check-file扩展及命令均不可用时通过sftp读取文件计算
+//
+//	@author duanzt
+//	@date 2026-10-19 20:38:50
+//	@receiver c *connection
+//	@param name string 远端文件路径
+//	@param algo internal.HashAlgorithm 摘要算法
+//	@return string 摘要（十六进制）
+//	@return error 文件不存在或计算异常时返回
+func (c *connection) Checksum(name string, algo internal.HashAlgorithm) (string, error) {
+	h, err := algo.New()
+	if err != nil {
+		return "", err
+	}
+	if _, err := c.Stat(name); err != nil {
+		return "", toPathError("checksum", name, err)
+	}
+	if sum, err := c.fileSum(name, algo); err == nil {
+		return sum, nil
+	}
+	file, err := c.Open(name)
+	if err != nil {
+		return "", toPathError("checksum", name, err)
+	}
+	defer file.Close()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", toPathError("checksum", name, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// fileSum 计算远端文件的摘要
+//
+//	@author duanzt
+//	@date 2026-10-19 13:47:35
+//	@receiver c *connection
+//	@param name string 远端文件路径
+//	@param algo internal.HashAlgorithm 摘要算法
+//	@return string 摘要（十六进制）
+//	@return error 计算异常时返回
+func (c *connection) fileSum(name string, algo internal.HashAlgorithm) (string, error) {
+	sftpClient, err := c.getSftpClient()
+	if err != nil {
+		return "", err
+	}
+	if _, ok := sftpClient.HasExtension(checkFileExtension); ok {
+		// 服务端可能不支持指定的算法，失败时使用命令计算
+		if sum, err := c.checkFile(name, algo); err == nil {
+			return sum, nil
+		}
+	}
+	return c.sumByCommand(name, algo)
+}
+
+// sumByCommand 使用sha256sum等命令计算远端文件的摘要
+//
+//	@author duanzt
+//	@date 2026-10-19 13:48:20
+//	@receiver c *connection
+//	@param name string 远端文件路径
+//	@param algo internal.HashAlgorithm 摘要算法
+//	@return string 摘要（十六进制）
+//	@return error 计算异常时返回
+func (c *connection) sumByCommand(name string, algo internal.HashAlgorithm) (string, error) {
+	command, ok := sumCommands[algo]
+	if !ok {
+		return "", fmt.Errorf("不支持的摘要算法: %s", string(algo))
+	}
+	output, err := c.ExecShell(context.Background(), command+" -- "+tools.ShellTools.Quote(name))
+	if err != nil {
+		return "", fmt.Errorf("%s执行失败: %w, %s", command, err, strings.TrimSpace(output))
+	}
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("%s输出不合法: %s", command, output)
+	}
+	return strings.ToLower(fields[0]), nil
+}
+
+// checkFile 通过sftp的check-file-name扩展计算远端文件的摘要
+// pkg/sftp未提供发送扩展报文的方法，这里单独打开一个sftp子系统会话
+//
+//	@author duanzt
+//	@date 2026-10-19 13:49:42
+//	@receiver c *connection
+//	@param name string 远端文件路径
+//	@param algo internal.HashAlgorithm 摘要算法
+//	@return string 摘要（十六进制）
+//	@return error 服务端不支持或计算异常时返回
+func (c *connection) checkFile(name string, algo internal.HashAlgorithm) (string, error) {
+	sess, err := c.client.NewSession()
+	if err != nil {
+		return "", err
+	}
+	defer sess.Close()
+	w, err := sess.StdinPipe()
+	if err != nil {
+		return "", err
+	}
+	r, err := sess.StdoutPipe()
+	if err != nil {
+		return "", err
+	}
+	if err := sess.RequestSubsystem("sftp"); err != nil {
+		return "", err
+	}
+
+	// 初始化：SSH_FXP_INIT(version 3) -> SSH_FXP_VERSION
+	if err := writePacket(w, sshFxpInit, appendUint32(nil, 3)); err != nil {
+		return "", err
+	}
+	if typ, _, err := readPacket(r); err != nil {
+		return "", err
+	} else if typ != sshFxpVersion {
+		return "", fmt.Errorf("sftp初始化失败，报文类型: %d", typ)
+	}
+
+	// SSH_FXP_EXTENDED "check-file-name"：起始位置0、长度0（到文件末尾）、块大小0（整个文件一个摘要）
+	const requestId = 1
+	payload := appendUint32(nil, requestId)
+	payload = appendString(payload, "check-file-name")
+	payload = appendString(payload, name)
+	payload = appendString(payload, string(algo))
+	payload = appendUint64(payload, 0)
+	payload = appendUint64(payload, 0)
+	payload = appendUint32(payload, 0)
+	if err := writePacket(w, sshFxpExtended, payload); err != nil {
+		return "", err
+	}
+	typ, data, err := readPacket(r)
+	if err != nil {
+		return "", err
+	}
+	if typ == sshFxpStatus {
+		return "", fmt.Errorf("check-file执行失败: %s", statusMessage(data))
+	}
+	if typ != sshFxpExtendedReply {
+		return "", fmt.Errorf("check-file响应不合法，报文类型: %d", typ)
+	}
+
+	// SSH_FXP_EXTENDED_REPLY：request-id、"check-file"、实际使用的算法、摘要
+	data, ok := consumeUint32(data)
+	if !ok {
+		return "", errCheckFileReply
+	}
+	if _, data, ok = consumeString(data); !ok {
+		return "", errCheckFileReply
+	}
+	used, sum, ok := consumeString(data)
+	if !ok || len(sum) == 0 {
+		return "", errCheckFileReply
+	}
+	if used != string(algo) {
+		return "", fmt.Errorf("check-file不支持%s，服务端使用了%s", string(algo), used)
+	}
+	return hex.EncodeToString(sum), nil
+}
+
+// errCheckFileReply check-file响应格式不合法
+var errCheckFileReply = errors.New("check-file响应格式不合法")
+
+// writePacket 发送sftp报文（uint32长度 + byte类型 + 内容）
+func writePacket(w io.Writer, typ byte, payload []byte) error {
+	packet := appendUint32(make([]byte, 0, 5+len(payload)), uint32(1+len(payload)))
+	packet = append(packet, typ)
+	packet = append(packet, payload...)
+	_, err := w.Write(packet)
+	return err
+}
+
+// readPacket 读取sftp报文
+func readPacket(r io.Reader) (byte, []byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	length := binary.BigEndian.Uint32(header[:])
+	if length == 0 || length > maxPacketSize {
+		return 0, nil, fmt.Errorf("sftp报文长度不合法: %d", length)
+	}
+	packet := make([]byte, length)
+	if _, err := io.ReadFull(r, packet); err != nil {
+		return 0, nil, err
+	}
+	return packet[0], packet[1:], nil
+}
+
+// statusMessage 解析SSH_FXP_STATUS报文中的错误信息
+func statusMessage(data []byte) string {
+	data, ok := consumeUint32(data)
+	if !ok {
+		return "未知错误"
+	}
+	if len(data) < 4 {
+		return "未知错误"
+	}
+	code := binary.BigEndian.Uint32(data)
+	if msg, _, ok := consumeString(data[4:]); ok && msg != "" {
+		return msg
+	}
+	return fmt.Sprintf("错误码%d", code)
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	return appendUint32(appendUint32(b, uint32(v>>32)), uint32(v))
+}
+
+func appendString(b []byte, s string) []byte {
+	return append(appendUint32(b, uint32(len(s))), s...)
+}
+
+func consumeUint32(b []byte) ([]byte, bool) {
+	if len(b) < 4 {
+		return nil, false
+	}
+	return b[4:], true
+}
+
+func consumeString(b []byte) (string, []byte, bool) {
+	if len(b) < 4 {
+		return "", nil, false
+	}
+	n := binary.BigEndian.Uint32(b)
+	if uint32(len(b)-4) < n {
+		return "", nil, false
+	}
+	return string(b[4 : 4+n]), b[4+n:], true
+}
@@ -2,7 +2,7 @@
  * @Author: duanzt
  * @Date: 2023-07-14 10:27:51
  * @LastEditors: duanzt
- * @LastEditTime: 2023-07-17 13:12:18
+ * @LastEditTime: 2026-10-19 23:58:30
  * @FilePath: connection.go
  * @Description: 远程ssh连接
  *
@@ -11,19 +11,23 @@
 package remote
 
 import (
+	"bytes"
 	"context"
 	"embed"
+	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net"
 	"os"
-	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/duanztop/gossh/internal"
+	"github.com/duanztop/gossh/internal/local"
 	"github.com/duanztop/gossh/internal/tools"
+	"github.com/duanztop/gossh/internal/transfer"
 	"github.com/pkg/sftp"
 	"golang.org/x/crypto/ssh"
 )
@@ -35,6 +39,9 @@ const (
 
 	// defaultPrivateKey 默认私钥存放路径
 	defaultPrivateKey = ".ssh/id_rsa"
+
+	// dialTimeout 建立连接的超时时间
+	dialTimeout = time.Minute
 )
 
 var (
@@ -43,8 +50,15 @@ var (
 )
 
 type connection struct {
-	client *ssh.Client
-	addr   string // 地址信息
+	client               *ssh.Client
+	jumps                []*ssh.Client // 经过的跳板机连接（关闭连接时依次关闭）
+	addr                 string        // 地址信息
+	sftpClient           *sftp.Client  // sftp客户端（懒加载，同一连接内复用）
+	sftpLock             sync.Mutex    // 保护sftpClient的创建、替换与关闭
+	sftpUnsupported      bool          // 服务端拒绝过sftp子系统请求（不再重复请求）
+	doneOnce             sync.Once     // 保证只启动一次等待连接结束
+	done                 chan struct{} // ssh连接断开时关闭
+	transfer.ConnLimiter               // 连接级别的限速
 }
 
 // Close 关闭连接
@@ -52,7 +66,17 @@ type connection struct {
 // @date 2023-07-14 09:48:57
 // @return error 关闭异常时返回
 func (c *connection) Close() error {
-	return c.client.Close()
+	c.sftpLock.Lock()
+	if c.sftpClient != nil {
+		_ = c.sftpClient.Close()
+		c.sftpClient = nil
+	}
+	c.sftpLock.Unlock()
+	err := c.client.Close()
+	for i := len(c.jumps) - 1; i >= 0; i-- {
+		_ = c.jumps[i].Close()
+	}
+	return err
 }
 
 // Exec 执行(自定义session动作)
@@ -94,39 +118,69 @@ func (c *connection) ExecShell(ctx context.Context, shell string) (string, error
 	})
 }
 
-// CopyFileLTR 拷贝文件流到远端
+// Run 执行shell命令，分别获取标准输出、错误输出及退出码，ctx取消时向远端发送KILL信号并关闭session
+//
+//	@author duanzt
+//	@date 2026-10-19 21:01:20
+//	@receiver c *connection
+//	@param ctx context.Context 上下文（取消时结束命令）
+//	@param shell string shell命令
+//	@return *internal.CommandResult 执行结果
+//	@return error 无法执行、退出码不为0或ctx取消时返回
+func (c *connection) Run(ctx context.Context, shell string) (*internal.CommandResult, error) {
+	result := &internal.CommandResult{ExitCode: -1}
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+	sess, err := c.client.NewSession()
+	if err != nil {
+		return result, err
+	}
+	defer sess.Close()
+	var stdout, stderr bytes.Buffer
+	sess.Stdout, sess.Stderr = &stdout, &stderr
+	if err := sess.Start(shell); err != nil {
+		return result, err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- sess.Wait()
+	}()
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		// 部分sshd不支持signal，关闭session时远端同样会结束命令
+		_ = sess.Signal(ssh.SIGKILL)
+		_ = sess.Close()
+		<-done
+		err = ctx.Err()
+	}
+	result.Stdout, result.Stderr = stdout.String(), stderr.String()
+	var exitErr *ssh.ExitError
+	switch {
+	case err == nil:
+		result.ExitCode = 0
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitStatus()
+	}
+	return result, err
+}
+
+// CopyFileITR 拷贝文件流到远端
 //
 //	@author duanzt
 //	@date 2023-07-14 09:56:42
 //	@param src io.Reader 流
 //	@param dest string 远端目标文件地址
-//	@param mode string 文件权限
+//	@param mode string 文件权限（八进制或符号格式，为空时保留目标文件已有权限）
+//	@param opts ...internal.CopyOption 拷贝配置
 //	@return error ssh异常时返回
-func (c *connection) CopyFileITR(src io.Reader, dest string, mode string) error {
-	sftpClient, err := sftp.NewClient(c.client)
-	if err != nil {
-		return err
-	}
-	defer sftpClient.Close()
-	err = sftpClient.MkdirAll(filepath.Dir(dest))
-	if err != nil {
-		return err
-	}
-	fd, err := sftpClient.Create(dest)
-	if err != nil {
-		return err
+func (c *connection) CopyFileITR(src io.Reader, dest string, mode string, opts ...internal.CopyOption) error {
+	o := c.CopyOptions(opts)
+	if c.useScp(o) {
+		return c.scpUpload(src, dest, mode, o)
 	}
-	defer fd.Close()
-	_, err = io.Copy(fd, src)
-	// buf := make([]byte, 1024)
-	// for {
-	// 	n, _ := src.Read(buf)
-	// 	if n == 0 {
-	// 		break
-	// 	}
-	// 	fd.Write(buf[:n])
-	// }
-	return err
+	return c.upload(src, dest, mode, o)
 }
 
 // CopyFileITRMon 拷贝文件流到远端（监控远端目标文件大小）
@@ -135,47 +189,14 @@ func (c *connection) CopyFileITR(src io.Reader, dest string, mode string) error
 //	@date 2023-07-14 10:02:16
 //	@param src io.Reader 流
 //	@param dest string 远端目标文件地址
-//	@param mode string 文件权限
+//	@param mode string 文件权限（八进制或符号格式，为空时保留目标文件已有权限）
 //	@param destSizeChan chan int64 返回远端目标文件大小，单位：byte
+//	@param opts ...internal.CopyOption 拷贝配置
 //	@return error ssh异常时返回
-func (c *connection) CopyFileITRMon(src io.Reader, dest string, mode string, destSizeChan chan int64) (err error) {
-	sftpClient, err := sftp.NewClient(c.client)
-	if err != nil {
-		return err
-	}
-	defer sftpClient.Close()
-	if err := sftpClient.MkdirAll(filepath.Dir(dest)); err != nil {
-		return err
-	}
-	fd, err := sftpClient.Create(dest)
-	if err != nil {
-		return err
-	}
-	defer fd.Close()
-
-	var waitGroup sync.WaitGroup
-	waitGroup.Add(1)
-	go func() {
-		_, err = io.Copy(fd, src)
-		waitGroup.Done()
-	}()
-
-	ticker := time.NewTicker(time.Millisecond * 100)
-	defer ticker.Stop()
-	go func() {
-		defer func() {
-			_ = recover()
-		}()
-		for range ticker.C {
-			stat, _ := fd.Stat()
-			destSizeChan <- stat.Size()
-		}
-	}()
-	waitGroup.Wait()
-	stat, _ := fd.Stat()
-	destSizeChan <- stat.Size()
-	close(destSizeChan)
-	return err
+func (c *connection) CopyFileITRMon(src io.Reader, dest string, mode string, destSizeChan chan int64, opts ...internal.CopyOption) (err error) {
+	progress := transfer.NewChanProgress(destSizeChan)
+	defer progress.Close()
+	return c.CopyFileITR(src, dest, mode, append(opts[:len(opts):len(opts)], internal.WithProgress(progress))...)
 }
 
 // CopyFileLTR 拷贝本地文件到远端
@@ -184,14 +205,16 @@ func (c *connection) CopyFileITRMon(src io.Reader, dest string, mode string, des
 //	@date 2023-07-14 10:00:05
 //	@param  src dest 本地文件地址
 //	@param dest string 远端目标文件地址
-//	@param mode string 文件权限
+//	@param mode string 文件权限（八进制或符号格式，为空时保留目标文件已有权限）
+//	@param opts ...internal.CopyOption 拷贝配置
 //	@return error ssh异常时返回
-func (c *connection) CopyFileLTR(src string, dest string, mode string) error {
+func (c *connection) CopyFileLTR(src string, dest string, mode string, opts ...internal.CopyOption) error {
 	file, err := os.Open(src)
 	if err != nil {
 		return err
 	}
-	return c.CopyFileITR(file, dest, mode)
+	defer file.Close()
+	return c.CopyFileITR(file, dest, mode, opts...)
 }
 
 // CopyFileLTRMon 拷贝本地文件到远端（监控远端目标文件大小）
@@ -200,15 +223,14 @@ func (c *connection) CopyFileLTR(src string, dest string, mode string) error {
 //	@date 2023-07-14 10:00:05
 //	@param src string 本地文件地址
 //	@param dest string 远端目标文件地址
-//	@param mode string 文件权限
+//	@param mode string 文件权限（八进制或符号格式，为空时保留目标文件已有权限）
 //	@param destSizeChan chan int64 返回远端目标文件大小，单位：byte
+//	@param opts ...internal.CopyOption 拷贝配置
 //	@return error ssh异常时返回
-func (c *connection) CopyFileLTRMon(src string, dest string, mode string, destSizeChan chan int64) (err error) {
-	file, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	return c.CopyFileITRMon(file, dest, mode, destSizeChan)
+func (c *connection) CopyFileLTRMon(src string, dest string, mode string, destSizeChan chan int64, opts ...internal.CopyOption) (err error) {
+	progress := transfer.NewChanProgress(destSizeChan)
+	defer progress.Close()
+	return c.CopyFileLTR(src, dest, mode, append(opts[:len(opts):len(opts)], internal.WithProgress(progress))...)
 }
 
 // CopyFileRTL 拷贝远端文件到本地
@@ -217,25 +239,15 @@ func (c *connection) CopyFileLTRMon(src string, dest string, mode string, destSi
 //	@date 2023-07-14 09:59:07
 //	@param src string 远端文件地址
 //	@param dest string 本地目标文件地址
-//	@param mode string 文件权限
+//	@param mode string 文件权限（八进制或符号格式，为空时保留目标文件已有权限）
+//	@param opts ...internal.CopyOption 拷贝配置
 //	@return error ssh异常时返回
-func (c *connection) CopyFileRTL(src string, dest string, mode string) error {
-	sftpClient, err := sftp.NewClient(c.client)
-	if err != nil {
-		return err
-	}
-	defer sftpClient.Close()
-	file, err := sftpClient.Open(src)
-	if err != nil {
-		return err
-	}
-	destFile, err := tools.FileTools.CreateFile(dest)
-	if err != nil {
-		return err
+func (c *connection) CopyFileRTL(src string, dest string, mode string, opts ...internal.CopyOption) error {
+	o := c.CopyOptions(opts)
+	if c.useScp(o) {
+		return c.scpDownload(src, dest, mode, o)
 	}
-
-	_, err = io.Copy(destFile, file)
-	return err
+	return c.download(src, dest, mode, o)
 }
 
 // CopyFileRTLMon 拷贝远端文件到本地（监控本地目标文件大小）
@@ -244,49 +256,98 @@ func (c *connection) CopyFileRTL(src string, dest string, mode string) error {
 //	@date 2023-07-14 09:59:07
 //	@param src string 远端文件地址
 //	@param dest string 本地目标文件地址
-//	@param mode string 文件权限
+//	@param mode string 文件权限（八进制或符号格式，为空时保留目标文件已有权限）
 //	@param destSizeChan chan int64 返回本地目标文件大小，单位：byte
+//	@param opts ...internal.CopyOption 拷贝配置
 //	@return error ssh异常时返回
-func (c *connection) CopyFileRTLMon(src string, dest string, mode string, destSizeChan chan int64) (err error) {
-	sftpClient, err := sftp.NewClient(c.client)
-	if err != nil {
-		return err
+func (c *connection) CopyFileRTLMon(src string, dest string, mode string, destSizeChan chan int64, opts ...internal.CopyOption) (err error) {
+	progress := transfer.NewChanProgress(destSizeChan)
+	defer progress.Close()
+	return c.CopyFileRTL(src, dest, mode, append(opts[:len(opts):len(opts)], internal.WithProgress(progress))...)
+}
+
+// CopyDirLTR 递归拷贝本地目录到远端
+//
+//	@author duanzt
+//	@date 2026-10-19 11:42:05
+//	@receiver c *connection
+//	@param src string 本地目录（为文件时仅拷贝该文件）
+//	@param dest string 远端目标目录
+//	@param opts ...internal.CopyOption 拷贝配置
+//	@return *internal.CopySummary 拷贝结果汇总
+//	@return error 拷贝异常时返回（未开启FailFast时汇总全部失败文件）
+func (c *connection) CopyDirLTR(src, dest string, opts ...internal.CopyOption) (*internal.CopySummary, error) {
+	o := c.CopyOptions(opts)
+	if o.Tar {
+		if info, err := os.Stat(src); err == nil && info.IsDir() {
+			return c.tarCopyDirLTR(src, dest, o)
+		}
 	}
-	defer sftpClient.Close()
-	file, err := sftpClient.Open(src)
-	if err != nil {
-		return err
+	if c.useScp(o) {
+		return c.scpCopyDirLTR(src, dest, o)
 	}
+	return transfer.CopyDir(local.NewConnection(), c, src, dest, func(src, dest, mode string) error {
+		return c.CopyFileLTR(src, dest, mode, opts...)
+	}, internal.NewCopyOptions(opts...))
+}
 
-	destFile, err := tools.FileTools.CreateFile(dest)
-	if err != nil {
-		return err
+// CopyDirRTL 递归拷贝远端目录到本地
+//
+//	@author duanzt
+//	@date 2026-10-19 11:42:48
+//	@receiver c *connection
+//	@param src string 远端目录（为文件时仅拷贝该文件）
+//	@param dest string 本地目标目录
+//	@param opts ...internal.CopyOption 拷贝配置
+//	@return *internal.CopySummary 拷贝结果汇总
+//	@return error 拷贝异常时返回（未开启FailFast时汇总全部失败文件）
+func (c *connection) CopyDirRTL(src, dest string, opts ...internal.CopyOption) (*internal.CopySummary, error) {
+	o := c.CopyOptions(opts)
+	if o.Tar {
+		if info, err := c.statByCommand(src); err == nil && info.IsDir() {
+			return c.tarCopyDirRTL(src, dest, o)
+		}
 	}
+	if c.useScp(o) {
+		return c.scpCopyDirRTL(src, dest, o)
+	}
+	return transfer.CopyDir(c, local.NewConnection(), src, dest, func(src, dest, mode string) error {
+		return c.CopyFileRTL(src, dest, mode, opts...)
+	}, internal.NewCopyOptions(opts...))
+}
 
-	var waitGroup sync.WaitGroup
-	waitGroup.Add(1)
-	go func() {
-		_, err = io.Copy(destFile, file)
-		waitGroup.Done()
-	}()
-
-	ticker := time.NewTicker(time.Millisecond * 100)
-	defer ticker.Stop()
-	go func() {
-		defer func() {
-			_ = recover()
-		}()
-		for range ticker.C {
-			stat, _ := destFile.Stat()
-			destSizeChan <- stat.Size()
-		}
-	}()
-	waitGroup.Wait()
-	stat, _ := destFile.Stat()
-	destSizeChan <- stat.Size()
-	close(destSizeChan)
-	return err
+// SyncDirLTR 同步本地目录到远端（基于sftp比较及拷贝）
+//
+//	@author duanzt
+//	@date 2026-10-19 18:28:10
+//	@receiver c *connection
+//	@param src string 本地目录
+//	@param dest string 远端目标目录
+//	@param opts ...internal.CopyOption 拷贝配置
+//	@return *internal.SyncReport 同步结果
+//	@return error 同步异常时返回（未开启FailFast时汇总全部失败文件）
+func (c *connection) SyncDirLTR(src, dest string, opts ...internal.CopyOption) (*internal.SyncReport, error) {
+	fileOpts := transfer.SyncFileOptions(opts)
+	return transfer.Sync(transfer.SyncSide{FS: local.NewConnection(), Sum: transfer.FileSum}, transfer.SyncSide{FS: c, Sum: c.fileSum}, src, dest, func(src, dest, mode string) error {
+		return c.CopyFileLTR(src, dest, mode, fileOpts...)
+	}, internal.NewCopyOptions(opts...))
+}
 
+// SyncDirRTL 同步远端目录到本地（基于sftp比较及拷贝）
+//
+//	@author duanzt
+//	@date 2026-10-19 18:28:40
+//	@receiver c *connection
+//	@param src string 远端目录
+//	@param dest string 本地目标目录
+//	@param opts ...internal.CopyOption 拷贝配置
+//	@return *internal.SyncReport 同步结果
+//	@return error 同步异常时返回（未开启FailFast时汇总全部失败文件）
+func (c *connection) SyncDirRTL(src, dest string, opts ...internal.CopyOption) (*internal.SyncReport, error) {
+	fileOpts := transfer.SyncFileOptions(opts)
+	return transfer.Sync(transfer.SyncSide{FS: c, Sum: c.fileSum}, transfer.SyncSide{FS: local.NewConnection(), Sum: transfer.FileSum}, src, dest, func(src, dest, mode string) error {
+		return c.CopyFileRTL(src, dest, mode, fileOpts...)
+	}, internal.NewCopyOptions(opts...))
 }
 
 // GetAddr 获取ssh连接地址（例127.0.0.1:22）
@@ -307,6 +368,99 @@ func (c *connection) GetIp() string {
 	return strings.Split(c.addr, ":")[0]
 }
 
+// getSftpClient 获取连接内复用的sftp客户端，首次调用或上一个客户端断开后重新创建
+//
+//	@author duanzt
+//	@date 2026-10-19 09:20:41
+//	@receiver c *connection
+//	@return *sftp.Client sftp客户端（可并发使用，调用方不可关闭）
+//	@return error 服务端拒绝sftp子系统时返回errSftpUnsupported（结果记录在连接上），其他异常原样返回
+func (c *connection) getSftpClient() (*sftp.Client, error) {
+	c.sftpLock.Lock()
+	defer c.sftpLock.Unlock()
+	if c.sftpClient != nil {
+		return c.sftpClient, nil
+	}
+	if c.sftpUnsupported {
+		return nil, errSftpUnsupported
+	}
+	sftpClient, err := c.newSftpClient()
+	if err != nil {
+		if errors.Is(err, errSftpUnsupported) {
+			c.sftpUnsupported = true
+		}
+		return nil, err
+	}
+	c.sftpClient = sftpClient
+
+	// sftp子系统断开（或被关闭）后清空缓存，下次使用时重新创建
+	go func() {
+		_ = sftpClient.Wait()
+		c.resetSftpClient(sftpClient)
+	}()
+	return sftpClient, nil
+}
+
+// errSftpUnsupported 服务端拒绝sftp子系统请求（未开启sftp）
+var errSftpUnsupported = errors.New("远端未开启sftp子系统")
+
+// newSftpClient 请求sftp子系统并创建客户端，失败时关闭session
+//
+//	@author duanzt
+//	@date 2026-10-19 23:53:00
+//	@receiver c *connection
+//	@return *sftp.Client sftp客户端
+//	@return error 服务端拒绝sftp子系统时返回errSftpUnsupported
+func (c *connection) newSftpClient() (*sftp.Client, error) {
+	sess, err := c.client.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	if err := sess.RequestSubsystem("sftp"); err != nil {
+		_ = sess.Close()
+		// x/crypto在服务端回复拒绝时返回该异常（连接断开等情况返回io.EOF等其他异常）
+		if err.Error() == "ssh: subsystem request failed" {
+			return nil, fmt.Errorf("%w: %v", errSftpUnsupported, err)
+		}
+		return nil, err
+	}
+	stdin, err := sess.StdinPipe()
+	if err != nil {
+		_ = sess.Close()
+		return nil, err
+	}
+	stdout, err := sess.StdoutPipe()
+	if err != nil {
+		_ = sess.Close()
+		return nil, err
+	}
+	sftpClient, err := sftp.NewClientPipe(stdout, stdin)
+	if err != nil {
+		_ = sess.Close()
+		return nil, err
+	}
+	// sftp客户端关闭时关闭session
+	go func() {
+		_ = sftpClient.Wait()
+		_ = sess.Close()
+	}()
+	return sftpClient, nil
+}
+
+// resetSftpClient 清空已断开的sftp客户端缓存（仅当缓存仍是该客户端时）
+//
+//	@author duanzt
+//	@date 2026-10-19 09:22:07
+//	@receiver c *connection
+//	@param sftpClient *sftp.Client 已断开的sftp客户端
+func (c *connection) resetSftpClient(sftpClient *sftp.Client) {
+	c.sftpLock.Lock()
+	defer c.sftpLock.Unlock()
+	if c.sftpClient == sftpClient {
+		c.sftpClient = nil
+	}
+}
+
 // generateSession 生成session对象
 //
 //	@author duanzt
@@ -333,7 +487,141 @@ func (c *connection) generateSession() (*session, error) {
 //	@return internal.IConnection ssh连接对象
 //	@return error 连接异常时返回
 func NewConnection1(username, password, addr string) (internal.IConnection, error) {
-	auth := []ssh.AuthMethod{}
+	return newConnectionBasic(passwordAuth(password), username, addr)
+}
+
+// NewConnection2 新建连接（通过username+私钥方式）
+//
+//	@author duanzt
+//	@date 2023-07-14 05:16:52
+//	@param username string 用户名
+//	@param privateKey string 私钥文件地址
+//	@param addr string ssh连接地址
+//	@return internal.IConnection ssh连接
+//	@return error 连接异常时返回
+func NewConnection2(username, privateKey, addr string) (internal.IConnection, error) {
+	auth, err := privateKeyAuth(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return newConnectionBasic(auth, username, addr)
+}
+
+// NewConnectionDefault 使用默认方式新建连接（默认用户名：root，默认使用私钥连接，私钥地址为当前目录下的.ssh/id_rsa文件）
+//
+//	@author duanzt
+//	@date 2023-07-14 06:16:26
+//	@param addr string ssh连接地址
+//	@return internal.IConnection ssh连接
+//	@return error 连接异常时返回
+func NewConnectionDefault(addr string) (internal.IConnection, error) {
+	auth, err := defaultKeyAuth()
+	if err != nil {
+		return nil, err
+	}
+	return newConnectionBasic(auth, defaultUsername, addr)
+}
+
+// Dial 根据主机连接配置新建连接（依次尝试私钥及密码验证，均未配置时使用默认私钥），ctx取消或超时时停止连接
+//
+//	@author duanzt
+//	@date 2026-10-19 20:58:10
+//	@param ctx context.Context 上下文
+//	@param host internal.Host 主机连接配置
+//	@return internal.IConnection ssh连接
+//	@return error 连接异常时返回
+func Dial(ctx context.Context, host internal.Host) (internal.IConnection, error) {
+	addr, err := tools.SshAddrTools.SetRightAddr(host.Addr)
+	if err != nil {
+		return nil, err
+	}
+	username := host.User
+	if username == "" {
+		username = defaultUsername
+	}
+	auth, err := hostAuth(host)
+	if err != nil {
+		return nil, err
+	}
+	if host.Jump == "" {
+		return newConnectionContext(ctx, auth, username, addr)
+	}
+	// 依次连接跳板机，再经过最后一台跳板机连接目标主机（跳板机使用与目标主机相同的验证方式）
+	dialer := net.Dialer{Timeout: dialTimeout}
+	dial := dialer.DialContext
+	var jumps []*ssh.Client
+	closeJumps := func() {
+		for i := len(jumps) - 1; i >= 0; i-- {
+			_ = jumps[i].Close()
+		}
+	}
+	for _, jump := range strings.Split(host.Jump, ",") {
+		jumpUser, jumpAddr := username, strings.TrimSpace(jump)
+		if i := strings.LastIndex(jumpAddr, "@"); i >= 0 {
+			jumpUser, jumpAddr = jumpAddr[:i], jumpAddr[i+1:]
+		}
+		if jumpAddr, err = tools.SshAddrTools.SetRightAddr(jumpAddr); err != nil {
+			closeJumps()
+			return nil, err
+		}
+		client, err := dialClient(ctx, dial, auth, jumpUser, jumpAddr)
+		if err != nil {
+			closeJumps()
+			return nil, fmt.Errorf("连接跳板机%s失败: %w", jumpAddr, err)
+		}
+		jumps = append(jumps, client)
+		dial = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dialThrough(ctx, client, network, addr)
+		}
+	}
+	client, err := dialClient(ctx, dial, auth, username, addr)
+	if err != nil {
+		closeJumps()
+		return nil, err
+	}
+	return &connection{client: client, addr: addr, jumps: jumps}, nil
+}
+
+// dialThrough 经过跳板机建立到目标地址的连接，ctx取消或超时时立即返回（client.Dial不支持ctx，完成较晚的连接会被关闭）
+//
+//	@author duanzt
+//	@date 2026-10-19 23:57:00
+//	@param ctx context.Context 上下文
+//	@param client *ssh.Client 跳板机连接
+//	@param network string 网络类型
+//	@param addr string 目标地址
+//	@return net.Conn 网络连接
+//	@return error 连接异常或ctx取消时返回
+func dialThrough(ctx context.Context, client *ssh.Client, network, addr string) (net.Conn, error) {
+	type result struct {
+		conn net.Conn
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		conn, err := client.Dial(network, addr)
+		done <- result{conn: conn, err: err}
+	}()
+	select {
+	case r := <-done:
+		return r.conn, r.err
+	case <-ctx.Done():
+		go func() {
+			if r := <-done; r.conn != nil {
+				_ = r.conn.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+// passwordAuth 密码验证方式（同时支持keyboard-interactive）
+//
+//	@author duanzt
+//	@date 2026-10-19 20:58:50
+//	@param password string 密码
+//	@return []ssh.AuthMethod auth方法
+func passwordAuth(password string) []ssh.AuthMethod {
 	keyboardInteractiveChallenge := func(
 		username,
 		instruction string,
@@ -345,22 +633,42 @@ func NewConnection1(username, password, addr string) (internal.IConnection, erro
 		}
 		return []string{password}, nil
 	}
-	auth = append(auth, ssh.Password(password))
-	auth = append(auth, ssh.KeyboardInteractive(keyboardInteractiveChallenge))
-	return newConnectionBasic(auth, username, addr)
+	return []ssh.AuthMethod{ssh.Password(password), ssh.KeyboardInteractive(keyboardInteractiveChallenge)}
 }
 
-// NewConnection2 新建连接（通过username+私钥方式）
+// hostAuth 主机连接配置的验证方式：私钥在前，密码（含keyboard-interactive）在后，均未配置时使用默认私钥
 //
 //	@author duanzt
-//	@date 2023-07-14 05:16:52
-//	@param username string 用户名
+//	@date 2026-10-19 23:55:00
+//	@param host internal.Host 主机连接配置
+//	@return []ssh.AuthMethod auth方法
+//	@return error 读取或解析私钥异常时返回
+func hostAuth(host internal.Host) ([]ssh.AuthMethod, error) {
+	var auth []ssh.AuthMethod
+	if host.PrivateKey != "" {
+		keyAuth, err := privateKeyAuth(host.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		auth = append(auth, keyAuth...)
+	}
+	if host.Password != "" {
+		auth = append(auth, passwordAuth(host.Password)...)
+	}
+	if len(auth) == 0 {
+		return defaultKeyAuth()
+	}
+	return auth, nil
+}
+
+// privateKeyAuth 私钥验证方式
+//
+//	@author duanzt
+//	@date 2026-10-19 20:59:20
 //	@param privateKey string 私钥文件地址
-//	@param addr string ssh连接地址
-//	@return internal.IConnection ssh连接
-//	@return error 连接异常时返回
-func NewConnection2(username, privateKey, addr string) (internal.IConnection, error) {
-	auth := []ssh.AuthMethod{}
+//	@return []ssh.AuthMethod auth方法
+//	@return error 读取或解析私钥异常时返回
+func privateKeyAuth(privateKey string) ([]ssh.AuthMethod, error) {
 	pkData, err := ioutil.ReadFile(privateKey)
 	if err != nil {
 		return nil, err
@@ -369,19 +677,16 @@ func NewConnection2(username, privateKey, addr string) (internal.IConnection, er
 	if err != nil {
 		return nil, err
 	}
-	auth = append(auth, ssh.PublicKeys(pk))
-	return newConnectionBasic(auth, username, addr)
+	return []ssh.AuthMethod{ssh.PublicKeys(pk)}, nil
 }
 
-// NewConnectionDefault 使用默认方式新建连接（默认用户名：root，默认使用私钥连接，私钥地址为当前目录下的.ssh/id_rsa文件）
+// defaultKeyAuth 默认私钥验证方式
 //
 //	@author duanzt
-//	@date 2023-07-14 06:16:26
-//	@param addr string ssh连接地址
-//	@return internal.IConnection ssh连接
-//	@return error 连接异常时返回
-func NewConnectionDefault(addr string) (internal.IConnection, error) {
-	auth := []ssh.AuthMethod{}
+//	@date 2026-10-19 20:59:50
+//	@return []ssh.AuthMethod auth方法
+//	@return error 读取或解析私钥异常时返回
+func defaultKeyAuth() ([]ssh.AuthMethod, error) {
 	f, _ := multi.Open(defaultPrivateKey)
 	defer f.Close()
 	data, err := tools.FileTools.ReadFile(f)
@@ -392,8 +697,7 @@ func NewConnectionDefault(addr string) (internal.IConnection, error) {
 	if err != nil {
 		return nil, err
 	}
-	auth = append(auth, ssh.PublicKeys(pk))
-	return newConnectionBasic(auth, defaultUsername, addr)
+	return []ssh.AuthMethod{ssh.PublicKeys(pk)}, nil
 }
 
 // newConnectionBasic 新建连接（默认方法，auth需要前置组装）
@@ -406,6 +710,40 @@ func NewConnectionDefault(addr string) (internal.IConnection, error) {
 //	@return internal.IConnection ssh连接
 //	@return error 连接异常时返回
 func newConnectionBasic(auth []ssh.AuthMethod, username, addr string) (internal.IConnection, error) {
+	return newConnectionContext(context.Background(), auth, username, addr)
+}
+
+// newConnectionContext 新建连接，ctx取消或超时时停止连接（包括ssh握手）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:00:30
+//	@param ctx context.Context 上下文
+//	@param auth []ssh.AuthMethod auth方法
+//	@param username string 用户名
+//	@param addr string ssh连接地址
+//	@return internal.IConnection ssh连接
+//	@return error 连接异常时返回
+func newConnectionContext(ctx context.Context, auth []ssh.AuthMethod, username, addr string) (internal.IConnection, error) {
+	dialer := net.Dialer{Timeout: dialTimeout}
+	client, err := dialClient(ctx, dialer.DialContext, auth, username, addr)
+	if err != nil {
+		return nil, err
+	}
+	return &connection{client: client, addr: addr}, nil
+}
+
+// dialClient 通过dial建立底层连接并完成ssh握手，ctx取消或超时时停止
+//
+//	@author duanzt
+//	@date 2026-10-19 21:44:10
+//	@param ctx context.Context 上下文
+//	@param dial func(ctx context.Context, network, addr string) (net.Conn, error) 建立底层连接的方法（直连或经过跳板机）
+//	@param auth []ssh.AuthMethod auth方法
+//	@param username string 用户名
+//	@param addr string ssh连接地址
+//	@return *ssh.Client ssh客户端
+//	@return error 连接异常时返回
+func dialClient(ctx context.Context, dial func(ctx context.Context, network, addr string) (net.Conn, error), auth []ssh.AuthMethod, username, addr string) (*ssh.Client, error) {
 	config := ssh.Config{
 		Ciphers: []string{"aes128-ctr", "aes192-ctr", "aes256-ctr", "aes128-gcm@openssh.com", "arcfour256", "arcfour128", "aes128-cbc", "3des-cbc", "aes192-cbc", "aes256-cbc"},
 	}
@@ -413,16 +751,33 @@ func newConnectionBasic(auth []ssh.AuthMethod, username, addr string) (internal.
 	clientConfig := &ssh.ClientConfig{
 		User:    username,
 		Auth:    auth,
-		Timeout: time.Duration(1) * time.Minute,
+		Timeout: dialTimeout,
 		Config:  config,
 		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
 			return nil
 		},
 	}
 
-	client, err := ssh.Dial("tcp", addr, clientConfig)
+	conn, err := dial(ctx, "tcp", addr)
 	if err != nil {
 		return nil, err
 	}
-	return &connection{client: client, addr: addr}, nil
+	// 握手期间ctx取消时关闭底层连接
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close()
+	})
+	conn.SetDeadline(time.Now().Add(clientConfig.Timeout))
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, clientConfig)
+	if !stop() {
+		if err == nil {
+			sshConn.Close()
+		}
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	return ssh.NewClient(sshConn, chans, reqs), nil
 }
@@ -0,0 +1,541 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 09:52:18
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-20 00:05:40
+ * @FilePath: filesystem.go
+ * @Description: 远程ssh连接的文件系统操作（基于sftp实现）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package remote
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/duanztop/gossh/internal"
+	"github.com/pkg/sftp"
+)
+
+const (
+
+	// posixRenameExtension 支持覆盖目标文件的重命名扩展
+	posixRenameExtension = "posix-rename@openssh.com"
+
+	// hardlinkExtension 创建硬链接的扩展
+	hardlinkExtension = "hardlink@openssh.com"
+
+	// fsyncExtension 将文件内容刷入磁盘的扩展
+	fsyncExtension = "fsync@openssh.com"
+)
+
+// Open 以只读方式打开文件
+//
+//	@author duanzt
+//	@date 2026-10-19 10:42:03
+//	@receiver c *connection
+//	@param name string 文件路径
+//	@return fs.File 文件对象，使用完毕后需要关闭
+//	@return error 文件不存在等异常时返回
+func (c *connection) Open(name string) (fs.File, error) {
+	sftpClient, err := c.getSftpClient()
+	if err != nil {
+		return nil, err
+	}
+	file, err := sftpClient.Open(name)
+	if err != nil {
+		return nil, toPathError("open", name, err)
+	}
+	return file, nil
+}
+
+// OpenFile 按标志打开文件（sftp文件句柄，大块读写时并发请求）
+//
+//	@author duanzt
+//	@date 2026-10-19 19:08:10
+//	@receiver c *connection
+//	@param name string 文件路径
+//	@param flag int 打开标志（同os.OpenFile）
+//	@param perm fs.FileMode 新建文件的权限（不受umask影响）
+//	@return internal.IFile 文件句柄（*sftp.File）
+//	@return error 文件不存在等异常时返回
+func (c *connection) OpenFile(name string, flag int, perm fs.FileMode) (internal.IFile, error) {
+	sftpClient, err := c.getSftpClient()
+	if err != nil {
+		return nil, err
+	}
+	// sftp打开文件时无法指定权限，新建的文件在打开后设置
+	created := false
+	if flag&os.O_CREATE != 0 {
+		if _, err := sftpClient.Lstat(name); err != nil {
+			created = errors.Is(normaliseError(err), fs.ErrNotExist)
+		}
+	}
+	file, err := sftpClient.OpenFile(name, flag)
+	if err != nil {
+		return nil, toPathError("open", name, err)
+	}
+	if created {
+		if err := file.Chmod(perm.Perm()); err != nil {
+			file.Close()
+			return nil, toPathError("chmod", name, err)
+		}
+	}
+	// sftp按偏移写入，部分服务端不处理追加标志，从文件末尾开始写入
+	if flag&os.O_APPEND != 0 {
+		if _, err := file.Seek(0, io.SeekEnd); err != nil {
+			file.Close()
+			return nil, toPathError("seek", name, err)
+		}
+	}
+	return file, nil
+}
+
+// Create 创建文件（已存在时清空），以读写方式打开
+//
+//	@author duanzt
+//	@date 2026-10-19 19:08:45
+//	@receiver c *connection
+//	@param name string 文件路径
+//	@return internal.IFile 文件句柄（*sftp.File）
+//	@return error 创建异常时返回
+func (c *connection) Create(name string) (internal.IFile, error) {
+	return c.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+}
+
+// ReadFile 读取文件全部内容（并发请求读取）
+//
+//	@author duanzt
+//	@date 2026-10-19 19:09:12
+//	@receiver c *connection
+//	@param name string 文件路径
+//	@return []byte 文件内容
+//	@return error 文件不存在等异常时返回
+func (c *connection) ReadFile(name string) ([]byte, error) {
+	file, err := c.OpenFile(name, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var buf bytes.Buffer
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		buf.Grow(int(info.Size()))
+	}
+	// *sftp.File实现了io.WriterTo，io.Copy会并发读取
+	if _, err := io.Copy(&buf, file); err != nil {
+		return nil, toPathError("read", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteFile 将内容写入文件（并发请求写入）
+//
+//	@author duanzt
+//	@date 2026-10-19 19:09:40
+//	@receiver c *connection
+//	@param name string 文件路径
+//	@param data []byte 文件内容
+//	@param perm fs.FileMode 新建文件的权限（不受umask影响）
+//	@return error 写入异常时返回
+func (c *connection) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	file, err := c.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	// *sftp.File实现了io.ReaderFrom，io.Copy会并发写入
+	if _, err := io.Copy(file, bytes.NewReader(data)); err != nil {
+		file.Close()
+		return toPathError("write", name, err)
+	}
+	return toPathError("close", name, file.Close())
+}
+
+// Stat 获取文件信息（跟随符号链接）
+//
+//	@author duanzt
+//	@date 2026-10-19 09:53:02
+//	@receiver c *connection
+//	@param name string 文件路径
+//	@return fs.FileInfo 文件信息
+//	@return error 文件不存在等异常时返回
+func (c *connection) Stat(name string) (fs.FileInfo, error) {
+	sftpClient, err := c.getSftpClient()
+	if err != nil {
+		return nil, err
+	}
+	info, err := sftpClient.Stat(name)
+	if err != nil {
+		return nil, toPathError("stat", name, err)
+	}
+	return info, nil
+}
+
+// Lstat 获取文件信息（不跟随符号链接）
+//
+//	@author duanzt
+//	@date 2026-10-19 09:53:40
+//	@receiver c *connection
+//	@param name string 文件路径
+//	@return fs.FileInfo 文件信息
+//	@return error 文件不存在等异常时返回
+func (c *connection) Lstat(name string) (fs.FileInfo, error) {
+	sftpClient, err := c.getSftpClient()
+	if err != nil {
+		return nil, err
+	}
+	info, err := sftpClient.Lstat(name)
+	if err != nil {
+		return nil, toPathError("lstat", name, err)
+	}
+	return info, nil
+}
+
+// ReadDir 读取目录下的文件信息（按文件名排序）
+//
+//	@author duanzt
+//	@date 2026-10-19 09:54:11
+//	@receiver c *connection
+//	@param name string 目录路径
+//	@return []fs.FileInfo 目录下的文件信息
+//	@return error 目录不存在等异常时返回
+func (c *connection) ReadDir(name string) ([]fs.FileInfo, error) {
+	sftpClient, err := c.getSftpClient()
+	if err != nil {
+		return nil, err
+	}
+	infos, err := sftpClient.ReadDir(name)
+	if err != nil {
+		return nil, toPathError("readdir", name, err)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name() < infos[j].Name()
+	})
+	return infos, nil
+}
+
+// Remove 删除文件或空目录
+//
+//	@author duanzt
+//	@date 2026-10-19 09:54:45
+//	@receiver c *connection
+//	@param name string 文件/目录路径
+//	@return error 删除异常时返回
+func (c *connection) Remove(name string) error {
+	sftpClient, err := c.getSftpClient()
+	if err != nil {
+		return err
+	}
+	return toPathError("remove", name, sftpClient.Remove(name))
+}
+
+// RemoveAll 递归删除文件或目录（路径不存在时不返回异常）
+//
+//	@author duanzt
+//	@date 2026-10-19 09:55:20
+//	@receiver c *connection
+//	@param name string 文件/目录路径
+//	@return error 删除异常时返回
+func (c *connection) RemoveAll(name string) error {
+	info, err := c.Lstat(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if info.IsDir() {
+		children, err := c.ReadDir(name)
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			if err := c.RemoveAll(path.Join(name, child.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	if err := c.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Rename 重命名（目标文件存在时覆盖）
+//
+//	@author duanzt
+//	@date 2026-10-19 09:56:03
+//	@receiver c *connection
+//	@param oldname string 原路径
+//	@param newname string 新路径
+//	@return error 重命名异常时返回
+func (c *connection) Rename(oldname, newname string) error {
+	sftpClient, err := c.getSftpClient()
+	if err != nil {
+		return err
+	}
+	// sftp v3协议的rename在目标存在时会失败，优先使用posix-rename扩展保证与os.Rename语义一致
+	if _, ok := sftpClient.HasExtension(posixRenameExtension); ok {
+		err = sftpClient.PosixRename(oldname, newname)
+	} else {
+		err = sftpClient.Rename(oldname, newname)
+		// 仅在源路径存在且目标为已存在的非目录时删除目标后重试，其他原因的失败不删除目标
+		if err != nil {
+			if _, statErr := sftpClient.Lstat(oldname); statErr == nil {
+				if info, statErr := sftpClient.Lstat(newname); statErr == nil && !info.IsDir() && sftpClient.Remove(newname) == nil {
+					err = sftpClient.Rename(oldname, newname)
+				}
+			}
+		}
+	}
+	if err != nil {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: normaliseError(err)}
+	}
+	return nil
+}
+
+// Mkdir 创建目录
+//
+//	@author duanzt
+//	@date 2026-10-19 09:56:48
+//	@receiver c *connection
+//	@param name string 目录路径
+//	@param perm fs.FileMode 目录权限
+//	@return error 创建异常时返回
+func (c *connection) Mkdir(name string, perm fs.FileMode) error {
+	sftpClient, err := c.getSftpClient()
+	if err != nil {
+		return err
+	}
+	if err := sftpClient.Mkdir(name); err != nil {
+		// 多数sftp服务端对已存在的路径只返回通用失败码，这里补充判断
+		if _, statErr := sftpClient.Lstat(name); statErr == nil {
+			return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrExist}
+		}
+		return toPathError("mkdir", name, err)
+	}
+	return toPathError("chmod", name, sftpClient.Chmod(name, perm))
+}
+
+// MkdirAll 递归创建目录（目录已存在时不返回异常）
+//
+//	@author duanzt
+//	@date 2026-10-19 09:57:30
+//	@receiver c *connection
+//	@param name string 目录路径
+//	@param perm fs.FileMode 新建目录的权限
+//	@return error 创建异常时返回
+func (c *connection) MkdirAll(name string, perm fs.FileMode) error {
+	info, err := c.Stat(name)
+	if err == nil {
+		if info.IsDir() {
+			return nil
+		}
+		return &fs.PathError{Op: "mkdir", Path: name, Err: syscall.ENOTDIR}
+	}
+
+	// 先创建父目录，再创建当前目录
+	if parent := path.Dir(name); parent != name && parent != "." && parent != "/" {
+		if err := c.MkdirAll(parent, perm); err != nil {
+			return err
+		}
+	}
+	if err := c.Mkdir(name, perm); err != nil {
+		if info, statErr := c.Lstat(name); statErr == nil && info.IsDir() {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// Chmod 修改文件权限
+//
+//	@author duanzt
+//	@date 2026-10-19 09:58:12
+//	@receiver c *connection
+//	@param name string 文件路径
+//	@param mode fs.FileMode 文件权限
+//	@return error 修改异常时返回
+func (c *connection) Chmod(name string, mode fs.FileMode) error {
+	sftpClient, err := c.getSftpClient()
+	if err != nil {
+		return err
+	}
+	return toPathError("chmod", name, sftpClient.Chmod(name, mode))
+}
+
+// Chown 修改文件所属用户及用户组
+//
+//	@author duanzt
+//	@date 2026-10-19 09:58:40
+//	@receiver c *connection
+//	@param name string 文件路径
+//	@param uid int 用户id（小于0时不修改）
+//	@param gid int 用户组id（小于0时不修改）
+//	@return error 修改异常时返回
+func (c *connection) Chown(name string, uid, gid int) error {
+	sftpClient, err := c.getSftpClient()
+	if err != nil {
+		return err
+	}
+	// sftp需要同时设置用户及用户组，与os.Chown一致，小于0的一方保持不变
+	if uid < 0 || gid < 0 {
+		info, err := sftpClient.Stat(name)
+		if err != nil {
+			return toPathError("chown", name, err)
+		}
+		if stat, ok := info.Sys().(*sftp.FileStat); ok {
+			if uid < 0 {
+				uid = int(stat.UID)
+			}
+			if gid < 0 {
+				gid = int(stat.GID)
+			}
+		}
+	}
+	return toPathError("chown", name, sftpClient.Chown(name, uid, gid))
+}
+
+// Chtimes 修改文件访问时间及修改时间
+//
+//	@author duanzt
+//	@date 2026-10-19 09:59:11
+//	@receiver c *connection
+//	@param name string 文件路径
+//	@param atime time.Time 访问时间
+//	@param mtime time.Time 修改时间
+//	@return error 修改异常时返回
+func (c *connection) Chtimes(name string, atime, mtime time.Time) error {
+	sftpClient, err := c.getSftpClient()
+	if err != nil {
+		return err
+	}
+	return toPathError("chtimes", name, sftpClient.Chtimes(name, atime, mtime))
+}
+
+// Symlink 创建符号链接newname，指向oldname
+//
+//	@author duanzt
+//	@date 2026-10-19 09:59:45
+//	@receiver c *connection
+//	@param oldname string 链接指向的路径
+//	@param newname string 符号链接路径
+//	@return error 创建异常时返回
+func (c *connection) Symlink(oldname, newname string) error {
+	sftpClient, err := c.getSftpClient()
+	if err != nil {
+		return err
+	}
+	if err := sftpClient.Symlink(oldname, newname); err != nil {
+		if _, statErr := sftpClient.Lstat(newname); statErr == nil {
+			err = fs.ErrExist
+		}
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: normaliseError(err)}
+	}
+	return nil
+}
+
+// Readlink 读取符号链接指向的路径
+//
+//	@author duanzt
+//	@date 2026-10-19 10:00:20
+//	@receiver c *connection
+//	@param name string 符号链接路径
+//	@return string 链接指向的路径
+//	@return error 读取异常时返回
+func (c *connection) Readlink(name string) (string, error) {
+	sftpClient, err := c.getSftpClient()
+	if err != nil {
+		return "", err
+	}
+	target, err := sftpClient.ReadLink(name)
+	if err != nil {
+		return "", toPathError("readlink", name, err)
+	}
+	return target, nil
+}
+
+// Truncate 修改文件大小
+//
+//	@author duanzt
+//	@date 2026-10-19 10:00:52
+//	@receiver c *connection
+//	@param name string 文件路径
+//	@param size int64 文件大小，单位：byte
+//	@return error 修改异常时返回
+func (c *connection) Truncate(name string, size int64) error {
+	sftpClient, err := c.getSftpClient()
+	if err != nil {
+		return err
+	}
+	return toPathError("truncate", name, sftpClient.Truncate(name, size))
+}
+
+// Glob 查找匹配通配符的文件（逐级读取远端目录匹配，结果按路径排序）
+//
+//	@author duanzt
+//	@date 2026-10-19 19:35:45
+//	@receiver c *connection
+//	@param pattern string 通配符
+//	@return []string 匹配的文件路径
+//	@return error 通配符不合法时返回
+func (c *connection) Glob(pattern string) ([]string, error) {
+	sftpClient, err := c.getSftpClient()
+	if err != nil {
+		return nil, err
+	}
+	matches, err := sftpClient.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	// 与filepath.Glob保持一致，按路径排序
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// toPathError 将sftp异常转换为*fs.PathError，与os包的错误语义保持一致
+//
+//	@author duanzt
+//	@date 2026-10-19 10:01:30
+//	@param op string 操作名称
+//	@param name string 文件路径
+//	@param err error sftp异常
+//	@return error 转换后的异常（err为nil时返回nil）
+func toPathError(op, name string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return &fs.PathError{Op: op, Path: name, Err: normaliseError(pathErr.Err)}
+	}
+	return &fs.PathError{Op: op, Path: name, Err: normaliseError(err)}
+}
+
+// normaliseError 将sftp状态码转换为fs包定义的标准异常
+//
+//	@author duanzt
+//	@date 2026-10-19 10:02:04
+//	@param err error sftp异常
+//	@return error 标准异常（无法转换时原样返回）
+func normaliseError(err error) error {
+	var statusErr *sftp.StatusError
+	if !errors.As(err, &statusErr) {
+		return err
+	}
+	switch statusErr.FxCode() {
+	case sftp.ErrSSHFxNoSuchFile:
+		return fs.ErrNotExist
+	case sftp.ErrSSHFxPermissionDenied:
+		return fs.ErrPermission
+	}
+	return err
+}
@@ -0,0 +1,248 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 19:28:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:52:00
+ * @FilePath: find.go
+ * @Description: 远端查找文件（优先使用find命令一次性输出结果，不支持时通过sftp遍历）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package remote
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/duanztop/gossh/internal"
+	"github.com/duanztop/gossh/internal/tools"
+	"github.com/duanztop/gossh/internal/transfer"
+	"golang.org/x/crypto/ssh"
+)
+
+// findFormat find命令的输出格式：类型 权限 大小 修改时间 深度 相对路径，以NUL分隔
+const findFormat = `%y %m %s %T@ %d %P\0`
+
+// findTypes find命令输出的文件类型对应的fs.FileMode
+var findTypes = map[byte]fs.FileMode{
+	'f': 0,
+	'd': fs.ModeDir,
+	'l': fs.ModeSymlink,
+	'p': fs.ModeNamedPipe,
+	's': fs.ModeSocket,
+	'c': fs.ModeDevice | fs.ModeCharDevice,
+	'b': fs.ModeDevice,
+}
+
+// Find 查找满足条件的文件（同find -H：跟随根目录本身的符号链接，不跟随子项的符号链接）
+// 远端支持GNU find时执行一次命令获取结果，否则通过sftp逐个目录遍历；无法读取的子目录会被跳过，结果按路径排序
+//
+//	@author duanzt
+//	@date 2026-10-19 19:29:02
+//	@receiver c *connection
+//	@param ctx context.Context 上下文（sftp遍历时取消即停止）
+//	@param root string 查找的根目录
+//	@param opts ...internal.FindOption 查找条件
+//	@return []*internal.FindEntry 查找到的文件
+//	@return error 根目录不存在或查找异常时返回
+func (c *connection) Find(ctx context.Context, root string, opts ...internal.FindOption) ([]*internal.FindEntry, error) {
+	o := internal.NewFindOptions(opts...)
+	entries, err := c.findByCommand(ctx, root, o)
+	if !errors.Is(err, errFindUnsupported) {
+		return entries, err
+	}
+	return transfer.Find(ctx, c, root, o)
+}
+
+// errFindUnsupported 远端不支持find命令（或不支持-printf）
+var errFindUnsupported = errors.New("远端不支持find -printf")
+
+// findByCommand 通过find命令查找文件，文件名及类型、深度条件交给find处理，其余条件在本地判断
+//
+//	@author duanzt
+//	@date 2026-10-19 19:30:10
+//	@receiver c *connection
+//	@param ctx context.Context 上下文
+//	@param root string 查找的根目录
+//	@param o *internal.FindOptions 查找条件
+//	@return []*internal.FindEntry 查找到的文件
+//	@return error 远端不支持时返回errFindUnsupported，ctx取消、连接断开等其他异常原样返回
+func (c *connection) findByCommand(ctx context.Context, root string, o *internal.FindOptions) ([]*internal.FindEntry, error) {
+	output, err := c.runFindCommand(ctx, findCommand(root, o))
+	if err != nil {
+		return nil, err
+	}
+	switch output {
+	case "missing\n":
+		return nil, &fs.PathError{Op: "stat", Path: root, Err: fs.ErrNotExist}
+	case "unsupported\n":
+		return nil, errFindUnsupported
+	}
+	var entries []*internal.FindEntry
+	for _, record := range bytes.Split([]byte(output), []byte{0}) {
+		if len(record) == 0 {
+			continue
+		}
+		entry, err := parseFindRecord(root, string(record))
+		if err != nil {
+			return nil, err
+		}
+		if entry.Depth >= o.MinDepth && o.Match(entry.FileInfo) {
+			entries = append(entries, entry)
+		}
+	}
+	transfer.SortFindEntries(entries)
+	return entries, nil
+}
+
+// runFindCommand 执行find命令，ctx取消时结束命令
+//
+//	@author duanzt
+//	@date 2026-10-19 23:52:00
+//	@receiver c *connection
+//	@param ctx context.Context 上下文
+//	@param command string shell命令
+//	@return string 标准输出
+//	@return error 无法执行或退出码为127（命令不存在）时返回errFindUnsupported，其他异常附加错误输出
+func (c *connection) runFindCommand(ctx context.Context, command string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	sess, err := c.client.NewSession()
+	if err != nil {
+		return "", err
+	}
+	defer sess.Close()
+	var stdout, stderr bytes.Buffer
+	sess.Stdout, sess.Stderr = &stdout, &stderr
+	// 无法执行命令（例如服务端仅开启sftp）时同样视为不支持
+	if err := sess.Start(command); err != nil {
+		return "", errFindUnsupported
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- sess.Wait()
+	}()
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		_ = sess.Signal(ssh.SIGKILL)
+		_ = sess.Close()
+		<-done
+		return "", ctx.Err()
+	}
+	if err != nil {
+		var exitErr *ssh.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitStatus() == 127 {
+			return "", errFindUnsupported
+		}
+		if output := strings.TrimSpace(stderr.String()); output != "" {
+			return "", fmt.Errorf("%w, %s", err, output)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// findCommand 生成find命令：根目录不存在时输出missing，不支持-printf时输出unsupported
+//
+//	@author duanzt
+//	@date 2026-10-19 19:31:02
+//	@param root string 查找的根目录
+//	@param o *internal.FindOptions 查找条件
+//	@return string shell命令
+func findCommand(root string, o *internal.FindOptions) string {
+	arg := root
+	if strings.HasPrefix(arg, "-") {
+		// 避免被find解析为表达式
+		arg = "./" + arg
+	}
+	quoted := tools.ShellTools.Quote(arg)
+	var b strings.Builder
+	b.WriteString("if [ ! -e " + quoted + " ] && [ ! -L " + quoted + " ]; then echo missing; exit 0; fi; ")
+	b.WriteString("find -H " + quoted + " -maxdepth 0 -printf '' 2>/dev/null || { echo unsupported; exit 0; }; ")
+	b.WriteString("find -H " + quoted)
+	if o.MinDepth > 0 {
+		b.WriteString(" -mindepth " + strconv.Itoa(o.MinDepth))
+	}
+	if o.MaxDepth >= 0 {
+		b.WriteString(" -maxdepth " + strconv.Itoa(o.MaxDepth))
+	}
+	if len(o.Names) > 0 {
+		b.WriteString(" \\(")
+		for i, pattern := range o.Names {
+			if i > 0 {
+				b.WriteString(" -o")
+			}
+			b.WriteString(" -name " + tools.ShellTools.Quote(pattern))
+		}
+		b.WriteString(" \\)")
+	}
+	if o.Type != internal.FindAny {
+		b.WriteString(" -type " + string(o.Type))
+	}
+	b.WriteString(" -printf '" + findFormat + "' 2>/dev/null; exit 0")
+	return b.String()
+}
+
+// parseFindRecord 解析find命令输出的一条记录
+//
+//	@author duanzt
+//	@date 2026-10-19 19:32:10
+//	@param root string 查找的根目录
+//	@param record string 记录（类型 权限 大小 修改时间 深度 相对路径）
+//	@return *internal.FindEntry 查找到的文件
+//	@return error 记录不合法时返回
+func parseFindRecord(root, record string) (*internal.FindEntry, error) {
+	fields := strings.SplitN(record, " ", 6)
+	if len(fields) != 6 || len(fields[0]) != 1 {
+		return nil, fmt.Errorf("find输出不合法: %q", record)
+	}
+	typ, ok := findTypes[fields[0][0]]
+	if !ok {
+		typ = fs.ModeIrregular
+	}
+	perm, err1 := strconv.ParseUint(fields[1], 8, 32)
+	size, err2 := strconv.ParseInt(fields[2], 10, 64)
+	modTime, err3 := parseFindTime(fields[3])
+	depth, err4 := strconv.Atoi(fields[4])
+	if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+		return nil, fmt.Errorf("find输出不合法: %q", record)
+	}
+	name := root
+	if fields[5] != "" {
+		name = path.Join(root, fields[5])
+	}
+	info := &scpFileInfo{name: path.Base(name), size: size, mode: tools.ModeTools.FromUnix(uint32(perm)) | typ, modTime: modTime}
+	return &internal.FindEntry{Path: name, Depth: depth, FileInfo: info}, nil
+}
+
+// parseFindTime 解析find命令输出的修改时间（%T@，秒.纳秒）
+//
+//	@author duanzt
+//	@date 2026-10-19 19:33:02
+//	@param s string 修改时间
+//	@return time.Time 修改时间
+//	@return error 格式不合法时返回
+func parseFindTime(s string) (time.Time, error) {
+	secs, frac, _ := strings.Cut(s, ".")
+	sec, err := strconv.ParseInt(secs, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var nsec int64
+	if frac != "" {
+		frac = (frac + "000000000")[:9]
+		if nsec, err = strconv.ParseInt(frac, 10, 64); err != nil {
+			return time.Time{}, err
+		}
+	}
+	return time.Unix(sec, nsec), nil
+}
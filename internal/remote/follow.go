@@ -0,0 +1,105 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 18:50:20
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 18:50:20
+ * @FilePath: follow.go
+ * @Description: 远端文件跟踪（远端运行tail -F，由其处理轮转及截断）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package remote
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/duanztop/gossh/internal"
+	"github.com/duanztop/gossh/internal/tools"
+	"golang.org/x/crypto/ssh"
+)
+
+// errFollowExited 远端tail在跟踪结束前退出
+var errFollowExited = errors.New("远端tail已退出")
+
+// Follow 跟踪远端文件新增的行（远端运行tail -F，文件被轮转或截断时从新文件开头继续读取，文件不存在时等待其被创建）；
+// 阻塞直到ctx取消
+//
+//	@author duanzt
+//	@date 2026-10-19 18:51:05
+//	@receiver c *connection
+//	@param ctx context.Context 上下文（取消时停止跟踪）
+//	@param name string 远端文件路径
+//	@param handler internal.FollowFunc 处理跟踪到的行
+//	@param opts ...internal.FollowOption 跟踪配置
+//	@return error ctx取消时返回nil，tail异常退出时返回
+func (c *connection) Follow(ctx context.Context, name string, handler internal.FollowFunc, opts ...internal.FollowOption) error {
+	o := internal.NewFollowOptions(opts...)
+	lines := "0"
+	if o.FromStart {
+		lines = "+1"
+	}
+	sess, err := c.client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer sess.Close()
+	stdout, err := sess.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	sess.Stderr = &stderr
+	if err := sess.Start("tail -n " + lines + " -F -- " + tools.ShellTools.Quote(name)); err != nil {
+		return err
+	}
+
+	// ctx取消时结束远端tail（不支持signal的服务端在会话关闭后结束）
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = sess.Signal(ssh.SIGTERM)
+			_ = sess.Close()
+		case <-done:
+		}
+	}()
+
+	host := c.GetAddr()
+	reader := bufio.NewReader(stdout)
+	for {
+		text, err := reader.ReadString('\n')
+		if text != "" && (err == nil || ctx.Err() == nil) {
+			text = strings.TrimSuffix(strings.TrimSuffix(text, "\n"), "\r")
+			handler(&internal.FollowLine{Host: host, Path: name, Text: text, Time: time.Now()})
+		}
+		if err == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != io.EOF {
+			return err
+		}
+		// tail异常退出（例如命令不存在）
+		waitErr := sess.Wait()
+		if ctx.Err() != nil {
+			return nil
+		}
+		if waitErr == nil {
+			waitErr = errFollowExited
+		}
+		if output := strings.TrimSpace(stderr.String()); output != "" {
+			return fmt.Errorf("tail: %w, %s", waitErr, output)
+		}
+		return fmt.Errorf("tail: %w", waitErr)
+	}
+}
@@ -0,0 +1,335 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 16:45:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 19:40:00
+ * @FilePath: scp.go
+ * @Description: scp协议（通过exec会话运行远端scp，-t为接收端，-f为发送端）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package remote
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/duanztop/gossh/internal/tools"
+	"golang.org/x/crypto/ssh"
+)
+
+// errScpName scp记录中的文件名不合法（包含路径分隔符或为.、..，防止写入目标目录之外）
+var errScpName = errors.New("scp记录中的文件名不合法")
+
+// scpError 对端返回的异常（fatal为false时为警告，对端会继续传输后续文件）
+type scpError struct {
+	message string
+	fatal   bool
+}
+
+// Error 实现error接口
+func (e *scpError) Error() string {
+	return "scp: " + strings.TrimPrefix(e.message, "scp: ")
+}
+
+// Is 远端文件不存在时与fs.ErrNotExist匹配
+func (e *scpError) Is(target error) bool {
+	return target == fs.ErrNotExist && strings.Contains(e.message, "No such file or directory")
+}
+
+// isScpWarning 判断是否为对端返回的警告（对端会继续传输后续文件）
+//
+//	@author duanzt
+//	@date 2026-10-19 16:51:40
+//	@param err error 异常
+//	@return bool 为警告时返回true
+func isScpWarning(err error) bool {
+	var scpErr *scpError
+	return errors.As(err, &scpErr) && !scpErr.fatal
+}
+
+// scpConn 一次scp会话
+type scpConn struct {
+	sess   *ssh.Session
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	stderr bytes.Buffer
+}
+
+// startScp 在远端运行scp命令
+//
+//	@author duanzt
+//	@date 2026-10-19 16:46:02
+//	@receiver c *connection
+//	@param command string 远端命令（包含scp -t或scp -f）
+//	@return *scpConn scp会话
+//	@return error 创建会话异常时返回
+func (c *connection) startScp(command string) (*scpConn, error) {
+	sess, err := c.client.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	s := &scpConn{sess: sess}
+	if s.stdin, err = sess.StdinPipe(); err != nil {
+		sess.Close()
+		return nil, err
+	}
+	stdout, err := sess.StdoutPipe()
+	if err != nil {
+		sess.Close()
+		return nil, err
+	}
+	s.stdout = bufio.NewReader(stdout)
+	sess.Stderr = &s.stderr
+	if err := sess.Start(command); err != nil {
+		sess.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// ack 读取对端的应答（0成功，1警告，2致命异常，后两者附带一行异常信息）
+//
+//	@author duanzt
+//	@date 2026-10-19 16:47:15
+//	@receiver s *scpConn
+//	@return error 对端返回异常或连接断开时返回
+func (s *scpConn) ack() error {
+	b, err := s.stdout.ReadByte()
+	if err != nil {
+		return s.broken(err)
+	}
+	switch b {
+	case 0:
+		return nil
+	case 1, 2:
+		message, _ := s.stdout.ReadString('\n')
+		return &scpError{message: strings.TrimSpace(message), fatal: b == 2}
+	default:
+		// 非协议内容（例如远端shell启动时的输出）
+		line, _ := s.stdout.ReadString('\n')
+		return fmt.Errorf("scp协议异常: %q", string(b)+line)
+	}
+}
+
+// send 发送一条记录并读取应答
+//
+//	@author duanzt
+//	@date 2026-10-19 16:48:02
+//	@receiver s *scpConn
+//	@param record string 记录（不含换行）
+//	@return error 发送异常或对端返回异常时返回
+func (s *scpConn) send(record string) error {
+	if _, err := io.WriteString(s.stdin, record+"\n"); err != nil {
+		return s.broken(err)
+	}
+	return s.ack()
+}
+
+// ok 发送成功应答
+//
+//	@author duanzt
+//	@date 2026-10-19 16:48:40
+//	@receiver s *scpConn
+//	@return error 发送异常时返回
+func (s *scpConn) ok() error {
+	if _, err := s.stdin.Write([]byte{0}); err != nil {
+		return s.broken(err)
+	}
+	return nil
+}
+
+// next 读取对端发送的下一条记录（T、C、D、E）
+//
+//	@author duanzt
+//	@date 2026-10-19 16:49:12
+//	@receiver s *scpConn
+//	@return string 记录（不含换行）
+//	@return error 对端返回异常时返回*scpError，传输结束时返回io.EOF
+func (s *scpConn) next() (string, error) {
+	b, err := s.stdout.ReadByte()
+	if err == io.EOF {
+		return "", io.EOF
+	}
+	if err != nil {
+		return "", s.broken(err)
+	}
+	line, err := s.stdout.ReadString('\n')
+	if err != nil {
+		return "", s.broken(err)
+	}
+	line = strings.TrimSuffix(line, "\n")
+	if b == 1 || b == 2 {
+		return "", &scpError{message: line, fatal: b == 2}
+	}
+	return string(b) + line, nil
+}
+
+// nextEntry 读取对端发送的下一条C、D或E记录（之前的T记录解析为修改时间）
+//
+//	@author duanzt
+//	@date 2026-10-19 16:49:40
+//	@receiver s *scpConn
+//	@return string 记录（不含换行）
+//	@return time.Time T记录中的修改时间（没有T记录时为零值）
+//	@return error 对端返回异常时返回*scpError，传输结束时返回io.EOF
+func (s *scpConn) nextEntry() (string, time.Time, error) {
+	var modTime time.Time
+	for {
+		record, err := s.next()
+		if err != nil {
+			return "", modTime, err
+		}
+		if record[0] != 'T' {
+			return record, modTime, nil
+		}
+		if modTime, err = parseScpTime(record); err != nil {
+			return "", modTime, err
+		}
+		if err := s.ok(); err != nil {
+			return "", modTime, err
+		}
+	}
+}
+
+// close 结束传输并等待远端scp退出
+//
+//	@author duanzt
+//	@date 2026-10-19 16:50:05
+//	@receiver s *scpConn
+//	@return error 远端scp异常退出时返回
+func (s *scpConn) close() error {
+	defer s.sess.Close()
+	_ = s.stdin.Close()
+	if err := s.sess.Wait(); err != nil {
+		return s.broken(err)
+	}
+	return nil
+}
+
+// abort 中断传输（协议状态无法恢复时使用）
+//
+//	@author duanzt
+//	@date 2026-10-19 16:50:40
+//	@receiver s *scpConn
+func (s *scpConn) abort() {
+	_ = s.sess.Close()
+}
+
+// broken 为连接异常附加远端scp的错误输出（例如scp命令不存在）
+//
+//	@author duanzt
+//	@date 2026-10-19 16:51:12
+//	@receiver s *scpConn
+//	@param err error 连接异常
+//	@return error 附加错误输出后的异常
+func (s *scpConn) broken(err error) error {
+	if stderr := strings.TrimSpace(s.stderr.String()); stderr != "" {
+		return fmt.Errorf("scp: %w, %s", err, stderr)
+	}
+	return fmt.Errorf("scp: %w", err)
+}
+
+// scpTimeRecord 生成时间记录
+//
+//	@author duanzt
+//	@date 2026-10-19 16:52:02
+//	@param modTime time.Time 修改时间（访问时间使用相同值）
+//	@return string T记录
+func scpTimeRecord(modTime time.Time) string {
+	return fmt.Sprintf("T%d 0 %d 0", modTime.Unix(), modTime.Unix())
+}
+
+// scpEntryRecord 生成文件（C）或目录（D）记录
+//
+//	@author duanzt
+//	@date 2026-10-19 16:52:40
+//	@param kind byte 'C'或'D'
+//	@param mode fs.FileMode 权限
+//	@param size int64 文件大小（目录为0）
+//	@param name string 文件名
+//	@return string 记录
+func scpEntryRecord(kind byte, mode fs.FileMode, size int64, name string) string {
+	return fmt.Sprintf("%c%04o %d %s", kind, tools.ModeTools.ToUnix(mode), size, name)
+}
+
+// parseScpTime 解析时间记录（T<mtime> <usec> <atime> <usec>）
+//
+//	@author duanzt
+//	@date 2026-10-19 16:53:20
+//	@param record string T记录
+//	@return time.Time 修改时间
+//	@return error 格式不合法时返回
+func parseScpTime(record string) (time.Time, error) {
+	fields := strings.Fields(record[1:])
+	if len(fields) != 4 {
+		return time.Time{}, fmt.Errorf("scp时间记录不合法: %q", record)
+	}
+	sec, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("scp时间记录不合法: %q", record)
+	}
+	return time.Unix(sec, 0), nil
+}
+
+// parseScpEntry 解析文件（C）或目录（D）记录（C<mode> <size> <name>）
+//
+//	@author duanzt
+//	@date 2026-10-19 16:54:05
+//	@param record string 记录
+//	@return fs.FileMode 权限
+//	@return int64 文件大小
+//	@return string 文件名（已校验不含路径分隔符）
+//	@return error 格式不合法时返回
+func parseScpEntry(record string) (fs.FileMode, int64, string, error) {
+	fields := strings.SplitN(record[1:], " ", 3)
+	if len(fields) != 3 {
+		return 0, 0, "", fmt.Errorf("scp记录不合法: %q", record)
+	}
+	mode, err := strconv.ParseUint(fields[0], 8, 32)
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("scp记录不合法: %q", record)
+	}
+	size, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil || size < 0 {
+		return 0, 0, "", fmt.Errorf("scp记录不合法: %q", record)
+	}
+	name := fields[2]
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, "/\x00") {
+		return 0, 0, "", fmt.Errorf("%w: %q", errScpName, name)
+	}
+	return tools.ModeTools.FromUnix(uint32(mode)), size, name, nil
+}
+
+// scpFileInfo 通过scp记录、stat或find命令获取的文件信息
+type scpFileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (i *scpFileInfo) Name() string       { return i.name }
+func (i *scpFileInfo) Size() int64        { return i.size }
+func (i *scpFileInfo) Mode() fs.FileMode  { return i.mode }
+func (i *scpFileInfo) ModTime() time.Time { return i.modTime }
+func (i *scpFileInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i *scpFileInfo) Sys() interface{}   { return nil }
+
+// scpReader 文件内容（提供Size方法，用于进度报告获取总大小）
+type scpReader struct {
+	io.Reader
+	size int64
+}
+
+// Size 获取文件大小
+func (r *scpReader) Size() int64 {
+	return r.size
+}
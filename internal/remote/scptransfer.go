@@ -0,0 +1,911 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 16:58:20
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-20 00:01:00
+ * @FilePath: scptransfer.go
+ * @Description: 基于scp协议的文件及目录传输（远端未开启sftp子系统时使用）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package remote
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/duanztop/gossh/internal"
+	"github.com/duanztop/gossh/internal/tools"
+	"github.com/duanztop/gossh/internal/transfer"
+)
+
+// errScpDirUnsupported scp上传目录时不支持的配置
+var errScpDirUnsupported = errors.New("scp上传目录时不支持Owner、Verify及Atomic配置")
+
+// useScp 判断是否使用scp传输（默认优先sftp，仅服务端拒绝sftp子系统时使用scp，连接断开等其他异常仍走sftp并返回原异常）
+//
+//	@author duanzt
+//	@date 2026-10-19 16:59:05
+//	@receiver c *connection
+//	@param o *internal.CopyOptions 拷贝配置
+//	@return bool 使用scp时返回true
+func (c *connection) useScp(o *internal.CopyOptions) bool {
+	switch o.Protocol {
+	case internal.ProtocolSCP:
+		return true
+	case internal.ProtocolSFTP:
+		return false
+	}
+	_, err := c.getSftpClient()
+	return errors.Is(err, errSftpUnsupported)
+}
+
+// scpUpload 通过scp将流写入远端文件
+//
+//	@author duanzt
+//	@date 2026-10-19 17:00:12
+//	@receiver c *connection
+//	@param src io.Reader 流
+//	@param dest string 远端目标文件地址
+//	@param mode string 文件权限（八进制或符号格式）
+//	@param o *internal.CopyOptions 拷贝配置
+//	@return error 拷贝异常时返回
+func (c *connection) scpUpload(src io.Reader, dest, mode string, o *internal.CopyOptions) (err error) {
+	tracker := transfer.NewTracker(dest, o)
+	defer func() {
+		tracker.Finish(err)
+	}()
+	srcInfo := tools.FileTools.StatReader(src)
+	perm, err := tools.ModeTools.Resolve(mode, srcInfo, o.PreserveMode, func() (fs.FileInfo, error) {
+		return c.statByCommand(dest)
+	})
+	if err != nil {
+		return err
+	}
+	size, ok := transfer.StreamSize(src)
+	if !ok {
+		// scp需要预先发送文件大小，大小未知的流先写入本地临时文件
+		spool, err := os.CreateTemp("", "gossh-scp-*")
+		if err != nil {
+			return err
+		}
+		defer func() {
+			spool.Close()
+			os.Remove(spool.Name())
+		}()
+		if size, err = io.Copy(spool, src); err != nil {
+			return err
+		}
+		if _, err := spool.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		src = spool
+	}
+	var modTime time.Time
+	if o.PreserveTimes && srcInfo != nil {
+		modTime = srcInfo.ModTime()
+	}
+
+	// 原子写入时先写入同目录下的临时文件
+	target := dest
+	if o.Atomic {
+		target = path.Join(path.Dir(dest), transfer.TempName(path.Base(dest), o))
+		defer func() {
+			if err != nil {
+				_, _ = c.ExecShell(context.Background(), "rm -f -- "+tools.ShellTools.Quote(target))
+			}
+		}()
+	}
+	var h hash.Hash
+	if o.Verify != "" {
+		if h, err = o.Verify.New(); err != nil {
+			return err
+		}
+	}
+	// umask置为0，保证新文件的权限与C记录一致（-p时已有文件也会修改权限）
+	s, err := c.startScp("mkdir -p -- " + tools.ShellTools.Quote(path.Dir(target)) + " && umask 000 && scp -p -t -- " + tools.ShellTools.Quote(target))
+	if err != nil {
+		return err
+	}
+	if err := s.ack(); err != nil {
+		s.abort()
+		return err
+	}
+	if err := scpSendFile(s, src, path.Base(target), perm, size, modTime, o, tracker, h); err != nil {
+		s.abort()
+		return err
+	}
+	if err := s.close(); err != nil {
+		return err
+	}
+
+	if h != nil {
+		if err := transfer.Verify(target, o.Verify, hex.EncodeToString(h.Sum(nil)), func() (string, error) {
+			return c.sumByCommand(target, o.Verify)
+		}); err != nil {
+			return err
+		}
+	}
+	if err := c.chownByCommand(target, o); err != nil {
+		return err
+	}
+	if !o.Atomic {
+		return nil
+	}
+	return c.renameByCommand(target, dest, o.Backup)
+}
+
+// scpSendFile 发送一个文件（T、C记录及内容）
+//
+//	@author duanzt
+//	@date 2026-10-19 17:02:30
+//	@param s *scpConn scp会话
+//	@param src io.Reader 文件内容
+//	@param name string 文件名
+//	@param perm fs.FileMode 文件权限
+//	@param size int64 文件大小
+//	@param modTime time.Time 修改时间（为零值时不发送T记录）
+//	@param o *internal.CopyOptions 拷贝配置
+//	@param tracker *Tracker 进度跟踪（为nil时不跟踪）
+//	@param h hash.Hash 计算发送内容的摘要（为nil时不计算）
+//	@return error 发送异常时返回（对端返回警告时为非致命的*scpError，其余情况协议已无法继续）
+func scpSendFile(s *scpConn, src io.Reader, name string, perm fs.FileMode, size int64, modTime time.Time, o *internal.CopyOptions, tracker *transfer.Tracker, h hash.Hash) error {
+	if !modTime.IsZero() {
+		if err := s.send(scpTimeRecord(modTime)); err != nil {
+			return err
+		}
+	}
+	if err := s.send(scpEntryRecord('C', perm, size, name)); err != nil {
+		return err
+	}
+	tracker.Start(0, size)
+	r := io.LimitReader(src, size)
+	if h != nil {
+		r = io.TeeReader(r, h)
+	}
+	n, err := io.Copy(tracker.Writer(transfer.LimitWriter(o.Ctx(), s.stdin, o.Limiters)), r)
+	if err != nil {
+		return err
+	}
+	if n < size {
+		// 源在传输期间变小，已无法补齐声明的大小
+		return io.ErrUnexpectedEOF
+	}
+	if err := s.ok(); err != nil {
+		return err
+	}
+	return s.ack()
+}
+
+// scpDownload 通过scp将远端文件写入本地文件
+//
+//	@author duanzt
+//	@date 2026-10-19 17:04:10
+//	@receiver c *connection
+//	@param src string 远端文件地址
+//	@param dest string 本地目标文件地址
+//	@param mode string 文件权限（八进制或符号格式）
+//	@param o *internal.CopyOptions 拷贝配置
+//	@return error 拷贝异常时返回
+func (c *connection) scpDownload(src, dest, mode string, o *internal.CopyOptions) (err error) {
+	tracker := transfer.NewTracker(dest, o)
+	defer func() {
+		tracker.Finish(err)
+	}()
+	s, err := c.startScp("scp " + scpFlags("-f", o) + " -- " + tools.ShellTools.Quote(src))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			s.abort()
+		}
+	}()
+	if err := s.ok(); err != nil {
+		return err
+	}
+	record, modTime, err := s.nextEntry()
+	if err == io.EOF {
+		return s.broken(io.ErrUnexpectedEOF)
+	}
+	if err != nil {
+		return err
+	}
+	if record[0] != 'C' {
+		return fmt.Errorf("scp协议异常: %q", record)
+	}
+	srcMode, size, name, err := parseScpEntry(record)
+	if err != nil {
+		return err
+	}
+	srcInfo := &scpFileInfo{name: name, size: size, mode: srcMode, modTime: modTime}
+	perm, err := tools.ModeTools.Resolve(mode, srcInfo, o.PreserveMode, func() (fs.FileInfo, error) {
+		return os.Stat(dest)
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.ok(); err != nil {
+		return err
+	}
+	if err := transfer.WriteLocal(&scpReader{Reader: io.LimitReader(s.stdout, size), size: size}, dest, perm, o, tracker); err != nil {
+		return err
+	}
+	if err := s.ack(); err != nil {
+		return err
+	}
+	if err := s.ok(); err != nil {
+		return err
+	}
+	if err := s.close(); err != nil {
+		return err
+	}
+	if o.PreserveTimes && !modTime.IsZero() {
+		return os.Chtimes(dest, modTime, modTime)
+	}
+	return nil
+}
+
+// scpCopyDirLTR 通过scp递归上传本地目录（scp -r -t）
+//
+//	@author duanzt
+//	@date 2026-10-19 17:06:20
+//	@receiver c *connection
+//	@param src string 本地目录（为文件时仅拷贝该文件）
+//	@param dest string 远端目标目录
+//	@param o *internal.CopyOptions 拷贝配置
+//	@return *internal.CopySummary 拷贝结果汇总
+//	@return error 拷贝异常时返回（未开启FailFast时汇总全部失败文件）
+func (c *connection) scpCopyDirLTR(src, dest string, o *internal.CopyOptions) (*internal.CopySummary, error) {
+	summary := &internal.CopySummary{}
+	info, err := os.Stat(src)
+	if err != nil {
+		return summary, err
+	}
+	if !info.IsDir() {
+		// 与scp一致，新文件沿用源文件权限
+		file, err := os.Open(src)
+		if err == nil {
+			defer file.Close()
+			err = c.scpUpload(file, dest, fmt.Sprintf("%04o", info.Mode().Perm()), o)
+		}
+		if err != nil {
+			summary.Errors = append(summary.Errors, &internal.CopyError{Path: src, Err: err})
+			return summary, summary.Err()
+		}
+		summary.Files++
+		summary.Bytes += info.Size()
+		return summary, nil
+	}
+	if o.Owner != "" || o.Group != "" || o.Verify != "" || o.Atomic {
+		return summary, errScpDirUnsupported
+	}
+
+	// 以目标目录的上级目录为接收目录，目标目录本身作为第一条D记录发送（同时设置其权限及修改时间）
+	dest = path.Clean(dest)
+	root := path.Base(dest)
+	receiveDir := path.Dir(dest)
+	if root == "/" || root == "." || root == ".." {
+		root, receiveDir = "", dest
+	}
+	if _, err := c.statByCommand(dest); errors.Is(err, fs.ErrNotExist) {
+		summary.Dirs++
+	} else if err != nil {
+		return summary, err
+	}
+	// umask置为0，新建的文件及目录权限与记录一致；-p时已有的文件及目录也会修改权限，因此仅在保留源权限时使用
+	flags := "-r -t"
+	if o.PreserveMode {
+		flags = "-r -p -t"
+	}
+	s, err := c.startScp("mkdir -p -- " + tools.ShellTools.Quote(receiveDir) + " && umask 000 && scp " + flags + " -- " + tools.ShellTools.Quote(receiveDir))
+	if err != nil {
+		return summary, err
+	}
+	if err := s.ack(); err != nil {
+		s.abort()
+		return summary, err
+	}
+	sender := &scpDirSender{s: s, o: o, summary: summary}
+	if root != "" {
+		sender.dirs = []*scpDirEntry{{name: root, remote: dest, info: info}}
+	} else {
+		sender.dirs = []*scpDirEntry{{remote: dest, sent: true}}
+	}
+	realSrc, err := filepath.EvalSymlinks(src)
+	if err != nil {
+		s.abort()
+		return summary, err
+	}
+	if err := sender.sendDir(src, "", []string{realSrc}); err != nil {
+		s.abort()
+		return summary, err
+	}
+	if err := sender.leaveDir(); err != nil {
+		s.abort()
+		return summary, err
+	}
+	if err := s.close(); err != nil {
+		return summary, err
+	}
+	return summary, summary.Err()
+}
+
+// scpDirEntry scp上传目录时已进入的目录
+type scpDirEntry struct {
+	name   string      // D记录中的目录名
+	remote string      // 远端目录路径
+	info   fs.FileInfo // 本地目录信息
+	sent   bool        // D记录是否已发送
+}
+
+// scpDirSender scp上传目录的上下文
+// D记录在目录下有文件时才发送（配置了include时避免产生大量空目录），离开目录时发送E记录
+type scpDirSender struct {
+	s       *scpConn
+	o       *internal.CopyOptions
+	summary *internal.CopySummary
+	dirs    []*scpDirEntry // 已进入的目录（栈）
+}
+
+// sendDir 发送目录下的全部文件及子目录（当前目录已在dirs栈顶）
+//
+//	@author duanzt
+//	@date 2026-10-19 17:08:05
+//	@receiver w *scpDirSender
+//	@param src string 本地目录
+//	@param rel string 相对于拷贝根目录的路径
+//	@param ancestors []string 已进入的本地目录的真实路径（用于发现符号链接循环）
+//	@return error 开启FailFast或协议无法继续时返回
+func (w *scpDirSender) sendDir(src, rel string, ancestors []string) error {
+	if len(w.o.Includes) == 0 {
+		if err := w.flush(); err != nil {
+			return err
+		}
+	}
+	children, err := os.ReadDir(src)
+	if err != nil {
+		return w.fail(src, err)
+	}
+	parent := w.dirs[len(w.dirs)-1]
+	for _, child := range children {
+		childSrc := filepath.Join(src, child.Name())
+		childRel := path.Join(rel, child.Name())
+		childRemote := path.Join(parent.remote, child.Name())
+		if tools.GlobTools.MatchAny(w.o.Excludes, childRel) {
+			w.summary.Skipped++
+			continue
+		}
+		childInfo, err := child.Info()
+		if err != nil {
+			if err := w.fail(childSrc, err); err != nil {
+				return err
+			}
+			continue
+		}
+		isLink := childInfo.Mode()&fs.ModeSymlink != 0
+		if isLink {
+			// scp无法表示符号链接，SymlinkPreserve按SymlinkSkip处理
+			if w.o.Symlink != internal.SymlinkFollow {
+				w.summary.Skipped++
+				continue
+			}
+			if childInfo, err = os.Stat(childSrc); err != nil {
+				if err := w.fail(childSrc, err); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		switch {
+		case childInfo.IsDir():
+			real, err := filepath.EvalSymlinks(childSrc)
+			if err == nil && isLink && (transfer.IsAncestor(ancestors, real) || len(ancestors) > transfer.MaxDirDepth) {
+				err = transfer.ErrSymlinkLoop
+			}
+			if err != nil {
+				if err := w.fail(childSrc, err); err != nil {
+					return err
+				}
+				continue
+			}
+			w.dirs = append(w.dirs, &scpDirEntry{name: child.Name(), remote: childRemote, info: childInfo})
+			childAncestors := append(append(make([]string, 0, len(ancestors)+1), ancestors...), real)
+			if err := w.sendDir(childSrc, childRel, childAncestors); err != nil {
+				return err
+			}
+			if err := w.leaveDir(); err != nil {
+				return err
+			}
+		case childInfo.Mode().IsRegular():
+			if len(w.o.Includes) > 0 && !tools.GlobTools.MatchAny(w.o.Includes, childRel) {
+				w.summary.Skipped++
+				continue
+			}
+			if err := w.sendFile(childSrc, childRemote, childInfo); err != nil {
+				return err
+			}
+		default:
+			// 设备文件、管道、socket等无法拷贝
+			w.summary.Skipped++
+		}
+	}
+	return nil
+}
+
+// sendFile 发送普通文件
+//
+//	@author duanzt
+//	@date 2026-10-19 17:09:30
+//	@receiver w *scpDirSender
+//	@param src string 本地文件
+//	@param remote string 远端文件路径
+//	@param info fs.FileInfo 本地文件信息
+//	@return error 开启FailFast或协议无法继续时返回
+func (w *scpDirSender) sendFile(src, remote string, info fs.FileInfo) (err error) {
+	file, err := os.Open(src)
+	if err != nil {
+		return w.fail(src, err)
+	}
+	defer file.Close()
+	if err := w.flush(); err != nil {
+		return err
+	}
+	tracker := transfer.NewTracker(remote, w.o)
+	var modTime time.Time
+	if w.o.PreserveTimes {
+		modTime = info.ModTime()
+	}
+	// 与scp一致，新文件沿用源文件权限
+	err = scpSendFile(w.s, file, path.Base(remote), info.Mode().Perm(), info.Size(), modTime, w.o, tracker, nil)
+	tracker.Finish(err)
+	if err != nil {
+		failErr := w.fail(src, err)
+		if !isScpWarning(err) {
+			// 协议已无法继续，无论是否开启FailFast均终止
+			return w.summary.Errors[len(w.summary.Errors)-1]
+		}
+		return failErr
+	}
+	w.summary.Files++
+	w.summary.Bytes += info.Size()
+	return nil
+}
+
+// flush 发送尚未发送的D记录
+//
+//	@author duanzt
+//	@date 2026-10-19 17:10:12
+//	@receiver w *scpDirSender
+//	@return error 协议无法继续时返回
+func (w *scpDirSender) flush() error {
+	for i, dir := range w.dirs {
+		if dir.sent {
+			continue
+		}
+		if w.o.PreserveTimes {
+			if err := w.s.send(scpTimeRecord(dir.info.ModTime())); err != nil {
+				return err
+			}
+		}
+		perm := transfer.DefaultDirPerm
+		if w.o.PreserveMode {
+			perm = dir.info.Mode().Perm()
+		}
+		if err := w.s.send(scpEntryRecord('D', perm, 0, dir.name)); err != nil {
+			return err
+		}
+		dir.sent = true
+		// 根目录是否新建已单独统计，scp无法区分子目录是否已存在，按发送的D记录计数
+		if i > 0 {
+			w.summary.Dirs++
+		}
+	}
+	return nil
+}
+
+// leaveDir 离开栈顶目录（已发送D记录时发送E记录）
+//
+//	@author duanzt
+//	@date 2026-10-19 17:10:50
+//	@receiver w *scpDirSender
+//	@return error 协议无法继续时返回
+func (w *scpDirSender) leaveDir() error {
+	dir := w.dirs[len(w.dirs)-1]
+	w.dirs = w.dirs[:len(w.dirs)-1]
+	if !dir.sent || dir.name == "" {
+		return nil
+	}
+	return w.s.send("E")
+}
+
+// fail 记录拷贝异常
+//
+//	@author duanzt
+//	@date 2026-10-19 17:11:25
+//	@receiver w *scpDirSender
+//	@param src string 源文件路径
+//	@param err error 异常信息
+//	@return error 开启FailFast时返回该异常，否则返回nil继续拷贝
+func (w *scpDirSender) fail(src string, err error) error {
+	copyErr := &internal.CopyError{Path: src, Err: err}
+	w.summary.Errors = append(w.summary.Errors, copyErr)
+	if w.o.FailFast {
+		return copyErr
+	}
+	return nil
+}
+
+// scpCopyDirRTL 通过scp递归下载远端目录（scp -r -f）
+//
+//	@author duanzt
+//	@date 2026-10-19 17:12:40
+//	@receiver c *connection
+//	@param src string 远端目录（为文件时仅拷贝该文件）
+//	@param dest string 本地目标目录
+//	@param o *internal.CopyOptions 拷贝配置
+//	@return *internal.CopySummary 拷贝结果汇总
+//	@return error 拷贝异常时返回（未开启FailFast时汇总全部失败文件）
+func (c *connection) scpCopyDirRTL(src, dest string, o *internal.CopyOptions) (*internal.CopySummary, error) {
+	summary := &internal.CopySummary{}
+	s, err := c.startScp("scp -r " + scpFlags("-f", o) + " -- " + tools.ShellTools.Quote(src))
+	if err != nil {
+		return summary, err
+	}
+	receiver := &scpDirReceiver{s: s, o: o, summary: summary, src: path.Clean(src), dest: dest, createdDirs: make(map[string]bool)}
+	if err := receiver.receive(); err != nil {
+		s.abort()
+		return summary, err
+	}
+	if err := s.close(); err != nil && len(summary.Errors) == 0 {
+		return summary, err
+	}
+	return summary, summary.Err()
+}
+
+// scpRecvDir scp下载目录时已进入的目录
+type scpRecvDir struct {
+	local   string      // 本地目录路径
+	remote  string      // 远端目录路径
+	rel     string      // 相对于拷贝根目录的路径
+	mode    fs.FileMode // 远端目录权限
+	modTime time.Time   // 远端目录修改时间（未保留修改时间时为零值）
+	skip    bool        // 目录被过滤，其下文件全部丢弃
+}
+
+// scpDirReceiver scp下载目录的上下文
+type scpDirReceiver struct {
+	s           *scpConn
+	o           *internal.CopyOptions
+	summary     *internal.CopySummary
+	src         string
+	dest        string
+	dirs        []*scpRecvDir // 已进入的目录（栈）
+	createdDirs map[string]bool
+}
+
+// receive 接收并处理远端发送的全部记录
+//
+//	@author duanzt
+//	@date 2026-10-19 17:14:02
+//	@receiver r *scpDirReceiver
+//	@return error 开启FailFast或协议无法继续时返回
+func (r *scpDirReceiver) receive() error {
+	if err := r.s.ok(); err != nil {
+		return err
+	}
+	for {
+		record, modTime, err := r.s.nextEntry()
+		if err == io.EOF {
+			return nil
+		}
+		if isScpWarning(err) {
+			// 远端无法读取某个文件时发送警告并继续发送其余文件
+			if err := r.fail(r.src, err); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		switch record[0] {
+		case 'D':
+			err = r.enterDir(record, modTime)
+		case 'E':
+			err = r.leaveDir()
+		case 'C':
+			err = r.receiveFile(record, modTime)
+		default:
+			err = fmt.Errorf("scp协议异常: %q", record)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// enterDir 处理D记录
+//
+//	@author duanzt
+//	@date 2026-10-19 17:15:10
+//	@receiver r *scpDirReceiver
+//	@param record string D记录
+//	@param modTime time.Time 修改时间
+//	@return error 协议无法继续时返回
+func (r *scpDirReceiver) enterDir(record string, modTime time.Time) error {
+	mode, _, name, err := parseScpEntry(record)
+	if err != nil {
+		return err
+	}
+	dir := &scpRecvDir{local: r.dest, remote: r.src, mode: mode, modTime: modTime}
+	if len(r.dirs) > 0 {
+		parent := r.dirs[len(r.dirs)-1]
+		dir.local = filepath.Join(parent.local, name)
+		dir.remote = path.Join(parent.remote, name)
+		dir.rel = path.Join(parent.rel, name)
+		dir.skip = parent.skip
+		if !dir.skip && tools.GlobTools.MatchAny(r.o.Excludes, dir.rel) {
+			dir.skip = true
+			r.summary.Skipped++
+		}
+	}
+	r.dirs = append(r.dirs, dir)
+	// 配置了include时目录按需创建，避免产生大量空目录
+	if !dir.skip && len(r.o.Includes) == 0 {
+		if err := r.ensureDir(dir.local); err != nil {
+			if err := r.fail(dir.remote, err); err != nil {
+				return err
+			}
+		}
+	}
+	return r.s.ok()
+}
+
+// leaveDir 处理E记录（按配置设置目录权限及修改时间）
+//
+//	@author duanzt
+//	@date 2026-10-19 17:16:02
+//	@receiver r *scpDirReceiver
+//	@return error 开启FailFast或协议无法继续时返回
+func (r *scpDirReceiver) leaveDir() error {
+	if len(r.dirs) == 0 {
+		return errors.New("scp协议异常: 多余的E记录")
+	}
+	dir := r.dirs[len(r.dirs)-1]
+	r.dirs = r.dirs[:len(r.dirs)-1]
+	if !dir.skip && r.createdDirs[dir.local] {
+		if r.o.PreserveMode {
+			if err := os.Chmod(dir.local, dir.mode.Perm()); err != nil {
+				if err := r.fail(dir.remote, err); err != nil {
+					return err
+				}
+			}
+		}
+		if r.o.PreserveTimes && !dir.modTime.IsZero() {
+			if err := os.Chtimes(dir.local, dir.modTime, dir.modTime); err != nil {
+				if err := r.fail(dir.remote, err); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return r.s.ok()
+}
+
+// receiveFile 处理C记录（被过滤或写入失败时丢弃内容，保持协议同步）
+//
+//	@author duanzt
+//	@date 2026-10-19 17:17:20
+//	@receiver r *scpDirReceiver
+//	@param record string C记录
+//	@param modTime time.Time 修改时间
+//	@return error 开启FailFast或协议无法继续时返回
+func (r *scpDirReceiver) receiveFile(record string, modTime time.Time) (err error) {
+	mode, size, name, err := parseScpEntry(record)
+	if err != nil {
+		return err
+	}
+	// 源为文件时目标即为文件路径
+	local, remote, rel := r.dest, r.src, name
+	skip := false
+	if len(r.dirs) > 0 {
+		parent := r.dirs[len(r.dirs)-1]
+		local = filepath.Join(parent.local, name)
+		remote = path.Join(parent.remote, name)
+		rel = path.Join(parent.rel, name)
+		skip = parent.skip || tools.GlobTools.MatchAny(r.o.Excludes, rel) ||
+			(len(r.o.Includes) > 0 && !tools.GlobTools.MatchAny(r.o.Includes, rel))
+		if skip && !parent.skip {
+			r.summary.Skipped++
+		}
+	}
+	if err := r.s.ok(); err != nil {
+		return err
+	}
+	content := io.LimitReader(r.s.stdout, size)
+
+	var writeErr error
+	if !skip {
+		tracker := transfer.NewTracker(local, r.o)
+		writeErr = r.ensureDir(filepath.Dir(local))
+		if writeErr == nil {
+			// 与scp一致，新文件沿用源文件权限
+			writeErr = transfer.WriteLocal(&scpReader{Reader: content, size: size}, local, mode.Perm(), r.o, tracker)
+		}
+		tracker.Finish(writeErr)
+	}
+	if _, err := io.Copy(io.Discard, content); err != nil {
+		return r.s.broken(err)
+	}
+	if err := r.s.ack(); err != nil {
+		return err
+	}
+	if err := r.s.ok(); err != nil {
+		return err
+	}
+	if skip {
+		return nil
+	}
+	if writeErr == nil && r.o.PreserveTimes && !modTime.IsZero() {
+		writeErr = os.Chtimes(local, modTime, modTime)
+	}
+	if writeErr != nil {
+		return r.fail(remote, writeErr)
+	}
+	r.summary.Files++
+	r.summary.Bytes += size
+	return nil
+}
+
+// ensureDir 创建本地目录（已创建过的目录不再重复创建）
+//
+//	@author duanzt
+//	@date 2026-10-19 17:18:30
+//	@receiver r *scpDirReceiver
+//	@param dir string 本地目录
+//	@return error 创建异常时返回
+func (r *scpDirReceiver) ensureDir(dir string) error {
+	if r.createdDirs[dir] {
+		return nil
+	}
+	if _, err := os.Stat(dir); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		if err := os.MkdirAll(dir, transfer.DefaultDirPerm); err != nil {
+			return err
+		}
+		r.summary.Dirs++
+	}
+	r.createdDirs[dir] = true
+	return nil
+}
+
+// fail 记录拷贝异常
+//
+//	@author duanzt
+//	@date 2026-10-19 17:19:02
+//	@receiver r *scpDirReceiver
+//	@param src string 远端文件路径
+//	@param err error 异常信息
+//	@return error 开启FailFast时返回该异常，否则返回nil继续拷贝
+func (r *scpDirReceiver) fail(src string, err error) error {
+	copyErr := &internal.CopyError{Path: src, Err: err}
+	r.summary.Errors = append(r.summary.Errors, copyErr)
+	if r.o.FailFast {
+		return copyErr
+	}
+	return nil
+}
+
+// scpFlags 生成远端scp的参数（保留修改时间时增加-p）
+//
+//	@author duanzt
+//	@date 2026-10-19 17:20:10
+//	@param mode string -t或-f
+//	@param o *internal.CopyOptions 拷贝配置
+//	@return string 参数
+func scpFlags(mode string, o *internal.CopyOptions) string {
+	if o.PreserveTimes {
+		return "-p " + mode
+	}
+	return mode
+}
+
+// statByCommand 通过stat命令获取远端文件信息（跟随符号链接）
+//
+//	@author duanzt
+//	@date 2026-10-19 17:21:05
+//	@receiver c *connection
+//	@param name string 远端文件路径
+//	@return fs.FileInfo 文件信息
+//	@return error 文件不存在时返回fs.ErrNotExist
+func (c *connection) statByCommand(name string) (fs.FileInfo, error) {
+	quoted := tools.ShellTools.Quote(name)
+	output, err := c.ExecShell(context.Background(), "if [ -e "+quoted+" ]; then stat -L -c '%f %s %Y' -- "+quoted+"; else echo missing; fi")
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fmt.Errorf("%w, %s", err, strings.TrimSpace(output))}
+	}
+	fields := strings.Fields(output)
+	if len(fields) == 1 && fields[0] == "missing" {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	if len(fields) != 3 {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fmt.Errorf("stat输出不合法: %s", output)}
+	}
+	raw, err1 := strconv.ParseUint(fields[0], 16, 32)
+	size, err2 := strconv.ParseInt(fields[1], 10, 64)
+	mtime, err3 := strconv.ParseInt(fields[2], 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fmt.Errorf("stat输出不合法: %s", output)}
+	}
+	mode := tools.ModeTools.FromUnix(uint32(raw) & 07777)
+	switch raw & 0170000 {
+	case 0040000:
+		mode |= fs.ModeDir
+	case 0100000:
+	default:
+		mode |= fs.ModeIrregular
+	}
+	return &scpFileInfo{name: path.Base(name), size: size, mode: mode, modTime: time.Unix(mtime, 0)}, nil
+}
+
+// chownByCommand 根据拷贝配置通过chown命令修改远端文件的所属用户及用户组
+//
+//	@author duanzt
+//	@date 2026-10-19 17:22:10
+//	@receiver c *connection
+//	@param name string 远端文件路径
+//	@param o *internal.CopyOptions 拷贝配置
+//	@return error 修改异常时返回
+func (c *connection) chownByCommand(name string, o *internal.CopyOptions) error {
+	if o.Owner == "" && o.Group == "" {
+		return nil
+	}
+	spec := o.Owner
+	if o.Group != "" {
+		spec += ":" + o.Group
+	}
+	output, err := c.ExecShell(context.Background(), "chown "+tools.ShellTools.Quote(spec)+" -- "+tools.ShellTools.Quote(name))
+	if err != nil {
+		return &fs.PathError{Op: "chown", Path: name, Err: fmt.Errorf("%w, %s", err, strings.TrimSpace(output))}
+	}
+	return nil
+}
+
+// renameByCommand 通过mv命令将临时文件重命名为目标文件（按配置先备份原目标文件，优先使用硬链接）
+//
+//	@author duanzt
+//	@date 2026-10-19 17:23:02
+//	@receiver c *connection
+//	@param target string 临时文件路径
+//	@param dest string 目标文件路径
+//	@param backup string 备份文件后缀（为空表示不备份）
+//	@return error 重命名异常时返回
+func (c *connection) renameByCommand(target, dest, backup string) error {
+	quotedDest := tools.ShellTools.Quote(dest)
+	command := "mv -f -- " + tools.ShellTools.Quote(target) + " " + quotedDest
+	if backup != "" {
+		quotedBackup := tools.ShellTools.Quote(dest + backup)
+		command = "if [ -e " + quotedDest + " ]; then rm -f -- " + quotedBackup + " && { ln -- " + quotedDest + " " + quotedBackup +
+			" 2>/dev/null || mv -f -- " + quotedDest + " " + quotedBackup + "; }; fi && " + command
+	}
+	output, err := c.ExecShell(context.Background(), command)
+	if err != nil {
+		return &fs.PathError{Op: "rename", Path: target, Err: fmt.Errorf("%w, %s", err, strings.TrimSpace(output))}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 22:54:20
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:56:30
+ * @FilePath: shell.go
+ * @Description: 远端交互式shell及网络转发（direct-tcpip、tcpip-forward）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package remote
+
+import (
+	"context"
+	"io"
+	"net"
+
+	"github.com/duanztop/gossh/internal"
+	"golang.org/x/crypto/ssh"
+)
+
+// Shell 在远端启动交互式shell（或执行命令），ctx取消时关闭session
+//
+//	@author duanzt
+//	@date 2026-10-19 22:54:50
+//	@receiver c *connection
+//	@param ctx context.Context 上下文（取消时结束shell）
+//	@param stdin io.Reader 输入
+//	@param stdout io.Writer 输出
+//	@param stderr io.Writer 错误输出
+//	@param opts ...internal.ShellOption 配置
+//	@return error 无法启动、退出码不为0（*ssh.ExitError）或ctx取消时返回
+func (c *connection) Shell(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, opts ...internal.ShellOption) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	o := internal.NewShellOptions(opts...)
+	sess, err := c.client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer sess.Close()
+	sess.Stdin, sess.Stdout, sess.Stderr = stdin, stdout, stderr
+	if o.Pty {
+		modes := ssh.TerminalModes{ssh.ECHO: 1, ssh.TTY_OP_ISPEED: 14400, ssh.TTY_OP_OSPEED: 14400}
+		if err := sess.RequestPty(o.Term, o.Size.Height, o.Size.Width, modes); err != nil {
+			return err
+		}
+	}
+	if o.Command != "" {
+		err = sess.Start(o.Command)
+	} else {
+		err = sess.Shell()
+	}
+	if err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- sess.Wait()
+	}()
+	resize := o.Resize
+	for {
+		select {
+		case err = <-done:
+			return err
+		case size, ok := <-resize:
+			if !ok {
+				resize = nil
+				continue
+			}
+			_ = sess.WindowChange(size.Height, size.Width)
+		case <-ctx.Done():
+			_ = sess.Signal(ssh.SIGHUP)
+			_ = sess.Close()
+			<-done
+			return ctx.Err()
+		}
+	}
+}
+
+// Dial 通过ssh direct-tcpip通道从远端建立网络连接
+//
+//	@author duanzt
+//	@date 2026-10-19 22:55:30
+//	@receiver c *connection
+//	@param network string 网络类型
+//	@param addr string 目标地址（由远端解析）
+//	@return net.Conn 网络连接
+//	@return error 连接异常时返回
+func (c *connection) Dial(network, addr string) (net.Conn, error) {
+	return c.client.Dial(network, addr)
+}
+
+// Listen 通过ssh tcpip-forward请求在远端监听，连接经ssh通道转发到本地
+//
+//	@author duanzt
+//	@date 2026-10-19 22:56:00
+//	@receiver c *connection
+//	@param network string 网络类型
+//	@param addr string 远端监听地址
+//	@return net.Listener 监听
+//	@return error sshd不允许转发或监听异常时返回
+func (c *connection) Listen(network, addr string) (net.Listener, error) {
+	return c.client.Listen(network, addr)
+}
+
+// Done ssh连接断开（或被关闭）时关闭的通道，首次调用时开始等待连接结束
+//
+//	@author duanzt
+//	@date 2026-10-19 23:56:20
+//	@receiver c *connection
+//	@return <-chan struct{} 通道
+func (c *connection) Done() <-chan struct{} {
+	c.doneOnce.Do(func() {
+		c.done = make(chan struct{})
+		go func() {
+			_ = c.client.Wait()
+			close(c.done)
+		}()
+	})
+	return c.done
+}
@@ -0,0 +1,185 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 17:58:20
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-20 00:01:00
+ * @FilePath: tartransfer.go
+ * @Description: 基于tar流的目录拷贝（远端运行tar命令，本地由transfer包打包及解包）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package remote
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/duanztop/gossh/internal"
+	"github.com/duanztop/gossh/internal/tools"
+	"github.com/duanztop/gossh/internal/transfer"
+	"golang.org/x/crypto/ssh"
+)
+
+// tarCopyDirLTR 将本地目录打包为tar流，由远端tar -x解包到目标目录
+//
+//	@author duanzt
+//	@date 2026-10-19 17:59:02
+//	@receiver c *connection
+//	@param src string 本地目录
+//	@param dest string 远端目标目录
+//	@param o *internal.CopyOptions 拷贝配置
+//	@return *internal.CopySummary 拷贝结果汇总
+//	@return error 拷贝异常时返回（未开启FailFast时汇总全部失败文件）
+func (c *connection) tarCopyDirLTR(src, dest string, o *internal.CopyOptions) (summary *internal.CopySummary, err error) {
+	if err := transfer.CheckTarOptions(o); err != nil {
+		return &internal.CopySummary{}, err
+	}
+	// 始终使用-p保证文件权限与条目一致，-o不恢复条目中的所属用户
+	quoted := tools.ShellTools.Quote(dest)
+	command := "mkdir -p -- " + quoted + " && tar -x -p -o" + tarFlags(o) + " -f - -C " + quoted
+	if !o.PreserveTimes {
+		command += " -m"
+	}
+	sess, err := c.client.NewSession()
+	if err != nil {
+		return &internal.CopySummary{}, err
+	}
+	defer sess.Close()
+	stdin, err := sess.StdinPipe()
+	if err != nil {
+		return &internal.CopySummary{}, err
+	}
+	var stderr bytes.Buffer
+	sess.Stderr = &stderr
+	if err := sess.Start(command); err != nil {
+		return &internal.CopySummary{}, err
+	}
+
+	tracker := transfer.NewTracker(dest, o)
+	defer func() {
+		tracker.Finish(err)
+	}()
+	w, err := transfer.CompressWriter(transfer.LimitWriter(o.Ctx(), stdin, o.Limiters), o.Compression)
+	if err != nil {
+		return &internal.CopySummary{}, err
+	}
+	summary, err = transfer.WriteTar(w, src, o, tracker)
+	if err == nil {
+		err = w.Close()
+	}
+	// 关闭输入后远端tar读到结尾退出（tar流不完整时异常退出）
+	_ = stdin.Close()
+	if err != nil {
+		_ = sess.Wait()
+		return summary, tarError(err, &stderr)
+	}
+	if err := sess.Wait(); err != nil {
+		return summary, tarError(err, &stderr)
+	}
+	return summary, summary.Err()
+}
+
+// tarCopyDirRTL 由远端tar -c将目录打包为tar流，解包到本地目标目录
+//
+//	@author duanzt
+//	@date 2026-10-19 18:00:30
+//	@receiver c *connection
+//	@param src string 远端目录
+//	@param dest string 本地目标目录
+//	@param o *internal.CopyOptions 拷贝配置
+//	@return *internal.CopySummary 拷贝结果汇总
+//	@return error 拷贝异常时返回（未开启FailFast时汇总全部失败文件）
+func (c *connection) tarCopyDirRTL(src, dest string, o *internal.CopyOptions) (summary *internal.CopySummary, err error) {
+	if err := transfer.CheckTarOptions(o); err != nil {
+		return &internal.CopySummary{}, err
+	}
+	command := "tar -c" + tarFlags(o)
+	if o.Symlink == internal.SymlinkFollow {
+		command += " -h"
+	}
+	command += " -f - -C " + tools.ShellTools.Quote(src) + " ."
+	sess, err := c.client.NewSession()
+	if err != nil {
+		return &internal.CopySummary{}, err
+	}
+	defer sess.Close()
+	stdout, err := sess.StdoutPipe()
+	if err != nil {
+		return &internal.CopySummary{}, err
+	}
+	var stderr bytes.Buffer
+	sess.Stderr = &stderr
+	if err := sess.Start(command); err != nil {
+		return &internal.CopySummary{}, err
+	}
+
+	tracker := transfer.NewTracker(dest, o)
+	defer func() {
+		tracker.Finish(err)
+	}()
+	if len(o.Limiters) > 0 {
+		stdout = io.TeeReader(stdout, transfer.LimitWriter(o.Ctx(), io.Discard, o.Limiters))
+	}
+	r, err := transfer.DecompressReader(stdout, o.Compression)
+	if err != nil {
+		return &internal.CopySummary{}, abortTar(sess, err, &stderr)
+	}
+	defer r.Close()
+	summary, err = transfer.ExtractTar(r, dest, o, tracker)
+	if err != nil {
+		return summary, abortTar(sess, err, &stderr)
+	}
+	// 读取压缩流的剩余内容（tar结尾的填充块），避免远端tar因管道关闭而异常退出
+	_, _ = io.Copy(io.Discard, stdout)
+	if err := sess.Wait(); err != nil {
+		return summary, tarError(err, &stderr)
+	}
+	return summary, summary.Err()
+}
+
+// abortTar 中断远端tar（关闭会话并等待输出读取结束）
+//
+//	@author duanzt
+//	@date 2026-10-19 18:01:30
+//	@param sess *ssh.Session 会话
+//	@param err error 异常
+//	@param stderr *bytes.Buffer 远端错误输出
+//	@return error 附加错误输出后的异常
+func abortTar(sess *ssh.Session, err error, stderr *bytes.Buffer) error {
+	_ = sess.Close()
+	_ = sess.Wait()
+	return tarError(err, stderr)
+}
+
+// tarFlags 根据压缩算法生成远端tar命令的参数
+//
+//	@author duanzt
+//	@date 2026-10-19 18:01:12
+//	@param o *internal.CopyOptions 拷贝配置
+//	@return string 参数（以空格开头，不压缩时为空）
+func tarFlags(o *internal.CopyOptions) string {
+	switch o.Compression {
+	case internal.CompressionGzip:
+		return " -z"
+	case internal.CompressionZstd:
+		return " --zstd"
+	default:
+		return ""
+	}
+}
+
+// tarError 为异常附加远端tar的错误输出（例如tar命令不存在或不支持压缩算法）
+//
+//	@author duanzt
+//	@date 2026-10-19 18:01:50
+//	@param err error 异常
+//	@param stderr *bytes.Buffer 远端错误输出
+//	@return error 附加错误输出后的异常
+func tarError(err error, stderr *bytes.Buffer) error {
+	if output := strings.TrimSpace(stderr.String()); output != "" {
+		return fmt.Errorf("tar: %w, %s", err, output)
+	}
+	return fmt.Errorf("tar: %w", err)
+}
@@ -0,0 +1,242 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 12:31:44
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 15:05:20
+ * @FilePath: transfer.go
+ * @Description: 远程ssh连接的文件传输（上传、下载及目标文件属性处理）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/duanztop/gossh/internal"
+	"github.com/duanztop/gossh/internal/tools"
+	"github.com/duanztop/gossh/internal/transfer"
+	"github.com/pkg/sftp"
+)
+
+// upload 将流写入远端文件
+//
+//	@author duanzt
+//	@date 2026-10-19 12:32:30
+//	@receiver c *connection
+//	@param src io.Reader 流
+//	@param dest string 远端目标文件地址
+//	@param mode string 文件权限（八进制或符号格式）
+//	@param o *internal.CopyOptions 拷贝配置
+//	@return error 拷贝异常时返回
+func (c *connection) upload(src io.Reader, dest, mode string, o *internal.CopyOptions) (err error) {
+	tracker := transfer.NewTracker(dest, o)
+	defer func() {
+		tracker.Finish(err)
+	}()
+	sftpClient, err := c.getSftpClient()
+	if err != nil {
+		return err
+	}
+	perm, err := tools.ModeTools.Resolve(mode, tools.FileTools.StatReader(src), o.PreserveMode, func() (fs.FileInfo, error) {
+		return sftpClient.Stat(dest)
+	})
+	if err != nil {
+		return err
+	}
+	if err := sftpClient.MkdirAll(path.Dir(dest)); err != nil {
+		return err
+	}
+
+	// 原子写入时先写入同目录下的临时文件
+	target := dest
+	if o.Atomic {
+		target = path.Join(path.Dir(dest), transfer.TempName(path.Base(dest), o))
+		defer func() {
+			if err != nil && !o.Resume {
+				_ = sftpClient.Remove(target)
+			}
+		}()
+	}
+	fd, err := sftpClient.OpenFile(target, transfer.OpenFlag(o))
+	if err != nil {
+		return toPathError("open", target, err)
+	}
+	defer fd.Close()
+
+	sum, err := transfer.WriteStream(fd, src, o, tracker)
+	if err != nil {
+		return err
+	}
+	if _, ok := sftpClient.HasExtension(fsyncExtension); ok && o.Atomic {
+		if err := fd.Sync(); err != nil {
+			return toPathError("fsync", target, err)
+		}
+	}
+	if err := fd.Close(); err != nil {
+		return toPathError("close", target, err)
+	}
+	if o.Verify != "" {
+		if err := transfer.Verify(target, o.Verify, sum, func() (string, error) {
+			return c.fileSum(target, o.Verify)
+		}); err != nil {
+			return err
+		}
+	}
+	// 写入完成后再修改权限，避免只读权限或写入清除setuid位导致权限不符合预期
+	if err := c.Chmod(target, perm); err != nil {
+		return err
+	}
+	if err := c.chownRemote(target, o); err != nil {
+		return err
+	}
+	if !o.Atomic {
+		return nil
+	}
+	if o.Backup != "" {
+		if err := c.backup(dest, dest+o.Backup); err != nil {
+			return err
+		}
+	}
+	return c.Rename(target, dest)
+}
+
+// backup 备份远端文件（服务端支持hardlink扩展时使用硬链接，保证备份期间目标文件一直存在，否则重命名）
+//
+//	@author duanzt
+//	@date 2026-10-19 14:22:40
+//	@receiver c *connection
+//	@param name string 远端文件路径
+//	@param backup string 备份文件路径
+//	@return error 备份异常时返回
+func (c *connection) backup(name, backup string) error {
+	sftpClient, err := c.getSftpClient()
+	if err != nil {
+		return err
+	}
+	if _, err := c.Lstat(name); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err := c.Remove(backup); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if _, ok := sftpClient.HasExtension(hardlinkExtension); ok {
+		if err := sftpClient.Link(name, backup); err == nil {
+			return nil
+		}
+	}
+	return c.Rename(name, backup)
+}
+
+// download 将远端文件写入本地文件
+//
+//	@author duanzt
+//	@date 2026-10-19 12:34:05
+//	@receiver c *connection
+//	@param src string 远端文件地址
+//	@param dest string 本地目标文件地址
+//	@param mode string 文件权限（八进制或符号格式）
+//	@param o *internal.CopyOptions 拷贝配置
+//	@return error 拷贝异常时返回
+func (c *connection) download(src, dest, mode string, o *internal.CopyOptions) (err error) {
+	tracker := transfer.NewTracker(dest, o)
+	defer func() {
+		tracker.Finish(err)
+	}()
+	sftpClient, err := c.getSftpClient()
+	if err != nil {
+		return err
+	}
+	file, err := sftpClient.Open(src)
+	if err != nil {
+		return toPathError("open", src, err)
+	}
+	defer file.Close()
+
+	perm, err := tools.ModeTools.Resolve(mode, tools.FileTools.StatReader(file), o.PreserveMode, func() (fs.FileInfo, error) {
+		return os.Stat(dest)
+	})
+	if err != nil {
+		return err
+	}
+	return transfer.WriteLocal(file, dest, perm, o, tracker)
+}
+
+// chownRemote 根据拷贝配置修改远端文件的所属用户及用户组
+//
+//	@author duanzt
+//	@date 2026-10-19 12:35:40
+//	@receiver c *connection
+//	@param dest string 远端文件地址
+//	@param o *internal.CopyOptions 拷贝配置
+//	@return error 修改异常时返回
+func (c *connection) chownRemote(dest string, o *internal.CopyOptions) error {
+	if o.Owner == "" && o.Group == "" {
+		return nil
+	}
+	uid, err := c.lookupId("id -u", o.Owner)
+	if err != nil {
+		return err
+	}
+	gid, err := c.lookupId("getent group", o.Group)
+	if err != nil {
+		return err
+	}
+	// sftp协议需要同时指定uid及gid，未指定的一方沿用文件当前值
+	if uid < 0 || gid < 0 {
+		info, err := c.Stat(dest)
+		if err != nil {
+			return err
+		}
+		if stat, ok := info.Sys().(*sftp.FileStat); ok {
+			if uid < 0 {
+				uid = int(stat.UID)
+			}
+			if gid < 0 {
+				gid = int(stat.GID)
+			}
+		}
+	}
+	return c.Chown(dest, uid, gid)
+}
+
+// lookupId 获取远端用户或用户组对应的id
+//
+//	@author duanzt
+//	@date 2026-10-19 12:36:52
+//	@receiver c *connection
+//	@param command string 查询命令（id -u 或 getent group）
+//	@param name string 用户名/组名或数字id，为空时返回-1
+//	@return int id
+//	@return error 查询异常时返回
+func (c *connection) lookupId(command, name string) (int, error) {
+	if name == "" {
+		return -1, nil
+	}
+	if id, err := strconv.Atoi(name); err == nil {
+		return id, nil
+	}
+	output, err := c.ExecShell(context.Background(), command+" "+tools.ShellTools.Quote(name))
+	if err != nil {
+		return -1, fmt.Errorf("查询%s失败: %w", name, err)
+	}
+	// id -u 输出uid；getent group 输出 name:x:gid:members
+	fields := strings.Split(strings.TrimSpace(output), ":")
+	idText := fields[0]
+	if len(fields) >= 3 {
+		idText = fields[2]
+	}
+	id, err := strconv.Atoi(idText)
+	if err != nil {
+		return -1, fmt.Errorf("查询%s失败: %s", name, strings.TrimSpace(output))
+	}
+	return id, nil
+}
@@ -0,0 +1,239 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 21:22:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-20 00:02:40
+ * @FilePath: rollout.go
+ * @Description: 分批滚动执行的结果、状态记录及配置
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrRolloutHalted 失败的主机超过错误预算（或批次被中断），已停止滚动执行
+var ErrRolloutHalted = errors.New("失败主机超过错误预算，已停止滚动执行")
+
+// BatchResult 单个批次的执行结果
+type BatchResult struct {
+	Index   int           // 批次序号（从1开始，恢复执行时延续之前的序号）
+	Results []*HostResult // 各主机的执行结果（包含前置及后置任务的输出）
+	Health  []*HostResult // 健康检查结果（仅对执行成功的主机检查）
+	Failed  int           // 执行或健康检查失败的主机数
+	Skipped int           // 因中断未执行（或未完成健康检查）的主机数
+}
+
+// RolloutState 滚动执行的状态记录（可保存为json文件，用于中断后继续执行）
+type RolloutState struct {
+	Batches int       `json:"batches"` // 已完成的批次数
+	Done    []string  `json:"done"`    // 已成功的主机（主机名称）
+	Failed  []string  `json:"failed"`  // 最近一次执行失败的主机（恢复执行时重试）
+	Halted  bool      `json:"halted"`  // 是否已停止
+	Updated time.Time `json:"updated"` // 更新时间
+}
+
+// RolloutReport 滚动执行结果
+type RolloutReport struct {
+	Batches   []*BatchResult // 本次执行的批次
+	Resumed   int            // 根据状态记录跳过的已成功主机数
+	Succeeded int            // 本次执行成功的主机数
+	Failed    int            // 本次执行失败的主机数
+	Remaining int            // 因停止未执行的主机数
+	Halted    bool           // 是否因超过错误预算或批次被中断而停止
+	State     *RolloutState  // 执行后的状态记录
+	Duration  time.Duration  // 总耗时
+}
+
+// Err 汇总执行异常，全部成功时返回nil
+//
+//	@author duanzt
+//	@date 2026-10-19 21:23:02
+//	@receiver r *RolloutReport
+//	@return error 执行异常（停止时包装ErrRolloutHalted）
+func (r *RolloutReport) Err() error {
+	switch {
+	case r.Halted:
+		return fmt.Errorf("%w（%d台主机失败，%d台未执行）", ErrRolloutHalted, r.Failed, r.Remaining)
+	case r.Failed > 0:
+		return fmt.Errorf("%d台主机执行失败（未超过错误预算）", r.Failed)
+	}
+	return nil
+}
+
+// RolloutOptions 滚动执行配置
+type RolloutOptions struct {
+	BatchSize          int                // 每批的主机数
+	BatchPercent       float64            // 每批的主机数占全部主机的百分比（向上取整，设置时优先于BatchSize）
+	Pause              time.Duration      // 批次之间的间隔
+	PreHook            HostTask           // 每台主机执行任务前的前置任务（失败时不执行任务）
+	PostHook           HostTask           // 每台主机执行任务后的后置任务
+	HealthCheck        HostTask           // 每批执行完成后对该批主机的健康检查
+	ErrorBudget        int                // 允许失败的主机数（包含状态记录中此前失败且尚未重试成功的主机）
+	ErrorBudgetPercent float64            // 允许失败的主机数占全部主机的百分比（向下取整，设置时优先于ErrorBudget）
+	StateFile          string             // 状态记录文件（本地json文件，存在时跳过已成功的主机）
+	Fanout             *FanoutOptions     // 批次内的执行配置（并发数默认为批次大小）
+	BatchHandler       func(*BatchResult) // 每批执行完成时调用
+}
+
+// RolloutOption 滚动执行配置项
+type RolloutOption func(*RolloutOptions)
+
+// NewRolloutOptions 根据配置项生成滚动执行配置
+//
+//	@author duanzt
+//	@date 2026-10-19 21:23:40
+//	@param opts ...RolloutOption 配置项
+//	@return *RolloutOptions 滚动执行配置
+func NewRolloutOptions(opts ...RolloutOption) *RolloutOptions {
+	o := &RolloutOptions{BatchSize: 1, Fanout: &FanoutOptions{}}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return o
+}
+
+// WithBatchSize 设置每批的主机数（默认1）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:24:10
+//	@param n int 主机数
+//	@return RolloutOption 配置项
+func WithBatchSize(n int) RolloutOption {
+	return func(o *RolloutOptions) {
+		if n > 0 {
+			o.BatchSize = n
+		}
+	}
+}
+
+// WithBatchPercent 设置每批的主机数占全部主机的百分比（向上取整，至少1台）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:24:40
+//	@param percent float64 百分比，例如10表示10%
+//	@return RolloutOption 配置项
+func WithBatchPercent(percent float64) RolloutOption {
+	return func(o *RolloutOptions) {
+		o.BatchPercent = percent
+	}
+}
+
+// WithBatchPause 设置批次之间的间隔
+//
+//	@author duanzt
+//	@date 2026-10-19 21:25:10
+//	@param pause time.Duration 间隔
+//	@return RolloutOption 配置项
+func WithBatchPause(pause time.Duration) RolloutOption {
+	return func(o *RolloutOptions) {
+		o.Pause = pause
+	}
+}
+
+// WithPreHook 设置每台主机执行任务前的前置任务（例如从负载均衡摘除）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:25:40
+//	@param hook HostTask 前置任务
+//	@return RolloutOption 配置项
+func WithPreHook(hook HostTask) RolloutOption {
+	return func(o *RolloutOptions) {
+		o.PreHook = hook
+	}
+}
+
+// WithPostHook 设置每台主机执行任务后的后置任务（例如加回负载均衡）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:26:10
+//	@param hook HostTask 后置任务
+//	@return RolloutOption 配置项
+func WithPostHook(hook HostTask) RolloutOption {
+	return func(o *RolloutOptions) {
+		o.PostHook = hook
+	}
+}
+
+// WithHealthCheck 设置每批执行完成后对该批主机的健康检查（失败的主机计入错误预算）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:26:40
+//	@param check HostTask 健康检查
+//	@return RolloutOption 配置项
+func WithHealthCheck(check HostTask) RolloutOption {
+	return func(o *RolloutOptions) {
+		o.HealthCheck = check
+	}
+}
+
+// WithErrorBudget 设置允许失败的主机数（默认0，即任一主机失败时在当前批次完成后停止；
+// 恢复执行时状态记录中此前失败且尚未重试成功的主机同样计入）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:27:10
+//	@param n int 主机数
+//	@return RolloutOption 配置项
+func WithErrorBudget(n int) RolloutOption {
+	return func(o *RolloutOptions) {
+		o.ErrorBudget = n
+	}
+}
+
+// WithErrorBudgetPercent 设置允许失败的主机数占全部主机的百分比（向下取整）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:27:40
+//	@param percent float64 百分比，例如5表示5%
+//	@return RolloutOption 配置项
+func WithErrorBudgetPercent(percent float64) RolloutOption {
+	return func(o *RolloutOptions) {
+		o.ErrorBudgetPercent = percent
+	}
+}
+
+// WithStateFile 设置状态记录文件，每批完成后更新，存在时跳过其中已成功的主机（全部重新执行时删除该文件）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:28:10
+//	@param name string 本地文件路径
+//	@return RolloutOption 配置项
+func WithStateFile(name string) RolloutOption {
+	return func(o *RolloutOptions) {
+		o.StateFile = name
+	}
+}
+
+// WithRolloutFanout 设置批次内的执行配置（并发数、单台主机超时、建立连接的方法、结果处理等）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:28:40
+//	@param opts ...FanoutOption 多主机执行配置项
+//	@return RolloutOption 配置项
+func WithRolloutFanout(opts ...FanoutOption) RolloutOption {
+	return func(o *RolloutOptions) {
+		for _, opt := range opts {
+			if opt != nil {
+				opt(o.Fanout)
+			}
+		}
+	}
+}
+
+// WithBatchHandler 设置每批执行完成时的处理方法
+//
+//	@author duanzt
+//	@date 2026-10-19 21:29:10
+//	@param handler func(*BatchResult) 处理方法
+//	@return RolloutOption 配置项
+func WithBatchHandler(handler func(*BatchResult)) RolloutOption {
+	return func(o *RolloutOptions) {
+		o.BatchHandler = handler
+	}
+}
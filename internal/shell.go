@@ -0,0 +1,91 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 22:50:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 22:50:10
+ * @FilePath: shell.go
+ * @Description: 交互式shell的配置（伪终端、窗口大小变化、执行的命令）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package internal
+
+// DefaultTerm 请求伪终端时默认的终端类型
+const DefaultTerm = "xterm-256color"
+
+// WindowSize 终端窗口大小
+type WindowSize struct {
+	Width  int // 列数
+	Height int // 行数
+}
+
+// ShellOptions 交互式shell配置
+type ShellOptions struct {
+	Pty     bool              // 是否请求伪终端（远程连接有效）
+	Term    string            // 终端类型
+	Size    WindowSize        // 初始窗口大小
+	Resize  <-chan WindowSize // 窗口大小变化（关闭或为nil时不再通知）
+	Command string            // 执行的命令（为空时启动登录shell）
+}
+
+// ShellOption 交互式shell配置项
+type ShellOption func(*ShellOptions)
+
+// NewShellOptions 根据配置项生成交互式shell配置
+//
+//	@author duanzt
+//	@date 2026-10-19 22:50:40
+//	@param opts ...ShellOption 配置项
+//	@return *ShellOptions 交互式shell配置
+func NewShellOptions(opts ...ShellOption) *ShellOptions {
+	o := &ShellOptions{Term: DefaultTerm, Size: WindowSize{Width: 80, Height: 24}}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return o
+}
+
+// WithPty 请求伪终端（本地终端需由调用方切换为raw模式）
+//
+//	@author duanzt
+//	@date 2026-10-19 22:51:10
+//	@param term string 终端类型（为空时使用DefaultTerm）
+//	@param size WindowSize 初始窗口大小
+//	@return ShellOption 配置项
+func WithPty(term string, size WindowSize) ShellOption {
+	return func(o *ShellOptions) {
+		o.Pty = true
+		if term != "" {
+			o.Term = term
+		}
+		if size.Width > 0 && size.Height > 0 {
+			o.Size = size
+		}
+	}
+}
+
+// WithWindowResize 设置窗口大小变化的通知（例如收到SIGWINCH时发送当前大小）
+//
+//	@author duanzt
+//	@date 2026-10-19 22:51:40
+//	@param resize <-chan WindowSize 窗口大小变化
+//	@return ShellOption 配置项
+func WithWindowResize(resize <-chan WindowSize) ShellOption {
+	return func(o *ShellOptions) {
+		o.Resize = resize
+	}
+}
+
+// WithShellCommand 设置执行的命令（不设置时启动登录shell）
+//
+//	@author duanzt
+//	@date 2026-10-19 22:52:10
+//	@param command string 命令
+//	@return ShellOption 配置项
+func WithShellCommand(command string) ShellOption {
+	return func(o *ShellOptions) {
+		o.Command = command
+	}
+}
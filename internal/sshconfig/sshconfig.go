@@ -0,0 +1,413 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 23:09:40
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:09:40
+ * @FilePath: sshconfig.go
+ * @Description: 解析openssh客户端配置（~/.ssh/config），按主机别名生成主机连接配置
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package sshconfig
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/duanztop/gossh/internal"
+)
+
+const (
+
+	// DefaultPath 默认的配置文件路径
+	DefaultPath = "~/.ssh/config"
+
+	// maxIncludeDepth Include的最大嵌套层数
+	maxIncludeDepth = 16
+)
+
+// block Host段（Match段仅支持Match all，其它Match段被忽略）
+type block struct {
+	patterns []string            // 主机匹配规则（!开头为排除）
+	options  map[string][]string // 配置项（关键字为小写，同一段内保留首次出现的值）
+}
+
+// Config openssh客户端配置
+type Config struct {
+	blocks []*block
+}
+
+// Parse 解析配置内容，支持Host（*、?通配及!排除）、Match all、Include，
+// 关键字不区分大小写，支持key value及key=value格式，不识别的关键字被忽略
+//
+//	@author duanzt
+//	@date 2026-10-19 23:10:20
+//	@param data []byte 配置内容
+//	@return *Config 配置
+//	@return error 格式不正确或Include的文件读取异常时返回
+func Parse(data []byte) (*Config, error) {
+	c := &Config{}
+	if err := c.parse(data, "", 0); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Load 读取配置文件（~开头的路径展开为用户目录），文件不存在时返回空配置
+//
+//	@author duanzt
+//	@date 2026-10-19 23:10:50
+//	@param name string 配置文件路径（为空时使用DefaultPath）
+//	@return *Config 配置
+//	@return error 读取或解析异常时返回
+func Load(name string) (*Config, error) {
+	if name == "" {
+		name = DefaultPath
+	}
+	name = expandHome(name)
+	data, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	c := &Config{}
+	if err := c.parse(data, name, 0); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// parse 解析配置内容并追加到c
+//
+//	@author duanzt
+//	@date 2026-10-19 23:11:30
+//	@receiver c *Config
+//	@param data []byte 配置内容
+//	@param name string 配置文件路径（用于异常信息）
+//	@param depth int Include的嵌套层数
+//	@return error 格式不正确时返回
+func (c *Config) parse(data []byte, name string, depth int) error {
+	if depth > maxIncludeDepth {
+		return fmt.Errorf("%s: Include嵌套超过%d层", name, maxIncludeDepth)
+	}
+	// 第一个Host之前的配置项适用于全部主机
+	current := &block{patterns: []string{"*"}, options: map[string][]string{}}
+	c.blocks = append(c.blocks, current)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		key, args, err := splitLine(scanner.Text())
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", name, line, err)
+		}
+		switch key {
+		case "":
+		case "host":
+			if len(args) == 0 {
+				return fmt.Errorf("%s:%d: Host缺少匹配规则", name, line)
+			}
+			current = &block{patterns: args, options: map[string][]string{}}
+			c.blocks = append(c.blocks, current)
+		case "match":
+			current = &block{options: map[string][]string{}}
+			if len(args) == 1 && strings.EqualFold(args[0], "all") {
+				current.patterns = []string{"*"}
+			}
+			c.blocks = append(c.blocks, current)
+		case "include":
+			for _, pattern := range args {
+				if err := c.include(pattern, current, depth); err != nil {
+					return fmt.Errorf("%s:%d: %w", name, line, err)
+				}
+			}
+		default:
+			if len(args) == 0 {
+				return fmt.Errorf("%s:%d: %s缺少值", name, line, key)
+			}
+			if _, ok := current.options[key]; !ok {
+				current.options[key] = args
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// include 读取Include的文件（相对路径基于~/.ssh，支持通配），其中的配置项在所在的Host段内生效
+//
+//	@author duanzt
+//	@date 2026-10-19 23:12:10
+//	@receiver c *Config
+//	@param pattern string 文件路径
+//	@param current *block 所在的Host段
+//	@param depth int 当前嵌套层数
+//	@return error 读取或解析异常时返回
+func (c *Config) include(pattern string, current *block, depth int) error {
+	pattern = expandHome(pattern)
+	if !filepath.IsAbs(pattern) {
+		pattern = expandHome(filepath.Join("~/.ssh", pattern))
+	}
+	names, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		sub := &Config{}
+		if err := sub.parse(data, name, depth+1); err != nil {
+			return err
+		}
+		// 被包含文件中第一个Host之前的配置项受所在Host段限制
+		sub.blocks[0].patterns = current.patterns
+		c.blocks = append(c.blocks, sub.blocks...)
+	}
+	return nil
+}
+
+// Get 获取主机别名对应的配置项（按出现顺序，首个匹配的值生效）
+//
+//	@author duanzt
+//	@date 2026-10-19 23:12:50
+//	@receiver c *Config
+//	@param alias string 主机别名
+//	@param key string 关键字（不区分大小写）
+//	@return string 配置值（多个参数以空格连接，未配置时为空）
+func (c *Config) Get(alias, key string) string {
+	key = strings.ToLower(key)
+	for _, b := range c.blocks {
+		if args, ok := b.options[key]; ok && b.match(alias) {
+			return strings.Join(args, " ")
+		}
+	}
+	return ""
+}
+
+// Aliases 获取配置中定义的主机别名（不含通配及排除规则，按出现顺序去重）
+//
+//	@author duanzt
+//	@date 2026-10-19 23:13:20
+//	@receiver c *Config
+//	@return []string 主机别名
+func (c *Config) Aliases() []string {
+	var aliases []string
+	seen := map[string]bool{}
+	for _, b := range c.blocks {
+		for _, p := range b.patterns {
+			if strings.ContainsAny(p, "*?!") || seen[p] {
+				continue
+			}
+			seen[p] = true
+			aliases = append(aliases, p)
+		}
+	}
+	return aliases
+}
+
+// Host 按主机别名生成主机连接配置：HostName（支持%h）、Port、User、IdentityFile（取首个）、ProxyJump（none表示不使用）
+//
+//	@author duanzt
+//	@date 2026-10-19 23:13:50
+//	@receiver c *Config
+//	@param alias string 主机别名（未配置HostName时作为主机地址）
+//	@return internal.Host 主机连接配置（Name为alias）
+func (c *Config) Host(alias string) internal.Host {
+	host := internal.Host{
+		Name: alias,
+		Addr: c.addr(alias),
+		User: c.Get(alias, "User"),
+	}
+	if identity := c.first(alias, "IdentityFile"); identity != "" {
+		host.PrivateKey = expandHome(strings.ReplaceAll(identity, "%d", "~"))
+	}
+	if jump := c.Get(alias, "ProxyJump"); jump != "" && !strings.EqualFold(jump, "none") {
+		host.Jump = c.resolveJump(jump)
+	}
+	return host
+}
+
+// addr 按主机别名获取连接地址（HostName及Port）
+//
+//	@author duanzt
+//	@date 2026-10-19 23:14:00
+//	@receiver c *Config
+//	@param alias string 主机别名
+//	@return string 连接地址
+func (c *Config) addr(alias string) string {
+	hostname := c.Get(alias, "HostName")
+	if hostname == "" {
+		hostname = alias
+	}
+	hostname = strings.ReplaceAll(hostname, "%h", alias)
+	port := c.Get(alias, "Port")
+	if port == "" {
+		port = "22"
+	}
+	return net.JoinHostPort(hostname, port)
+}
+
+// resolveJump 将跳板机中的主机别名替换为配置的HostName、Port及User（已指定端口的跳板机保持不变）
+//
+//	@author duanzt
+//	@date 2026-10-19 23:14:10
+//	@receiver c *Config
+//	@param jump string 跳板机，格式为[user@]host[:port]，多级以逗号分隔
+//	@return string 替换后的跳板机
+func (c *Config) resolveJump(jump string) string {
+	jumps := strings.Split(jump, ",")
+	for i, j := range jumps {
+		j = strings.TrimPrefix(strings.TrimSpace(j), "ssh://")
+		user, name := "", j
+		if k := strings.LastIndex(j, "@"); k >= 0 {
+			user, name = j[:k+1], j[k+1:]
+		}
+		if strings.Contains(name, ":") {
+			jumps[i] = j
+			continue
+		}
+		if u := c.Get(name, "User"); user == "" && u != "" {
+			user = u + "@"
+		}
+		jumps[i] = user + c.addr(name)
+	}
+	return strings.Join(jumps, ",")
+}
+
+// first 获取配置项的第一个参数
+//
+//	@author duanzt
+//	@date 2026-10-19 23:14:20
+//	@receiver c *Config
+//	@param alias string 主机别名
+//	@param key string 关键字
+//	@return string 第一个参数
+func (c *Config) first(alias, key string) string {
+	value, _, _ := strings.Cut(c.Get(alias, key), " ")
+	return value
+}
+
+// match 主机别名是否匹配Host段（任一规则匹配且没有排除规则匹配）
+//
+//	@author duanzt
+//	@date 2026-10-19 23:14:50
+//	@receiver b *block
+//	@param alias string 主机别名
+//	@return bool 匹配则返回true
+func (b *block) match(alias string) bool {
+	matched := false
+	for _, p := range b.patterns {
+		if strings.HasPrefix(p, "!") {
+			if wildcard(p[1:], alias) {
+				return false
+			}
+		} else if wildcard(p, alias) {
+			matched = true
+		}
+	}
+	return matched
+}
+
+// wildcard 通配匹配（*匹配任意个字符，?匹配单个字符，不区分大小写）
+//
+//	@author duanzt
+//	@date 2026-10-19 23:15:20
+//	@param pattern string 匹配规则
+//	@param s string 字符串
+//	@return bool 匹配则返回true
+func wildcard(pattern, s string) bool {
+	pattern, s = strings.ToLower(pattern), strings.ToLower(s)
+	// 记录最近一个*的位置，失配时回溯
+	p, i, star, mark := 0, 0, -1, 0
+	for i < len(s) {
+		switch {
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == s[i]):
+			p++
+			i++
+		case p < len(pattern) && pattern[p] == '*':
+			star, mark = p, i
+			p++
+		case star >= 0:
+			p = star + 1
+			mark++
+			i = mark
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// splitLine 拆分配置行为小写关键字及参数（支持双引号包裹的参数，#开头为注释）
+//
+//	@author duanzt
+//	@date 2026-10-19 23:15:50
+//	@param line string 配置行
+//	@return string 关键字（空行或注释为空）
+//	@return []string 参数
+//	@return error 引号未闭合时返回
+func splitLine(line string) (string, []string, error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", nil, nil
+	}
+	// 关键字与参数之间可以是空白或=
+	end := strings.IndexAny(line, " \t=")
+	if end < 0 {
+		return strings.ToLower(line), nil, nil
+	}
+	key, rest := strings.ToLower(line[:end]), strings.TrimLeft(line[end:], " \t")
+	rest = strings.TrimLeft(strings.TrimPrefix(rest, "="), " \t")
+	var args []string
+	for rest != "" {
+		if rest[0] == '"' {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return "", nil, fmt.Errorf("%s的引号未闭合", key)
+			}
+			args = append(args, rest[1:end+1])
+			rest = strings.TrimLeft(rest[end+2:], " \t")
+			continue
+		}
+		end := strings.IndexAny(rest, " \t")
+		if end < 0 {
+			end = len(rest)
+		}
+		if strings.HasPrefix(rest[:end], "#") {
+			break
+		}
+		args = append(args, rest[:end])
+		rest = strings.TrimLeft(rest[end:], " \t")
+	}
+	return key, args, nil
+}
+
+// expandHome 展开~开头的路径为用户目录下的路径（无法获取用户目录时原样返回）
+//
+//	@author duanzt
+//	@date 2026-10-19 23:16:20
+//	@param name string 路径
+//	@return string 展开后的路径
+func expandHome(name string) string {
+	if name != "~" && !strings.HasPrefix(name, "~/") {
+		return name
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return name
+	}
+	return filepath.Join(home, name[1:])
+}
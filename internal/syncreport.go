@@ -0,0 +1,60 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 18:10:20
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 18:10:20
+ * @FilePath: syncreport.go
+ * @Description: 目录同步结果
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package internal
+
+// SyncAction 同步操作类型
+type SyncAction string
+
+const (
+
+	// SyncCreate 目标端不存在，新建
+	SyncCreate SyncAction = "create"
+
+	// SyncUpdate 目标端已存在但内容、类型或权限不一致，覆盖
+	SyncUpdate SyncAction = "update"
+
+	// SyncDelete 源端不存在，删除目标端多余的文件/目录
+	SyncDelete SyncAction = "delete"
+)
+
+// SyncChange 单个文件/目录的同步操作
+type SyncChange struct {
+	Action SyncAction // 操作类型
+	Path   string     // 相对于同步根目录的路径（使用/分隔）
+	Dir    bool       // 是否为目录
+	Size   int64      // 源文件大小（删除时为目标文件大小）
+	Reason string     // 需要同步的原因（missing、size、mtime、checksum、mode、type、link、extraneous）
+}
+
+// SyncReport 目录同步结果（DryRun时仅包含计划的操作，不修改目标端）
+type SyncReport struct {
+	CopySummary               // 实际拷贝的文件汇总（DryRun时除Skipped外均为0）
+	Changes     []*SyncChange // 同步操作（按遍历顺序）
+	Unchanged   int           // 无需同步的文件数
+	DryRun      bool          // 是否为演练
+}
+
+// Count 统计指定类型的操作数
+//
+//	@author duanzt
+//	@date 2026-10-19 18:11:02
+//	@receiver r *SyncReport
+//	@param action SyncAction 操作类型
+//	@return int 操作数
+func (r *SyncReport) Count(action SyncAction) int {
+	n := 0
+	for _, change := range r.Changes {
+		if change.Action == action {
+			n++
+		}
+	}
+	return n
+}
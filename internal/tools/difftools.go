@@ -0,0 +1,283 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 20:25:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 20:25:10
+ * @FilePath: difftools.go
+ * @Description: 文本比较工具（Myers算法，输出unified格式）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package tools
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// maxDiffEdits 最大编辑距离，超过时将差异部分整体视为删除后新增（避免差异过大时占用过多内存）
+const maxDiffEdits = 1024
+
+type difftools struct{}
+
+var (
+	DiffTools = difftools{}
+)
+
+// diffOp 编辑操作
+type diffOp struct {
+	kind byte // ' '相同、'-'删除、'+'新增
+	line string
+}
+
+// Unified 比较两段文本，输出unified格式的差异（内容相同时返回空字符串）
+//
+//	@author duanzt
+//	@date 2026-10-19 20:25:50
+//	@receiver d difftools
+//	@param oldName string 原文本的名称（---行）
+//	@param newName string 新文本的名称（+++行）
+//	@param oldText []byte 原文本
+//	@param newText []byte 新文本
+//	@param context int 差异前后保留的相同行数
+//	@return string unified格式的差异
+func (d difftools) Unified(oldName, newName string, oldText, newText []byte, context int) string {
+	if bytes.Equal(oldText, newText) {
+		return ""
+	}
+	if context < 0 {
+		context = 0
+	}
+	a, b := splitLines(oldText), splitLines(newText)
+	ops := diffLines(a, b)
+
+	var out strings.Builder
+	out.WriteString("--- " + oldName + "\n")
+	out.WriteString("+++ " + newName + "\n")
+	// 按差异分组：相邻差异之间的相同行不超过2*context时合并为一个hunk
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			same := end
+			for same < len(ops) && ops[same].kind == ' ' {
+				same++
+			}
+			if same == len(ops) || same-end > 2*context {
+				break
+			}
+			end = same
+		}
+		stop := end + context
+		if stop > len(ops) {
+			stop = len(ops)
+		}
+		writeHunk(&out, ops, start, stop)
+		i = stop
+	}
+	return out.String()
+}
+
+// writeHunk 输出一个hunk
+//
+//	@author duanzt
+//	@date 2026-10-19 20:26:40
+//	@param out *strings.Builder 输出
+//	@param ops []diffOp 全部编辑操作
+//	@param start int hunk的起始操作（包含）
+//	@param stop int hunk的结束操作（不包含）
+func writeHunk(out *strings.Builder, ops []diffOp, start, stop int) {
+	oldStart, newStart := 1, 1
+	for _, op := range ops[:start] {
+		if op.kind != '+' {
+			oldStart++
+		}
+		if op.kind != '-' {
+			newStart++
+		}
+	}
+	oldCount, newCount := 0, 0
+	for _, op := range ops[start:stop] {
+		if op.kind != '+' {
+			oldCount++
+		}
+		if op.kind != '-' {
+			newCount++
+		}
+	}
+	out.WriteString("@@ -" + hunkRange(oldStart, oldCount) + " +" + hunkRange(newStart, newCount) + " @@\n")
+	for _, op := range ops[start:stop] {
+		out.WriteByte(op.kind)
+		if strings.HasSuffix(op.line, "\n") {
+			out.WriteString(op.line)
+		} else {
+			out.WriteString(op.line + "\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange 输出hunk的行范围（同GNU diff：行数为1时省略，行数为0时起始行为前一行）
+//
+//	@author duanzt
+//	@date 2026-10-19 20:27:20
+//	@param start int 起始行
+//	@param count int 行数
+//	@return string 行范围
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start-1)
+	case 1:
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// splitLines 按行拆分文本（保留换行符，最后一行可能没有换行符）
+//
+//	@author duanzt
+//	@date 2026-10-19 20:27:50
+//	@param text []byte 文本
+//	@return []string 行
+func splitLines(text []byte) []string {
+	lines := strings.SplitAfter(string(text), "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines 计算两组行的最短编辑操作（先去除相同的前缀及后缀）
+//
+//	@author duanzt
+//	@date 2026-10-19 20:28:30
+//	@param a []string 原文本的行
+//	@param b []string 新文本的行
+//	@return []diffOp 编辑操作
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	ops = append(ops, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+// myers 使用Myers算法计算最短编辑操作，编辑距离超过maxDiffEdits时整体删除后新增
+//
+//	@author duanzt
+//	@date 2026-10-19 20:29:10
+//	@param a []string 原文本的行
+//	@param b []string 新文本的行
+//	@return []diffOp 编辑操作
+func myers(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	limit := n + m
+	if limit > maxDiffEdits {
+		limit = maxDiffEdits
+	}
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	// trace[d]保存第d步开始前的v（仅k在[-d,d]范围内有效）
+	var trace [][]int
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace, d)
+			}
+		}
+	}
+	ops := make([]diffOp, 0, n+m)
+	for _, line := range a {
+		ops = append(ops, diffOp{'-', line})
+	}
+	for _, line := range b {
+		ops = append(ops, diffOp{'+', line})
+	}
+	return ops
+}
+
+// backtrack 根据每一步的v回溯出编辑操作
+//
+//	@author duanzt
+//	@date 2026-10-19 20:30:02
+//	@param a []string 原文本的行
+//	@param b []string 新文本的行
+//	@param trace [][]int 每一步开始前的v
+//	@param d int 编辑距离
+//	@return []diffOp 编辑操作
+func backtrack(a, b []string, trace [][]int, d int) []diffOp {
+	var reversed []diffOp
+	x, y := len(a), len(b)
+	for ; d > 0; d-- {
+		v := trace[d]
+		at := func(k int) int { return v[k+d] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			reversed = append(reversed, diffOp{' ', a[x]})
+		}
+		if x == prevX {
+			y--
+			reversed = append(reversed, diffOp{'+', b[y]})
+		} else {
+			x--
+			reversed = append(reversed, diffOp{'-', a[x]})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		reversed = append(reversed, diffOp{' ', a[x]})
+	}
+	ops := make([]diffOp, len(reversed))
+	for i := range reversed {
+		ops[i] = reversed[len(reversed)-1-i]
+	}
+	return ops
+}
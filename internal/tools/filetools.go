@@ -2,7 +2,7 @@
  * @Author: duanzt
  * @Date: 2023-07-14 18:21:26
  * @LastEditors: duanzt
- * @LastEditTime: 2023-07-17 13:16:55
+ * @LastEditTime: 2026-10-19 15:05:20
  * @FilePath: filetools.go
  * @Description: 文件处理工具
  *
@@ -14,7 +14,7 @@ import (
 	"io"
 	"io/fs"
 	"os"
-	"strings"
+	"path/filepath"
 )
 
 type filetools struct{}
@@ -81,18 +81,32 @@ func (filetools) PathExists(path string) (bool, error) {
 	return false, err
 }
 
-// CreateFile 创建文件
+// CreateFile 创建文件（父目录不存在时自动创建，文件已存在时清空内容）
 //
 //	@author duanzt
 //	@date 2023-07-17 12:48:55
 //	@receiver f filetools
 //	@param path string 文件路径
+//	@param perm fs.FileMode 文件权限（不受umask影响）
 //	@return *os.File 返回创建完的文件对象
 //	@return error 创建失败时返回
-func (f filetools) CreateFile(path string) (*os.File, error) {
-	// 截取最后一个[/]判断目录是否存在，不存在则进行创建
-	ss := strings.Split(path, "/")
-	dir := ss[len(ss)-2]
+func (f filetools) CreateFile(path string, perm fs.FileMode) (*os.File, error) {
+	return f.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, perm)
+}
+
+// OpenFile 打开文件（父目录不存在时自动创建）
+//
+//	@author duanzt
+//	@date 2026-10-19 13:12:36
+//	@receiver f filetools
+//	@param path string 文件路径
+//	@param flag int 打开方式，例如os.O_RDWR|os.O_CREATE
+//	@param perm fs.FileMode 文件权限（不受umask影响）
+//	@return *os.File 文件对象
+//	@return error 打开失败时返回
+func (f filetools) OpenFile(path string, flag int, perm fs.FileMode) (*os.File, error) {
+	// 判断父目录是否存在，不存在则进行创建
+	dir := filepath.Dir(path)
 	if dirF, err := f.PathExists(dir); err != nil {
 		return nil, err
 	} else if !dirF {
@@ -100,16 +114,35 @@ func (f filetools) CreateFile(path string) (*os.File, error) {
 			return nil, err
 		}
 	}
-
-	// 判断文件是否存在，不存在则进行创建
-	var destFile *os.File
-	if destF, err := f.PathExists(path); err != nil {
+	file, err := os.OpenFile(path, flag, perm.Perm())
+	if err != nil {
 		return nil, err
-	} else if !destF {
-		destFile, err = os.Create(path)
-		if err != nil {
-			return nil, err
-		}
 	}
-	return destFile, nil
+	// OpenFile的权限受umask影响，且对已存在的文件不生效，这里统一修改一次
+	if err := file.Chmod(perm); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+// StatReader 获取流对应的文件信息（流实现了Stat方法时，例如*os.File、*sftp.File）
+//
+//	@author duanzt
+//	@date 2026-10-19 12:18:02
+//	@receiver filetools
+//	@param r io.Reader 流
+//	@return fs.FileInfo 文件信息，无法获取时返回nil
+func (filetools) StatReader(r io.Reader) fs.FileInfo {
+	statter, ok := r.(interface {
+		Stat() (fs.FileInfo, error)
+	})
+	if !ok {
+		return nil
+	}
+	info, err := statter.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		return nil
+	}
+	return info
 }
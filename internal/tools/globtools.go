@@ -0,0 +1,98 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 11:18:44
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 11:18:44
+ * @FilePath: globtools.go
+ * @Description: glob匹配工具（支持**匹配任意层目录）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package tools
+
+import (
+	"path"
+	"strings"
+)
+
+const (
+
+	// globSplit glob路径分隔符
+	globSplit = "/"
+
+	// globAnyDir 匹配任意层目录
+	globAnyDir = "**"
+)
+
+type globtools struct{}
+
+var (
+	GlobTools = globtools{}
+)
+
+// Match 判断相对路径是否匹配glob
+// 不含"/"的glob只匹配文件名（例如*.log匹配a/b/c.log），含"/"的glob匹配完整相对路径，"**"匹配任意层目录
+//
+//	@author duanzt
+//	@date 2026-10-19 11:19:36
+//	@receiver g globtools
+//	@param pattern string glob
+//	@param relPath string 使用"/"分隔的相对路径
+//	@return bool 匹配时返回true（glob不合法时返回false）
+func (g globtools) Match(pattern, relPath string) bool {
+	if !strings.Contains(pattern, globSplit) {
+		if pattern == globAnyDir {
+			return true
+		}
+		ok, err := path.Match(pattern, path.Base(relPath))
+		return err == nil && ok
+	}
+	return g.matchSegments(strings.Split(strings.Trim(pattern, globSplit), globSplit), strings.Split(relPath, globSplit))
+}
+
+// MatchAny 判断相对路径是否匹配任一glob
+//
+//	@author duanzt
+//	@date 2026-10-19 11:20:15
+//	@receiver g globtools
+//	@param patterns []string glob列表
+//	@param relPath string 使用"/"分隔的相对路径
+//	@return bool 匹配任一glob时返回true
+func (g globtools) MatchAny(patterns []string, relPath string) bool {
+	for i := range patterns {
+		if g.Match(patterns[i], relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments 逐级匹配路径
+//
+//	@author duanzt
+//	@date 2026-10-19 11:21:02
+//	@receiver g globtools
+//	@param patterns []string 按"/"拆分的glob
+//	@param names []string 按"/"拆分的路径
+//	@return bool 匹配时返回true
+func (g globtools) matchSegments(patterns, names []string) bool {
+	for len(patterns) > 0 {
+		if patterns[0] == globAnyDir {
+			// **匹配0到多级目录
+			for i := 0; i <= len(names); i++ {
+				if g.matchSegments(patterns[1:], names[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(names) == 0 {
+			return false
+		}
+		if ok, err := path.Match(patterns[0], names[0]); err != nil || !ok {
+			return false
+		}
+		patterns, names = patterns[1:], names[1:]
+	}
+	return len(names) == 0
+}
@@ -2,7 +2,7 @@
  * @Author: duanzt
  * @Date: 2023-07-14 16:38:35
  * @LastEditors: duanzt
- * @LastEditTime: 2023-07-17 18:48:08
+ * @LastEditTime: 2026-10-19 22:14:10
  * @FilePath: iptools.go
  * @Description: ip处理的工具
  *
@@ -13,6 +13,7 @@ package tools
 import (
 	"net"
 	"regexp"
+	"strings"
 )
 
 const (
@@ -23,6 +24,9 @@ const (
 	// ipv6Regex ipv6地址校验正则
 	ipv6Regex = `^(([0-9a-fA-F]{1,4}:){7,7}[0-9a-fA-F]{1,4}|([0-9a-fA-F]{1,4}:){1,7}:|([0-9a-fA-F]{1,4}:){1,6}:([0-9a-fA-F]{1,4}|:)|([0-9a-fA-F]{1,4}:){1,5}(:[0-9a-fA-F]{1,4}){1,2}|([0-9a-fA-F]{1,4}:){1,4}(:[0-9a-fA-F]{1,4}){1,3}|([0-9a-fA-F]{1,4}:){1,3}(:[0-9a-fA-F]{1,4}){1,4}|([0-9a-fA-F]{1,4}:){1,2}(:[0-9a-fA-F]{1,4}){1,5}|[0-9a-fA-F]{1,4}:((:[0-9a-fA-F]{1,4}){1,6})|:((:[0-9a-fA-F]{1,4}){1,7}|:)|fe80:(:[0-9a-fA-F]{0,4}){0,4}%[0-9a-zA-Z]{1,}|::(ffff(:0{1,4}){0,1}:){0,1}((25[0-5]|(2[0-4]|1{0,1}[0-9]){0,1}[0-9]).){3,3}(25[0-5]|(2[0-4]|1{0,1}[0-9]){0,1}[0-9])|([0-9a-fA-F]{1,4}:){1,4}:((25[0-5]|(2[0-4]|1{0,1}[0-9]){0,1}[0-9]).){3,3}(25[0-5]|(2[0-4]|1{0,1}[0-9]){0,1}[0-9]))$`
 
+	// hostnameRegex 主机名校验正则（不能全部由数字及点组成，避免与不合法的ipv4混淆）
+	hostnameRegex = `^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)*[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`
+
 	localhost   = "localhost"
 	localhostIp = "127.0.0.1"
 )
@@ -91,6 +95,21 @@ func (iptools) IsV4(ip string) bool {
 	return err == nil && match
 }
 
+// IsHostname 校验是否是合法的主机名（例如web01.dc1，全部由数字及点组成时不是主机名）
+//
+//	@author duanzt
+//	@date 2026-10-19 21:42:20
+//	@receiver iptools
+//	@param host string 主机名字符串
+//	@return bool 是主机名则返回true
+func (iptools) IsHostname(host string) bool {
+	if strings.Trim(host, "0123456789.") == "" {
+		return false
+	}
+	match, err := regexp.MatchString(hostnameRegex, host)
+	return err == nil && match
+}
+
 // IsV6 校验ip是否是ipv6
 //
 //	@author duanzt
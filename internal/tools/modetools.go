@@ -0,0 +1,273 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 12:05:33
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 17:30:00
+ * @FilePath: modetools.go
+ * @Description: 文件权限处理工具（支持八进制及chmod符号格式，例如0755、u+x,go-w）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package tools
+
+import (
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+const (
+
+	// defaultFileMode 新建文件的默认权限（与umask无关）
+	defaultFileMode fs.FileMode = 0644
+
+	// 各类用户对应的权限位（含特殊权限位）
+	whoUser  uint32 = 04700
+	whoGroup uint32 = 02070
+	whoOther uint32 = 01007
+	whoAll   uint32 = 07777
+)
+
+type modetools struct{}
+
+var (
+	ModeTools = modetools{}
+)
+
+// DefaultFileMode 获取新建文件的默认权限
+//
+//	@author duanzt
+//	@date 2026-10-19 12:06:20
+//	@receiver modetools
+//	@return fs.FileMode 默认权限0644
+func (modetools) DefaultFileMode() fs.FileMode {
+	return defaultFileMode
+}
+
+// IsOctal 判断权限是否为八进制格式（八进制权限与原有权限无关）
+//
+//	@author duanzt
+//	@date 2026-10-19 12:06:51
+//	@receiver modetools
+//	@param mode string 权限字符串
+//	@return bool 八进制格式时返回true
+func (modetools) IsOctal(mode string) bool {
+	if mode == "" || len(mode) > 5 {
+		return false
+	}
+	for _, r := range mode {
+		if r < '0' || r > '7' {
+			return false
+		}
+	}
+	return true
+}
+
+// Parse 在原有权限base的基础上计算新的权限（语义同chmod，但不受umask影响）
+// 支持八进制（0755、755、4755）及符号格式（u+x、go-w、a=r、u=rwx,g=rx,o=、g=u、+X），mode为空时返回base
+//
+//	@author duanzt
+//	@date 2026-10-19 12:07:40
+//	@receiver m modetools
+//	@param mode string 权限字符串
+//	@param base fs.FileMode 原有权限（符号格式的计算基础，判断X时使用其目录标识）
+//	@return fs.FileMode 新的权限（仅包含权限位及setuid、setgid、sticky位）
+//	@return error 权限格式不合法时返回
+func (m modetools) Parse(mode string, base fs.FileMode) (fs.FileMode, error) {
+	mode = strings.TrimSpace(mode)
+	if mode == "" {
+		return fromUnixMode(toUnixMode(base)), nil
+	}
+	if m.IsOctal(mode) {
+		v, err := strconv.ParseUint(mode, 8, 32)
+		if err != nil || v > 07777 {
+			return 0, fmt.Errorf("权限格式不合法: %s", mode)
+		}
+		return fromUnixMode(uint32(v)), nil
+	}
+
+	cur := toUnixMode(base)
+	for _, clause := range strings.Split(mode, ",") {
+		next, err := applyClause(clause, cur, base.IsDir())
+		if err != nil {
+			return 0, fmt.Errorf("权限格式不合法: %s", mode)
+		}
+		cur = next
+	}
+	return fromUnixMode(cur), nil
+}
+
+// applyClause 计算单个符号格式子句（例如ug+rw）
+//
+//	@author duanzt
+//	@date 2026-10-19 12:09:02
+//	@param clause string 符号格式子句
+//	@param cur uint32 当前权限
+//	@param isDir bool 是否为目录（用于X）
+//	@return uint32 计算后的权限
+//	@return error 格式不合法时返回
+func applyClause(clause string, cur uint32, isDir bool) (uint32, error) {
+	// 解析用户部分
+	var who uint32
+	i := 0
+parseWho:
+	for ; i < len(clause); i++ {
+		switch clause[i] {
+		case 'u':
+			who |= whoUser
+		case 'g':
+			who |= whoGroup
+		case 'o':
+			who |= whoOther
+		case 'a':
+			who |= whoAll
+		default:
+			break parseWho
+		}
+	}
+	if who == 0 {
+		who = whoAll
+	}
+	if i == len(clause) {
+		return 0, fmt.Errorf("缺少操作符: %s", clause)
+	}
+
+	// 解析一个或多个操作（例如u+x-w）
+	for i < len(clause) {
+		op := clause[i]
+		if op != '+' && op != '-' && op != '=' {
+			return 0, fmt.Errorf("操作符不合法: %s", clause)
+		}
+		i++
+		var bits uint32
+		for ; i < len(clause) && strings.IndexByte("+-=", clause[i]) < 0; i++ {
+			switch clause[i] {
+			case 'r':
+				bits |= 0444
+			case 'w':
+				bits |= 0222
+			case 'x':
+				bits |= 0111
+			case 'X':
+				if isDir || cur&0111 != 0 {
+					bits |= 0111
+				}
+			case 's':
+				bits |= 06000
+			case 't':
+				bits |= 01000
+			case 'u':
+				bits |= copyBits((cur >> 6) & 07)
+			case 'g':
+				bits |= copyBits((cur >> 3) & 07)
+			case 'o':
+				bits |= copyBits(cur & 07)
+			default:
+				return 0, fmt.Errorf("权限不合法: %s", clause)
+			}
+		}
+		bits &= who
+		switch op {
+		case '+':
+			cur |= bits
+		case '-':
+			cur &^= bits
+		case '=':
+			cur = cur&^who | bits
+		}
+	}
+	return cur, nil
+}
+
+// copyBits 将rwx权限复制到u、g、o三个位置（用于g=u等格式）
+func copyBits(v uint32) uint32 {
+	return v<<6 | v<<3 | v
+}
+
+// toUnixMode 将fs.FileMode转换为unix权限位
+//
+//	@author duanzt
+//	@date 2026-10-19 12:10:15
+//	@param mode fs.FileMode 文件权限
+//	@return uint32 unix权限位（含setuid、setgid、sticky）
+func toUnixMode(mode fs.FileMode) uint32 {
+	v := uint32(mode.Perm())
+	if mode&fs.ModeSetuid != 0 {
+		v |= 04000
+	}
+	if mode&fs.ModeSetgid != 0 {
+		v |= 02000
+	}
+	if mode&fs.ModeSticky != 0 {
+		v |= 01000
+	}
+	return v
+}
+
+// fromUnixMode 将unix权限位转换为fs.FileMode
+//
+//	@author duanzt
+//	@date 2026-10-19 12:10:42
+//	@param v uint32 unix权限位（含setuid、setgid、sticky）
+//	@return fs.FileMode 文件权限
+func fromUnixMode(v uint32) fs.FileMode {
+	mode := fs.FileMode(v & 0777)
+	if v&04000 != 0 {
+		mode |= fs.ModeSetuid
+	}
+	if v&02000 != 0 {
+		mode |= fs.ModeSetgid
+	}
+	if v&01000 != 0 {
+		mode |= fs.ModeSticky
+	}
+	return mode
+}
+
+// ToUnix 将fs.FileMode转换为unix权限位（例如scp协议中的权限）
+//
+//	@author duanzt
+//	@date 2026-10-19 16:40:12
+//	@receiver modetools
+//	@param mode fs.FileMode 文件权限
+//	@return uint32 unix权限位（含setuid、setgid、sticky）
+func (modetools) ToUnix(mode fs.FileMode) uint32 {
+	return toUnixMode(mode)
+}
+
+// FromUnix 将unix权限位转换为fs.FileMode
+//
+//	@author duanzt
+//	@date 2026-10-19 16:40:40
+//	@receiver modetools
+//	@param v uint32 unix权限位（含setuid、setgid、sticky）
+//	@return fs.FileMode 文件权限
+func (modetools) FromUnix(v uint32) fs.FileMode {
+	return fromUnixMode(v)
+}
+
+// Resolve 计算拷贝后目标文件的权限
+// 计算基础：保留源文件权限时为源文件权限，否则为目标文件已有权限，目标文件不存在时为默认权限0644
+//
+//	@author duanzt
+//	@date 2026-10-19 12:22:36
+//	@receiver m modetools
+//	@param mode string 权限字符串（八进制或符号格式，为空时使用计算基础）
+//	@param srcInfo fs.FileInfo 源文件信息（无法获取时为nil）
+//	@param preserve bool 是否保留源文件权限
+//	@param destStat func() (fs.FileInfo, error) 获取目标文件信息的方法（仅在需要时调用）
+//	@return fs.FileMode 目标文件权限
+//	@return error 权限格式不合法时返回
+func (m modetools) Resolve(mode string, srcInfo fs.FileInfo, preserve bool, destStat func() (fs.FileInfo, error)) (fs.FileMode, error) {
+	if m.IsOctal(mode) {
+		return m.Parse(mode, 0)
+	}
+	base := defaultFileMode
+	if preserve && srcInfo != nil {
+		base = srcInfo.Mode()
+	} else if info, err := destStat(); err == nil && info.Mode().IsRegular() {
+		base = info.Mode()
+	}
+	return m.Parse(mode, base)
+}
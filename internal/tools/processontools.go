@@ -2,7 +2,7 @@
  * @Author: duanzt
  * @Date: 2023-07-17 09:28:40
  * @LastEditors: duanzt
- * @LastEditTime: 2023-07-17 14:18:09
+ * @LastEditTime: 2026-10-19 15:29:30
  * @FilePath: processontools.go
  * @Description: 一个简易的进度条工具
  *
@@ -42,8 +42,14 @@ var (
 //	@param totalVale int64 期望大小
 func (processontools) Print(currValue, totalVale int64) {
 
-	// 比例
-	rate := float32((currValue + 1) * 100 / totalVale)
+	// 比例（期望大小为0时视为已完成）
+	rate := float32(100)
+	if totalVale > 0 {
+		rate = float32((currValue + 1) * 100 / totalVale)
+	}
+	if rate > 100 {
+		rate = 100
+	}
 	upperLimit := int(printGraphNumber * rate / 100)
 
 	// [####################################################################################################][100%][|]
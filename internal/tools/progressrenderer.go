@@ -0,0 +1,357 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 15:12:06
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-20 00:03:20
+ * @FilePath: progressrenderer.go
+ * @Description: 多进度条渲染（终端中原地刷新，非终端时定期输出日志行）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package tools
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/duanztop/gossh/internal"
+	"golang.org/x/term"
+)
+
+const (
+
+	// defaultTermWidth 无法获取终端宽度时使用的宽度
+	defaultTermWidth = 80
+
+	// minBarWidth 进度条图形的最小宽度
+	minBarWidth = 10
+
+	// defaultLogInterval 非终端输出时同一进度的最小输出间隔
+	defaultLogInterval = 5 * time.Second
+)
+
+// ProgressRenderer 多进度条渲染（实现internal.IProgress，可被多个传输并发使用）
+// 终端中每个进行中的传输占一行并原地刷新，结束的传输输出最终状态后不再刷新；
+// 非终端（例如重定向到文件、CI日志）时不输出回车及光标控制符，而是定期输出日志行
+type ProgressRenderer struct {
+	mu          sync.Mutex
+	w           io.Writer
+	tty         bool
+	width       func() int
+	logInterval time.Duration
+	active      []*progressBar // 进行中的传输（按开始顺序）
+	bars        map[string]*progressBar
+	lines       int // 上次刷新时输出的行数
+}
+
+// progressBar 单个传输的进度
+type progressBar struct {
+	label   string
+	info    internal.ProgressInfo
+	lastLog time.Time
+}
+
+// NewRenderer 创建多进度条渲染
+//
+//	@author duanzt
+//	@date 2026-10-19 15:13:20
+//	@receiver processontools
+//	@param w io.Writer 输出目标（为终端时原地刷新，否则输出日志行）
+//	@return *ProgressRenderer 多进度条渲染，使用完毕后调用Stop
+func (processontools) NewRenderer(w io.Writer) *ProgressRenderer {
+	r := &ProgressRenderer{
+		w:           w,
+		width:       func() int { return defaultTermWidth },
+		logInterval: defaultLogInterval,
+		bars:        make(map[string]*progressBar),
+	}
+	if f, ok := w.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		r.tty = true
+		r.width = func() int {
+			if width, _, err := term.GetSize(int(f.Fd())); err == nil && width > 0 {
+				return width
+			}
+			return defaultTermWidth
+		}
+	}
+	return r
+}
+
+// SetLogInterval 设置非终端输出时同一进度的最小输出间隔（默认5s）
+//
+//	@author duanzt
+//	@date 2026-10-19 15:14:02
+//	@receiver r *ProgressRenderer
+//	@param interval time.Duration 输出间隔
+//	@return *ProgressRenderer 当前对象
+func (r *ProgressRenderer) SetLogInterval(interval time.Duration) *ProgressRenderer {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.logInterval = interval
+	return r
+}
+
+// WithLabel 获取带前缀的进度报告（例如按主机区分：host1: /opt/app.tar.gz）
+//
+//	@author duanzt
+//	@date 2026-10-19 15:14:40
+//	@receiver r *ProgressRenderer
+//	@param label string 前缀
+//	@return internal.IProgress 进度报告
+func (r *ProgressRenderer) WithLabel(label string) internal.IProgress {
+	return internal.ProgressFunc(func(info internal.ProgressInfo) {
+		r.report(label+": "+info.Name, info)
+	})
+}
+
+// Report 实现internal.IProgress
+//
+//	@author duanzt
+//	@date 2026-10-19 15:15:12
+//	@receiver r *ProgressRenderer
+//	@param info internal.ProgressInfo 传输进度
+func (r *ProgressRenderer) Report(info internal.ProgressInfo) {
+	r.report(info.Name, info)
+}
+
+// Stop 结束渲染（清理终端中未结束的进度行）
+//
+//	@author duanzt
+//	@date 2026-10-19 15:15:40
+//	@receiver r *ProgressRenderer
+func (r *ProgressRenderer) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tty && r.lines > 0 {
+		fmt.Fprint(r.w, "\n")
+	}
+	r.lines = 0
+	r.active = nil
+	r.bars = make(map[string]*progressBar)
+}
+
+// report 更新进度并输出
+func (r *ProgressRenderer) report(label string, info internal.ProgressInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	bar, ok := r.bars[label]
+	if !ok {
+		bar = &progressBar{label: label}
+		r.bars[label] = bar
+		r.active = append(r.active, bar)
+	}
+	bar.info = info
+	if info.Finished {
+		delete(r.bars, label)
+	}
+	if r.tty {
+		r.redraw()
+		return
+	}
+
+	// 非终端：开始、结束时各输出一次，期间按间隔输出
+	now := time.Now()
+	if !ok || info.Finished || now.Sub(bar.lastLog) >= r.logInterval {
+		bar.lastLog = now
+		fmt.Fprintln(r.w, r.formatLine(bar, 0))
+	}
+	if info.Finished {
+		r.removeActive(bar)
+	}
+}
+
+// redraw 终端中重新绘制全部进度行，已结束的进度输出后移出刷新区域
+func (r *ProgressRenderer) redraw() {
+	var b strings.Builder
+	if r.lines > 0 {
+		fmt.Fprintf(&b, "\r\x1b[%dA", r.lines)
+	}
+	width := r.width()
+	var active []*progressBar
+	for _, bar := range r.active {
+		if bar.info.Finished {
+			b.WriteString("\r\x1b[K" + r.formatLine(bar, width) + "\n")
+		}
+	}
+	for _, bar := range r.active {
+		if !bar.info.Finished {
+			b.WriteString("\r\x1b[K" + r.formatLine(bar, width) + "\n")
+			active = append(active, bar)
+		}
+	}
+	// 清理上次多出的行
+	b.WriteString("\x1b[J")
+	r.active = active
+	r.lines = len(active)
+	fmt.Fprint(r.w, b.String())
+}
+
+// removeActive 移出进行中的传输
+func (r *ProgressRenderer) removeActive(bar *progressBar) {
+	for i, item := range r.active {
+		if item == bar {
+			r.active = append(r.active[:i], r.active[i+1:]...)
+			return
+		}
+	}
+}
+
+// formatLine 格式化单行进度，width大于0时绘制进度条图形并适配宽度
+// 例：app.tar.gz [#########           ]  45.2% 12.3MiB/27.0MiB 3.4MiB/s ETA 00:05
+func (r *ProgressRenderer) formatLine(bar *progressBar, width int) string {
+	info := bar.info
+	var stats string
+	switch {
+	case info.Finished && info.Err != nil:
+		stats = fmt.Sprintf("失败 %s: %v", FormatBytes(info.Done), info.Err)
+	case info.Finished:
+		stats = fmt.Sprintf("完成 %s %s/s %s", FormatBytes(info.Done), FormatBytes(int64(info.Rate)), FormatDuration(sinceStart(info)))
+	default:
+		percent := "   ?%"
+		size := FormatBytes(info.Done)
+		if info.Total >= 0 {
+			percent = fmt.Sprintf("%5.1f%%", info.Percent())
+			size += "/" + FormatBytes(info.Total)
+		}
+		eta := "--:--"
+		if info.ETA >= 0 {
+			eta = FormatDuration(info.ETA)
+		}
+		stats = fmt.Sprintf("%s %s %s/s ETA %s", percent, size, FormatBytes(int64(info.Rate)), eta)
+	}
+	if width <= 0 {
+		return bar.label + " " + stats
+	}
+
+	// 宽度分配：标签最多占1/3，剩余给进度条图形
+	labelWidth := width / 3
+	label := truncateLeft(bar.label, labelWidth)
+	if info.Finished {
+		return truncateRight(label+" "+stats, width-1)
+	}
+	graphWidth := width - 1 - DisplayWidth(label) - DisplayWidth(stats) - 4
+	if graphWidth < minBarWidth {
+		return truncateRight(label+" "+stats, width-1)
+	}
+	filled := 0
+	if info.Total > 0 {
+		filled = int(float64(graphWidth) * float64(info.Done) / float64(info.Total))
+	}
+	if filled > graphWidth {
+		filled = graphWidth
+	}
+	return fmt.Sprintf("%s [%s%s] %s", label, strings.Repeat(graph, filled), strings.Repeat(graphBackground, graphWidth-filled), stats)
+}
+
+// sinceStart 根据平均速度估算本次传输耗时
+func sinceStart(info internal.ProgressInfo) time.Duration {
+	if info.Rate <= 0 {
+		return 0
+	}
+	return time.Duration(float64(info.Done) / info.Rate * float64(time.Second))
+}
+
+// FormatBytes 格式化字节数（1024进制，例如1.5MiB）
+//
+//	@author duanzt
+//	@date 2026-10-19 15:18:30
+//	@param n int64 字节数
+//	@return string 格式化结果
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	value := float64(n)
+	units := []string{"KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	i := -1
+	for value >= unit && i < len(units)-1 {
+		value /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f%s", value, units[i])
+}
+
+// FormatDuration 格式化时长（mm:ss，超过1小时为hh:mm:ss）
+//
+//	@author duanzt
+//	@date 2026-10-19 15:19:05
+//	@param d time.Duration 时长
+//	@return string 格式化结果
+func FormatDuration(d time.Duration) string {
+	seconds := int64((d + time.Second/2) / time.Second)
+	if seconds < 0 {
+		seconds = 0
+	}
+	if seconds >= 3600 {
+		return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds%3600/60, seconds%60)
+	}
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
+
+// wideRanges 终端中占2列的东亚宽字符及全角字符范围
+var wideRanges = []struct{ lo, hi rune }{
+	{0x1100, 0x115F}, {0x2E80, 0x303E}, {0x3041, 0x33FF}, {0x3400, 0x4DBF}, {0x4E00, 0x9FFF},
+	{0xA000, 0xA4CF}, {0xAC00, 0xD7A3}, {0xF900, 0xFAFF}, {0xFE30, 0xFE4F}, {0xFF00, 0xFF60},
+	{0xFFE0, 0xFFE6}, {0x1F300, 0x1F64F}, {0x1F900, 0x1F9FF}, {0x20000, 0x2FFFD}, {0x30000, 0x3FFFD},
+}
+
+// runeWidth 字符在终端中占的列数（组合字符为0，东亚宽字符及全角字符为2，其余为1）
+func runeWidth(r rune) int {
+	if unicode.Is(unicode.Mn, r) {
+		return 0
+	}
+	for _, w := range wideRanges {
+		if r >= w.lo && r <= w.hi {
+			return 2
+		}
+	}
+	return 1
+}
+
+// DisplayWidth 计算字符串在终端中占的列数（中日韩文字等宽字符占2列）
+//
+//	@author duanzt
+//	@date 2026-10-20 00:03:20
+//	@param s string 字符串
+//	@return int 列数
+func DisplayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// truncateLeft 超出宽度时保留末尾（文件路径末尾更有辨识度）
+func truncateLeft(s string, width int) string {
+	if DisplayWidth(s) <= width || width < 4 {
+		return s
+	}
+	runes := []rune(s)
+	i, w := len(runes), 3
+	for i > 0 && w+runeWidth(runes[i-1]) <= width {
+		i--
+		w += runeWidth(runes[i])
+	}
+	return "..." + string(runes[i:])
+}
+
+// truncateRight 超出宽度时截断末尾
+func truncateRight(s string, width int) string {
+	if DisplayWidth(s) <= width || width < 0 {
+		return s
+	}
+	runes := []rune(s)
+	i, w := 0, 0
+	for i < len(runes) && w+runeWidth(runes[i]) <= width {
+		w += runeWidth(runes[i])
+		i++
+	}
+	return string(runes[:i])
+}
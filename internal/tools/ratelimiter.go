@@ -0,0 +1,145 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 16:04:20
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-20 00:01:00
+ * @FilePath: ratelimiter.go
+ * @Description: 令牌桶限速（实现internal.ILimiter，速度可在传输过程中调整）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package tools
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// maxLimitWait 单次等待的最长时间（等待期间调整速度时尽快生效）
+const maxLimitWait = 100 * time.Millisecond
+
+type limitertools struct{}
+
+var (
+	LimiterTools = limitertools{}
+)
+
+// RateLimiter 令牌桶限速（并发安全）
+// 令牌按速度持续补充，最多积累burst个，传输前消耗与字节数相同的令牌，不足时等待
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // 速度，单位：byte/s（不大于0表示不限速）
+	burst  float64 // 令牌桶容量，单位：byte
+	tokens float64 // 当前令牌数
+	last   time.Time
+}
+
+// NewRateLimiter 创建令牌桶限速
+//
+//	@author duanzt
+//	@date 2026-10-19 16:05:10
+//	@receiver limitertools
+//	@param rate int64 速度，单位：byte/s（不大于0表示不限速）
+//	@param burst int64 允许突发的字节数（不大于0时使用rate，即最多积累1秒）
+//	@return *RateLimiter 限速
+func (limitertools) NewRateLimiter(rate, burst int64) *RateLimiter {
+	l := &RateLimiter{last: time.Now()}
+	l.SetRate(rate, burst)
+	l.tokens = l.burst
+	return l
+}
+
+// SetRate 调整速度（对正在进行的传输立即生效）
+//
+//	@author duanzt
+//	@date 2026-10-19 16:06:02
+//	@receiver l *RateLimiter
+//	@param rate int64 速度，单位：byte/s（不大于0表示不限速）
+//	@param burst int64 允许突发的字节数（不大于0时使用rate）
+func (l *RateLimiter) SetRate(rate, burst int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(time.Now())
+	if burst <= 0 {
+		burst = rate
+	}
+	l.rate = float64(rate)
+	l.burst = float64(burst)
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
+
+// Rate 获取当前速度
+//
+//	@author duanzt
+//	@date 2026-10-19 16:06:40
+//	@receiver l *RateLimiter
+//	@return int64 速度，单位：byte/s（0表示不限速）
+func (l *RateLimiter) Rate() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rate <= 0 {
+		return 0
+	}
+	return int64(l.rate)
+}
+
+// WaitN 实现internal.ILimiter，阻塞直到允许传输n个字节（n大于burst时分多次获取），ctx取消时立即返回
+//
+//	@author duanzt
+//	@date 2026-10-19 16:07:25
+//	@receiver l *RateLimiter
+//	@param ctx context.Context 上下文（取消时停止等待）
+//	@param n int 字节数
+//	@return error ctx取消时返回ctx.Err()
+func (l *RateLimiter) WaitN(ctx context.Context, n int) error {
+	need := float64(n)
+	for need > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		l.mu.Lock()
+		if l.rate <= 0 {
+			l.mu.Unlock()
+			return nil
+		}
+		now := time.Now()
+		l.refill(now)
+		take := need
+		if take > l.burst {
+			take = l.burst
+		}
+		if l.tokens >= take {
+			l.tokens -= take
+			need -= take
+			l.mu.Unlock()
+			continue
+		}
+		wait := time.Duration((take - l.tokens) / l.rate * float64(time.Second))
+		l.mu.Unlock()
+		if wait > maxLimitWait {
+			wait = maxLimitWait
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// refill 按经过的时间补充令牌
+func (l *RateLimiter) refill(now time.Time) {
+	if elapsed := now.Sub(l.last).Seconds(); elapsed > 0 && l.rate > 0 {
+		l.tokens += elapsed * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+}
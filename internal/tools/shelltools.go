@@ -0,0 +1,32 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 12:13:08
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 12:13:08
+ * @FilePath: shelltools.go
+ * @Description: shell命令拼接工具
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package tools
+
+import (
+	"strings"
+)
+
+type shelltools struct{}
+
+var (
+	ShellTools = shelltools{}
+)
+
+// Quote 使用单引号转义shell参数，防止参数中的空格、$、;等字符被shell解析
+//
+//	@author duanzt
+//	@date 2026-10-19 12:13:40
+//	@receiver shelltools
+//	@param arg string shell参数
+//	@return string 转义后的参数，例如：it's -> 'it'\''s'
+func (shelltools) Quote(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
@@ -2,7 +2,7 @@
  * @Author: duanzt
  * @Date: 2023-07-17 18:27:15
  * @LastEditors: duanzt
- * @LastEditTime: 2023-07-17 18:52:24
+ * @LastEditTime: 2026-10-19 22:14:10
  * @FilePath: sshaddrtools.go
  * @Description: ssh addr工具
  *
@@ -43,11 +43,11 @@ func (s sshAddrtools) SetRightAddr(addr string) (string, error) {
 		return "", errors.New("连接地址不可为空")
 	}
 	ss := strings.Split(addr, addrSplit)
-	// 判断ip是否正确
+	// 判断ip是否正确（也可使用主机名）
 	if ss[0] != localhost {
-		isv4 := IpTools.IsV4(ss[0])
+		isv4 := IpTools.IsV4(ss[0]) || IpTools.IsHostname(ss[0])
 		if !isv4 {
-			return "", errors.New("ip不合法,请使用ipv4地址")
+			return "", errors.New("ip不合法,请使用ipv4地址或主机名")
 		}
 	}
 	if len(ss) == 1 {
@@ -0,0 +1,63 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 12:24:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 12:24:10
+ * @FilePath: usertools.go
+ * @Description: 本机用户及用户组处理工具
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package tools
+
+import (
+	"os/user"
+	"strconv"
+)
+
+type usertools struct{}
+
+var (
+	UserTools = usertools{}
+)
+
+// LookupId 获取本机用户及用户组对应的uid、gid
+//
+//	@author duanzt
+//	@date 2026-10-19 12:24:52
+//	@receiver usertools
+//	@param owner string 用户名或uid，为空时返回-1
+//	@param group string 组名或gid，为空时返回-1
+//	@return int uid
+//	@return int gid
+//	@return error 用户或用户组不存在时返回
+func (usertools) LookupId(owner, group string) (int, int, error) {
+	uid, gid := -1, -1
+	if owner != "" {
+		if id, err := strconv.Atoi(owner); err == nil {
+			uid = id
+		} else {
+			u, err := user.Lookup(owner)
+			if err != nil {
+				return -1, -1, err
+			}
+			if uid, err = strconv.Atoi(u.Uid); err != nil {
+				return -1, -1, err
+			}
+		}
+	}
+	if group != "" {
+		if id, err := strconv.Atoi(group); err == nil {
+			gid = id
+		} else {
+			g, err := user.LookupGroup(group)
+			if err != nil {
+				return -1, -1, err
+			}
+			if gid, err = strconv.Atoi(g.Gid); err != nil {
+				return -1, -1, err
+			}
+		}
+	}
+	return uid, gid, nil
+}
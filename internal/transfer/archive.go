@@ -0,0 +1,537 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 19:52:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-20 00:08:20
+ * @FilePath: archive.go
+ * @Description: 通过文件系统interface创建及解压归档文件（本地连接及远端缺少tar/unzip命令时使用）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package transfer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/duanztop/gossh/internal"
+	"github.com/duanztop/gossh/internal/tools"
+)
+
+// errArchiveName 归档条目的路径不合法（绝对路径或包含..，防止写入目标目录之外）
+var errArchiveName = errors.New("归档条目的路径不合法")
+
+// ArchiveEntryPath 计算条目解压后相对于目标目录的路径（同tar --strip-components，按原始路径的各级去除）
+//
+//	@author duanzt
+//	@date 2026-10-19 19:52:50
+//	@param name string 条目路径
+//	@param strip int 去除的级数
+//	@return string 相对路径
+//	@return bool 去除后为空或为目标根目录时返回false（忽略该条目）
+//	@return error 路径为绝对路径或包含..时返回
+func ArchiveEntryPath(name string, strip int) (string, bool, error) {
+	if name == "" || strings.HasPrefix(name, "/") || strings.ContainsRune(name, 0) {
+		return "", false, fmt.Errorf("%w: %q", errArchiveName, name)
+	}
+	parts := strings.FieldsFunc(name, func(r rune) bool { return r == '/' })
+	if len(parts) <= strip {
+		return "", false, nil
+	}
+	rel := path.Clean(strings.Join(parts[strip:], "/"))
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", false, fmt.Errorf("%w: %q", errArchiveName, name)
+	}
+	return rel, rel != ".", nil
+}
+
+// archiveExtractor 解压归档的上下文
+type archiveExtractor struct {
+	fsys     internal.IFileSystem
+	target   *extractTarget
+	o        *internal.ArchiveOptions
+	manifest *internal.ArchiveManifest
+}
+
+// ExtractArchive 将归档文件解压到目标目录（归档文件及目标目录均位于fsys）
+// 条目路径为绝对路径、包含..或上级路径为符号链接时停止解压并返回异常；文件权限与条目一致（不受umask影响）
+//
+//	@author duanzt
+//	@date 2026-10-19 19:53:40
+//	@param fsys internal.IFileSystem 文件系统
+//	@param archive string 归档文件路径
+//	@param dest string 目标目录（不存在时创建）
+//	@param o *internal.ArchiveOptions 归档配置
+//	@return *internal.ArchiveManifest 已解压的条目清单
+//	@return error 解压异常时返回
+func ExtractArchive(fsys internal.IFileSystem, archive, dest string, o *internal.ArchiveOptions) (*internal.ArchiveManifest, error) {
+	file, err := fsys.OpenFile(archive, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	e := &archiveExtractor{
+		fsys:     fsys,
+		target:   newExtractTarget(fsys, path.Clean(dest), path.Join),
+		o:        o,
+		manifest: &internal.ArchiveManifest{},
+	}
+	if err := e.target.ensureDir("."); err != nil {
+		return e.manifest, err
+	}
+	if o.Format == internal.ArchiveZip {
+		err = e.extractZip(file)
+	} else {
+		err = e.extractTar(file)
+	}
+	if err != nil {
+		return e.manifest, err
+	}
+	return e.manifest, e.target.finishDirs(true, true, func(_ string, err error) error { return err })
+}
+
+// extractTar 解压tar格式
+//
+//	@author duanzt
+//	@date 2026-10-19 19:54:30
+//	@receiver e *archiveExtractor
+//	@param file internal.IFile 归档文件
+//	@return error 解压异常时返回
+func (e *archiveExtractor) extractTar(file internal.IFile) error {
+	r, err := DecompressReader(file, e.o.Format.Compression())
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		rel, ok, err := ArchiveEntryPath(header.Name, e.o.StripComponents)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		mode := tools.ModeTools.FromUnix(uint32(header.Mode) & 07777)
+		uid, gid := e.o.Uid, e.o.Gid
+		if e.o.SameOwner {
+			uid, gid = header.Uid, header.Gid
+			if e.o.Uid >= 0 {
+				uid = e.o.Uid
+			}
+			if e.o.Gid >= 0 {
+				gid = e.o.Gid
+			}
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = e.dir(rel, mode, header.ModTime, uid, gid)
+		case tar.TypeReg:
+			err = e.file(rel, tr, mode, header.ModTime, uid, gid)
+		case tar.TypeSymlink:
+			err = e.symlink(rel, header.Linkname)
+		case tar.TypeLink:
+			err = e.link(rel, header.Linkname, mode, header.ModTime, uid, gid)
+		default:
+			// 设备文件、管道等不解压
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", header.Name, err)
+		}
+	}
+}
+
+// extractZip 解压zip格式（先校验全部条目的路径）
+//
+//	@author duanzt
+//	@date 2026-10-19 19:55:20
+//	@receiver e *archiveExtractor
+//	@param file internal.IFile 归档文件
+//	@return error 解压异常时返回
+func (e *archiveExtractor) extractZip(file internal.IFile) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	zr, err := zip.NewReader(file, info.Size())
+	if err != nil && !errors.Is(err, zip.ErrInsecurePath) {
+		return err
+	}
+	rels := make([]string, len(zr.File))
+	for i, f := range zr.File {
+		rel, ok, err := ArchiveEntryPath(f.Name, e.o.StripComponents)
+		if err != nil {
+			return err
+		}
+		if ok {
+			rels[i] = rel
+		}
+	}
+	for i, f := range zr.File {
+		if rels[i] == "" {
+			continue
+		}
+		if err := e.zipEntry(f, rels[i]); err != nil {
+			return fmt.Errorf("%s: %w", f.Name, err)
+		}
+	}
+	return nil
+}
+
+// zipEntry 解压zip的单个条目
+//
+//	@author duanzt
+//	@date 2026-10-19 19:56:02
+//	@receiver e *archiveExtractor
+//	@param f *zip.File 条目
+//	@param rel string 解压后的相对路径
+//	@return error 解压异常时返回
+func (e *archiveExtractor) zipEntry(f *zip.File, rel string) error {
+	mode := f.Mode()
+	if mode.IsDir() || strings.HasSuffix(f.Name, "/") {
+		return e.dir(rel, mode.Perm(), f.Modified, e.o.Uid, e.o.Gid)
+	}
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	switch {
+	case mode&fs.ModeSymlink != 0:
+		target, err := io.ReadAll(io.LimitReader(r, 4096))
+		if err != nil {
+			return err
+		}
+		return e.symlink(rel, string(target))
+	case mode.IsRegular():
+		return e.file(rel, r, mode&(fs.ModePerm|fs.ModeSetuid|fs.ModeSetgid|fs.ModeSticky), f.Modified, e.o.Uid, e.o.Gid)
+	}
+	return nil
+}
+
+// dir 创建目录条目（权限及修改时间在解压结束后设置）
+//
+//	@author duanzt
+//	@date 2026-10-19 19:56:40
+//	@receiver e *archiveExtractor
+//	@param rel string 相对路径
+//	@param mode fs.FileMode 目录权限
+//	@param modTime time.Time 修改时间
+//	@param uid int 所属用户，小于0时不修改
+//	@param gid int 所属用户组，小于0时不修改
+//	@return error 创建异常时返回
+func (e *archiveExtractor) dir(rel string, mode fs.FileMode, modTime time.Time, uid, gid int) error {
+	if err := e.target.ensureDir(rel); err != nil {
+		return err
+	}
+	if err := e.chown(rel, uid, gid); err != nil {
+		return err
+	}
+	e.target.addDir(rel, mode, modTime)
+	e.manifest.Add(rel, true)
+	return nil
+}
+
+// file 写入普通文件条目（已存在的文件或符号链接先删除，不会跟随链接写入）
+//
+//	@author duanzt
+//	@date 2026-10-19 19:57:20
+//	@receiver e *archiveExtractor
+//	@param rel string 相对路径
+//	@param r io.Reader 文件内容
+//	@param mode fs.FileMode 文件权限
+//	@param modTime time.Time 修改时间
+//	@param uid int 所属用户，小于0时不修改
+//	@param gid int 所属用户组，小于0时不修改
+//	@return error 写入异常时返回
+func (e *archiveExtractor) file(rel string, r io.Reader, mode fs.FileMode, modTime time.Time, uid, gid int) error {
+	target, err := e.target.prepare(rel)
+	if err != nil {
+		return err
+	}
+	file, err := e.fsys.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode.Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := e.fsys.Chmod(target, mode); err != nil {
+		return err
+	}
+	if err := e.chown(rel, uid, gid); err != nil {
+		return err
+	}
+	if err := e.fsys.Chtimes(target, modTime, modTime); err != nil {
+		return err
+	}
+	e.manifest.Add(rel, false)
+	return nil
+}
+
+// symlink 创建符号链接条目
+//
+//	@author duanzt
+//	@date 2026-10-19 19:58:02
+//	@receiver e *archiveExtractor
+//	@param rel string 相对路径
+//	@param linkname string 链接目标
+//	@return error 创建异常时返回
+func (e *archiveExtractor) symlink(rel, linkname string) error {
+	target, err := e.target.prepare(rel)
+	if err != nil {
+		return err
+	}
+	if err := e.fsys.Symlink(linkname, target); err != nil {
+		return err
+	}
+	e.manifest.Add(rel, false)
+	return nil
+}
+
+// link 硬链接条目（文件系统interface不支持创建硬链接，拷贝已解压的链接目标）
+//
+//	@author duanzt
+//	@date 2026-10-19 19:58:40
+//	@receiver e *archiveExtractor
+//	@param rel string 相对路径
+//	@param linkname string 链接目标（归档中的条目路径）
+//	@param mode fs.FileMode 文件权限
+//	@param modTime time.Time 修改时间
+//	@param uid int 所属用户，小于0时不修改
+//	@param gid int 所属用户组，小于0时不修改
+//	@return error 拷贝异常时返回
+func (e *archiveExtractor) link(rel, linkname string, mode fs.FileMode, modTime time.Time, uid, gid int) error {
+	linkRel, ok, err := ArchiveEntryPath(linkname, e.o.StripComponents)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: %q", errArchiveName, linkname)
+	}
+	name, err := e.target.linkTarget(linkRel)
+	if err != nil {
+		return err
+	}
+	src, err := e.fsys.OpenFile(name, os.O_RDONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	return e.file(rel, src, mode, modTime, uid, gid)
+}
+
+// chown 修改所属用户及用户组
+//
+//	@author duanzt
+//	@date 2026-10-19 20:00:40
+//	@receiver e *archiveExtractor
+//	@param rel string 相对路径
+//	@param uid int 所属用户，小于0时不修改
+//	@param gid int 所属用户组，小于0时不修改
+//	@return error 修改异常时返回
+func (e *archiveExtractor) chown(rel string, uid, gid int) error {
+	if uid < 0 && gid < 0 {
+		return nil
+	}
+	return e.fsys.Chown(e.target.path(rel), uid, gid)
+}
+
+// archiveWriter 创建归档的上下文
+type archiveWriter struct {
+	fsys     internal.IFileSystem
+	archive  string // 归档文件路径（位于源目录内时跳过）
+	o        *internal.ArchiveOptions
+	manifest *internal.ArchiveManifest
+	tw       *tar.Writer
+	zw       *zip.Writer
+}
+
+// CreateArchive 将源目录下的内容打包为归档文件（条目路径相对于源目录，不跟随符号链接，设备文件、管道等被跳过）
+//
+//	@author duanzt
+//	@date 2026-10-19 20:02:02
+//	@param fsys internal.IFileSystem 文件系统
+//	@param src string 源目录
+//	@param archive string 归档文件路径（已存在时覆盖）
+//	@param o *internal.ArchiveOptions 归档配置
+//	@return *internal.ArchiveManifest 已打包的条目清单
+//	@return error 打包异常时返回（归档文件被删除）
+func CreateArchive(fsys internal.IFileSystem, src, archive string, o *internal.ArchiveOptions) (manifest *internal.ArchiveManifest, err error) {
+	info, err := fsys.Stat(src)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, &fs.PathError{Op: "archive", Path: src, Err: errors.New("不是目录")}
+	}
+	file, err := fsys.Create(archive)
+	if err != nil {
+		return nil, err
+	}
+	w := &archiveWriter{fsys: fsys, archive: path.Clean(archive), o: o, manifest: &internal.ArchiveManifest{}}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			_ = fsys.Remove(archive)
+		}
+	}()
+
+	if o.Format == internal.ArchiveZip {
+		w.zw = zip.NewWriter(file)
+		if err := w.walk(path.Clean(src), ""); err != nil {
+			return w.manifest, err
+		}
+		return w.manifest, w.zw.Close()
+	}
+	cw, err := CompressWriter(file, o.Format.Compression())
+	if err != nil {
+		return w.manifest, err
+	}
+	w.tw = tar.NewWriter(cw)
+	if err := w.walk(path.Clean(src), ""); err != nil {
+		return w.manifest, err
+	}
+	if err := w.tw.Close(); err != nil {
+		return w.manifest, err
+	}
+	return w.manifest, cw.Close()
+}
+
+// walk 按名称顺序打包目录下的条目
+//
+//	@author duanzt
+//	@date 2026-10-19 20:02:50
+//	@receiver w *archiveWriter
+//	@param dir string 目录路径
+//	@param rel string 相对于源目录的路径（源目录为空）
+//	@return error 打包异常时返回
+func (w *archiveWriter) walk(dir, rel string) error {
+	infos, err := w.fsys.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name() < infos[j].Name() })
+	for _, info := range infos {
+		name := path.Join(dir, info.Name())
+		if name == w.archive {
+			continue
+		}
+		childRel := path.Join(rel, info.Name())
+		if err := w.write(name, childRel, info); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if info.IsDir() {
+			if err := w.walk(name, childRel); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// write 写入单个条目
+//
+//	@author duanzt
+//	@date 2026-10-19 20:03:30
+//	@receiver w *archiveWriter
+//	@param name string 文件路径
+//	@param rel string 相对于源目录的路径
+//	@param info fs.FileInfo 文件信息（不跟随符号链接）
+//	@return error 写入异常时返回
+func (w *archiveWriter) write(name, rel string, info fs.FileInfo) error {
+	mode := info.Mode()
+	link := ""
+	switch {
+	case mode.IsDir(), mode.IsRegular():
+	case mode&fs.ModeSymlink != 0:
+		target, err := w.fsys.Readlink(name)
+		if err != nil {
+			return err
+		}
+		link = target
+	default:
+		return nil
+	}
+
+	var content io.Writer
+	if w.zw != nil {
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = rel
+		if mode.IsDir() {
+			header.Name += "/"
+		} else if mode.IsRegular() {
+			header.Method = zip.Deflate
+		}
+		if content, err = w.zw.CreateHeader(header); err != nil {
+			return err
+		}
+	} else {
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = rel
+		if mode.IsDir() {
+			header.Name += "/"
+		}
+		header.Mode = int64(tools.ModeTools.ToUnix(mode))
+		if w.o.Uid >= 0 {
+			header.Uid, header.Uname = w.o.Uid, ""
+		}
+		if w.o.Gid >= 0 {
+			header.Gid, header.Gname = w.o.Gid, ""
+		}
+		if err := w.tw.WriteHeader(header); err != nil {
+			return err
+		}
+		content = w.tw
+	}
+
+	switch {
+	case link != "":
+		// zip以条目内容保存符号链接目标
+		if w.zw != nil {
+			if _, err := io.WriteString(content, link); err != nil {
+				return err
+			}
+		}
+	case mode.IsRegular():
+		file, err := w.fsys.OpenFile(name, os.O_RDONLY, 0)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		if _, err := io.Copy(content, file); err != nil {
+			return err
+		}
+	}
+	w.manifest.Add(rel, mode.IsDir())
+	return nil
+}
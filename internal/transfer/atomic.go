@@ -0,0 +1,36 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 14:12:30
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 14:12:30
+ * @FilePath: atomic.go
+ * @Description: 原子写入（临时文件+重命名）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package transfer
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/duanztop/gossh/internal"
+)
+
+// TempName 生成原子写入时的临时文件名（需与目标文件在同一目录，保证重命名不跨文件系统）
+// 开启断点续传时名称固定，以便中断后继续写入同一个临时文件
+//
+//	@author duanzt
+//	@date 2026-10-19 14:13:02
+//	@param base string 目标文件名
+//	@param o *internal.CopyOptions 拷贝配置
+//	@return string 临时文件名
+func TempName(base string, o *internal.CopyOptions) string {
+	if o.Resume {
+		return fmt.Sprintf(".%s.gossh.part", base)
+	}
+	var b [6]byte
+	_, _ = rand.Read(b[:])
+	return fmt.Sprintf(".%s.gossh-%s.tmp", base, hex.EncodeToString(b[:]))
+}
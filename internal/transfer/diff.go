@@ -0,0 +1,332 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 20:40:02
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 20:40:02
+ * @FilePath: diff.go
+ * @Description: 比较两个连接上的目录/文件（按摘要判断是否一致，文本文件生成unified差异）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package transfer
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"path"
+	"sort"
+
+	"github.com/duanztop/gossh/internal"
+	"github.com/duanztop/gossh/internal/tools"
+)
+
+// binarySniffSize 判断二进制文件时检查的字节数（同git）
+const binarySniffSize = 8000
+
+// errDiffTypeMismatch 比较的一端为目录而另一端为文件
+var errDiffTypeMismatch = errors.New("比较的两端一个是目录一个是文件")
+
+// DiffFunc 处理比较出的差异（按遍历顺序调用）
+//
+//	@param entry *internal.DiffEntry 有差异的文件
+//	@return error 返回异常时停止比较
+type DiffFunc func(entry *internal.DiffEntry) error
+
+// differ 比较过程的上下文
+type differ struct {
+	old     internal.IConnection
+	new     internal.IConnection
+	o       *internal.DiffOptions
+	report  *internal.DiffReport
+	handler DiffFunc
+}
+
+// Diff 比较两个连接上的目录（或两个文件），两端均为目录时递归比较全部文件，两端均为文件时比较这两个文件
+// 文件按摘要判断是否一致，不一致的文本文件生成unified差异；符号链接按其指向的文件比较，指向目录或已失效的符号链接被跳过
+//
+//	@author duanzt
+//	@date 2026-10-19 20:41:10
+//	@param oldConn internal.IConnection 原端连接
+//	@param newConn internal.IConnection 新端连接
+//	@param oldPath string 原端路径
+//	@param newPath string 新端路径
+//	@param o *internal.DiffOptions 比较配置
+//	@param handler DiffFunc 处理比较出的差异（可为nil）
+//	@return *internal.DiffReport 比较结果（发生异常时为已比较的部分）
+//	@return error 比较异常时返回
+func Diff(oldConn, newConn internal.IConnection, oldPath, newPath string, o *internal.DiffOptions, handler DiffFunc) (*internal.DiffReport, error) {
+	d := &differ{
+		old: oldConn,
+		new: newConn,
+		o:   o,
+		report: &internal.DiffReport{
+			OldAddr: oldConn.GetAddr(),
+			OldRoot: oldPath,
+			NewAddr: newConn.GetAddr(),
+			NewRoot: newPath,
+		},
+		handler: handler,
+	}
+	oldInfo, err := oldConn.Stat(oldPath)
+	if err != nil {
+		return d.report, err
+	}
+	newInfo, err := newConn.Stat(newPath)
+	if err != nil {
+		return d.report, err
+	}
+	switch {
+	case oldInfo.IsDir() && newInfo.IsDir():
+		return d.report, d.diffDir(oldPath, newPath, ".")
+	case !oldInfo.IsDir() && !newInfo.IsDir():
+		return d.report, d.compareFile(oldPath, newPath, path.Base(newPath), oldInfo, newInfo)
+	default:
+		return d.report, &fs.PathError{Op: "diff", Path: newPath, Err: errDiffTypeMismatch}
+	}
+}
+
+// diffDir 比较目录
+//
+//	@author duanzt
+//	@date 2026-10-19 20:42:02
+//	@receiver d *differ
+//	@param oldDir string 原端目录
+//	@param newDir string 新端目录
+//	@param rel string 相对于比较根目录的路径
+//	@return error 比较异常时返回
+func (d *differ) diffDir(oldDir, newDir, rel string) error {
+	oldChildren, err := listDiffDir(d.old, oldDir)
+	if err != nil {
+		return err
+	}
+	newChildren, err := listDiffDir(d.new, newDir)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(oldChildren)+len(newChildren))
+	for name := range oldChildren {
+		names = append(names, name)
+	}
+	for name := range newChildren {
+		if _, ok := oldChildren[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		childRel := path.Join(rel, name)
+		if tools.GlobTools.MatchAny(d.o.Excludes, childRel) {
+			continue
+		}
+		oldChild, newChild := path.Join(oldDir, name), path.Join(newDir, name)
+		oldInfo, newInfo := oldChildren[name], newChildren[name]
+		var err error
+		switch {
+		case oldInfo != nil && newInfo != nil && oldInfo.IsDir() && newInfo.IsDir():
+			err = d.diffDir(oldChild, newChild, childRel)
+		case oldInfo != nil && newInfo != nil && !oldInfo.IsDir() && !newInfo.IsDir():
+			err = d.compareFile(oldChild, newChild, childRel, oldInfo, newInfo)
+		default:
+			// 仅一端存在或两端类型不同：原端的全部文件视为删除，新端的全部文件视为新增
+			if oldInfo != nil {
+				err = d.onlyOneSide(d.old, oldChild, childRel, oldInfo, internal.DiffRemoved)
+			}
+			if err == nil && newInfo != nil {
+				err = d.onlyOneSide(d.new, newChild, childRel, newInfo, internal.DiffAdded)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// onlyOneSide 报告仅一端存在的文件（为目录时报告其中的全部文件）
+//
+//	@author duanzt
+//	@date 2026-10-19 20:43:10
+//	@receiver d *differ
+//	@param conn internal.IConnection 存在该文件的一端
+//	@param name string 文件/目录路径
+//	@param rel string 相对于比较根目录的路径
+//	@param info fs.FileInfo 文件信息
+//	@param status internal.DiffStatus DiffAdded或DiffRemoved
+//	@return error 比较异常时返回
+func (d *differ) onlyOneSide(conn internal.IConnection, name, rel string, info fs.FileInfo, status internal.DiffStatus) error {
+	if info.IsDir() {
+		children, err := listDiffDir(conn, name)
+		if err != nil {
+			return err
+		}
+		names := make([]string, 0, len(children))
+		for child := range children {
+			names = append(names, child)
+		}
+		sort.Strings(names)
+		for _, child := range names {
+			childRel := path.Join(rel, child)
+			if tools.GlobTools.MatchAny(d.o.Excludes, childRel) {
+				continue
+			}
+			if err := d.onlyOneSide(conn, path.Join(name, child), childRel, children[child], status); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	sum, err := conn.Checksum(name, d.o.Hash)
+	if err != nil {
+		return err
+	}
+	entry := &internal.DiffEntry{Status: status, Path: rel}
+	if status == internal.DiffAdded {
+		entry.NewSize, entry.NewSum = info.Size(), sum
+		err = d.unified(entry, "", name)
+	} else {
+		entry.OldSize, entry.OldSum = info.Size(), sum
+		err = d.unified(entry, name, "")
+	}
+	if err != nil {
+		return err
+	}
+	return d.emit(entry)
+}
+
+// compareFile 按摘要比较两端均存在的文件
+//
+//	@author duanzt
+//	@date 2026-10-19 20:44:02
+//	@receiver d *differ
+//	@param oldName string 原端文件路径
+//	@param newName string 新端文件路径
+//	@param rel string 相对于比较根目录的路径
+//	@param oldInfo fs.FileInfo 原端文件信息
+//	@param newInfo fs.FileInfo 新端文件信息
+//	@return error 比较异常时返回
+func (d *differ) compareFile(oldName, newName, rel string, oldInfo, newInfo fs.FileInfo) error {
+	oldSum, err := d.old.Checksum(oldName, d.o.Hash)
+	if err != nil {
+		return err
+	}
+	newSum, err := d.new.Checksum(newName, d.o.Hash)
+	if err != nil {
+		return err
+	}
+	if oldSum == newSum {
+		d.report.Unchanged++
+		return nil
+	}
+	entry := &internal.DiffEntry{
+		Status:  internal.DiffModified,
+		Path:    rel,
+		OldSize: oldInfo.Size(),
+		NewSize: newInfo.Size(),
+		OldSum:  oldSum,
+		NewSum:  newSum,
+	}
+	if err := d.unified(entry, oldName, newName); err != nil {
+		return err
+	}
+	return d.emit(entry)
+}
+
+// unified 读取两端的内容生成unified差异，超过大小限制或为二进制文件时标记为Binary
+//
+//	@author duanzt
+//	@date 2026-10-19 20:45:10
+//	@receiver d *differ
+//	@param entry *internal.DiffEntry 有差异的文件
+//	@param oldName string 原端文件路径（不存在时为空）
+//	@param newName string 新端文件路径（不存在时为空）
+//	@return error 读取异常时返回
+func (d *differ) unified(entry *internal.DiffEntry, oldName, newName string) error {
+	if entry.OldSize > d.o.MaxTextSize || entry.NewSize > d.o.MaxTextSize {
+		entry.Binary = true
+		return nil
+	}
+	var oldText, newText []byte
+	var err error
+	oldLabel, newLabel := "/dev/null", "/dev/null"
+	if oldName != "" {
+		if oldText, err = d.old.ReadFile(oldName); err != nil {
+			return err
+		}
+		oldLabel = "a/" + entry.Path
+	}
+	if newName != "" {
+		if newText, err = d.new.ReadFile(newName); err != nil {
+			return err
+		}
+		newLabel = "b/" + entry.Path
+	}
+	if isBinary(oldText) || isBinary(newText) {
+		entry.Binary = true
+		return nil
+	}
+	entry.Unified = tools.DiffTools.Unified(oldLabel, newLabel, oldText, newText, d.o.Context)
+	return nil
+}
+
+// emit 记录差异并交给handler处理
+//
+//	@author duanzt
+//	@date 2026-10-19 20:45:50
+//	@receiver d *differ
+//	@param entry *internal.DiffEntry 有差异的文件
+//	@return error handler返回的异常
+func (d *differ) emit(entry *internal.DiffEntry) error {
+	d.report.Entries = append(d.report.Entries, entry)
+	if d.handler != nil {
+		return d.handler(entry)
+	}
+	return nil
+}
+
+// listDiffDir 列出目录下参与比较的文件及目录（符号链接替换为其指向的文件信息，跳过指向目录或已失效的符号链接及特殊文件）
+//
+//	@author duanzt
+//	@date 2026-10-19 20:46:30
+//	@param conn internal.IConnection 连接
+//	@param dir string 目录
+//	@return map[string]fs.FileInfo 文件名及文件信息
+//	@return error 读取目录异常时返回
+func listDiffDir(conn internal.IConnection, dir string) (map[string]fs.FileInfo, error) {
+	infos, err := conn.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	children := make(map[string]fs.FileInfo, len(infos))
+	for _, info := range infos {
+		name := info.Name()
+		if info.Mode()&fs.ModeSymlink != 0 {
+			// 不进入符号链接指向的目录，避免循环
+			target, err := conn.Stat(path.Join(dir, name))
+			if err != nil || target.IsDir() {
+				continue
+			}
+			info = target
+		}
+		// 跳过设备、管道等特殊文件
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			continue
+		}
+		children[name] = info
+	}
+	return children, nil
+}
+
+// isBinary 内容的前binarySniffSize个字节中包含NUL时视为二进制
+//
+//	@author duanzt
+//	@date 2026-10-19 20:47:02
+//	@param data []byte 文件内容
+//	@return bool 是否为二进制
+func isBinary(data []byte) bool {
+	if len(data) > binarySniffSize {
+		data = data[:binarySniffSize]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
@@ -0,0 +1,332 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 11:25:16
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:54:10
+ * @FilePath: dircopy.go
+ * @Description: 目录递归拷贝（源端与目标端均通过IFileSystem访问，单文件拷贝由调用方提供）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/duanztop/gossh/internal"
+	"github.com/duanztop/gossh/internal/tools"
+)
+
+const (
+
+	// DefaultDirPerm 未保留源目录权限时新建目录的权限
+	DefaultDirPerm fs.FileMode = 0755
+
+	// MaxDirDepth 跟随符号链接时允许的最大目录层级（无法通过路径识别的循环以此兜底）
+	MaxDirDepth = 255
+)
+
+// ErrSymlinkLoop 跟随符号链接时出现循环
+var ErrSymlinkLoop = errors.New("符号链接指向了其上级目录，已跳过")
+
+// CopyFileFunc 单文件拷贝方法
+//
+//	@param src string 源文件路径
+//	@param dest string 目标文件路径
+//	@param mode string 文件权限（八进制字符串，例如0644）
+//	@return error 拷贝异常时返回
+type CopyFileFunc func(src, dest, mode string) error
+
+// dirCopier 目录拷贝过程的上下文
+type dirCopier struct {
+	srcFS       internal.IFileSystem
+	destFS      internal.IFileSystem
+	copyFile    CopyFileFunc
+	o           *internal.CopyOptions
+	summary     *internal.CopySummary
+	createdDirs map[string]bool // 已创建的目标目录
+}
+
+// CopyDir 递归拷贝目录（src为文件时仅拷贝该文件）
+//
+//	@author duanzt
+//	@date 2026-10-19 11:26:40
+//	@param srcFS internal.IFileSystem 源端文件系统
+//	@param destFS internal.IFileSystem 目标端文件系统
+//	@param src string 源目录
+//	@param dest string 目标目录
+//	@param copyFile CopyFileFunc 单文件拷贝方法
+//	@param o *internal.CopyOptions 拷贝配置
+//	@return *internal.CopySummary 拷贝结果汇总
+//	@return error 未开启FailFast时汇总全部失败文件，开启时返回首个异常
+func CopyDir(srcFS, destFS internal.IFileSystem, src, dest string, copyFile CopyFileFunc, o *internal.CopyOptions) (*internal.CopySummary, error) {
+	d := &dirCopier{
+		srcFS:       srcFS,
+		destFS:      destFS,
+		copyFile:    copyFile,
+		o:           o,
+		summary:     &internal.CopySummary{},
+		createdDirs: make(map[string]bool),
+	}
+	info, err := srcFS.Stat(src)
+	if err != nil {
+		return d.summary, err
+	}
+	if !info.IsDir() {
+		if err := d.copyRegular(src, dest, info); err != nil {
+			return d.summary, err
+		}
+		return d.summary, d.summary.Err()
+	}
+	if err := d.copyDir(src, dest, "", info, []string{path.Clean(src)}); err != nil {
+		return d.summary, err
+	}
+	return d.summary, d.summary.Err()
+}
+
+// copyDir 递归拷贝目录
+//
+//	@author duanzt
+//	@date 2026-10-19 11:28:05
+//	@receiver d *dirCopier
+//	@param src string 源目录
+//	@param dest string 目标目录
+//	@param rel string 相对于拷贝根目录的路径
+//	@param info fs.FileInfo 源目录信息
+//	@param ancestors []string 已进入的源目录（用于发现符号链接循环）
+//	@return error 开启FailFast且发生异常时返回
+func (d *dirCopier) copyDir(src, dest, rel string, info fs.FileInfo, ancestors []string) error {
+	// 配置了include时目录按需创建，避免产生大量空目录
+	if len(d.o.Includes) == 0 {
+		if err := d.ensureDir(dest); err != nil {
+			return d.fail(src, err)
+		}
+	}
+
+	children, err := d.srcFS.ReadDir(src)
+	if err != nil {
+		return d.fail(src, err)
+	}
+	for _, child := range children {
+		childSrc := path.Join(src, child.Name())
+		childDest := path.Join(dest, child.Name())
+		childRel := path.Join(rel, child.Name())
+		if tools.GlobTools.MatchAny(d.o.Excludes, childRel) {
+			d.summary.Skipped++
+			continue
+		}
+
+		childInfo := child
+		if child.Mode()&fs.ModeSymlink != 0 {
+			switch d.o.Symlink {
+			case internal.SymlinkSkip:
+				d.summary.Skipped++
+				continue
+			case internal.SymlinkPreserve:
+				if err := d.copySymlink(childSrc, childDest, childRel); err != nil {
+					return err
+				}
+				continue
+			default:
+				if childInfo, err = d.srcFS.Stat(childSrc); err != nil {
+					if err := d.fail(childSrc, err); err != nil {
+						return err
+					}
+					continue
+				}
+			}
+		}
+
+		switch {
+		case childInfo.IsDir():
+			childAncestors := append(append(make([]string, 0, len(ancestors)+2), ancestors...), path.Clean(childSrc))
+			if child.Mode()&fs.ModeSymlink != 0 {
+				target, err := d.resolveLink(childSrc)
+				if err == nil && (IsAncestor(ancestors, target) || len(ancestors) > MaxDirDepth) {
+					err = ErrSymlinkLoop
+				}
+				if err != nil {
+					if err := d.fail(childSrc, err); err != nil {
+						return err
+					}
+					continue
+				}
+				childAncestors = append(childAncestors, target)
+			}
+			if err := d.copyDir(childSrc, childDest, childRel, childInfo, childAncestors); err != nil {
+				return err
+			}
+		case childInfo.Mode().IsRegular():
+			if len(d.o.Includes) > 0 && !tools.GlobTools.MatchAny(d.o.Includes, childRel) {
+				d.summary.Skipped++
+				continue
+			}
+			if err := d.copyRegular(childSrc, childDest, childInfo); err != nil {
+				return err
+			}
+		default:
+			// 设备文件、管道、socket等无法拷贝
+			d.summary.Skipped++
+		}
+	}
+
+	if !d.createdDirs[dest] {
+		return nil
+	}
+	if d.o.PreserveMode {
+		if err := d.destFS.Chmod(dest, info.Mode().Perm()); err != nil {
+			return d.fail(src, err)
+		}
+	}
+	if d.o.PreserveTimes {
+		if err := d.destFS.Chtimes(dest, info.ModTime(), info.ModTime()); err != nil {
+			return d.fail(src, err)
+		}
+	}
+	return nil
+}
+
+// copyRegular 拷贝普通文件
+//
+//	@author duanzt
+//	@date 2026-10-19 11:30:12
+//	@receiver d *dirCopier
+//	@param src string 源文件
+//	@param dest string 目标文件
+//	@param info fs.FileInfo 源文件信息
+//	@return error 开启FailFast且发生异常时返回
+func (d *dirCopier) copyRegular(src, dest string, info fs.FileInfo) error {
+	if err := d.ensureDir(path.Dir(dest)); err != nil {
+		return d.fail(src, err)
+	}
+	// 与scp一致，新文件沿用源文件权限，已存在的文件未开启PreserveMode时保持原权限（单文件拷贝负责设置权限）
+	mode := ""
+	if _, err := d.destFS.Lstat(dest); d.o.PreserveMode || err != nil {
+		mode = fmt.Sprintf("%04o", info.Mode().Perm())
+	}
+	if err := d.copyFile(src, dest, mode); err != nil {
+		return d.fail(src, err)
+	}
+	if d.o.PreserveTimes {
+		if err := d.destFS.Chtimes(dest, info.ModTime(), info.ModTime()); err != nil {
+			return d.fail(src, err)
+		}
+	}
+	d.summary.Files++
+	d.summary.Bytes += info.Size()
+	return nil
+}
+
+// copySymlink 在目标端重建符号链接
+//
+//	@author duanzt
+//	@date 2026-10-19 11:31:26
+//	@receiver d *dirCopier
+//	@param src string 源符号链接
+//	@param dest string 目标符号链接
+//	@param rel string 相对于拷贝根目录的路径
+//	@return error 开启FailFast且发生异常时返回
+func (d *dirCopier) copySymlink(src, dest, rel string) error {
+	if len(d.o.Includes) > 0 && !tools.GlobTools.MatchAny(d.o.Includes, rel) {
+		d.summary.Skipped++
+		return nil
+	}
+	target, err := d.srcFS.Readlink(src)
+	if err != nil {
+		return d.fail(src, err)
+	}
+	if err := d.ensureDir(path.Dir(dest)); err != nil {
+		return d.fail(src, err)
+	}
+	// 目标端已存在同名文件时先删除，保证重复拷贝的结果一致
+	if _, err := d.destFS.Lstat(dest); err == nil {
+		if err := d.destFS.Remove(dest); err != nil {
+			return d.fail(src, err)
+		}
+	}
+	if err := d.destFS.Symlink(target, dest); err != nil {
+		return d.fail(src, err)
+	}
+	d.summary.Symlinks++
+	return nil
+}
+
+// ensureDir 创建目标目录（已创建过的目录不再重复创建）
+//
+//	@author duanzt
+//	@date 2026-10-19 11:32:40
+//	@receiver d *dirCopier
+//	@param dir string 目标目录
+//	@return error 创建异常时返回
+func (d *dirCopier) ensureDir(dir string) error {
+	if d.createdDirs[dir] {
+		return nil
+	}
+	if _, err := d.destFS.Stat(dir); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		if err := d.destFS.MkdirAll(dir, DefaultDirPerm); err != nil {
+			return err
+		}
+		d.summary.Dirs++
+	}
+	d.createdDirs[dir] = true
+	return nil
+}
+
+// resolveLink 获取符号链接指向的路径
+//
+//	@author duanzt
+//	@date 2026-10-19 11:33:21
+//	@receiver d *dirCopier
+//	@param link string 符号链接路径
+//	@return string 链接指向的路径（已转换为绝对路径）
+//	@return error 读取异常时返回
+func (d *dirCopier) resolveLink(link string) (string, error) {
+	target, err := d.srcFS.Readlink(link)
+	if err != nil {
+		return "", err
+	}
+	if !path.IsAbs(target) {
+		target = path.Join(path.Dir(link), target)
+	}
+	return path.Clean(target), nil
+}
+
+// fail 记录拷贝异常
+//
+//	@author duanzt
+//	@date 2026-10-19 11:34:02
+//	@receiver d *dirCopier
+//	@param src string 源文件路径
+//	@param err error 异常信息
+//	@return error 开启FailFast时返回该异常，否则返回nil继续拷贝
+func (d *dirCopier) fail(src string, err error) error {
+	copyErr := &internal.CopyError{Path: src, Err: err}
+	d.summary.Errors = append(d.summary.Errors, copyErr)
+	if d.o.FailFast {
+		return copyErr
+	}
+	return nil
+}
+
+// IsAncestor 判断target是否为已进入的目录或其上级目录
+//
+//	@author duanzt
+//	@date 2026-10-19 11:34:40
+//	@param ancestors []string 已进入的目录
+//	@param target string 符号链接指向的目录
+//	@return bool 是上级目录时返回true
+func IsAncestor(ancestors []string, target string) bool {
+	for _, ancestor := range ancestors {
+		if ancestor == target || strings.HasPrefix(ancestor, strings.TrimSuffix(target, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,221 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-20 00:06:00
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-20 00:06:00
+ * @FilePath: extract.go
+ * @Description: 解包到目标目录的路径安全处理（tar流解包及归档解压共用）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"time"
+)
+
+// extractFS 解包时使用的文件系统操作（internal.IFileSystem的子集）
+type extractFS interface {
+	Stat(name string) (fs.FileInfo, error)
+	Lstat(name string) (fs.FileInfo, error)
+	Remove(name string) error
+	Mkdir(name string, perm fs.FileMode) error
+	MkdirAll(name string, perm fs.FileMode) error
+	Chmod(name string, mode fs.FileMode) error
+	Chtimes(name string, atime, mtime time.Time) error
+}
+
+// osFS 本地文件系统（直接调用os的同名方法）
+type osFS struct{}
+
+func (osFS) Stat(name string) (fs.FileInfo, error)        { return os.Stat(name) }
+func (osFS) Lstat(name string) (fs.FileInfo, error)       { return os.Lstat(name) }
+func (osFS) Remove(name string) error                     { return os.Remove(name) }
+func (osFS) Mkdir(name string, perm fs.FileMode) error    { return os.Mkdir(name, perm) }
+func (osFS) MkdirAll(name string, perm fs.FileMode) error { return os.MkdirAll(name, perm) }
+func (osFS) Chmod(name string, mode fs.FileMode) error    { return os.Chmod(name, mode) }
+func (osFS) Chtimes(name string, atime, mtime time.Time) error {
+	return os.Chtimes(name, atime, mtime)
+}
+
+// extractDir 解包出的目录（解包结束后设置权限及修改时间，避免只读目录无法写入子文件）
+type extractDir struct {
+	rel     string
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+// extractTarget 解包的目标目录：条目只能写入目标目录之内，已存在的各级上级路径必须是目录（不跟随符号链接，目标根目录除外）
+type extractTarget struct {
+	fsys    extractFS
+	dest    string
+	join    func(elem ...string) string // 路径拼接（本地为filepath.Join，文件系统interface为path.Join）
+	checked map[string]bool             // 已确认为目录（非符号链接）的相对路径
+	created map[string]bool             // 本次新建的目录
+	dirs    []*extractDir
+}
+
+// newExtractTarget 创建解包的目标目录
+//
+//	@author duanzt
+//	@date 2026-10-20 00:06:00
+//	@param fsys extractFS 文件系统
+//	@param dest string 目标目录（已清理）
+//	@param join func(elem ...string) string 路径拼接
+//	@return *extractTarget 目标目录
+func newExtractTarget(fsys extractFS, dest string, join func(elem ...string) string) *extractTarget {
+	return &extractTarget{fsys: fsys, dest: dest, join: join, checked: make(map[string]bool), created: make(map[string]bool)}
+}
+
+// path 计算相对路径对应的目标路径
+//
+//	@author duanzt
+//	@date 2026-10-20 00:06:20
+//	@receiver t *extractTarget
+//	@param rel string 相对于目标目录的路径（/分隔）
+//	@return string 目标路径
+func (t *extractTarget) path(rel string) string {
+	return t.join(t.dest, rel)
+}
+
+// ensureDir 逐级创建目标目录，已存在的各级路径必须是目录（不跟随符号链接，目标根目录除外）
+//
+//	@author duanzt
+//	@date 2026-10-20 00:06:40
+//	@receiver t *extractTarget
+//	@param rel string 相对于目标目录的路径（"."表示目标根目录）
+//	@return error 创建异常或路径不安全时返回
+func (t *extractTarget) ensureDir(rel string) error {
+	if t.checked[rel] {
+		return nil
+	}
+	if rel == "." {
+		if _, err := t.fsys.Stat(t.dest); err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			if err := t.fsys.MkdirAll(t.dest, DefaultDirPerm); err != nil {
+				return err
+			}
+			t.created[rel] = true
+		}
+		t.checked[rel] = true
+		return nil
+	}
+	if err := t.ensureDir(path.Dir(rel)); err != nil {
+		return err
+	}
+	dir := t.path(rel)
+	info, err := t.fsys.Lstat(dir)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		if err := t.fsys.Mkdir(dir, DefaultDirPerm); err != nil {
+			return err
+		}
+		t.created[rel] = true
+	case err != nil:
+		return err
+	case !info.IsDir():
+		return fmt.Errorf("%w: %s", errTarUnsafe, dir)
+	}
+	t.checked[rel] = true
+	return nil
+}
+
+// prepare 创建上级目录并删除已存在的同名文件/符号链接（之后写入的文件不会跟随链接或通过硬链接写入）
+//
+//	@author duanzt
+//	@date 2026-10-20 00:07:00
+//	@receiver t *extractTarget
+//	@param rel string 相对于目标目录的路径
+//	@return string 目标路径
+//	@return error 上级路径不安全、已存在同名目录或删除异常时返回
+func (t *extractTarget) prepare(rel string) (string, error) {
+	if err := t.ensureDir(path.Dir(rel)); err != nil {
+		return "", err
+	}
+	target := t.path(rel)
+	if info, err := t.fsys.Lstat(target); err == nil {
+		if info.IsDir() {
+			return "", fmt.Errorf("%w: %s", errTarUnsafe, target)
+		}
+		if err := t.fsys.Remove(target); err != nil {
+			return "", err
+		}
+	}
+	return target, nil
+}
+
+// linkTarget 校验硬链接的目标：必须是已解包的普通文件，且上级路径不经过符号链接（防止链接到目标目录之外的文件）
+//
+//	@author duanzt
+//	@date 2026-10-20 00:07:20
+//	@receiver t *extractTarget
+//	@param linkRel string 链接目标相对于目标目录的路径
+//	@return string 链接目标路径
+//	@return error 链接目标不安全或不是普通文件时返回
+func (t *extractTarget) linkTarget(linkRel string) (string, error) {
+	if err := t.ensureDir(path.Dir(linkRel)); err != nil {
+		return "", err
+	}
+	name := t.path(linkRel)
+	info, err := t.fsys.Lstat(name)
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%w: %s", errTarUnsafe, name)
+	}
+	return name, nil
+}
+
+// addDir 记录解包出的目录（权限及修改时间在finishDirs中设置）
+//
+//	@author duanzt
+//	@date 2026-10-20 00:07:40
+//	@receiver t *extractTarget
+//	@param rel string 相对于目标目录的路径
+//	@param mode fs.FileMode 目录权限
+//	@param modTime time.Time 修改时间
+func (t *extractTarget) addDir(rel string, mode fs.FileMode, modTime time.Time) {
+	t.dirs = append(t.dirs, &extractDir{rel: rel, mode: mode, modTime: modTime})
+}
+
+// finishDirs 为本次新建的目录设置权限及修改时间（逆序处理，子目录先于上级目录）
+//
+//	@author duanzt
+//	@date 2026-10-20 00:08:00
+//	@receiver t *extractTarget
+//	@param mode bool 是否设置权限
+//	@param times bool 是否设置修改时间
+//	@param fail func(rel string, err error) error 异常处理（返回nil时继续处理）
+//	@return error 异常处理返回的异常
+func (t *extractTarget) finishDirs(mode, times bool, fail func(rel string, err error) error) error {
+	for i := len(t.dirs) - 1; i >= 0; i-- {
+		dir := t.dirs[i]
+		if !t.created[dir.rel] {
+			continue
+		}
+		name := t.path(dir.rel)
+		if mode {
+			if err := t.fsys.Chmod(name, dir.mode); err != nil {
+				if err := fail(dir.rel, err); err != nil {
+					return err
+				}
+			}
+		}
+		if times {
+			if err := t.fsys.Chtimes(name, dir.modTime, dir.modTime); err != nil {
+				if err := fail(dir.rel, err); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 19:25:02
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 19:25:02
+ * @FilePath: find.go
+ * @Description: 遍历文件系统查找文件（本地连接及不支持find命令的远程连接使用）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package transfer
+
+import (
+	"context"
+	"path"
+	"sort"
+
+	"github.com/duanztop/gossh/internal"
+)
+
+// Find 遍历根目录查找满足条件的文件（同find -H：跟随根目录本身的符号链接，不跟随子项的符号链接）
+// 无法读取的子目录会被跳过，结果按路径排序
+//
+//	@author duanzt
+//	@date 2026-10-19 19:25:40
+//	@param ctx context.Context 上下文（取消时停止遍历）
+//	@param fsys internal.IFileSystem 文件系统
+//	@param root string 查找的根目录
+//	@param o *internal.FindOptions 查找条件
+//	@return []*internal.FindEntry 查找到的文件
+//	@return error 根目录不存在、无法读取或ctx取消时返回
+func Find(ctx context.Context, fsys internal.IFileSystem, root string, o *internal.FindOptions) ([]*internal.FindEntry, error) {
+	info, err := fsys.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	var entries []*internal.FindEntry
+	visit := func(entry *internal.FindEntry) {
+		if entry.Depth >= o.MinDepth && o.Match(entry.FileInfo) {
+			entries = append(entries, entry)
+		}
+	}
+	var walk func(dir string, depth int) error
+	walk = func(dir string, depth int) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		infos, err := fsys.ReadDir(dir)
+		if err != nil {
+			if depth == 0 {
+				return err
+			}
+			return nil
+		}
+		for _, child := range infos {
+			entry := &internal.FindEntry{Path: path.Join(dir, child.Name()), Depth: depth + 1, FileInfo: child}
+			visit(entry)
+			if child.IsDir() && (o.MaxDepth < 0 || depth+1 < o.MaxDepth) {
+				if err := walk(entry.Path, depth+1); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	visit(&internal.FindEntry{Path: root, FileInfo: info})
+	if info.IsDir() && o.MaxDepth != 0 {
+		if err := walk(root, 0); err != nil {
+			return nil, err
+		}
+	}
+	SortFindEntries(entries)
+	return entries, nil
+}
+
+// SortFindEntries 按路径排序查找结果
+//
+//	@author duanzt
+//	@date 2026-10-19 19:26:30
+//	@param entries []*internal.FindEntry 查找结果
+func SortFindEntries(entries []*internal.FindEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+}
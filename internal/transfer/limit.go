@@ -0,0 +1,142 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 16:12:40
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-20 00:01:00
+ * @FilePath: limit.go
+ * @Description: 传输限速（按写入目标文件的字节数限速，上传及下载共用）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package transfer
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/duanztop/gossh/internal"
+)
+
+// limitPiece 限速时单次写入的最大字节数（避免大块写入造成突发）
+const limitPiece = 32 << 10
+
+// ConnLimiter 连接级别的限速（嵌入connection，实现IConnection.SetRateLimiter）
+type ConnLimiter struct {
+	mu      sync.Mutex
+	limiter internal.ILimiter
+}
+
+// SetRateLimiter 设置连接的限速，对之后开始的拷贝生效
+//
+//	@author duanzt
+//	@date 2026-10-19 16:13:30
+//	@receiver c *ConnLimiter
+//	@param limiter internal.ILimiter 限速（为nil表示不限速）
+func (c *ConnLimiter) SetRateLimiter(limiter internal.ILimiter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.limiter = limiter
+}
+
+// CopyOptions 根据配置项生成拷贝配置（包含连接的限速）
+//
+//	@author duanzt
+//	@date 2026-10-19 16:14:12
+//	@receiver c *ConnLimiter
+//	@param opts []internal.CopyOption 配置项
+//	@return *internal.CopyOptions 拷贝配置
+func (c *ConnLimiter) CopyOptions(opts []internal.CopyOption) *internal.CopyOptions {
+	o := internal.NewCopyOptions(opts...)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.limiter != nil {
+		o.Limiters = append(o.Limiters, c.limiter)
+	}
+	return o
+}
+
+// LimitWriter 包装目标，写入前等待全部限速（ctx取消时写入返回ctx.Err()）
+//
+//	@author duanzt
+//	@date 2026-10-19 16:15:02
+//	@param ctx context.Context 上下文
+//	@param w io.Writer 目标
+//	@param limiters []internal.ILimiter 限速
+//	@return io.Writer 包装后的目标（未限速时原样返回）
+func LimitWriter(ctx context.Context, w io.Writer, limiters []internal.ILimiter) io.Writer {
+	if len(limiters) == 0 {
+		return w
+	}
+	return &limitWriter{ctx: ctx, w: w, limiters: limiters}
+}
+
+// limitWriter 限速写入
+type limitWriter struct {
+	ctx      context.Context
+	w        io.Writer
+	limiters []internal.ILimiter
+}
+
+// Write 实现io.Writer，按limitPiece拆分后逐块等待限速并写入
+func (l *limitWriter) Write(p []byte) (int, error) {
+	var written int
+	for len(p) > 0 {
+		piece := p
+		if len(piece) > limitPiece {
+			piece = piece[:limitPiece]
+		}
+		if err := waitLimiters(l.ctx, l.limiters, len(piece)); err != nil {
+			return written, err
+		}
+		n, err := l.w.Write(piece)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// writeAtLimited 按limitPiece拆分后逐块等待限速并写入指定位置
+//
+//	@author duanzt
+//	@date 2026-10-19 16:16:20
+//	@param ctx context.Context 上下文
+//	@param dst io.WriterAt 目标文件
+//	@param b []byte 内容
+//	@param off int64 写入位置
+//	@param limiters []internal.ILimiter 限速
+//	@return error 写入异常或ctx取消时返回
+func writeAtLimited(ctx context.Context, dst io.WriterAt, b []byte, off int64, limiters []internal.ILimiter) error {
+	if len(limiters) == 0 {
+		_, err := dst.WriteAt(b, off)
+		return err
+	}
+	for len(b) > 0 {
+		piece := b
+		if len(piece) > limitPiece {
+			piece = piece[:limitPiece]
+		}
+		if err := waitLimiters(ctx, limiters, len(piece)); err != nil {
+			return err
+		}
+		if _, err := dst.WriteAt(piece, off); err != nil {
+			return err
+		}
+		b = b[len(piece):]
+		off += int64(len(piece))
+	}
+	return nil
+}
+
+// waitLimiters 依次等待全部限速，ctx取消时返回ctx.Err()
+func waitLimiters(ctx context.Context, limiters []internal.ILimiter, n int) error {
+	for _, limiter := range limiters {
+		if err := limiter.WaitN(ctx, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
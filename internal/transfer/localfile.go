@@ -0,0 +1,110 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 14:15:20
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 15:05:20
+ * @FilePath: localfile.go
+ * @Description: 将流写入本地文件（本地拷贝及远端下载共用）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package transfer
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/duanztop/gossh/internal"
+	"github.com/duanztop/gossh/internal/tools"
+)
+
+// WriteLocal 将流写入本地文件，并按配置校验摘要、设置权限及所属用户（支持断点续传及原子写入）
+//
+//	@author duanzt
+//	@date 2026-10-19 14:16:05
+//	@param src io.Reader 源
+//	@param dest string 本地目标文件地址
+//	@param perm fs.FileMode 目标文件权限
+//	@param o *internal.CopyOptions 拷贝配置
+//	@param tracker *Tracker 进度跟踪（为nil时不跟踪，由调用方结束）
+//	@return error 拷贝异常时返回
+func WriteLocal(src io.Reader, dest string, perm fs.FileMode, o *internal.CopyOptions, tracker *Tracker) (err error) {
+	target := dest
+	if o.Atomic {
+		target = filepath.Join(filepath.Dir(dest), TempName(filepath.Base(dest), o))
+		defer func() {
+			if err != nil && !o.Resume {
+				_ = os.Remove(target)
+			}
+		}()
+	}
+	file, err := tools.FileTools.OpenFile(target, OpenFlag(o), perm)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	sum, err := WriteStream(file, src, o, tracker)
+	if err != nil {
+		return err
+	}
+	if o.Atomic {
+		if err := file.Sync(); err != nil {
+			return err
+		}
+	}
+	if o.Verify != "" {
+		if err := Verify(target, o.Verify, sum, func() (string, error) {
+			return FileSum(target, o.Verify)
+		}); err != nil {
+			return err
+		}
+	}
+	// 写入完成后再修改权限，文件已存在或受umask影响时创建权限不生效
+	if err := file.Chmod(perm); err != nil {
+		return err
+	}
+	if o.Owner != "" || o.Group != "" {
+		uid, gid, err := tools.UserTools.LookupId(o.Owner, o.Group)
+		if err != nil {
+			return err
+		}
+		if err := file.Chown(uid, gid); err != nil {
+			return err
+		}
+	}
+	if !o.Atomic {
+		return nil
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if o.Backup != "" {
+		if err := backupLocal(dest, dest+o.Backup); err != nil {
+			return err
+		}
+	}
+	return os.Rename(target, dest)
+}
+
+// backupLocal 备份本地文件（优先使用硬链接，保证备份期间目标文件一直存在）
+//
+//	@author duanzt
+//	@date 2026-10-19 14:18:12
+//	@param name string 文件路径
+//	@param backup string 备份文件路径
+//	@return error 备份异常时返回
+func backupLocal(name, backup string) error {
+	if _, err := os.Lstat(name); os.IsNotExist(err) {
+		return nil
+	}
+	if err := os.Remove(backup); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Link(name, backup); err == nil {
+		return nil
+	}
+	return os.Rename(name, backup)
+}
@@ -0,0 +1,151 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 15:44:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-20 00:01:00
+ * @FilePath: parallel.go
+ * @Description: 并行分块传输（按分块并发读取源并写入目标文件的对应位置）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package transfer
+
+import (
+	"context"
+	"encoding/hex"
+	"hash"
+	"io"
+	"sync"
+
+	"github.com/duanztop/gossh/internal"
+)
+
+// parallelSource 判断是否使用并行分块传输
+// 需要开启并行、非断点续传、源支持随机读取且大小超过一个分块
+//
+//	@author duanzt
+//	@date 2026-10-19 15:45:02
+//	@param src io.Reader 源
+//	@param o *internal.CopyOptions 拷贝配置
+//	@param offset int64 续传起始位置
+//	@param total int64 源大小（未知时为-1）
+//	@return io.ReaderAt 源的随机读取方式
+//	@return bool 是否使用并行分块传输
+func parallelSource(src io.Reader, o *internal.CopyOptions, offset, total int64) (io.ReaderAt, bool) {
+	if o.Parallel < 2 || o.ChunkSize < 1 || o.Resume || offset != 0 || total <= o.ChunkSize {
+		return nil, false
+	}
+	ra, ok := src.(io.ReaderAt)
+	return ra, ok
+}
+
+// writeParallel 按分块并发读取源并写入目标文件的对应位置
+// 开启校验时各分块写入后按顺序计算摘要（等待前一分块完成），不需要重新读取源
+//
+//	@author duanzt
+//	@date 2026-10-19 15:46:20
+//	@param dst io.WriterAt 目标文件
+//	@param src io.ReaderAt 源
+//	@param size int64 源大小
+//	@param o *internal.CopyOptions 拷贝配置
+//	@param tracker *Tracker 进度跟踪（为nil时不跟踪）
+//	@return string 开启校验时返回源内容的摘要（十六进制）
+//	@return error 任一分块异常时返回（其余分块不再开始）
+func writeParallel(dst io.WriterAt, src io.ReaderAt, size int64, o *internal.CopyOptions, tracker *Tracker) (string, error) {
+	var h hash.Hash
+	if o.Verify != "" {
+		var err error
+		if h, err = o.Verify.New(); err != nil {
+			return "", err
+		}
+	}
+	chunks := (size + o.ChunkSize - 1) / o.ChunkSize
+	workers := o.Parallel
+	if int64(workers) > chunks {
+		workers = int(chunks)
+	}
+
+	var (
+		mu       sync.Mutex
+		cond     = sync.NewCond(&mu)
+		next     int64 // 下一个待传输的分块
+		hashed   int64 // 已计算摘要的分块数
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, o.ChunkSize)
+			for {
+				mu.Lock()
+				if firstErr != nil || next >= chunks {
+					mu.Unlock()
+					return
+				}
+				index := next
+				next++
+				mu.Unlock()
+
+				chunk, err := copyChunk(o.Ctx(), dst, src, buf, index*o.ChunkSize, size, o.Limiters)
+				if err == nil {
+					tracker.Add(int64(len(chunk)))
+				}
+
+				mu.Lock()
+				if err == nil && h != nil {
+					// 分块按顺序分配，前一分块一定已被其他goroutine领取，不会死锁
+					for hashed != index && firstErr == nil {
+						cond.Wait()
+					}
+					if firstErr == nil {
+						h.Write(chunk)
+						hashed++
+						cond.Broadcast()
+					}
+				}
+				if err != nil && firstErr == nil {
+					firstErr = err
+					cond.Broadcast()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil || h == nil {
+		return "", firstErr
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// copyChunk 读取源的一个分块并写入目标文件的相同位置
+//
+//	@author duanzt
+//	@date 2026-10-19 15:48:05
+//	@param ctx context.Context 上下文（取消时停止等待限速）
+//	@param dst io.WriterAt 目标文件
+//	@param src io.ReaderAt 源
+//	@param buf []byte 缓冲区（分块大小）
+//	@param off int64 分块起始位置
+//	@param size int64 源大小
+//	@param limiters []internal.ILimiter 限速
+//	@return []byte 分块内容（buf的一部分）
+//	@return error 读写异常或ctx取消时返回，源在传输期间变小时返回io.ErrUnexpectedEOF
+func copyChunk(ctx context.Context, dst io.WriterAt, src io.ReaderAt, buf []byte, off, size int64, limiters []internal.ILimiter) ([]byte, error) {
+	if rest := size - off; rest < int64(len(buf)) {
+		buf = buf[:rest]
+	}
+	n, err := src.ReadAt(buf, off)
+	if n < len(buf) {
+		if err == nil || err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if err := writeAtLimited(ctx, dst, buf, off, limiters); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
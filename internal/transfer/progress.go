@@ -0,0 +1,194 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 14:45:30
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 15:55:00
+ * @FilePath: progress.go
+ * @Description: 传输进度跟踪（由实际写入的字节数驱动，不启动额外的goroutine）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package transfer
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/duanztop/gossh/internal"
+)
+
+// reportInterval 两次进度报告的最小间隔
+const reportInterval = 100 * time.Millisecond
+
+// Tracker 单个文件的进度跟踪（nil表示不跟踪，所有方法均可在nil上调用，Add可并发调用）
+type Tracker struct {
+	mu     sync.Mutex
+	p      internal.IProgress
+	info   internal.ProgressInfo
+	base   int64     // 本次传输的起始位置（断点续传时为已有部分大小）
+	start  time.Time // 本次传输的开始时间
+	last   time.Time // 上次报告的时间
+	ended  bool
+	writer io.Writer
+}
+
+// NewTracker 创建进度跟踪
+//
+//	@author duanzt
+//	@date 2026-10-19 14:46:12
+//	@param name string 目标文件路径
+//	@param o *internal.CopyOptions 拷贝配置
+//	@return *Tracker 未配置进度报告时返回nil
+func NewTracker(name string, o *internal.CopyOptions) *Tracker {
+	if o.Progress == nil {
+		return nil
+	}
+	return &Tracker{p: o.Progress, info: internal.ProgressInfo{Name: name, Total: -1, ETA: -1}}
+}
+
+// Start 开始传输
+//
+//	@author duanzt
+//	@date 2026-10-19 14:47:01
+//	@receiver t *Tracker
+//	@param offset int64 起始位置（断点续传时为已有部分大小）
+//	@param total int64 总字节数（未知时为-1）
+func (t *Tracker) Start(offset, total int64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.start = time.Now()
+	t.last = t.start
+	t.base = offset
+	t.info.Done = offset
+	t.info.Total = total
+	t.report(t.start)
+}
+
+// Writer 包装目标，统计实际写入的字节数
+//
+//	@author duanzt
+//	@date 2026-10-19 14:47:40
+//	@receiver t *Tracker
+//	@param w io.Writer 目标
+//	@return io.Writer 包装后的目标（t为nil时原样返回）
+func (t *Tracker) Writer(w io.Writer) io.Writer {
+	if t == nil {
+		return w
+	}
+	t.writer = w
+	return t
+}
+
+// Write 实现io.Writer
+func (t *Tracker) Write(b []byte) (int, error) {
+	n, err := t.writer.Write(b)
+	t.Add(int64(n))
+	return n, err
+}
+
+// Add 累加已写入的字节数（并行分块传输时由各分块并发调用）
+//
+//	@author duanzt
+//	@date 2026-10-19 15:42:30
+//	@receiver t *Tracker
+//	@param n int64 本次写入的字节数
+func (t *Tracker) Add(n int64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.info.Done += n
+	if now := time.Now(); now.Sub(t.last) >= reportInterval {
+		t.last = now
+		t.report(now)
+	}
+}
+
+// Finish 结束传输（重复调用时只报告一次）
+//
+//	@author duanzt
+//	@date 2026-10-19 14:48:26
+//	@receiver t *Tracker
+//	@param err error 传输异常
+func (t *Tracker) Finish(err error) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.ended {
+		return
+	}
+	t.ended = true
+	if t.start.IsZero() {
+		t.start = time.Now()
+	}
+	t.info.Finished = true
+	t.info.Err = err
+	t.report(time.Now())
+}
+
+// report 计算速度及剩余时间并报告
+func (t *Tracker) report(now time.Time) {
+	t.info.Rate = 0
+	if elapsed := now.Sub(t.start).Seconds(); elapsed > 0 {
+		t.info.Rate = float64(t.info.Done-t.base) / elapsed
+	}
+	t.info.ETA = -1
+	if t.info.Finished {
+		t.info.ETA = 0
+	} else if t.info.Total >= 0 && t.info.Rate > 0 {
+		t.info.ETA = time.Duration(float64(t.info.Total-t.info.Done) / t.info.Rate * float64(time.Second))
+	}
+	t.p.Report(t.info)
+}
+
+// ChanProgress 将进度中的目标文件大小发送到chan（兼容*Mon方法），传输结束后关闭chan
+type ChanProgress struct {
+	ch     chan int64
+	once   sync.Once
+	closed bool
+}
+
+// NewChanProgress 创建ChanProgress
+//
+//	@author duanzt
+//	@date 2026-10-19 14:50:05
+//	@param ch chan int64 接收目标文件大小的chan
+//	@return *ChanProgress 进度报告
+func NewChanProgress(ch chan int64) *ChanProgress {
+	return &ChanProgress{ch: ch}
+}
+
+// Report 发送目标文件大小，传输结束时关闭chan
+//
+//	@author duanzt
+//	@date 2026-10-19 14:50:40
+//	@receiver c *ChanProgress
+//	@param info internal.ProgressInfo 传输进度
+func (c *ChanProgress) Report(info internal.ProgressInfo) {
+	if c.closed {
+		return
+	}
+	c.ch <- info.Done
+	if info.Finished {
+		c.Close()
+	}
+}
+
+// Close 关闭chan（可重复调用，用于传输未开始就失败的情况）
+//
+//	@author duanzt
+//	@date 2026-10-19 14:51:12
+//	@receiver c *ChanProgress
+func (c *ChanProgress) Close() {
+	c.once.Do(func() {
+		c.closed = true
+		close(c.ch)
+	})
+}
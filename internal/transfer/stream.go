@@ -0,0 +1,226 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 13:02:14
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-20 00:01:00
+ * @FilePath: stream.go
+ * @Description: 单文件流拷贝（本地及远端目标文件共用，支持断点续传）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package transfer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/duanztop/gossh/internal"
+	"github.com/duanztop/gossh/internal/tools"
+)
+
+// DestFile 拷贝的目标文件（*os.File及*sftp.File均已实现）
+type DestFile interface {
+	io.Writer
+	io.WriterAt
+	io.ReaderAt
+	io.Seeker
+	Truncate(size int64) error
+	Stat() (fs.FileInfo, error)
+}
+
+// OpenFlag 获取打开目标文件的方式（开启断点续传时不清空已有内容）
+//
+//	@author duanzt
+//	@date 2026-10-19 13:14:02
+//	@param o *internal.CopyOptions 拷贝配置
+//	@return int 打开方式
+func OpenFlag(o *internal.CopyOptions) int {
+	if o.Resume {
+		return os.O_RDWR | os.O_CREATE
+	}
+	return os.O_RDWR | os.O_CREATE | os.O_TRUNC
+}
+
+// WriteStream 将流写入已打开的目标文件
+// 开启断点续传时目标文件需以读写方式打开且不清空内容，已有内容校验通过后从其末尾继续写入，否则从头写入
+// 开启并行分块传输且源支持随机读取时，按分块并发写入
+//
+//	@author duanzt
+//	@date 2026-10-19 13:03:20
+//	@param dst DestFile 目标文件
+//	@param src io.Reader 源
+//	@param o *internal.CopyOptions 拷贝配置
+//	@param tracker *Tracker 进度跟踪（为nil时不跟踪）
+//	@return string 开启校验时返回源内容的摘要（十六进制，断点续传时包含已有部分）
+//	@return error 拷贝异常时返回
+func WriteStream(dst DestFile, src io.Reader, o *internal.CopyOptions, tracker *Tracker) (string, error) {
+	var offset int64
+	if o.Resume {
+		var err error
+		if offset, err = resumeOffset(dst, src, o.ResumeVerify); err != nil {
+			return "", err
+		}
+		// 丢弃校验范围之外的内容（例如目标文件比源文件大，或校验失败从头写入）
+		if err := dst.Truncate(offset); err != nil {
+			return "", err
+		}
+		if _, err := dst.Seek(offset, io.SeekStart); err != nil {
+			return "", err
+		}
+	}
+	total := int64(-1)
+	if size, ok := StreamSize(src); ok {
+		total = size
+	}
+	tracker.Start(offset, total)
+	if ra, ok := parallelSource(src, o, offset, total); ok {
+		return writeParallel(dst, ra, total, o, tracker)
+	}
+	if o.Verify == "" {
+		_, err := io.Copy(tracker.Writer(LimitWriter(o.Ctx(), dst, o.Limiters)), src)
+		return "", err
+	}
+
+	h, err := o.Verify.New()
+	if err != nil {
+		return "", err
+	}
+	if offset > 0 {
+		// 断点续传时已有部分不再发送，从源端读取计算摘要
+		seeker := src.(io.Seeker)
+		if _, err := io.Copy(h, io.NewSectionReader(readerAt(src, seeker), 0, offset)); err != nil {
+			return "", err
+		}
+		if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+			return "", err
+		}
+	}
+	if _, err := io.Copy(tracker.Writer(LimitWriter(o.Ctx(), dst, o.Limiters)), io.TeeReader(src, h)); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// resumeOffset 计算断点续传的起始位置，并将源流定位到该位置
+// 源流不支持Seek时无法跳过已传输的内容，从头写入
+//
+//	@author duanzt
+//	@date 2026-10-19 13:05:41
+//	@param dst DestFile 目标文件
+//	@param src io.Reader 源
+//	@param verifyBytes int64 校验已有内容末尾的字节数（0表示仅按大小判断）
+//	@return int64 续传起始位置（0表示从头写入）
+//	@return error 读取异常时返回
+func resumeOffset(dst DestFile, src io.Reader, verifyBytes int64) (int64, error) {
+	seeker, ok := src.(io.Seeker)
+	if !ok {
+		return 0, nil
+	}
+	info, err := dst.Stat()
+	if err != nil {
+		return 0, err
+	}
+	offset := info.Size()
+	if offset == 0 {
+		return 0, nil
+	}
+	// 源大小已知时，目标文件不能比源大
+	if size, ok := StreamSize(src); ok && offset > size {
+		return 0, nil
+	}
+
+	if verifyBytes > offset {
+		verifyBytes = offset
+	}
+	if verifyBytes > 0 {
+		start := offset - verifyBytes
+		srcSum, err := tailSum(io.NewSectionReader(readerAt(src, seeker), start, verifyBytes), verifyBytes)
+		if err != nil {
+			return 0, err
+		}
+		dstSum, err := tailSum(io.NewSectionReader(dst, start, verifyBytes), verifyBytes)
+		if err != nil {
+			return 0, err
+		}
+		if !bytes.Equal(srcSum, dstSum) {
+			offset = 0
+		}
+	}
+	if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return offset, nil
+}
+
+// StreamSize 获取流的总大小（*os.File、*sftp.File、*bytes.Reader、*strings.Reader等）
+//
+//	@author duanzt
+//	@date 2026-10-19 13:16:20
+//	@param src io.Reader 源
+//	@return int64 总大小
+//	@return bool 无法获取时返回false
+func StreamSize(src io.Reader) (int64, bool) {
+	if info := tools.FileTools.StatReader(src); info != nil {
+		return info.Size(), true
+	}
+	if sizer, ok := src.(interface{ Size() int64 }); ok {
+		return sizer.Size(), true
+	}
+	return 0, false
+}
+
+// tailSum 计算指定长度内容的sha256（内容不足该长度时返回nil，视为校验失败）
+//
+//	@author duanzt
+//	@date 2026-10-19 13:07:02
+//	@param r io.Reader 内容
+//	@param n int64 长度
+//	@return []byte sha256
+//	@return error 读取异常时返回
+func tailSum(r io.Reader, n int64) ([]byte, error) {
+	h := sha256.New()
+	written, err := io.Copy(h, r)
+	if err != nil {
+		return nil, err
+	}
+	if written != n {
+		return nil, nil
+	}
+	return h.Sum(nil), nil
+}
+
+// readerAt 获取流的随机读取方式（未实现io.ReaderAt时通过Seek+Read模拟）
+//
+//	@author duanzt
+//	@date 2026-10-19 13:07:45
+//	@param src io.Reader 源
+//	@param seeker io.Seeker 源的Seek方法
+//	@return io.ReaderAt 随机读取方式
+func readerAt(src io.Reader, seeker io.Seeker) io.ReaderAt {
+	if ra, ok := src.(io.ReaderAt); ok {
+		return ra
+	}
+	return &seekReaderAt{r: src, s: seeker}
+}
+
+// seekReaderAt 通过Seek+Read实现的io.ReaderAt（非并发安全）
+type seekReaderAt struct {
+	r io.Reader
+	s io.Seeker
+}
+
+// ReadAt 实现io.ReaderAt
+func (sr *seekReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if _, err := sr.s.Seek(off, io.SeekStart); err != nil {
+		return 0, err
+	}
+	n, err := io.ReadFull(sr.r, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
@@ -0,0 +1,547 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 18:12:30
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:58:30
+ * @FilePath: sync.go
+ * @Description: 目录同步（比较源端与目标端，仅拷贝有变化的文件，可删除目标端多余的文件）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+
+	"github.com/duanztop/gossh/internal"
+	"github.com/duanztop/gossh/internal/tools"
+)
+
+// errSyncNotDir 同步的源不是目录
+var errSyncNotDir = errors.New("同步的源不是目录")
+
+// SumFunc 计算文件摘要
+//
+//	@param name string 文件路径
+//	@param algo internal.HashAlgorithm 摘要算法
+//	@return string 摘要（十六进制）
+//	@return error 计算异常时返回
+type SumFunc func(name string, algo internal.HashAlgorithm) (string, error)
+
+// SyncSide 同步的一端
+type SyncSide struct {
+	FS  internal.IFileSystem // 文件系统
+	Sum SumFunc              // 摘要计算（按摘要比较时使用）
+}
+
+// pendingDir 尚未创建的目标目录
+type pendingDir struct {
+	change  *internal.SyncChange // 创建时记录的操作
+	replace bool                 // 目标端为同名的非目录，需要先删除
+}
+
+// newPendingDir 记录尚未创建的目标目录
+//
+//	@author duanzt
+//	@date 2026-10-19 18:13:20
+//	@param rel string 相对于同步根目录的路径
+//	@param destInfo fs.FileInfo 目标端同名文件的信息（不存在时为nil）
+//	@return *pendingDir 尚未创建的目标目录
+func newPendingDir(rel string, destInfo fs.FileInfo) *pendingDir {
+	if destInfo != nil {
+		return &pendingDir{change: &internal.SyncChange{Action: internal.SyncUpdate, Path: rel, Dir: true, Reason: "type"}, replace: true}
+	}
+	return &pendingDir{change: &internal.SyncChange{Action: internal.SyncCreate, Path: rel, Dir: true, Reason: "missing"}}
+}
+
+// syncer 目录同步过程的上下文
+type syncer struct {
+	src      SyncSide
+	dest     SyncSide
+	copyFile CopyFileFunc
+	o        *internal.CopyOptions
+	report   *internal.SyncReport
+	pending  map[string]*pendingDir // 尚未创建的目标目录（配置了include时按需创建）
+}
+
+// SyncFileOptions 同步时单文件拷贝使用的配置：关闭断点续传（同步只拷贝已确认不一致的文件，目标端已有内容不可作为续传的前缀）
+//
+//	@author duanzt
+//	@date 2026-10-19 23:58:00
+//	@param opts []internal.CopyOption 拷贝配置
+//	@return []internal.CopyOption 单文件拷贝配置
+func SyncFileOptions(opts []internal.CopyOption) []internal.CopyOption {
+	return append(opts[:len(opts):len(opts)], func(o *internal.CopyOptions) {
+		o.Resume, o.ResumeVerify = false, 0
+	})
+}
+
+// Sync 同步目录：源端有而目标端没有或不一致的文件拷贝到目标端，开启Delete时删除目标端多余的文件/目录
+// 默认按大小及修改时间（秒）比较，配置Checksum时大小相同的文件再按摘要比较；拷贝后始终设置修改时间，保证下次比较结果准确；
+// 被排除的文件不会被删除，开启DryRun时仅报告计划的操作
+//
+//	@author duanzt
+//	@date 2026-10-19 18:14:02
+//	@param src SyncSide 源端
+//	@param dest SyncSide 目标端
+//	@param srcDir string 源目录
+//	@param destDir string 目标目录
+//	@param copyFile CopyFileFunc 单文件拷贝方法
+//	@param o *internal.CopyOptions 拷贝配置
+//	@return *internal.SyncReport 同步结果
+//	@return error 未开启FailFast时汇总全部失败文件，开启时返回首个异常
+func Sync(src, dest SyncSide, srcDir, destDir string, copyFile CopyFileFunc, o *internal.CopyOptions) (*internal.SyncReport, error) {
+	s := &syncer{
+		src:      src,
+		dest:     dest,
+		copyFile: copyFile,
+		o:        o,
+		report:   &internal.SyncReport{DryRun: o.DryRun},
+		pending:  make(map[string]*pendingDir),
+	}
+	info, err := src.FS.Stat(srcDir)
+	if err != nil {
+		return s.report, err
+	}
+	if !info.IsDir() {
+		return s.report, &fs.PathError{Op: "sync", Path: srcDir, Err: errSyncNotDir}
+	}
+	destInfo, err := dest.FS.Stat(destDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return s.report, err
+	}
+	if err != nil {
+		destInfo = nil
+	}
+	exists := destInfo != nil && destInfo.IsDir()
+	if !exists {
+		s.pending[destDir] = newPendingDir(".", destInfo)
+	}
+	if err := s.syncDir(srcDir, destDir, ".", info, exists, []string{path.Clean(srcDir)}); err != nil {
+		return s.report, err
+	}
+	if err := s.finishDir(srcDir, destDir, ".", info, destInfo); err != nil {
+		return s.report, err
+	}
+	return s.report, s.report.Err()
+}
+
+// syncDir 同步目录
+//
+//	@author duanzt
+//	@date 2026-10-19 18:15:40
+//	@receiver s *syncer
+//	@param src string 源目录
+//	@param dest string 目标目录
+//	@param rel string 相对于同步根目录的路径
+//	@param info fs.FileInfo 源目录信息
+//	@param exists bool 目标目录是否已存在
+//	@param ancestors []string 已进入的源目录（用于发现符号链接循环）
+//	@return error 开启FailFast且发生异常时返回
+func (s *syncer) syncDir(src, dest, rel string, info fs.FileInfo, exists bool, ancestors []string) error {
+	// 配置了include时目录按需创建，避免产生大量空目录
+	if len(s.o.Includes) == 0 {
+		if err := s.ensureDir(dest); err != nil {
+			return s.fail(src, err)
+		}
+	}
+	children, err := s.src.FS.ReadDir(src)
+	if err != nil {
+		return s.fail(src, err)
+	}
+	destChildren := make(map[string]fs.FileInfo)
+	if exists {
+		infos, err := s.dest.FS.ReadDir(dest)
+		if err != nil {
+			return s.fail(dest, err)
+		}
+		for _, child := range infos {
+			destChildren[child.Name()] = child
+		}
+	}
+	if s.o.Delete {
+		if err := s.deleteExtraneous(dest, rel, children, destChildren); err != nil {
+			return err
+		}
+	}
+
+	for _, child := range children {
+		childSrc := path.Join(src, child.Name())
+		childDest := path.Join(dest, child.Name())
+		childRel := path.Join(rel, child.Name())
+		if tools.GlobTools.MatchAny(s.o.Excludes, childRel) {
+			s.report.Skipped++
+			continue
+		}
+		destChild := destChildren[child.Name()]
+
+		childInfo := child
+		if child.Mode()&fs.ModeSymlink != 0 {
+			switch s.o.Symlink {
+			case internal.SymlinkSkip:
+				s.report.Skipped++
+				continue
+			case internal.SymlinkPreserve:
+				if err := s.syncSymlink(childSrc, childDest, childRel, destChild); err != nil {
+					return err
+				}
+				continue
+			default:
+				if childInfo, err = s.src.FS.Stat(childSrc); err != nil {
+					if err := s.fail(childSrc, err); err != nil {
+						return err
+					}
+					continue
+				}
+			}
+		}
+
+		switch {
+		case childInfo.IsDir():
+			childAncestors := append(append(make([]string, 0, len(ancestors)+2), ancestors...), path.Clean(childSrc))
+			if child.Mode()&fs.ModeSymlink != 0 {
+				target, err := s.resolveLink(childSrc)
+				if err == nil && (IsAncestor(ancestors, target) || len(ancestors) > MaxDirDepth) {
+					err = ErrSymlinkLoop
+				}
+				if err != nil {
+					if err := s.fail(childSrc, err); err != nil {
+						return err
+					}
+					continue
+				}
+				childAncestors = append(childAncestors, target)
+			}
+			childExists := destChild != nil && destChild.IsDir()
+			if !childExists {
+				s.pending[childDest] = newPendingDir(childRel, destChild)
+			}
+			if err := s.syncDir(childSrc, childDest, childRel, childInfo, childExists, childAncestors); err != nil {
+				return err
+			}
+			if err := s.finishDir(childSrc, childDest, childRel, childInfo, destChild); err != nil {
+				return err
+			}
+		case childInfo.Mode().IsRegular():
+			if len(s.o.Includes) > 0 && !tools.GlobTools.MatchAny(s.o.Includes, childRel) {
+				s.report.Skipped++
+				continue
+			}
+			if err := s.syncFile(childSrc, childDest, childRel, childInfo, destChild); err != nil {
+				return err
+			}
+		default:
+			// 设备文件、管道、socket等无法拷贝
+			s.report.Skipped++
+		}
+	}
+	return nil
+}
+
+// syncFile 同步普通文件
+//
+//	@author duanzt
+//	@date 2026-10-19 18:17:12
+//	@receiver s *syncer
+//	@param src string 源文件
+//	@param dest string 目标文件
+//	@param rel string 相对于同步根目录的路径
+//	@param info fs.FileInfo 源文件信息
+//	@param destInfo fs.FileInfo 目标文件信息（不存在时为nil，不跟随符号链接）
+//	@return error 开启FailFast且发生异常时返回
+func (s *syncer) syncFile(src, dest, rel string, info, destInfo fs.FileInfo) error {
+	change := &internal.SyncChange{Action: internal.SyncUpdate, Path: rel, Size: info.Size()}
+	switch {
+	case destInfo == nil:
+		change.Action, change.Reason = internal.SyncCreate, "missing"
+	case !destInfo.Mode().IsRegular():
+		change.Reason = "type"
+	case destInfo.Size() != info.Size():
+		change.Reason = "size"
+	case s.o.Checksum != "":
+		same, err := s.sameSum(src, dest)
+		if err != nil {
+			return s.fail(src, err)
+		}
+		if !same {
+			change.Reason = "checksum"
+		}
+	case destInfo.ModTime().Unix() != info.ModTime().Unix():
+		change.Reason = "mtime"
+	}
+
+	if change.Reason == "" {
+		// 内容一致：按需修正权限，按摘要比较时顺带修正修改时间
+		if s.o.PreserveMode && destInfo.Mode().Perm() != info.Mode().Perm() {
+			change.Reason = "mode"
+			s.report.Changes = append(s.report.Changes, change)
+			if !s.o.DryRun {
+				if err := s.dest.FS.Chmod(dest, info.Mode().Perm()); err != nil {
+					return s.fail(src, err)
+				}
+			}
+		} else {
+			s.report.Unchanged++
+		}
+		if !s.o.DryRun && destInfo.ModTime().Unix() != info.ModTime().Unix() {
+			if err := s.dest.FS.Chtimes(dest, info.ModTime(), info.ModTime()); err != nil {
+				return s.fail(src, err)
+			}
+		}
+		return nil
+	}
+
+	s.report.Changes = append(s.report.Changes, change)
+	if s.o.DryRun {
+		return nil
+	}
+	if err := s.ensureDir(path.Dir(dest)); err != nil {
+		return s.fail(src, err)
+	}
+	if change.Reason == "type" {
+		if err := s.dest.FS.RemoveAll(dest); err != nil {
+			return s.fail(src, err)
+		}
+	}
+	// 与目录拷贝一致，新文件沿用源文件权限，已存在的文件未开启PreserveMode时保持原权限（单文件拷贝负责设置权限）
+	mode := ""
+	if s.o.PreserveMode || destInfo == nil || change.Reason == "type" {
+		mode = fmt.Sprintf("%04o", info.Mode().Perm())
+	}
+	if err := s.copyFile(src, dest, mode); err != nil {
+		return s.fail(src, err)
+	}
+	if err := s.dest.FS.Chtimes(dest, info.ModTime(), info.ModTime()); err != nil {
+		return s.fail(src, err)
+	}
+	s.report.Files++
+	s.report.Bytes += info.Size()
+	return nil
+}
+
+// syncSymlink 同步符号链接（链接指向的路径不一致时重建）
+//
+//	@author duanzt
+//	@date 2026-10-19 18:18:30
+//	@receiver s *syncer
+//	@param src string 源符号链接
+//	@param dest string 目标符号链接
+//	@param rel string 相对于同步根目录的路径
+//	@param destInfo fs.FileInfo 目标文件信息（不存在时为nil，不跟随符号链接）
+//	@return error 开启FailFast且发生异常时返回
+func (s *syncer) syncSymlink(src, dest, rel string, destInfo fs.FileInfo) error {
+	if len(s.o.Includes) > 0 && !tools.GlobTools.MatchAny(s.o.Includes, rel) {
+		s.report.Skipped++
+		return nil
+	}
+	target, err := s.src.FS.Readlink(src)
+	if err != nil {
+		return s.fail(src, err)
+	}
+	change := &internal.SyncChange{Action: internal.SyncUpdate, Path: rel}
+	switch {
+	case destInfo == nil:
+		change.Action, change.Reason = internal.SyncCreate, "missing"
+	case destInfo.Mode()&fs.ModeSymlink == 0:
+		change.Reason = "type"
+	default:
+		destTarget, err := s.dest.FS.Readlink(dest)
+		if err != nil {
+			return s.fail(src, err)
+		}
+		if destTarget == target {
+			s.report.Unchanged++
+			return nil
+		}
+		change.Reason = "link"
+	}
+
+	s.report.Changes = append(s.report.Changes, change)
+	if s.o.DryRun {
+		return nil
+	}
+	if err := s.ensureDir(path.Dir(dest)); err != nil {
+		return s.fail(src, err)
+	}
+	if destInfo != nil {
+		if err := s.dest.FS.RemoveAll(dest); err != nil {
+			return s.fail(src, err)
+		}
+	}
+	if err := s.dest.FS.Symlink(target, dest); err != nil {
+		return s.fail(src, err)
+	}
+	s.report.Symlinks++
+	return nil
+}
+
+// deleteExtraneous 删除目标目录下源端不存在的文件/目录（被排除的除外）
+//
+//	@author duanzt
+//	@date 2026-10-19 18:19:45
+//	@receiver s *syncer
+//	@param dest string 目标目录
+//	@param rel string 相对于同步根目录的路径
+//	@param children []fs.FileInfo 源目录下的文件
+//	@param destChildren map[string]fs.FileInfo 目标目录下的文件
+//	@return error 开启FailFast且发生异常时返回
+func (s *syncer) deleteExtraneous(dest, rel string, children []fs.FileInfo, destChildren map[string]fs.FileInfo) error {
+	names := make(map[string]bool, len(children))
+	for _, child := range children {
+		names[child.Name()] = true
+	}
+	extraneous := make([]string, 0)
+	for name := range destChildren {
+		if !names[name] && !tools.GlobTools.MatchAny(s.o.Excludes, path.Join(rel, name)) {
+			extraneous = append(extraneous, name)
+		}
+	}
+	sort.Strings(extraneous)
+	for _, name := range extraneous {
+		info := destChildren[name]
+		childRel := path.Join(rel, name)
+		childDest := path.Join(dest, name)
+		s.report.Changes = append(s.report.Changes, &internal.SyncChange{
+			Action: internal.SyncDelete, Path: childRel, Dir: info.IsDir(), Size: info.Size(), Reason: "extraneous",
+		})
+		delete(destChildren, name)
+		if s.o.DryRun {
+			continue
+		}
+		if err := s.dest.FS.RemoveAll(childDest); err != nil {
+			if err := s.fail(childDest, err); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ensureDir 创建尚未创建的目标目录（逐级创建上级目录）
+//
+//	@author duanzt
+//	@date 2026-10-19 18:20:30
+//	@receiver s *syncer
+//	@param dir string 目标目录
+//	@return error 创建异常时返回
+func (s *syncer) ensureDir(dir string) error {
+	pending := s.pending[dir]
+	if pending == nil {
+		return nil
+	}
+	if err := s.ensureDir(path.Dir(dir)); err != nil {
+		return err
+	}
+	delete(s.pending, dir)
+	s.report.Changes = append(s.report.Changes, pending.change)
+	if s.o.DryRun {
+		return nil
+	}
+	if pending.replace {
+		if err := s.dest.FS.Remove(dir); err != nil {
+			return err
+		}
+	}
+	if err := s.dest.FS.MkdirAll(dir, DefaultDirPerm); err != nil {
+		return err
+	}
+	s.report.Dirs++
+	return nil
+}
+
+// finishDir 按配置同步目录的权限及修改时间（目录下的文件同步完成后）
+//
+//	@author duanzt
+//	@date 2026-10-19 18:21:12
+//	@receiver s *syncer
+//	@param src string 源目录
+//	@param dest string 目标目录
+//	@param rel string 相对于同步根目录的路径
+//	@param info fs.FileInfo 源目录信息
+//	@param destInfo fs.FileInfo 同步前的目标目录信息（不存在时为nil）
+//	@return error 开启FailFast且发生异常时返回
+func (s *syncer) finishDir(src, dest, rel string, info, destInfo fs.FileInfo) error {
+	if s.pending[dest] != nil {
+		// 配置了include且目录下没有需要同步的文件，未创建
+		delete(s.pending, dest)
+		return nil
+	}
+	created := destInfo == nil || !destInfo.IsDir()
+	if s.o.PreserveMode && (created || destInfo.Mode().Perm() != info.Mode().Perm()) {
+		if !created {
+			s.report.Changes = append(s.report.Changes, &internal.SyncChange{Action: internal.SyncUpdate, Path: rel, Dir: true, Reason: "mode"})
+		}
+		if !s.o.DryRun {
+			if err := s.dest.FS.Chmod(dest, info.Mode().Perm()); err != nil {
+				return s.fail(src, err)
+			}
+		}
+	}
+	if s.o.PreserveTimes && !s.o.DryRun {
+		if err := s.dest.FS.Chtimes(dest, info.ModTime(), info.ModTime()); err != nil {
+			return s.fail(src, err)
+		}
+	}
+	return nil
+}
+
+// sameSum 按摘要比较源文件与目标文件
+//
+//	@author duanzt
+//	@date 2026-10-19 18:22:02
+//	@receiver s *syncer
+//	@param src string 源文件
+//	@param dest string 目标文件
+//	@return bool 摘要一致时返回true
+//	@return error 计算异常时返回
+func (s *syncer) sameSum(src, dest string) (bool, error) {
+	srcSum, err := s.src.Sum(src, s.o.Checksum)
+	if err != nil {
+		return false, err
+	}
+	destSum, err := s.dest.Sum(dest, s.o.Checksum)
+	if err != nil {
+		return false, err
+	}
+	return srcSum == destSum, nil
+}
+
+// resolveLink 获取符号链接指向的路径
+//
+//	@author duanzt
+//	@date 2026-10-19 18:22:40
+//	@receiver s *syncer
+//	@param link string 符号链接路径
+//	@return string 链接指向的路径（已转换为绝对路径）
+//	@return error 读取异常时返回
+func (s *syncer) resolveLink(link string) (string, error) {
+	target, err := s.src.FS.Readlink(link)
+	if err != nil {
+		return "", err
+	}
+	if !path.IsAbs(target) {
+		target = path.Join(path.Dir(link), target)
+	}
+	return path.Clean(target), nil
+}
+
+// fail 记录同步异常
+//
+//	@author duanzt
+//	@date 2026-10-19 18:23:15
+//	@receiver s *syncer
+//	@param src string 文件路径
+//	@param err error 异常信息
+//	@return error 开启FailFast时返回该异常，否则返回nil继续同步
+func (s *syncer) fail(src string, err error) error {
+	copyErr := &internal.CopyError{Path: src, Err: err}
+	s.report.Errors = append(s.report.Errors, copyErr)
+	if s.o.FailFast {
+		return copyErr
+	}
+	return nil
+}
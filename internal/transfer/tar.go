@@ -0,0 +1,589 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 17:41:05
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-20 00:08:20
+ * @FilePath: tar.go
+ * @Description: tar流传输（本地端纯Go实现打包及解包，远端由tar命令处理）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package transfer
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/duanztop/gossh/internal"
+	"github.com/duanztop/gossh/internal/tools"
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+
+	// ErrTarUnsupported tar流传输不支持的配置
+	ErrTarUnsupported = errors.New("tar流传输不支持Owner、Verify、Atomic及断点续传配置")
+
+	// errTarName tar条目的路径不合法（绝对路径或包含..，防止写入目标目录之外）
+	errTarName = errors.New("tar条目的路径不合法")
+
+	// errTarUnsafe tar条目的上级路径为符号链接或非目录（防止通过符号链接写入目标目录之外）
+	errTarUnsafe = errors.New("tar条目的上级路径不是目录")
+)
+
+// CheckTarOptions 校验tar流传输的拷贝配置
+//
+//	@author duanzt
+//	@date 2026-10-19 17:41:40
+//	@param o *internal.CopyOptions 拷贝配置
+//	@return error 包含不支持的配置时返回ErrTarUnsupported
+func CheckTarOptions(o *internal.CopyOptions) error {
+	if o.Owner != "" || o.Group != "" || o.Verify != "" || o.Atomic || o.Resume {
+		return ErrTarUnsupported
+	}
+	switch o.Compression {
+	case internal.CompressionNone, internal.CompressionGzip, internal.CompressionZstd:
+		return nil
+	default:
+		return fmt.Errorf("不支持的压缩算法: %s", o.Compression)
+	}
+}
+
+// CompressWriter 按压缩算法包装目标（Close时写入压缩流的结尾，不关闭w）
+//
+//	@author duanzt
+//	@date 2026-10-19 17:42:12
+//	@param w io.Writer 目标
+//	@param compression internal.Compression 压缩算法
+//	@return io.WriteCloser 包装后的目标
+//	@return error 创建压缩器异常时返回
+func CompressWriter(w io.Writer, compression internal.Compression) (io.WriteCloser, error) {
+	switch compression {
+	case internal.CompressionGzip:
+		return gzip.NewWriter(w), nil
+	case internal.CompressionZstd:
+		return zstd.NewWriter(w)
+	default:
+		return nopWriteCloser{w}, nil
+	}
+}
+
+// DecompressReader 按压缩算法包装源
+//
+//	@author duanzt
+//	@date 2026-10-19 17:42:50
+//	@param r io.Reader 源
+//	@param compression internal.Compression 压缩算法
+//	@return io.ReadCloser 包装后的源（Close时释放解压器，不关闭r）
+//	@return error 压缩流的头部不合法时返回
+func DecompressReader(r io.Reader, compression internal.Compression) (io.ReadCloser, error) {
+	switch compression {
+	case internal.CompressionGzip:
+		return gzip.NewReader(r)
+	case internal.CompressionZstd:
+		decoder, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	default:
+		return io.NopCloser(r), nil
+	}
+}
+
+// nopWriteCloser 不压缩时的目标（Close无操作）
+type nopWriteCloser struct {
+	io.Writer
+}
+
+// Close 实现io.Closer
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// tarEntry 待打包的条目
+type tarEntry struct {
+	src  string      // 本地路径
+	name string      // 相对于拷贝根目录的路径
+	info fs.FileInfo // 文件信息（已按符号链接策略跟随）
+	link string      // 符号链接指向的路径（保留符号链接时）
+	skip bool        // 目录无需写入条目（由tar在解包时隐式创建或按include过滤后为空）
+}
+
+// tarWriter 打包本地目录的上下文
+type tarWriter struct {
+	o       *internal.CopyOptions
+	summary *internal.CopySummary
+	entries []*tarEntry
+}
+
+// WriteTar 将本地目录打包为tar流写入w（先遍历目录统计总大小，再依次写入）
+// 目录条目仅在保留权限或修改时间时写入（未保留时由tar按需创建，避免修改已存在目录的权限），空目录始终写入；
+// 根目录条目仅在保留权限时写入
+//
+//	@author duanzt
+//	@date 2026-10-19 17:44:02
+//	@param w io.Writer 目标（压缩由调用方处理）
+//	@param src string 本地目录
+//	@param o *internal.CopyOptions 拷贝配置
+//	@param tracker *Tracker 进度跟踪（按文件内容字节数统计，可为nil）
+//	@return *internal.CopySummary 拷贝结果汇总
+//	@return error 开启FailFast时返回首个异常，写入异常时返回（tar流已不完整）
+func WriteTar(w io.Writer, src string, o *internal.CopyOptions, tracker *Tracker) (*internal.CopySummary, error) {
+	t := &tarWriter{o: o, summary: &internal.CopySummary{}}
+	info, err := os.Stat(src)
+	if err != nil {
+		return t.summary, err
+	}
+	real, err := filepath.EvalSymlinks(src)
+	if err != nil {
+		return t.summary, err
+	}
+	if o.PreserveMode {
+		t.entries = append(t.entries, &tarEntry{src: src, name: ".", info: info})
+	}
+	if _, err := t.collect(src, "", []string{real}); err != nil {
+		return t.summary, err
+	}
+
+	var total int64
+	for _, entry := range t.entries {
+		if entry.info.Mode().IsRegular() && entry.link == "" {
+			total += entry.info.Size()
+		}
+	}
+	tracker.Start(0, total)
+	tw := tar.NewWriter(w)
+	for _, entry := range t.entries {
+		if err := t.write(tw, entry, tracker); err != nil {
+			return t.summary, err
+		}
+	}
+	return t.summary, tw.Close()
+}
+
+// collect 遍历本地目录，按过滤规则及符号链接策略收集待打包的条目
+//
+//	@author duanzt
+//	@date 2026-10-19 17:45:30
+//	@receiver t *tarWriter
+//	@param src string 本地目录
+//	@param rel string 相对于拷贝根目录的路径
+//	@param ancestors []string 已进入的本地目录的真实路径（用于发现符号链接循环）
+//	@return int 收集到的条目数（不含被过滤的目录）
+//	@return error 开启FailFast且发生异常时返回
+func (t *tarWriter) collect(src, rel string, ancestors []string) (int, error) {
+	children, err := os.ReadDir(src)
+	if err != nil {
+		return 0, t.fail(src, err)
+	}
+	count := 0
+	for _, child := range children {
+		childSrc := filepath.Join(src, child.Name())
+		childRel := path.Join(rel, child.Name())
+		if tools.GlobTools.MatchAny(t.o.Excludes, childRel) {
+			t.summary.Skipped++
+			continue
+		}
+		childInfo, err := child.Info()
+		if err != nil {
+			if err := t.fail(childSrc, err); err != nil {
+				return count, err
+			}
+			continue
+		}
+		isLink := childInfo.Mode()&fs.ModeSymlink != 0
+		if isLink {
+			switch t.o.Symlink {
+			case internal.SymlinkSkip:
+				t.summary.Skipped++
+				continue
+			case internal.SymlinkPreserve:
+				if len(t.o.Includes) > 0 && !tools.GlobTools.MatchAny(t.o.Includes, childRel) {
+					t.summary.Skipped++
+					continue
+				}
+				target, err := os.Readlink(childSrc)
+				if err != nil {
+					if err := t.fail(childSrc, err); err != nil {
+						return count, err
+					}
+					continue
+				}
+				t.entries = append(t.entries, &tarEntry{src: childSrc, name: childRel, info: childInfo, link: target})
+				count++
+				continue
+			default:
+				if childInfo, err = os.Stat(childSrc); err != nil {
+					if err := t.fail(childSrc, err); err != nil {
+						return count, err
+					}
+					continue
+				}
+			}
+		}
+
+		switch {
+		case childInfo.IsDir():
+			real, err := filepath.EvalSymlinks(childSrc)
+			if err == nil && isLink && (IsAncestor(ancestors, real) || len(ancestors) > MaxDirDepth) {
+				err = ErrSymlinkLoop
+			}
+			if err != nil {
+				if err := t.fail(childSrc, err); err != nil {
+					return count, err
+				}
+				continue
+			}
+			entry := &tarEntry{src: childSrc, name: childRel, info: childInfo}
+			t.entries = append(t.entries, entry)
+			childAncestors := append(append(make([]string, 0, len(ancestors)+1), ancestors...), real)
+			n, err := t.collect(childSrc, childRel, childAncestors)
+			if err != nil {
+				return count, err
+			}
+			switch {
+			case n == 0 && len(t.o.Includes) > 0:
+				// 配置了include时不创建空目录
+				entry.skip = true
+				continue
+			case n > 0 && !t.o.PreserveMode && !t.o.PreserveTimes:
+				entry.skip = true
+			}
+			t.summary.Dirs++
+			count++
+		case childInfo.Mode().IsRegular():
+			if len(t.o.Includes) > 0 && !tools.GlobTools.MatchAny(t.o.Includes, childRel) {
+				t.summary.Skipped++
+				continue
+			}
+			t.entries = append(t.entries, &tarEntry{src: childSrc, name: childRel, info: childInfo})
+			count++
+		default:
+			// 设备文件、管道、socket等无法拷贝
+			t.summary.Skipped++
+		}
+	}
+	return count, nil
+}
+
+// write 写入单个条目
+//
+//	@author duanzt
+//	@date 2026-10-19 17:47:12
+//	@receiver t *tarWriter
+//	@param tw *tar.Writer tar流
+//	@param entry *tarEntry 条目
+//	@param tracker *Tracker 进度跟踪
+//	@return error 开启FailFast或tar流已不完整时返回
+func (t *tarWriter) write(tw *tar.Writer, entry *tarEntry, tracker *Tracker) error {
+	if entry.skip {
+		return nil
+	}
+	header := &tar.Header{Name: entry.name, ModTime: entry.info.ModTime()}
+	switch {
+	case entry.link != "":
+		header.Typeflag = tar.TypeSymlink
+		header.Linkname = entry.link
+		header.Mode = 0777
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		t.summary.Symlinks++
+		return nil
+	case entry.info.IsDir():
+		header.Typeflag = tar.TypeDir
+		header.Name += "/"
+		header.Mode = int64(tools.ModeTools.ToUnix(DefaultDirPerm))
+		if t.o.PreserveMode {
+			header.Mode = int64(tools.ModeTools.ToUnix(entry.info.Mode().Perm()))
+		}
+		return tw.WriteHeader(header)
+	}
+
+	// 打开失败时跳过该文件；写入头部后内容不足则tar流已不完整，只能终止
+	file, err := os.Open(entry.src)
+	if err != nil {
+		return t.fail(entry.src, err)
+	}
+	defer file.Close()
+	// 与scp一致，新文件沿用源文件权限
+	header.Typeflag = tar.TypeReg
+	header.Mode = int64(tools.ModeTools.ToUnix(entry.info.Mode().Perm()))
+	header.Size = entry.info.Size()
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if _, err := io.CopyN(tracker.Writer(tw), file, header.Size); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		t.fail(entry.src, err)
+		return t.summary.Errors[len(t.summary.Errors)-1]
+	}
+	t.summary.Files++
+	t.summary.Bytes += header.Size
+	return nil
+}
+
+// fail 记录拷贝异常
+//
+//	@author duanzt
+//	@date 2026-10-19 17:47:50
+//	@receiver t *tarWriter
+//	@param src string 源文件路径
+//	@param err error 异常信息
+//	@return error 开启FailFast时返回该异常，否则返回nil继续拷贝
+func (t *tarWriter) fail(src string, err error) error {
+	copyErr := &internal.CopyError{Path: src, Err: err}
+	t.summary.Errors = append(t.summary.Errors, copyErr)
+	if t.o.FailFast {
+		return copyErr
+	}
+	return nil
+}
+
+// tarExtractor 解包到本地目录的上下文
+type tarExtractor struct {
+	target  *extractTarget
+	o       *internal.CopyOptions
+	summary *internal.CopySummary
+	tracker *Tracker
+}
+
+// ExtractTar 将tar流解包到本地目录
+// 条目路径为绝对路径、包含..或上级路径为符号链接时拒绝写入；过滤规则及符号链接策略在解包时生效
+//
+//	@author duanzt
+//	@date 2026-10-19 17:49:05
+//	@param r io.Reader tar流（解压由调用方处理）
+//	@param dest string 本地目标目录
+//	@param o *internal.CopyOptions 拷贝配置
+//	@param tracker *Tracker 进度跟踪（按文件内容字节数统计，可为nil）
+//	@return *internal.CopySummary 拷贝结果汇总
+//	@return error 开启FailFast时返回首个异常，tar流不合法时返回
+func ExtractTar(r io.Reader, dest string, o *internal.CopyOptions, tracker *Tracker) (*internal.CopySummary, error) {
+	e := &tarExtractor{
+		target:  newExtractTarget(osFS{}, filepath.Clean(dest), filepath.Join),
+		o:       o,
+		summary: &internal.CopySummary{},
+		tracker: tracker,
+	}
+	// 本次新建的目录计入汇总
+	defer func() { e.summary.Dirs = len(e.target.created) }()
+	tracker.Start(0, -1)
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return e.summary, err
+		}
+		if err := e.extract(tr, header); err != nil {
+			return e.summary, err
+		}
+	}
+	if err := e.target.finishDirs(o.PreserveMode, o.PreserveTimes, e.fail); err != nil {
+		return e.summary, err
+	}
+	return e.summary, e.summary.Err()
+}
+
+// extract 解包单个条目
+//
+//	@author duanzt
+//	@date 2026-10-19 17:50:30
+//	@receiver e *tarExtractor
+//	@param tr *tar.Reader tar流
+//	@param header *tar.Header 条目头部
+//	@return error 开启FailFast且发生异常时返回
+func (e *tarExtractor) extract(tr *tar.Reader, header *tar.Header) error {
+	rel, err := tarEntryName(header.Name)
+	if err != nil {
+		return e.fail(header.Name, err)
+	}
+	if e.excluded(rel) {
+		return nil
+	}
+
+	switch header.Typeflag {
+	case tar.TypeDir:
+		// 配置了include时目录按需创建，避免产生大量空目录
+		if len(e.o.Includes) == 0 {
+			if err := e.target.ensureDir(rel); err != nil {
+				return e.fail(header.Name, err)
+			}
+		}
+		e.target.addDir(rel, tools.ModeTools.FromUnix(uint32(header.Mode)&07777).Perm(), header.ModTime)
+		return nil
+	case tar.TypeReg:
+		if !e.included(rel) {
+			return nil
+		}
+		if err := e.writeFile(tr, header, rel); err != nil {
+			return e.fail(header.Name, err)
+		}
+		e.summary.Files++
+		e.summary.Bytes += header.Size
+		return nil
+	case tar.TypeSymlink:
+		if e.o.Symlink == internal.SymlinkSkip || !e.included(rel) {
+			e.summary.Skipped++
+			return nil
+		}
+		target, err := e.target.prepare(rel)
+		if err == nil {
+			err = os.Symlink(header.Linkname, target)
+		}
+		if err != nil {
+			return e.fail(header.Name, err)
+		}
+		e.summary.Symlinks++
+		return nil
+	case tar.TypeLink:
+		if !e.included(rel) {
+			return nil
+		}
+		linkRel, err := tarEntryName(header.Linkname)
+		if err != nil || linkRel == "." {
+			return e.fail(header.Name, fmt.Errorf("%w: %q", errTarName, header.Linkname))
+		}
+		oldname, err := e.target.linkTarget(linkRel)
+		if err != nil {
+			return e.fail(header.Name, err)
+		}
+		target, err := e.target.prepare(rel)
+		if err == nil {
+			err = os.Link(oldname, target)
+		}
+		if err != nil {
+			return e.fail(header.Name, err)
+		}
+		e.summary.Files++
+		return nil
+	default:
+		// 设备文件、管道等无法拷贝
+		e.summary.Skipped++
+		return nil
+	}
+}
+
+// writeFile 写入普通文件（已存在的文件或符号链接先删除，不会跟随链接或通过硬链接写入）
+//
+//	@author duanzt
+//	@date 2026-10-19 17:51:40
+//	@receiver e *tarExtractor
+//	@param tr *tar.Reader tar流（当前条目的内容）
+//	@param header *tar.Header 条目头部
+//	@param rel string 相对于目标目录的路径
+//	@return error 写入异常时返回
+func (e *tarExtractor) writeFile(tr *tar.Reader, header *tar.Header, rel string) (err error) {
+	target, err := e.target.prepare(rel)
+	if err != nil {
+		return err
+	}
+	// 与scp一致，新文件沿用源文件权限（不受umask影响）
+	perm := tools.ModeTools.FromUnix(uint32(header.Mode) & 07777).Perm()
+	file, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	if _, err := io.Copy(e.tracker.Writer(file), tr); err != nil {
+		return err
+	}
+	if err := file.Chmod(perm); err != nil {
+		return err
+	}
+	if e.o.PreserveTimes {
+		return os.Chtimes(target, header.ModTime, header.ModTime)
+	}
+	return nil
+}
+
+// excluded 判断条目或其任一上级目录是否被排除（条目本身被排除时计入跳过数）
+//
+//	@author duanzt
+//	@date 2026-10-19 17:54:40
+//	@receiver e *tarExtractor
+//	@param rel string 相对于拷贝根目录的路径
+//	@return bool 被排除时返回true
+func (e *tarExtractor) excluded(rel string) bool {
+	if len(e.o.Excludes) == 0 || rel == "." {
+		return false
+	}
+	if tools.GlobTools.MatchAny(e.o.Excludes, rel) {
+		e.summary.Skipped++
+		return true
+	}
+	for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+		if tools.GlobTools.MatchAny(e.o.Excludes, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// included 判断文件是否匹配include（未配置时全部匹配，不匹配时计入跳过数）
+//
+//	@author duanzt
+//	@date 2026-10-19 17:55:12
+//	@receiver e *tarExtractor
+//	@param rel string 相对于拷贝根目录的路径
+//	@return bool 匹配时返回true
+func (e *tarExtractor) included(rel string) bool {
+	if len(e.o.Includes) == 0 || tools.GlobTools.MatchAny(e.o.Includes, rel) {
+		return true
+	}
+	e.summary.Skipped++
+	return false
+}
+
+// fail 记录拷贝异常
+//
+//	@author duanzt
+//	@date 2026-10-19 17:55:40
+//	@receiver e *tarExtractor
+//	@param name string 条目路径
+//	@param err error 异常信息
+//	@return error 开启FailFast时返回该异常，否则返回nil继续拷贝
+func (e *tarExtractor) fail(name string, err error) error {
+	copyErr := &internal.CopyError{Path: name, Err: err}
+	e.summary.Errors = append(e.summary.Errors, copyErr)
+	if e.o.FailFast {
+		return copyErr
+	}
+	return nil
+}
+
+// tarEntryName 将tar条目路径转换为相对路径（去除./前缀及末尾的/）
+//
+//	@author duanzt
+//	@date 2026-10-19 17:56:15
+//	@param name string 条目路径
+//	@return string 相对路径（根目录为"."）
+//	@return error 为绝对路径或包含..时返回
+func tarEntryName(name string) (string, error) {
+	if name == "" || strings.HasPrefix(name, "/") || strings.ContainsRune(name, 0) {
+		return "", fmt.Errorf("%w: %q", errTarName, name)
+	}
+	rel := path.Clean(name)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", fmt.Errorf("%w: %q", errTarName, name)
+	}
+	return rel, nil
+}
@@ -0,0 +1,64 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 13:38:26
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 14:28:50
+ * @FilePath: verify.go
+ * @Description: 传输后目标文件的摘要校验
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package transfer
+
+import (
+	"encoding/hex"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/duanztop/gossh/internal"
+)
+
+// FileSum 计算本地文件的摘要
+//
+//	@author duanzt
+//	@date 2026-10-19 13:39:05
+//	@param name string 文件路径
+//	@param algo internal.HashAlgorithm 摘要算法
+//	@return string 摘要（十六进制）
+//	@return error 读取异常时返回
+func FileSum(name string, algo internal.HashAlgorithm) (string, error) {
+	h, err := algo.New()
+	if err != nil {
+		return "", err
+	}
+	file, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Verify 比对发送内容与目标文件的摘要
+//
+//	@author duanzt
+//	@date 2026-10-19 13:40:12
+//	@param dest string 目标文件路径
+//	@param algo internal.HashAlgorithm 摘要算法
+//	@param expected string 发送内容的摘要（十六进制）
+//	@param sum func() (string, error) 计算目标文件摘要的方法
+//	@return error 计算异常时返回，摘要不一致时返回*internal.ChecksumError
+func Verify(dest string, algo internal.HashAlgorithm, expected string, sum func() (string, error)) error {
+	actual, err := sum()
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(expected, actual) {
+		return &internal.ChecksumError{Path: dest, Algorithm: algo, Expected: expected, Actual: strings.ToLower(actual)}
+	}
+	return nil
+}
@@ -0,0 +1,523 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 11:47:22
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-20 00:01:00
+ * @FilePath: options.go
+ * @Description: 暴露拷贝等操作的可选配置
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package gossh
+
+import (
+	"github.com/duanztop/gossh/internal"
+	"github.com/duanztop/gossh/internal/forward"
+	"github.com/duanztop/gossh/internal/inventory"
+	"github.com/duanztop/gossh/internal/sshconfig"
+	"github.com/duanztop/gossh/internal/tools"
+)
+
+type (
+
+	// CopyOption 拷贝配置项
+	CopyOption = internal.CopyOption
+
+	// CopySummary 目录拷贝结果汇总
+	CopySummary = internal.CopySummary
+
+	// CopyError 单个文件的拷贝异常
+	CopyError = internal.CopyError
+
+	// SymlinkPolicy 目录拷贝时符号链接的处理策略
+	SymlinkPolicy = internal.SymlinkPolicy
+
+	// HashAlgorithm 摘要算法
+	HashAlgorithm = internal.HashAlgorithm
+
+	// ChecksumError 传输后目标文件的摘要与发送内容不一致
+	ChecksumError = internal.ChecksumError
+
+	// IProgress 传输进度报告interface
+	IProgress = internal.IProgress
+
+	// ProgressInfo 单个文件的传输进度
+	ProgressInfo = internal.ProgressInfo
+
+	// ProgressFunc 使用函数实现IProgress
+	ProgressFunc = internal.ProgressFunc
+
+	// ProgressRenderer 多进度条渲染（实现IProgress）
+	ProgressRenderer = tools.ProgressRenderer
+
+	// ILimiter 传输限速interface
+	ILimiter = internal.ILimiter
+
+	// RateLimiter 令牌桶限速（实现ILimiter，速度可在传输过程中调整）
+	RateLimiter = tools.RateLimiter
+
+	// Protocol 远程连接的文件传输协议
+	Protocol = internal.Protocol
+
+	// IFile 文件句柄interface（远程连接为*sftp.File，本地连接为*os.File）
+	IFile = internal.IFile
+
+	// FindEntry 查找到的文件（包含完整路径、深度及文件信息）
+	FindEntry = internal.FindEntry
+
+	// FindType 查找的文件类型
+	FindType = internal.FindType
+
+	// FindOption 查找条件配置项
+	FindOption = internal.FindOption
+
+	// ArchiveFormat 归档格式
+	ArchiveFormat = internal.ArchiveFormat
+
+	// ArchiveOption 归档配置项
+	ArchiveOption = internal.ArchiveOption
+
+	// ArchiveManifest 归档的条目清单
+	ArchiveManifest = internal.ArchiveManifest
+
+	// DiffStatus 文件的比较结果
+	DiffStatus = internal.DiffStatus
+
+	// DiffEntry 单个有差异的文件（包含两端的大小、摘要及unified差异）
+	DiffEntry = internal.DiffEntry
+
+	// DiffReport 比较结果
+	DiffReport = internal.DiffReport
+
+	// DiffOption 比较配置项
+	DiffOption = internal.DiffOption
+
+	// IConnection 连接（远程ssh连接或本地连接）
+	IConnection = internal.IConnection
+
+	// Host 主机连接配置
+	Host = internal.Host
+
+	// DialFunc 根据主机连接配置建立连接
+	DialFunc = internal.DialFunc
+
+	// CommandResult 命令执行结果（标准输出、错误输出及退出码）
+	CommandResult = internal.CommandResult
+
+	// HostResult 单台主机的执行结果
+	HostResult = internal.HostResult
+
+	// FanoutReport 多主机执行结果
+	FanoutReport = internal.FanoutReport
+
+	// FanoutOption 多主机执行配置项
+	FanoutOption = internal.FanoutOption
+
+	// HostTask 在单台主机上执行的任务
+	HostTask = internal.HostTask
+
+	// BatchResult 滚动执行中单个批次的执行结果
+	BatchResult = internal.BatchResult
+
+	// RolloutState 滚动执行的状态记录
+	RolloutState = internal.RolloutState
+
+	// RolloutReport 滚动执行结果
+	RolloutReport = internal.RolloutReport
+
+	// RolloutOption 滚动执行配置项
+	RolloutOption = internal.RolloutOption
+
+	// Playbook 任务文件
+	Playbook = internal.Playbook
+
+	// Task 任务文件中的任务
+	Task = internal.Task
+
+	// CopyStep 任务的拷贝步骤
+	CopyStep = internal.CopyStep
+
+	// TemplateStep 任务的模板步骤
+	TemplateStep = internal.TemplateStep
+
+	// FetchStep 任务的拉取步骤
+	FetchStep = internal.FetchStep
+
+	// TaskStatus 任务执行状态
+	TaskStatus = internal.TaskStatus
+
+	// TaskResult 任务在单台主机上的执行结果
+	TaskResult = internal.TaskResult
+
+	// Recap 单台主机的任务执行汇总
+	Recap = internal.Recap
+
+	// PlaybookReport 任务文件的执行结果
+	PlaybookReport = internal.PlaybookReport
+
+	// PlaybookOption 任务文件执行配置项
+	PlaybookOption = internal.PlaybookOption
+
+	// Inventory 主机清单（分组、嵌套分组、主机及分组变量）
+	Inventory = inventory.Inventory
+
+	// InventoryGroup 主机清单中的分组
+	InventoryGroup = inventory.Group
+
+	// InventoryFormat 主机清单格式
+	InventoryFormat = inventory.Format
+
+	// ShellOption 交互式shell配置项
+	ShellOption = internal.ShellOption
+
+	// WindowSize 终端窗口大小
+	WindowSize = internal.WindowSize
+
+	// ForwardKind 端口转发类型
+	ForwardKind = internal.ForwardKind
+
+	// ForwardSpec 端口转发配置
+	ForwardSpec = internal.ForwardSpec
+
+	// Forwarder 运行中的端口转发
+	Forwarder = forward.Forwarder
+
+	// SSHConfig openssh客户端配置（~/.ssh/config）
+	SSHConfig = sshconfig.Config
+
+	// Compression tar流传输的压缩算法
+	Compression = internal.Compression
+
+	// SyncReport 目录同步结果
+	SyncReport = internal.SyncReport
+
+	// SyncChange 单个文件/目录的同步操作
+	SyncChange = internal.SyncChange
+
+	// SyncAction 同步操作类型
+	SyncAction = internal.SyncAction
+
+	// FollowLine 跟踪到的一行内容
+	FollowLine = internal.FollowLine
+
+	// FollowFunc 处理跟踪到的行
+	FollowFunc = internal.FollowFunc
+
+	// FollowOption 跟踪配置项
+	FollowOption = internal.FollowOption
+)
+
+const (
+
+	// ForwardLocal 本地转发（同ssh -L）
+	ForwardLocal = internal.ForwardLocal
+
+	// ForwardRemote 远程转发（同ssh -R）
+	ForwardRemote = internal.ForwardRemote
+
+	// ForwardDynamic socks5动态转发（同ssh -D）
+	ForwardDynamic = internal.ForwardDynamic
+
+	// TaskOk 任务执行成功且无变更
+	TaskOk = internal.TaskOk
+
+	// TaskChanged 任务执行成功且有变更
+	TaskChanged = internal.TaskChanged
+
+	// TaskSkipped 不满足执行条件，任务已跳过
+	TaskSkipped = internal.TaskSkipped
+
+	// TaskFailed 任务执行失败
+	TaskFailed = internal.TaskFailed
+
+	// InventoryINI ini格式的主机清单
+	InventoryINI = inventory.FormatINI
+
+	// InventoryYAML yaml格式的主机清单（json格式同样适用）
+	InventoryYAML = inventory.FormatYAML
+
+	// SymlinkFollow 跟随符号链接，拷贝链接指向的文件/目录（默认）
+	SymlinkFollow = internal.SymlinkFollow
+
+	// SymlinkPreserve 在目标端重建相同的符号链接
+	SymlinkPreserve = internal.SymlinkPreserve
+
+	// SymlinkSkip 跳过符号链接
+	SymlinkSkip = internal.SymlinkSkip
+
+	// HashSHA256 sha256摘要
+	HashSHA256 = internal.HashSHA256
+
+	// HashSHA512 sha512摘要
+	HashSHA512 = internal.HashSHA512
+
+	// HashMD5 md5摘要（兼容老旧主机）
+	HashMD5 = internal.HashMD5
+
+	// DefaultParallel 并行分块传输的默认并发数
+	DefaultParallel = internal.DefaultParallel
+
+	// DefaultChunkSize 并行分块传输的默认分块大小（4MiB）
+	DefaultChunkSize = internal.DefaultChunkSize
+
+	// ProtocolAuto 优先使用sftp，远端未开启sftp子系统时使用scp（默认）
+	ProtocolAuto = internal.ProtocolAuto
+
+	// ProtocolSFTP 仅使用sftp
+	ProtocolSFTP = internal.ProtocolSFTP
+
+	// ProtocolSCP 仅使用scp
+	ProtocolSCP = internal.ProtocolSCP
+
+	// CompressionNone tar流不压缩
+	CompressionNone = internal.CompressionNone
+
+	// CompressionGzip tar流使用gzip压缩
+	CompressionGzip = internal.CompressionGzip
+
+	// CompressionZstd tar流使用zstd压缩（远端需要GNU tar 1.31及以上）
+	CompressionZstd = internal.CompressionZstd
+
+	// SyncCreate 目标端不存在，新建
+	SyncCreate = internal.SyncCreate
+
+	// SyncUpdate 目标端不一致，覆盖
+	SyncUpdate = internal.SyncUpdate
+
+	// SyncDelete 删除目标端多余的文件/目录
+	SyncDelete = internal.SyncDelete
+
+	// DefaultFollowInterval 本地连接跟踪文件的默认检查间隔
+	DefaultFollowInterval = internal.DefaultFollowInterval
+
+	// FindAny 不限文件类型
+	FindAny = internal.FindAny
+
+	// FindFile 普通文件
+	FindFile = internal.FindFile
+
+	// FindDir 目录
+	FindDir = internal.FindDir
+
+	// FindSymlink 符号链接
+	FindSymlink = internal.FindSymlink
+
+	// ArchiveTar 不压缩的tar
+	ArchiveTar = internal.ArchiveTar
+
+	// ArchiveTarGz gzip压缩的tar
+	ArchiveTarGz = internal.ArchiveTarGz
+
+	// ArchiveTarZst zstd压缩的tar
+	ArchiveTarZst = internal.ArchiveTarZst
+
+	// ArchiveZip zip
+	ArchiveZip = internal.ArchiveZip
+
+	// DiffAdded 仅新端存在
+	DiffAdded = internal.DiffAdded
+
+	// DiffRemoved 仅原端存在
+	DiffRemoved = internal.DiffRemoved
+
+	// DiffModified 两端均存在但摘要不同
+	DiffModified = internal.DiffModified
+)
+
+var (
+
+	// WithInclude 只拷贝匹配任一glob的文件（不含"/"的glob匹配文件名，"**"匹配任意层目录）
+	WithInclude = internal.WithInclude
+
+	// WithExclude 跳过匹配任一glob的文件/目录
+	WithExclude = internal.WithExclude
+
+	// WithSymlinkPolicy 设置符号链接处理策略
+	WithSymlinkPolicy = internal.WithSymlinkPolicy
+
+	// WithPreserveMode 保留源文件权限
+	WithPreserveMode = internal.WithPreserveMode
+
+	// WithOwner 设置目标文件的所属用户及用户组（用户名/组名或数字id，为空表示不修改）
+	WithOwner = internal.WithOwner
+
+	// WithPreserveTimes 保留源文件修改时间
+	WithPreserveTimes = internal.WithPreserveTimes
+
+	// WithFailFast 任一文件拷贝失败时立即终止（默认继续拷贝其余文件并汇总异常）
+	WithFailFast = internal.WithFailFast
+
+	// WithResume 开启断点续传（校验目标文件已有内容末尾verifyBytes字节后从其末尾继续写入）
+	WithResume = internal.WithResume
+
+	// WithVerify 传输后校验目标文件摘要，不一致时返回*ChecksumError
+	WithVerify = internal.WithVerify
+
+	// WithAtomic 原子写入（先写入同目录下的临时文件，完成后重命名为目标文件）
+	WithAtomic = internal.WithAtomic
+
+	// WithBackup 原子写入，并在覆盖前备份原目标文件（目标文件+后缀）
+	WithBackup = internal.WithBackup
+
+	// WithProgress 报告传输进度（已传输字节数、总字节数、速度、剩余时间及结束状态）
+	WithProgress = internal.WithProgress
+
+	// WithParallel 并行分块传输大文件（并发数及分块大小可配置）
+	WithParallel = internal.WithParallel
+
+	// WithLimiter 传输限速（单次拷贝使用新的限速，多个连接共享同一限速时限制总速度）
+	WithLimiter = internal.WithLimiter
+
+	// WithCopyContext 传输的上下文（取消或超时时限速等待立即结束，例如在FanoutTask的任务中传入ctx）
+	WithCopyContext = internal.WithCopyContext
+
+	// WithProtocol 指定远程连接的文件传输协议（sftp或scp，默认sftp不可用时使用scp）
+	WithProtocol = internal.WithProtocol
+
+	// WithTar 目录拷贝时以tar流整体传输（远端运行tar命令，本地纯Go实现，适合大量小文件）
+	WithTar = internal.WithTar
+
+	// WithChecksum 目录同步时大小相同的文件再按摘要比较（默认按大小及修改时间比较）
+	WithChecksum = internal.WithChecksum
+
+	// WithDelete 目录同步时删除目标端多余的文件/目录
+	WithDelete = internal.WithDelete
+
+	// WithDryRun 目录同步时仅报告计划的操作，不修改目标端
+	WithDryRun = internal.WithDryRun
+
+	// WithFromStart 跟踪文件时从开头读取已有内容
+	WithFromStart = internal.WithFromStart
+
+	// WithFollowInterval 设置本地连接跟踪文件的检查间隔
+	WithFollowInterval = internal.WithFollowInterval
+
+	// WithName 按文件名通配符查找
+	WithName = internal.WithName
+
+	// WithType 按文件类型查找
+	WithType = internal.WithType
+
+	// WithSize 按文件大小查找
+	WithSize = internal.WithSize
+
+	// WithModifiedAfter 查找修改时间晚于指定时间的文件
+	WithModifiedAfter = internal.WithModifiedAfter
+
+	// WithModifiedBefore 查找修改时间早于指定时间的文件
+	WithModifiedBefore = internal.WithModifiedBefore
+
+	// WithOlderThan 查找修改时间在指定时长之前的文件
+	WithOlderThan = internal.WithOlderThan
+
+	// WithDepth 按相对于查找根目录的深度查找
+	WithDepth = internal.WithDepth
+
+	// WithArchiveFormat 指定归档格式
+	WithArchiveFormat = internal.WithArchiveFormat
+
+	// WithStripComponents 解压时去除条目路径的前几级
+	WithStripComponents = internal.WithStripComponents
+
+	// WithSameOwner 解压时恢复tar条目记录的所属用户
+	WithSameOwner = internal.WithSameOwner
+
+	// WithArchiveOwner 指定解压出的文件/创建的tar条目的所属用户及用户组
+	WithArchiveOwner = internal.WithArchiveOwner
+
+	// WithDiffHash 比较文件内容使用的摘要算法（默认sha256）
+	WithDiffHash = internal.WithDiffHash
+
+	// WithDiffContext unified差异保留的上下文行数（默认3）
+	WithDiffContext = internal.WithDiffContext
+
+	// WithDiffMaxTextSize 生成文本差异的最大文件大小（默认1MiB，超过时仅报告摘要不同）
+	WithDiffMaxTextSize = internal.WithDiffMaxTextSize
+
+	// WithDiffExclude 比较时排除匹配glob的文件/目录
+	WithDiffExclude = internal.WithDiffExclude
+
+	// WithConcurrency 多主机执行时同时执行的主机数（默认10）
+	WithConcurrency = internal.WithConcurrency
+
+	// WithHostTimeout 多主机执行时单台主机的超时时间（包含建立连接）
+	WithHostTimeout = internal.WithHostTimeout
+
+	// WithFanoutTimeout 多主机执行的全局超时时间
+	WithFanoutTimeout = internal.WithFanoutTimeout
+
+	// WithFanoutFailFast 任一主机失败时停止执行剩余主机
+	WithFanoutFailFast = internal.WithFanoutFailFast
+
+	// WithAbortThreshold 失败主机数占全部主机的百分比达到该值时停止执行剩余主机
+	WithAbortThreshold = internal.WithAbortThreshold
+
+	// WithDialer 多主机执行时建立连接的方法（默认使用Dial）
+	WithDialer = internal.WithDialer
+
+	// WithResultHandler 每台主机执行完成时的处理方法（可用于实时输出）
+	WithResultHandler = internal.WithResultHandler
+
+	// ErrFanoutAborted 失败的主机达到中止条件，剩余主机未执行
+	ErrFanoutAborted = internal.ErrFanoutAborted
+
+	// ShellTask 生成执行shell命令的任务（用于Rollout的任务、前置/后置任务及健康检查）
+	ShellTask = internal.ShellTask
+
+	// WithBatchSize 滚动执行时每批的主机数（默认1）
+	WithBatchSize = internal.WithBatchSize
+
+	// WithBatchPercent 滚动执行时每批的主机数占全部主机的百分比
+	WithBatchPercent = internal.WithBatchPercent
+
+	// WithBatchPause 滚动执行时批次之间的间隔
+	WithBatchPause = internal.WithBatchPause
+
+	// WithPreHook 滚动执行时每台主机执行任务前的前置任务
+	WithPreHook = internal.WithPreHook
+
+	// WithPostHook 滚动执行时每台主机执行任务后的后置任务
+	WithPostHook = internal.WithPostHook
+
+	// WithHealthCheck 滚动执行时每批完成后的健康检查
+	WithHealthCheck = internal.WithHealthCheck
+
+	// WithErrorBudget 滚动执行允许失败的主机数（默认0）
+	WithErrorBudget = internal.WithErrorBudget
+
+	// WithErrorBudgetPercent 滚动执行允许失败的主机数占全部主机的百分比
+	WithErrorBudgetPercent = internal.WithErrorBudgetPercent
+
+	// WithStateFile 滚动执行的状态记录文件（中断后再次执行时跳过已成功的主机）
+	WithStateFile = internal.WithStateFile
+
+	// WithRolloutFanout 滚动执行时批次内的执行配置（并发数、单台主机超时、建立连接的方法等）
+	WithRolloutFanout = internal.WithRolloutFanout
+
+	// WithBatchHandler 滚动执行时每批完成后的处理方法
+	WithBatchHandler = internal.WithBatchHandler
+
+	// ErrRolloutHalted 失败的主机超过错误预算，已停止滚动执行
+	ErrRolloutHalted = internal.ErrRolloutHalted
+
+	// WithExtraVars 任务文件的额外变量（覆盖任务文件及主机清单中的同名变量）
+	WithExtraVars = internal.WithExtraVars
+
+	// WithTaskHandler 每个任务执行完成时的处理方法（可用于实时输出）
+	WithTaskHandler = internal.WithTaskHandler
+
+	// WithPlaybookFanout 多主机执行任务文件时的执行配置（并发数、单台主机超时等）
+	WithPlaybookFanout = internal.WithPlaybookFanout
+
+	// WithPty 交互式shell请求伪终端（本地终端需由调用方切换为raw模式）
+	WithPty = internal.WithPty
+
+	// WithWindowResize 交互式shell的窗口大小变化通知
+	WithWindowResize = internal.WithWindowResize
+
+	// WithShellCommand 交互式shell执行的命令（不设置时启动登录shell）
+	WithShellCommand = internal.WithShellCommand
+
+	// ParseForwardSpec 解析ssh格式的端口转发参数（-L、-R为[bind_address:]port:host:hostport，-D为[bind_address:]port）
+	ParseForwardSpec = internal.ParseForwardSpec
+)
@@ -0,0 +1,169 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 20:16:02
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 20:16:02
+ * @FilePath: archive_test.go
+ * @Description: 归档文件创建及解压相关单元测试
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package unit
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/duanztop/gossh"
+	"github.com/duanztop/gossh/internal"
+)
+
+// writeEvilTar 生成包含不安全条目的tar（路径包含..、通过符号链接写入目标目录之外）
+//
+//	@author duanzt
+//	@date 2026-10-19 20:16:40
+//	@param t *testing.T
+//	@param name string 归档文件路径
+//	@param outside string 目标目录之外的目录
+//	@param entry string 不安全条目的类型（dotdot、symlink）
+func writeEvilTar(t *testing.T, name, outside, entry string) {
+	file, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	tw := tar.NewWriter(file)
+	write := func(header *tar.Header, content string) {
+		header.Size = int64(len(content))
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(&tar.Header{Name: "ok.txt", Mode: 0644, Typeflag: tar.TypeReg}, "ok")
+	if entry == "dotdot" {
+		write(&tar.Header{Name: "a/../../evil.txt", Mode: 0644, Typeflag: tar.TypeReg}, "evil")
+	} else {
+		write(&tar.Header{Name: "link", Linkname: outside, Typeflag: tar.TypeSymlink}, "")
+		write(&tar.Header{Name: "link/evil.txt", Mode: 0644, Typeflag: tar.TypeReg}, "evil")
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testArchive 对本地及远程连接执行相同的归档创建及解压
+//
+//	@author duanzt
+//	@date 2026-10-19 20:17:30
+//	@param t *testing.T
+//	@param conn internal.IConnection 连接
+func testArchive(t *testing.T, conn internal.IConnection) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	writeTree(t, src, map[string]string{
+		"app/README":      "readme",
+		"app/bin/run.sh":  "#!/bin/sh",
+		"app/conf/a.conf": "a=1",
+	})
+	if err := os.Chmod(filepath.Join(src, "app", "bin", "run.sh"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("README", filepath.Join(src, "app", "current")); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"app/", "app/README", "app/bin/", "app/bin/run.sh", "app/conf/", "app/conf/a.conf", "app/current"}
+
+	for _, ext := range []string{"tar", "tar.gz", "tar.zst", "zip"} {
+		archive := filepath.Join(dir, "app."+ext)
+		manifest, err := conn.CreateArchive(src, archive, gossh.WithArchiveOwner(os.Getuid(), os.Getgid()))
+		if err != nil {
+			t.Fatalf("%s: create: %v", ext, err)
+		}
+		sort.Strings(manifest.Paths)
+		if !equalStrings(manifest.Paths, want) || manifest.Files != 4 || manifest.Dirs != 3 {
+			t.Fatalf("%s: create manifest = %+v", ext, manifest)
+		}
+
+		dest := filepath.Join(dir, "dest-"+ext)
+		manifest, err = conn.ExtractArchive(archive, dest, gossh.WithStripComponents(1), gossh.WithArchiveOwner(os.Getuid(), -1))
+		if err != nil {
+			t.Fatalf("%s: extract: %v", ext, err)
+		}
+		sort.Strings(manifest.Paths)
+		if !equalStrings(manifest.Paths, []string{"README", "bin/", "bin/run.sh", "conf/", "conf/a.conf", "current"}) {
+			t.Fatalf("%s: extract manifest = %+v", ext, manifest)
+		}
+		if got := listTree(t, dest); !equalStrings(got, []string{"README", "bin/run.sh", "conf/a.conf", "current"}) {
+			t.Fatalf("%s: unexpected tree: %v", ext, got)
+		}
+		assertContent(t, filepath.Join(dest, "conf", "a.conf"), []byte("a=1"))
+		if info, err := os.Stat(filepath.Join(dest, "bin", "run.sh")); err != nil || info.Mode().Perm() != 0755 {
+			t.Fatalf("%s: run.sh mode = %v, %v", ext, info, err)
+		}
+		if target, err := os.Readlink(filepath.Join(dest, "current")); err != nil || target != "README" {
+			t.Fatalf("%s: current = %q, %v", ext, target, err)
+		}
+	}
+
+	// 路径穿越
+	outside := filepath.Join(dir, "outside")
+	if err := os.Mkdir(outside, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range []string{"dotdot", "symlink"} {
+		archive := filepath.Join(dir, entry+".tar")
+		writeEvilTar(t, archive, outside, entry)
+		dest := filepath.Join(dir, "evil-"+entry)
+		if _, err := conn.ExtractArchive(archive, dest); err == nil {
+			t.Fatalf("%s: extract should fail", entry)
+		}
+		for _, name := range []string{filepath.Join(dir, "evil.txt"), filepath.Join(outside, "evil.txt")} {
+			if _, err := os.Lstat(name); err == nil {
+				t.Fatalf("%s: %s should not be written", entry, name)
+			}
+		}
+	}
+	zipName := filepath.Join(dir, "evil.zip")
+	file, err := os.Create(zipName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(file)
+	if _, err := zw.Create("../evil.txt"); err != nil {
+		t.Fatal(err)
+	}
+	zw.Close()
+	file.Close()
+	if _, err := conn.ExtractArchive(zipName, filepath.Join(dir, "evil-zip")); err == nil {
+		t.Fatal("zip: extract should fail")
+	}
+	if _, err := os.Lstat(filepath.Join(dir, "evil.txt")); err == nil {
+		t.Fatal("zip: evil.txt should not be written")
+	}
+
+	if _, err := conn.ExtractArchive(filepath.Join(dir, "app.rar"), dir); err == nil {
+		t.Fatal("unknown format should fail")
+	}
+}
+
+// TestArchive 测试本地连接、远程连接（tar/zip命令）及远程连接（sftp流式处理）的归档创建及解压
+func TestArchive(t *testing.T) {
+	t.Run("local", func(t *testing.T) {
+		testArchive(t, gossh.Local())
+	})
+	t.Run("command", func(t *testing.T) {
+		testArchive(t, startTestServer(t).connect(t))
+	})
+	t.Run("sftp", func(t *testing.T) {
+		server := startTestServer(t)
+		server.noExec = true
+		testArchive(t, server.connect(t))
+	})
+}
@@ -0,0 +1,98 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 14:26:30
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 14:26:30
+ * @FilePath: atomic_test.go
+ * @Description: 原子写入相关单元测试
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package unit
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/duanztop/gossh"
+	"github.com/duanztop/gossh/internal"
+)
+
+// listDir 列出目录下的文件名
+func listDir(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+// TestAtomicCopy 测试原子写入（备份、失败清理、断点续传）
+func TestAtomicCopy(t *testing.T) {
+	server := startTestServer(t)
+	remoteCon := server.connect(t)
+
+	data := testData(80000)
+	for name, con := range map[string]internal.IConnection{"local": gossh.Local(), "remote": remoteCon} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			dest := filepath.Join(dir, "app.conf")
+			if err := os.WriteFile(dest, []byte("old"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			// 失败时目标文件保持原内容，临时文件被删除
+			err := con.CopyFileITR(&cutReader{r: bytes.NewReader(data), n: 1000}, dest, "0600", gossh.WithAtomic())
+			if !errors.Is(err, errCut) {
+				t.Fatalf("expected interrupted copy, got %v", err)
+			}
+			assertContent(t, dest, []byte("old"))
+			if names := listDir(t, dir); !equalStrings(names, []string{"app.conf"}) {
+				t.Fatalf("temp file left behind: %v", names)
+			}
+
+			if err := con.CopyFileITR(bytes.NewReader(data), dest, "0600", gossh.WithBackup(".bak")); err != nil {
+				t.Fatal(err)
+			}
+			assertContent(t, dest, data)
+			assertContent(t, dest+".bak", []byte("old"))
+			if info, err := os.Stat(dest); err != nil || info.Mode().Perm() != 0600 {
+				t.Fatalf("mode not applied: %v, %v", info, err)
+			}
+			if names := listDir(t, dir); !equalStrings(names, []string{"app.conf", "app.conf.bak"}) {
+				t.Fatalf("unexpected files: %v", names)
+			}
+
+			// 断点续传时临时文件保留，下次从临时文件继续写入
+			err = con.CopyFileITR(&cutReader{r: bytes.NewReader(data), n: 30000}, dest, "", gossh.WithAtomic(), gossh.WithResume(1024))
+			if !errors.Is(err, errCut) {
+				t.Fatalf("expected interrupted copy, got %v", err)
+			}
+			part := filepath.Join(dir, ".app.conf.gossh.part")
+			if info, err := os.Stat(part); err != nil || info.Size() != 30000 {
+				t.Fatalf("partial temp file: %v, %v", info, err)
+			}
+			assertContent(t, dest, data)
+
+			src := &countingReader{r: bytes.NewReader(data)}
+			if err := con.CopyFileITR(src, dest, "", gossh.WithAtomic(), gossh.WithResume(1024), gossh.WithVerify(gossh.HashSHA256)); err != nil {
+				t.Fatal(err)
+			}
+			if src.count != len(data)-30000 {
+				t.Fatalf("read %d bytes, want %d", src.count, len(data)-30000)
+			}
+			assertContent(t, dest, data)
+			if _, err := os.Stat(part); !os.IsNotExist(err) {
+				t.Fatalf("temp file should be renamed: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 13:58:12
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 13:58:12
+ * @FilePath: checksum_test.go
+ * @Description: 传输后摘要校验相关单元测试
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package unit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/duanztop/gossh"
+)
+
+// TestVerifyUpload 测试上传后的摘要校验（check-file扩展及命令两种方式）
+func TestVerifyUpload(t *testing.T) {
+	data := testData(150000)
+	for _, checkFile := range []bool{false, true} {
+		server := startTestServer(t)
+		server.checkFile = checkFile
+		con := server.connect(t)
+		for _, algo := range []gossh.HashAlgorithm{gossh.HashSHA256, gossh.HashSHA512, gossh.HashMD5} {
+			dest := filepath.Join(t.TempDir(), "app.bin")
+			if err := con.CopyFileITR(bytes.NewReader(data), dest, "0644", gossh.WithVerify(algo)); err != nil {
+				t.Fatalf("checkFile=%v %s: %v", checkFile, algo, err)
+			}
+			assertContent(t, dest, data)
+		}
+		if calls := atomic.LoadInt32(&server.checkFileCalls); checkFile && calls != 3 {
+			t.Fatalf("check-file called %d times, want 3", calls)
+		}
+	}
+
+	if err := gossh.Local().CopyFileITR(bytes.NewReader(data), filepath.Join(t.TempDir(), "a"), "", gossh.WithVerify("crc32")); err == nil {
+		t.Fatal("unsupported algorithm should fail")
+	}
+}
+
+// TestVerifyMismatch 测试摘要不一致时返回ChecksumError
+func TestVerifyMismatch(t *testing.T) {
+	server := startTestServer(t)
+	server.checkFile = true
+	server.badCheckFile = true
+	con := server.connect(t)
+
+	data := testData(5000)
+	err := con.CopyFileITR(bytes.NewReader(data), filepath.Join(t.TempDir(), "app.bin"), "0644", gossh.WithVerify(gossh.HashSHA256))
+	var checksumErr *gossh.ChecksumError
+	if !errors.As(err, &checksumErr) {
+		t.Fatalf("expected ChecksumError, got %v", err)
+	}
+	sum := sha256.Sum256(data)
+	if checksumErr.Expected != hex.EncodeToString(sum[:]) || checksumErr.Actual == checksumErr.Expected {
+		t.Fatalf("unexpected digests: %+v", checksumErr)
+	}
+}
+
+// TestVerifyDownloadResume 测试下载及断点续传时的摘要校验（包含已有部分）
+func TestVerifyDownloadResume(t *testing.T) {
+	server := startTestServer(t)
+	con := server.connect(t)
+
+	data := testData(120000)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "remote.bin")
+	if err := os.WriteFile(src, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	dest := filepath.Join(dir, "local.bin")
+	if err := con.CopyFileRTL(src, dest, "", gossh.WithVerify(gossh.HashMD5)); err != nil {
+		t.Fatal(err)
+	}
+	assertContent(t, dest, data)
+
+	if err := os.Truncate(dest, 50000); err != nil {
+		t.Fatal(err)
+	}
+	if err := con.CopyFileRTL(src, dest, "", gossh.WithResume(1024), gossh.WithVerify(gossh.HashSHA256)); err != nil {
+		t.Fatal(err)
+	}
+	assertContent(t, dest, data)
+
+	upload := filepath.Join(dir, "upload.bin")
+	if err := os.WriteFile(upload, data[:50000], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := con.CopyFileLTR(src, upload, "", gossh.WithResume(0), gossh.WithVerify(gossh.HashSHA512)); err != nil {
+		t.Fatal(err)
+	}
+	assertContent(t, upload, data)
+}
@@ -0,0 +1,142 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 23:49:20
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:49:20
+ * @FilePath: cli_test.go
+ * @Description: gossh命令行工具单元测试（127.0.0.1、localhost使用本地连接）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// buildCLI 编译gossh命令行工具
+//
+//	@author duanzt
+//	@date 2026-10-19 23:49:50
+//	@param t *testing.T
+//	@return string 可执行文件路径
+func buildCLI(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("命令行测试依赖sh")
+	}
+	bin := filepath.Join(t.TempDir(), "gossh")
+	if out, err := exec.Command("go", "build", "-o", bin, "../cmd/gossh").CombinedOutput(); err != nil {
+		t.Fatalf("build: %v\n%s", err, out)
+	}
+	return bin
+}
+
+// runCLI 执行命令行工具
+//
+//	@author duanzt
+//	@date 2026-10-19 23:50:20
+//	@param t *testing.T
+//	@param bin string 可执行文件路径
+//	@param args ...string 参数
+//	@return string 标准输出
+//	@return string 标准错误
+//	@return int 退出码
+func runCLI(t *testing.T, bin string, args ...string) (string, string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(bin, args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		t.Fatal(err)
+	}
+	return stdout.String(), stderr.String(), cmd.ProcessState.ExitCode()
+}
+
+// TestCLI 测试gossh命令行工具的exec、cp、shell、run子命令、主机解析、输出格式及退出码
+func TestCLI(t *testing.T) {
+	bin := buildCLI(t)
+	dir := t.TempDir()
+
+	// exec：json输出，全部成功时退出码为0
+	stdout, stderr, code := runCLI(t, bin, "exec", "-o", "json", "-H", "127.0.0.1,localhost", "echo hi")
+	var report struct {
+		Results []struct {
+			Host   string `json:"host"`
+			Status string `json:"status"`
+			Stdout string `json:"stdout"`
+		} `json:"results"`
+		Succeeded int `json:"succeeded"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil || code != 0 || report.Succeeded != 2 {
+		t.Fatalf("exec = %q, %q, %d, %v", stdout, stderr, code, err)
+	}
+	var hosts []string
+	for _, r := range report.Results {
+		if r.Status != "ok" || r.Stdout != "hi\n" {
+			t.Fatalf("result = %+v", r)
+		}
+		hosts = append(hosts, r.Host)
+	}
+	sort.Strings(hosts)
+	if !equalStrings(hosts, []string{"127.0.0.1", "localhost"}) {
+		t.Fatalf("hosts = %v", hosts)
+	}
+
+	// ~/.ssh/config格式的别名；存在失败的主机时退出码为1
+	config := filepath.Join(dir, "config")
+	if err := os.WriteFile(config, []byte("Host box\n  HostName 127.0.0.1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr, code = runCLI(t, bin, "exec", "-F", config, "-o", "jsonl", "-H", "box", "exit 3")
+	if code != 1 || !strings.Contains(stdout, `"host":"box"`) || !strings.Contains(stdout, `"exit_code":3`) {
+		t.Fatalf("failed = %q, %q, %d", stdout, stderr, code)
+	}
+
+	// 参数不正确时退出码为2
+	for _, args := range [][]string{{"bogus"}, {"exec", "-H", "localhost"}, {"exec", "-o", "xml", "-H", "localhost", "true"}, {"cp", "-H", "localhost", "a", "b"}} {
+		if _, stderr, code := runCLI(t, bin, args...); code != 2 {
+			t.Fatalf("%v = %d, %q", args, code, stderr)
+		}
+	}
+	if _, _, code := runCLI(t, bin, "exec", "-h"); code != 0 {
+		t.Fatalf("help = %d", code)
+	}
+
+	// cp：上传到多台主机时每台主机各执行一次
+	src := filepath.Join(dir, "src.txt")
+	if err := os.WriteFile(src, []byte("content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dest := filepath.Join(dir, "dest") + "/"
+	if stdout, stderr, code := runCLI(t, bin, "cp", "-H", "localhost", src, ":"+dest); code != 0 {
+		t.Fatalf("cp = %q, %q, %d", stdout, stderr, code)
+	}
+	assertContent(t, filepath.Join(dest, "src.txt"), []byte("content"))
+
+	// shell：执行命令时退出码与命令一致
+	if stdout, _, code := runCLI(t, bin, "shell", "localhost", "--", "echo out; exit 7"); code != 7 || stdout != "out\n" {
+		t.Fatalf("shell = %q, %d", stdout, code)
+	}
+
+	// run：在本机执行任务文件，jsonl输出每个任务的结果及汇总
+	playbook := filepath.Join(dir, "site.yml")
+	if err := os.WriteFile(playbook, []byte("tasks:\n  - name: greet\n    shell: echo {{ .who }}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr, code = runCLI(t, bin, "run", "-local", "-o", "jsonl", "-e", "who=world", playbook)
+	if code != 0 || !strings.Contains(stdout, `"stdout":"world\n"`) || !strings.Contains(stdout, `"type":"recap"`) {
+		t.Fatalf("run = %q, %q, %d", stdout, stderr, code)
+	}
+}
@@ -0,0 +1,110 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 20:50:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 20:50:10
+ * @FilePath: diff_test.go
+ * @Description: 两个连接之间目录/文件比较相关单元测试
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package unit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/duanztop/gossh"
+)
+
+// TestDiff 测试本地目录与远端目录的比较（新增、删除、修改、二进制、目录与文件互换、排除）及差异流输出
+func TestDiff(t *testing.T) {
+	server := startTestServer(t)
+	server.noExec = true
+	con := server.connect(t)
+
+	dir := t.TempDir()
+	oldDir, newDir := filepath.Join(dir, "old"), filepath.Join(dir, "new")
+	writeTree(t, oldDir, map[string]string{
+		"same.txt":       "same\n",
+		"conf/app.conf":  "a=1\nb=2\nc=3\n",
+		"removed/x.txt":  "x\n",
+		"bin.dat":        "\x00\x01old",
+		"swap":           "file\n",
+		"skip/ignore.md": "old\n",
+	})
+	writeTree(t, newDir, map[string]string{
+		"same.txt":       "same\n",
+		"conf/app.conf":  "a=1\nb=3\nc=3",
+		"added.txt":      "new\n",
+		"bin.dat":        "\x00\x01new",
+		"swap/inner.txt": "inner\n",
+		"skip/ignore.md": "new\n",
+	})
+
+	report, err := gossh.Diff(gossh.Local(), oldDir, con, newDir, gossh.WithDiffExclude("skip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, entry := range report.Entries {
+		got = append(got, string(entry.Status)+" "+entry.Path)
+	}
+	want := []string{"added added.txt", "modified bin.dat", "modified conf/app.conf", "removed removed/x.txt", "removed swap", "added swap/inner.txt"}
+	if !equalStrings(got, want) {
+		t.Fatalf("entries = %v", got)
+	}
+	if report.Unchanged != 1 || report.Count(gossh.DiffAdded) != 2 || report.Count(gossh.DiffRemoved) != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if entry := report.Entries[1]; !entry.Binary || entry.Unified != "" || entry.OldSum == entry.NewSum {
+		t.Fatalf("bin.dat = %+v", entry)
+	}
+	wantConf := "--- a/conf/app.conf\n+++ b/conf/app.conf\n@@ -1,3 +1,3 @@\n a=1\n-b=2\n-c=3\n+b=3\n+c=3\n\\ No newline at end of file\n"
+	if entry := report.Entries[2]; entry.Unified != wantConf || entry.OldSize != 12 || entry.NewSize != 11 {
+		t.Fatalf("app.conf = %+v", entry)
+	}
+	if entry := report.Entries[0]; entry.Unified != "--- /dev/null\n+++ b/added.txt\n@@ -0,0 +1 @@\n+new\n" {
+		t.Fatalf("added.txt = %q", entry.Unified)
+	}
+
+	// 边比较边输出的差异流与比较结果的WriteTo一致
+	var stream, written bytes.Buffer
+	if _, err := gossh.DiffTo(&stream, con, oldDir, gossh.Local(), newDir, gossh.WithDiffExclude("skip"), gossh.WithDiffContext(0)); err != nil {
+		t.Fatal(err)
+	}
+	report, err = gossh.Diff(con, oldDir, gossh.Local(), newDir, gossh.WithDiffExclude("skip"), gossh.WithDiffContext(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := report.WriteTo(&written); err != nil {
+		t.Fatal(err)
+	}
+	if stream.String() != written.String() || !strings.Contains(stream.String(), "Binary files a/bin.dat and b/bin.dat differ\n") ||
+		!strings.Contains(stream.String(), "--- a/removed/x.txt\n+++ /dev/null\n@@ -1 +0,0 @@\n-x\n") {
+		t.Fatalf("unexpected stream:\n%s", stream.String())
+	}
+
+	// 比较单个文件，超过文本大小限制时不生成差异
+	report, err = gossh.Diff(con, filepath.Join(oldDir, "conf", "app.conf"), con, filepath.Join(newDir, "conf", "app.conf"),
+		gossh.WithDiffMaxTextSize(4), gossh.WithDiffHash(gossh.HashMD5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Entries) != 1 || report.Entries[0].Path != "app.conf" || !report.Entries[0].Binary || len(report.Entries[0].OldSum) != 32 {
+		t.Fatalf("unexpected report: %+v", report.Entries)
+	}
+
+	if _, err := gossh.Diff(con, oldDir, con, filepath.Join(newDir, "same.txt")); err == nil {
+		t.Fatal("dir vs file should fail")
+	}
+	if err := os.Remove(filepath.Join(newDir, "same.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gossh.Diff(gossh.Local(), filepath.Join(oldDir, "same.txt"), con, filepath.Join(newDir, "same.txt")); err == nil {
+		t.Fatal("missing file should fail")
+	}
+}
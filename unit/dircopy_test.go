@@ -0,0 +1,205 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 11:50:05
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:54:10
+ * @FilePath: dircopy_test.go
+ * @Description: 目录递归拷贝相关单元测试
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/duanztop/gossh"
+)
+
+// writeTree 按相对路径创建测试文件
+//
+//	@author duanzt
+//	@date 2026-10-19 11:50:42
+//	@param t *testing.T
+//	@param root string 根目录
+//	@param files map[string]string 相对路径 -> 文件内容
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// listTree 列出目录下全部文件的相对路径
+//
+//	@author duanzt
+//	@date 2026-10-19 11:51:20
+//	@param t *testing.T
+//	@param root string 根目录
+//	@return []string 排序后的相对路径
+func listTree(t *testing.T, root string) []string {
+	t.Helper()
+	var names []string
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			rel, _ := filepath.Rel(root, p)
+			names = append(names, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TestCopyDirRoundTrip 测试目录上传及下载（过滤、符号链接、保留权限及修改时间）
+//
+//	@author duanzt
+//	@date 2026-10-19 11:52:03
+//	@param t *testing.T
+func TestCopyDirRoundTrip(t *testing.T) {
+	conn := startTestServer(t).connect(t)
+	src := t.TempDir()
+	writeTree(t, src, map[string]string{
+		"bin/app.sh":         "#!/bin/sh",
+		"conf/app.yaml":      "name: gossh",
+		"conf/app.yaml.bak":  "old",
+		"logs/app.log":       "log",
+		"static/css/app.css": "body{}",
+	})
+	if err := os.Chmod(filepath.Join(src, "bin/app.sh"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(src, "conf/app.yaml"), mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("conf/app.yaml", filepath.Join(src, "current.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(".", filepath.Join(src, "loop")); err != nil {
+		t.Fatal(err)
+	}
+
+	remoteDir := filepath.Join(t.TempDir(), "deploy")
+	summary, err := conn.CopyDirLTR(src, remoteDir,
+		gossh.WithExclude("logs", "*.bak", "loop"),
+		gossh.WithSymlinkPolicy(gossh.SymlinkPreserve),
+		gossh.WithPreserveMode(), gossh.WithPreserveTimes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Files != 3 || summary.Symlinks != 1 || summary.Skipped != 3 {
+		t.Fatalf("summary = %+v", summary)
+	}
+	want := []string{"bin/app.sh", "conf/app.yaml", "current.yaml", "static/css/app.css"}
+	if got := listTree(t, remoteDir); !equalStrings(got, want) {
+		t.Fatalf("uploaded tree = %v, want %v", got, want)
+	}
+	if info, err := os.Stat(filepath.Join(remoteDir, "bin/app.sh")); err != nil || info.Mode().Perm() != 0750 {
+		t.Fatalf("mode not preserved: %v, %v", info, err)
+	}
+	if info, err := os.Stat(filepath.Join(remoteDir, "conf/app.yaml")); err != nil || !info.ModTime().Equal(mtime) {
+		t.Fatalf("mtime not preserved: %v, %v", info, err)
+	}
+	if target, err := os.Readlink(filepath.Join(remoteDir, "current.yaml")); err != nil || target != "conf/app.yaml" {
+		t.Fatalf("symlink not preserved: %q, %v", target, err)
+	}
+
+	localDir := filepath.Join(t.TempDir(), "download")
+	summary, err = conn.CopyDirRTL(remoteDir, localDir, gossh.WithInclude("conf/**"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := listTree(t, localDir); !equalStrings(got, []string{"conf/app.yaml"}) || summary.Bytes != int64(len("name: gossh")) {
+		t.Fatalf("downloaded tree = %v, summary = %+v", got, summary)
+	}
+}
+
+// TestCopyDirContinueOnError 测试单个文件失败时继续拷贝其余文件
+//
+//	@author duanzt
+//	@date 2026-10-19 11:53:30
+//	@param t *testing.T
+func TestCopyDirContinueOnError(t *testing.T) {
+	l := gossh.Local()
+	src := t.TempDir()
+	writeTree(t, src, map[string]string{"a.txt": "a", "b.txt": "b", "c.txt": "c"})
+	if err := os.Symlink("missing", filepath.Join(src, "broken")); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := t.TempDir()
+	summary, err := l.CopyDirLTR(src, dest)
+	if err == nil || len(summary.Errors) != 1 || summary.Files != 3 {
+		t.Fatalf("summary = %+v, err = %v", summary, err)
+	}
+
+	// 按文件名排序拷贝，broken之后的c.txt不再拷贝
+	summary, err = l.CopyDirLTR(src, t.TempDir(), gossh.WithFailFast())
+	if err == nil || len(summary.Errors) != 1 || summary.Files != 2 {
+		t.Fatalf("fail fast summary = %+v, err = %v", summary, err)
+	}
+}
+
+// TestCopyDirExistingMode 测试目录拷贝时新文件沿用源文件权限，已存在的文件未开启WithPreserveMode时保持原权限（sftp、scp及本地一致）
+func TestCopyDirExistingMode(t *testing.T) {
+	server := startTestServer(t)
+	conns := map[string]gossh.IConnection{"local": gossh.Local(), "sftp": server.connect(t)}
+	server.noSftp = true
+	conns["scp"] = server.connect(t)
+	for name, conn := range conns {
+		src := t.TempDir()
+		writeTree(t, src, map[string]string{"old.txt": "new content", "new.txt": "new"})
+		if err := os.Chmod(filepath.Join(src, "new.txt"), 0640); err != nil {
+			t.Fatal(err)
+		}
+		dest := t.TempDir()
+		writeTree(t, dest, map[string]string{"old.txt": "old"})
+		if err := os.Chmod(filepath.Join(dest, "old.txt"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := conn.CopyDirLTR(src, dest); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		assertContent(t, filepath.Join(dest, "old.txt"), []byte("new content"))
+		for file, want := range map[string]os.FileMode{"old.txt": 0600, "new.txt": 0640} {
+			if info, err := os.Stat(filepath.Join(dest, file)); err != nil || info.Mode().Perm() != want {
+				t.Fatalf("%s: %s mode = %v, %v, want %v", name, file, info.Mode().Perm(), err, want)
+			}
+		}
+		if _, err := conn.CopyDirLTR(src, dest, gossh.WithPreserveMode()); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if info, err := os.Stat(filepath.Join(dest, "old.txt")); err != nil || info.Mode().Perm() != 0644 {
+			t.Fatalf("%s: preserved mode = %v, %v", name, info.Mode().Perm(), err)
+		}
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
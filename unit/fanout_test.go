@@ -0,0 +1,197 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 21:15:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:55:30
+ * @FilePath: fanout_test.go
+ * @Description: 多主机并发执行相关单元测试
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package unit
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/duanztop/gossh"
+	"github.com/duanztop/gossh/internal"
+	"github.com/duanztop/gossh/internal/remote"
+	"golang.org/x/crypto/ssh"
+)
+
+// testHosts 生成连接到进程内ssh服务的主机列表，前bad台使用错误的密码
+//
+//	@author duanzt
+//	@date 2026-10-19 21:15:40
+//	@param server *testServer 进程内ssh服务
+//	@param n int 主机数
+//	@param bad int 连接失败的主机数
+//	@return []gossh.Host 主机列表
+func testHosts(server *testServer, n, bad int) []gossh.Host {
+	hosts := make([]gossh.Host, n)
+	for i := range hosts {
+		hosts[i] = gossh.Host{Name: fmt.Sprintf("web%02d", i+1), Addr: server.listener.Addr().String(), User: testUsername, Password: testPassword}
+		if i < bad {
+			hosts[i].Password = "wrong"
+		}
+	}
+	return hosts
+}
+
+// writeTestKey 生成ecdsa私钥文件
+//
+//	@author duanzt
+//	@date 2026-10-19 23:55:30
+//	@param t *testing.T
+//	@return string 私钥文件路径
+//	@return ssh.PublicKey 公钥
+func writeTestKey(t *testing.T) (string, ssh.PublicKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Join(t.TempDir(), "id_ecdsa")
+	if err := os.WriteFile(name, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ssh.NewPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return name, pub
+}
+
+// TestDialAuth 测试同时配置私钥及密码时依次尝试私钥及密码验证（127.0.0.1经gossh.Dial会使用本地连接，直接调用remote.Dial）
+func TestDialAuth(t *testing.T) {
+	server := startTestServer(t)
+	authorized, pub := writeTestKey(t)
+	other, _ := writeTestKey(t)
+	server.authorizedKey = pub
+	addr := server.listener.Addr().String()
+	for _, host := range []gossh.Host{
+		{Addr: addr, User: testUsername, PrivateKey: authorized},
+		{Addr: addr, User: testUsername, PrivateKey: authorized, Password: "wrong"},
+		{Addr: addr, User: testUsername, PrivateKey: other, Password: testPassword},
+	} {
+		conn, err := remote.Dial(context.Background(), host)
+		if err != nil {
+			t.Fatalf("dial %+v: %v", host, err)
+		}
+		conn.Close()
+	}
+	if _, err := remote.Dial(context.Background(), gossh.Host{Addr: addr, User: testUsername, PrivateKey: other, Password: "wrong"}); err == nil {
+		t.Fatal("dial with wrong key and password should fail")
+	}
+}
+
+// TestRun 测试执行命令获取标准输出、错误输出、退出码及取消
+func TestRun(t *testing.T) {
+	server := startTestServer(t)
+	for name, con := range map[string]internal.IConnection{"local": gossh.Local(), "remote": server.connect(t)} {
+		t.Run(name, func(t *testing.T) {
+			result, err := con.Run(context.Background(), "echo out; echo err >&2; exit 3")
+			if err == nil || result.Stdout != "out\n" || result.Stderr != "err\n" || result.ExitCode != 3 {
+				t.Fatalf("result = %+v, %v", result, err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			result, err = con.Run(ctx, "sleep 5")
+			if !errors.Is(err, context.DeadlineExceeded) || result.ExitCode != -1 || time.Since(start) > 3*time.Second {
+				t.Fatalf("result = %+v, %v", result, err)
+			}
+		})
+	}
+}
+
+// TestFanout 测试多主机并发执行（并发数限制、结果顺序、失败继续、FailFast、失败百分比中止、单台及全局超时）
+func TestFanout(t *testing.T) {
+	server := startTestServer(t)
+	ctx := context.Background()
+	var active, maxActive int32
+	dial := gossh.WithDialer(func(ctx context.Context, host gossh.Host) (internal.IConnection, error) {
+		n := atomic.AddInt32(&active, 1)
+		for {
+			old := atomic.LoadInt32(&maxActive)
+			if n <= old || atomic.CompareAndSwapInt32(&maxActive, old, n) {
+				break
+			}
+		}
+		return remote.Dial(ctx, host)
+	})
+	done := gossh.WithResultHandler(func(*gossh.HostResult) {
+		atomic.AddInt32(&active, -1)
+	})
+
+	hosts := testHosts(server, 8, 2)
+	report, err := gossh.Fanout(ctx, hosts, "echo hi; echo warn >&2", dial, done, gossh.WithConcurrency(3))
+	if err == nil || report.Succeeded != 6 || report.Failed != 2 || report.Skipped != 0 || report.Aborted {
+		t.Fatalf("report = %+v, %v", report, err)
+	}
+	if maxActive > 3 {
+		t.Fatalf("max concurrency = %d", maxActive)
+	}
+	for i, result := range report.Results {
+		if result.Host.Name != hosts[i].Name {
+			t.Fatalf("results[%d] = %s", i, result.Host.Name)
+		}
+		if i < 2 && (result.Err == nil || !result.Failed() || result.ExitCode != -1) {
+			t.Fatalf("results[%d] = %+v", i, result)
+		}
+		if i >= 2 && (result.Err != nil || result.Stdout != "hi\n" || result.Stderr != "warn\n" || result.ExitCode != 0 || result.Duration <= 0) {
+			t.Fatalf("results[%d] = %+v", i, result)
+		}
+	}
+
+	report, err = gossh.Fanout(ctx, testHosts(server, 3, 0), "exit 4", dial, done)
+	if err == nil || report.Failed != 3 || report.Results[2].ExitCode != 4 {
+		t.Fatalf("report = %+v, %v", report, err)
+	}
+
+	// FailFast：首台失败后不再执行剩余主机
+	report, err = gossh.Fanout(ctx, testHosts(server, 5, 1), "true", dial, done, gossh.WithConcurrency(1), gossh.WithFanoutFailFast())
+	if err == nil || !report.Aborted || report.Failed != 1 || report.Skipped != 4 || !errors.Is(report.Results[4].Err, gossh.ErrFanoutAborted) {
+		t.Fatalf("report = %+v, %v", report, err)
+	}
+
+	// 失败主机达到30%时中止
+	report, _ = gossh.Fanout(ctx, testHosts(server, 10, 5), "true", dial, done, gossh.WithConcurrency(1), gossh.WithAbortThreshold(30))
+	if !report.Aborted || report.Failed != 3 || report.Succeeded != 0 || report.Skipped != 7 {
+		t.Fatalf("report = %+v", report)
+	}
+	report, err = gossh.Fanout(ctx, testHosts(server, 10, 2), "true", dial, done, gossh.WithConcurrency(1), gossh.WithAbortThreshold(30))
+	if report.Aborted || report.Failed != 2 || report.Succeeded != 8 {
+		t.Fatalf("report = %+v, %v", report, err)
+	}
+
+	// 单台主机超时
+	start := time.Now()
+	report, _ = gossh.Fanout(ctx, testHosts(server, 2, 0), "sleep 5", dial, done, gossh.WithHostTimeout(200*time.Millisecond))
+	if report.Failed != 2 || !errors.Is(report.Results[0].Err, context.DeadlineExceeded) || time.Since(start) > 3*time.Second {
+		t.Fatalf("report = %+v", report)
+	}
+
+	// 全局超时：执行中的主机被结束，剩余主机未执行
+	start = time.Now()
+	report, _ = gossh.Fanout(ctx, testHosts(server, 4, 0), "sleep 5", dial, done, gossh.WithConcurrency(1), gossh.WithFanoutTimeout(300*time.Millisecond))
+	if report.Failed != 1 || report.Skipped != 3 || !errors.Is(report.Results[3].Err, context.DeadlineExceeded) || time.Since(start) > 3*time.Second {
+		t.Fatalf("report = %+v", report)
+	}
+}
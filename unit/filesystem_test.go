@@ -0,0 +1,283 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 10:26:51
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-20 00:09:10
+ * @FilePath: filesystem_test.go
+ * @Description: 文件系统操作相关单元测试
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package unit
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/duanztop/gossh"
+	"github.com/duanztop/gossh/internal"
+)
+
+// testFileSystem 对本地及远程连接执行相同的文件系统操作，校验两者语义一致
+//
+//	@author duanzt
+//	@date 2026-10-19 10:27:30
+//	@param t *testing.T
+//	@param conn internal.IConnection 连接
+func testFileSystem(t *testing.T, conn internal.IConnection) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "a", "b")
+	if err := conn.MkdirAll(dir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.MkdirAll(dir, 0750); err != nil {
+		t.Fatalf("MkdirAll on existing dir: %v", err)
+	}
+	if err := conn.Mkdir(dir, 0750); !errors.Is(err, fs.ErrExist) {
+		t.Fatalf("Mkdir on existing dir = %v, want fs.ErrExist", err)
+	}
+
+	file := filepath.Join(dir, "file.txt")
+	if err := conn.CopyFileITR(strings.NewReader("hello gossh"), file, "0644"); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Truncate(file, 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Chmod(file, 0600); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := conn.Chtimes(file, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	info, err := conn.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != 5 || info.Mode().Perm() != 0600 || !info.ModTime().Equal(mtime) {
+		t.Fatalf("Stat = size %d mode %v mtime %v", info.Size(), info.Mode(), info.ModTime())
+	}
+
+	link := filepath.Join(root, "link")
+	if err := conn.Symlink(file, link); err != nil {
+		t.Fatal(err)
+	}
+	if target, err := conn.Readlink(link); err != nil || target != file {
+		t.Fatalf("Readlink = %q, %v", target, err)
+	}
+	if info, err := conn.Lstat(link); err != nil || info.Mode()&fs.ModeSymlink == 0 {
+		t.Fatalf("Lstat = %v, %v", info, err)
+	}
+
+	renamed := filepath.Join(dir, "renamed.txt")
+	if err := conn.CopyFileITR(strings.NewReader("old"), renamed, "0644"); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Rename(file, renamed); err != nil {
+		t.Fatalf("Rename over existing file: %v", err)
+	}
+	// 源路径不存在时失败，不删除已存在的目标
+	var linkErr *os.LinkError
+	if err := conn.Rename(file, renamed); !errors.Is(err, fs.ErrNotExist) || !errors.As(err, &linkErr) {
+		t.Fatalf("Rename missing file = %v, want *os.LinkError with fs.ErrNotExist", err)
+	}
+	infos, err := conn.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 1 || infos[0].Name() != "renamed.txt" || infos[0].Size() != 5 {
+		t.Fatalf("ReadDir = %v", infos)
+	}
+
+	if _, err := conn.Stat(file); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Stat missing file = %v, want fs.ErrNotExist", err)
+	}
+	var pathErr *fs.PathError
+	if _, err := conn.Stat(file); !errors.As(err, &pathErr) || pathErr.Path != file {
+		t.Fatalf("Stat missing file = %v, want *fs.PathError", err)
+	}
+	if err := conn.Remove(filepath.Join(root, "a")); err == nil {
+		t.Fatal("Remove on non-empty dir should fail")
+	}
+	if err := conn.RemoveAll(filepath.Join(root, "a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.RemoveAll(filepath.Join(root, "a")); err != nil {
+		t.Fatalf("RemoveAll on missing path: %v", err)
+	}
+	if _, err := conn.Stat(dir); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Stat removed dir = %v, want fs.ErrNotExist", err)
+	}
+}
+
+// testFileHandle 对本地及远程连接执行相同的文件句柄读写操作，校验两者语义一致
+//
+//	@author duanzt
+//	@date 2026-10-19 19:12:20
+//	@param t *testing.T
+//	@param conn internal.IConnection 连接
+func testFileHandle(t *testing.T, conn internal.IConnection) {
+	root := t.TempDir()
+	name := filepath.Join(root, "handle.txt")
+	file, err := conn.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(file, "hello world"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.WriteAt([]byte("W"), 6); err != nil {
+		t.Fatal(err)
+	}
+	if pos, err := file.Seek(0, io.SeekStart); err != nil || pos != 0 {
+		t.Fatalf("Seek = %d, %v", pos, err)
+	}
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(file, buf); err != nil || string(buf) != "hello" {
+		t.Fatalf("Read = %q, %v", buf, err)
+	}
+	if n, err := file.ReadAt(buf, 6); n != 5 || string(buf) != "World" {
+		t.Fatalf("ReadAt = %q, %v", buf[:n], err)
+	}
+	if err := file.Truncate(5); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := file.Stat(); err != nil || info.Size() != 5 {
+		t.Fatalf("Stat = %v, %v", info, err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, err := conn.ReadFile(name); err != nil || string(data) != "hello" {
+		t.Fatalf("ReadFile = %q, %v", data, err)
+	}
+	file, err = conn.OpenFile(name, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(file, "!"); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	if data, err := conn.ReadFile(name); err != nil || string(data) != "hello!" {
+		t.Fatalf("ReadFile after append = %q, %v", data, err)
+	}
+
+	// 新建文件使用指定权限，已存在的文件保留原权限
+	written := filepath.Join(root, "written.txt")
+	data := []byte(strings.Repeat("gossh", 20000))
+	if err := conn.WriteFile(written, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := conn.ReadFile(written); err != nil || string(got) != string(data) {
+		t.Fatalf("ReadFile large = %d bytes, %v", len(got), err)
+	}
+	if err := conn.WriteFile(name, []byte("x"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := conn.Stat(written); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("WriteFile mode = %v, %v", info, err)
+	}
+	if info, err := conn.Stat(name); err != nil || info.Mode().Perm() == 0600 || info.Size() != 1 {
+		t.Fatalf("WriteFile existing = %v, %v", info, err)
+	}
+
+	missing := filepath.Join(root, "missing.txt")
+	if _, err := conn.ReadFile(missing); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("ReadFile missing file = %v, want fs.ErrNotExist", err)
+	}
+	if _, err := conn.OpenFile(missing, os.O_RDONLY, 0); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("OpenFile missing file = %v, want fs.ErrNotExist", err)
+	}
+}
+
+// TestLocalFileSystem 测试本地连接的文件系统操作
+//
+//	@author duanzt
+//	@date 2026-10-19 10:31:02
+//	@param t *testing.T
+func TestLocalFileSystem(t *testing.T) {
+	l := gossh.Local()
+	defer l.Close()
+	testFileSystem(t, l)
+	testFileHandle(t, l)
+}
+
+// TestRemoteFileSystem 测试远程连接（sftp）的文件系统操作
+//
+//	@author duanzt
+//	@date 2026-10-19 10:31:25
+//	@param t *testing.T
+func TestRemoteFileSystem(t *testing.T) {
+	conn := startTestServer(t).connect(t)
+	testFileSystem(t, conn)
+	testFileHandle(t, conn)
+}
+
+// TestRemoteFileSystemNoPosixRename 测试服务端不支持posix-rename扩展时的文件系统操作
+//
+//	@author duanzt
+//	@date 2026-10-20 00:05:40
+//	@param t *testing.T
+func TestRemoteFileSystemNoPosixRename(t *testing.T) {
+	server := startTestServer(t)
+	server.noPosixRename = true
+	testFileSystem(t, server.connect(t))
+}
+
+// TestSftpClientReuse 测试同一连接内复用sftp客户端，sftp子系统断开后重新创建
+//
+//	@author duanzt
+//	@date 2026-10-20 00:04:00
+//	@param t *testing.T
+func TestSftpClientReuse(t *testing.T) {
+	server := startTestServer(t)
+	conn := server.connect(t)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.txt")
+	if err := os.WriteFile(src, []byte("content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		if err := conn.CopyFileLTR(src, filepath.Join(dir, name), "0644"); err != nil {
+			t.Fatal(err)
+		}
+		if err := conn.CopyFileRTL(filepath.Join(dir, name), filepath.Join(dir, "local-"+name), "0644"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := conn.Stat(filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := atomic.LoadInt32(&server.sftpRequests); n != 1 {
+		t.Fatalf("sftp subsystem requests = %d, want 1", n)
+	}
+
+	// sftp子系统断开后（客户端随之关闭）重新创建
+	server.dropSftp()
+	var err error
+	for i := 0; i < 100; i++ {
+		if _, err = conn.Stat(src); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&server.sftpRequests); n != 2 {
+		t.Fatalf("sftp subsystem requests = %d, want 2", n)
+	}
+	if _, err := conn.Stat(src); err != nil || atomic.LoadInt32(&server.sftpRequests) != 2 {
+		t.Fatalf("stat = %v, requests = %d", err, server.sftpRequests)
+	}
+}
@@ -0,0 +1,135 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 19:38:02
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:52:00
+ * @FilePath: find_test.go
+ * @Description: 查找文件相关单元测试
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package unit
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/duanztop/gossh"
+	"github.com/duanztop/gossh/internal"
+)
+
+// testFind 对本地及远程连接执行相同的查找，校验结果一致
+//
+//	@author duanzt
+//	@date 2026-10-19 19:38:40
+//	@param t *testing.T
+//	@param conn internal.IConnection 连接
+func testFind(t *testing.T, conn internal.IConnection) {
+	root := filepath.Join(t.TempDir(), "app")
+	writeTree(t, root, map[string]string{
+		"a.log":          "0123456789",
+		"b.txt":          "abc",
+		"sub/c.log":      strings.Repeat("c", 100),
+		"sub/deep/d.log": "d",
+	})
+	old := time.Now().Add(-10 * 24 * time.Hour).Truncate(time.Second)
+	for _, name := range []string{"a.log", "sub/deep/d.log"} {
+		if err := os.Chtimes(filepath.Join(root, name), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("a.log", filepath.Join(root, "link.log")); err != nil {
+		t.Fatal(err)
+	}
+
+	find := func(opts ...gossh.FindOption) []*gossh.FindEntry {
+		t.Helper()
+		entries, err := conn.Find(context.Background(), root, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return entries
+	}
+	paths := func(entries []*gossh.FindEntry) []string {
+		rels := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			rel, _ := filepath.Rel(root, entry.Path)
+			rels = append(rels, filepath.ToSlash(rel))
+		}
+		return rels
+	}
+
+	entries := find(gossh.WithName("*.log"), gossh.WithType(gossh.FindFile))
+	if got := paths(entries); !equalStrings(got, []string{"a.log", "sub/c.log", "sub/deep/d.log"}) {
+		t.Fatalf("find *.log = %v", got)
+	}
+	if info := entries[0]; info.Size() != 10 || info.Mode().Perm() != 0644 || !info.ModTime().Equal(old) || info.Depth != 1 {
+		t.Fatalf("unexpected entry: %+v, %v, %v", info, info.Mode(), info.ModTime())
+	}
+	if got := paths(find(gossh.WithName("*.log"), gossh.WithType(gossh.FindFile), gossh.WithOlderThan(7*24*time.Hour))); !equalStrings(got, []string{"a.log", "sub/deep/d.log"}) {
+		t.Fatalf("find old *.log = %v", got)
+	}
+	if got := paths(find(gossh.WithDepth(1, 1))); !equalStrings(got, []string{"a.log", "b.txt", "link.log", "sub"}) {
+		t.Fatalf("find depth 1 = %v", got)
+	}
+	if got := paths(find(gossh.WithType(gossh.FindSymlink))); !equalStrings(got, []string{"link.log"}) {
+		t.Fatalf("find symlink = %v", got)
+	}
+	if got := paths(find(gossh.WithType(gossh.FindFile), gossh.WithSize(50, -1))); !equalStrings(got, []string{"sub/c.log"}) {
+		t.Fatalf("find size >= 50 = %v", got)
+	}
+	if got := paths(find(gossh.WithName("*.txt", "d.*"), gossh.WithModifiedAfter(old))); !equalStrings(got, []string{"b.txt"}) {
+		t.Fatalf("find new *.txt d.* = %v", got)
+	}
+	entries = find(gossh.WithType(gossh.FindDir))
+	if got := paths(entries); !equalStrings(got, []string{".", "sub", "sub/deep"}) || entries[0].Path != root || entries[0].Depth != 0 {
+		t.Fatalf("find dir = %v", got)
+	}
+
+	if _, err := conn.Find(context.Background(), filepath.Join(root, "missing")); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("find missing root = %v, want fs.ErrNotExist", err)
+	}
+}
+
+// TestFind 测试本地连接、远程连接（find命令）及远程连接（sftp遍历）查找文件
+func TestFind(t *testing.T) {
+	t.Run("local", func(t *testing.T) {
+		testFind(t, gossh.Local())
+	})
+	t.Run("command", func(t *testing.T) {
+		conn := startTestServer(t).connect(t)
+		testFind(t, conn)
+		// ctx取消等异常原样返回，不回退为sftp遍历
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := conn.Find(ctx, t.TempDir()); !errors.Is(err, context.Canceled) {
+			t.Fatalf("find canceled = %v, want context.Canceled", err)
+		}
+	})
+	t.Run("sftp", func(t *testing.T) {
+		server := startTestServer(t)
+		server.noExec = true
+		testFind(t, server.connect(t))
+	})
+}
+
+// TestGlob 测试本地及远程连接的通配符查找
+func TestGlob(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"a/x.log": "", "b/y.log": "", "b/z.txt": ""})
+	for _, conn := range []internal.IConnection{gossh.Local(), startTestServer(t).connect(t)} {
+		matches, err := conn.Glob(filepath.Join(root, "*", "*.log"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !equalStrings(matches, []string{filepath.Join(root, "a", "x.log"), filepath.Join(root, "b", "y.log")}) {
+			t.Fatalf("Glob = %v", matches)
+		}
+	}
+}
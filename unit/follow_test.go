@@ -0,0 +1,151 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 18:55:30
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 18:55:30
+ * @FilePath: follow_test.go
+ * @Description: 文件跟踪相关单元测试
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/duanztop/gossh"
+	"github.com/duanztop/gossh/internal"
+)
+
+// startFollow 在goroutine中跟踪文件
+//
+//	@author duanzt
+//	@date 2026-10-19 18:56:02
+//	@param t *testing.T
+//	@param con internal.IConnection 连接
+//	@param name string 文件路径
+//	@param opts ...gossh.FollowOption 跟踪配置
+//	@return chan *gossh.FollowLine 跟踪到的行
+//	@return func() error 停止跟踪并返回Follow的结果
+func startFollow(t *testing.T, con internal.IConnection, name string, opts ...gossh.FollowOption) (chan *gossh.FollowLine, func() error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	lines := make(chan *gossh.FollowLine, 100)
+	result := make(chan error, 1)
+	go func() {
+		result <- con.Follow(ctx, name, func(line *gossh.FollowLine) {
+			lines <- line
+		}, opts...)
+	}()
+	stop := func() error {
+		cancel()
+		select {
+		case err := <-result:
+			return err
+		case <-time.After(5 * time.Second):
+			t.Fatal("follow did not stop")
+			return nil
+		}
+	}
+	t.Cleanup(func() { cancel() })
+	return lines, stop
+}
+
+// expectLines 等待跟踪到指定的行
+//
+//	@author duanzt
+//	@date 2026-10-19 18:56:40
+//	@param t *testing.T
+//	@param lines chan *gossh.FollowLine 跟踪到的行
+//	@param want ...string 期望的行内容
+func expectLines(t *testing.T, lines chan *gossh.FollowLine, want ...string) {
+	t.Helper()
+	for _, text := range want {
+		select {
+		case line := <-lines:
+			if line.Text != text {
+				t.Fatalf("unexpected line: %q, want %q", line.Text, text)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for %q", text)
+		}
+	}
+}
+
+// appendFile 向文件末尾追加内容
+//
+//	@author duanzt
+//	@date 2026-10-19 18:57:12
+//	@param t *testing.T
+//	@param name string 文件路径
+//	@param content string 追加的内容
+func appendFile(t *testing.T, name, content string) {
+	t.Helper()
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestFollowLocal 测试本地跟踪文件（新增内容、不完整的行、截断、轮转）
+func TestFollowLocal(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, name, "old\n")
+	con := gossh.Local()
+	lines, stop := startFollow(t, con, name, gossh.WithFollowInterval(10*time.Millisecond))
+	time.Sleep(50 * time.Millisecond)
+
+	appendFile(t, name, "a\n\nb")
+	time.Sleep(50 * time.Millisecond)
+	appendFile(t, name, "c\r\n")
+	expectLines(t, lines, "a", "", "bc")
+
+	// 截断
+	if err := os.Truncate(name, 0); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	appendFile(t, name, "t\n")
+	expectLines(t, lines, "t")
+
+	// 轮转：重命名后旧文件的剩余内容、新文件的内容
+	if err := os.Rename(name, name+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendFile(t, name+".1", "late\n")
+	time.Sleep(50 * time.Millisecond)
+	appendFile(t, name, "new\n")
+	expectLines(t, lines, "late", "new")
+
+	appendFile(t, name, "meta\n")
+	line := <-lines
+	if line.Host != con.GetAddr() || line.Path != name || line.Time.IsZero() {
+		t.Fatalf("unexpected line: %+v", line)
+	}
+	if err := stop(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestFollowRemote 测试远程跟踪文件（从开头读取、新增内容、取消）
+func TestFollowRemote(t *testing.T) {
+	server := startTestServer(t)
+	con := server.connect(t)
+	name := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, name, "x\ny\n")
+
+	lines, stop := startFollow(t, con, name, gossh.WithFromStart())
+	expectLines(t, lines, "x", "y")
+	appendFile(t, name, "z\n")
+	expectLines(t, lines, "z")
+	if err := stop(); err != nil {
+		t.Fatal(err)
+	}
+}
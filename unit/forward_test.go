@@ -0,0 +1,272 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 23:47:20
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:56:30
+ * @FilePath: forward_test.go
+ * @Description: 交互式shell及端口转发相关单元测试
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package unit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/duanztop/gossh"
+	"golang.org/x/crypto/ssh"
+)
+
+// startEchoServer 启动按行回显的tcp服务，测试结束时自动关闭
+//
+//	@author duanzt
+//	@date 2026-10-19 23:47:50
+//	@param tb testing.TB
+//	@return string 监听地址
+func startEchoServer(tb testing.TB) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// assertEcho 通过连接发送一行内容并校验回显
+//
+//	@author duanzt
+//	@date 2026-10-19 23:48:20
+//	@param t *testing.T
+//	@param conn net.Conn 连接
+//	@param line string 内容
+func assertEcho(t *testing.T, conn net.Conn, line string) {
+	t.Helper()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := io.WriteString(conn, line+"\n"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil || got != line+"\n" {
+		t.Fatalf("echo = %q, %v", got, err)
+	}
+}
+
+// TestShell 测试交互式shell（输入输出、退出码、伪终端及窗口大小变化）
+func TestShell(t *testing.T) {
+	server := startTestServer(t)
+	conn := server.connect(t)
+
+	var stdout, stderr bytes.Buffer
+	err := conn.Shell(context.Background(), strings.NewReader("echo hello\necho oops >&2\nexit 3\n"), &stdout, &stderr)
+	var exitErr *ssh.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitStatus() != 3 || stdout.String() != "hello\n" || stderr.String() != "oops\n" {
+		t.Fatalf("shell = %q, %q, %v", stdout.String(), stderr.String(), err)
+	}
+
+	// 伪终端及窗口大小变化
+	stdin, input := io.Pipe()
+	resize := make(chan gossh.WindowSize)
+	done := make(chan error, 1)
+	stdout.Reset()
+	go func() {
+		done <- conn.Shell(context.Background(), stdin, &stdout, io.Discard,
+			gossh.WithPty("vt100", gossh.WindowSize{Width: 120, Height: 40}), gossh.WithWindowResize(resize))
+	}()
+	resize <- gossh.WindowSize{Width: 100, Height: 30}
+	io.WriteString(input, "exit 0\n")
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100 && atomic.LoadInt32(&server.windowChanges) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if atomic.LoadInt32(&server.ptyRequests) != 1 || atomic.LoadInt32(&server.windowChanges) != 1 {
+		t.Fatalf("pty = %d, window changes = %d", server.ptyRequests, server.windowChanges)
+	}
+
+	// 执行命令、ctx取消
+	stdout.Reset()
+	if err := conn.Shell(context.Background(), nil, &stdout, io.Discard, gossh.WithShellCommand("echo $((1+2))")); err != nil || stdout.String() != "3\n" {
+		t.Fatalf("command = %q, %v", stdout.String(), err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if err := conn.Shell(ctx, nil, io.Discard, io.Discard, gossh.WithShellCommand("sleep 10")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("cancel = %v", err)
+	}
+
+	// 本地连接
+	stdout.Reset()
+	err = gossh.Local().Shell(context.Background(), strings.NewReader("echo local\nexit 5\n"), &stdout, io.Discard)
+	var localErr interface{ ExitCode() int }
+	if !errors.As(err, &localErr) || localErr.ExitCode() != 5 || stdout.String() != "local\n" {
+		t.Fatalf("local = %q, %v", stdout.String(), err)
+	}
+}
+
+// TestParseForwardSpec 测试解析ssh格式的端口转发参数
+func TestParseForwardSpec(t *testing.T) {
+	for _, c := range []struct {
+		kind   gossh.ForwardKind
+		spec   string
+		listen string
+		target string
+	}{
+		{gossh.ForwardLocal, "8080:db:3306", "127.0.0.1:8080", "db:3306"},
+		{gossh.ForwardLocal, "*:8080:10.0.0.5:80", ":8080", "10.0.0.5:80"},
+		{gossh.ForwardLocal, "[::1]:8080:[fe80::1]:80", "[::1]:8080", "[fe80::1]:80"},
+		{gossh.ForwardRemote, "0.0.0.0:0:localhost:9000", "0.0.0.0:0", "localhost:9000"},
+		{gossh.ForwardDynamic, "1080", "127.0.0.1:1080", ""},
+		{gossh.ForwardDynamic, "localhost:1080", "127.0.0.1:1080", ""},
+	} {
+		spec, err := gossh.ParseForwardSpec(c.kind, c.spec)
+		if err != nil || spec.Listen != c.listen || spec.Target != c.target {
+			t.Fatalf("%s %s = %+v, %v", c.kind, c.spec, spec, err)
+		}
+	}
+	if spec, _ := gossh.ParseForwardSpec(gossh.ForwardLocal, "8080:db:3306"); spec.String() != "-L 127.0.0.1:8080:db:3306" {
+		t.Fatalf("string = %s", spec)
+	}
+	for _, spec := range []string{"8080", "8080:db", "0:db:3306", "8080:db:0", "70000:db:3306", "a:b:c:d:e"} {
+		if _, err := gossh.ParseForwardSpec(gossh.ForwardLocal, spec); err == nil {
+			t.Fatalf("%s should fail", spec)
+		}
+	}
+	if _, err := gossh.ParseForwardSpec(gossh.ForwardDynamic, "1:2:3"); err == nil {
+		t.Fatal("dynamic with target should fail")
+	}
+}
+
+// TestForward 测试本地转发、远程转发及socks5动态转发
+func TestForward(t *testing.T) {
+	server := startTestServer(t)
+	conn := server.connect(t)
+	echo := startEchoServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 本地转发：经ssh连接访问回显服务
+	local, err := gossh.Forward(ctx, conn, gossh.ForwardSpec{Kind: gossh.ForwardLocal, Listen: "127.0.0.1:0", Target: echo}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := net.Dial("tcp", local.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEcho(t, c, "local")
+	c.Close()
+	if local.Accepted() != 1 || atomic.LoadInt32(&server.forwards) != 1 {
+		t.Fatalf("accepted = %d, forwards = %d", local.Accepted(), server.forwards)
+	}
+
+	// 远程转发：在服务端监听（端口由服务端分配），连接转发到本地的回显服务
+	remote, err := gossh.Forward(ctx, conn, gossh.ForwardSpec{Kind: gossh.ForwardRemote, Listen: "127.0.0.1:0", Target: echo}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err = net.Dial("tcp", remote.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEcho(t, c, "remote")
+	c.Close()
+
+	// socks5动态转发：域名及ipv4地址
+	var handlerErr atomic.Value
+	dynamic, err := gossh.Forward(ctx, conn, gossh.ForwardSpec{Kind: gossh.ForwardDynamic, Listen: "127.0.0.1:0"}, func(err error) { handlerErr.Store(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, port, _ := net.SplitHostPort(echo)
+	portNum, _ := net.LookupPort("tcp", port)
+	portBytes := binary.BigEndian.AppendUint16(nil, uint16(portNum))
+	for _, addr := range [][]byte{
+		append([]byte{3, byte(len("localhost"))}, "localhost"...),
+		append([]byte{1}, net.ParseIP(host).To4()...),
+	} {
+		c, err = net.Dial("tcp", dynamic.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.SetDeadline(time.Now().Add(5 * time.Second))
+		c.Write([]byte{5, 1, 0})
+		reply := make([]byte, 2)
+		if _, err := io.ReadFull(c, reply); err != nil || !bytes.Equal(reply, []byte{5, 0}) {
+			t.Fatalf("method = %v, %v", reply, err)
+		}
+		c.Write(append(append([]byte{5, 1, 0}, addr...), portBytes...))
+		reply = make([]byte, 10)
+		if _, err := io.ReadFull(c, reply); err != nil || reply[1] != 0 {
+			t.Fatalf("connect = %v, %v", reply, err)
+		}
+		assertEcho(t, c, "dynamic")
+		c.Close()
+	}
+
+	// 目标地址无法连接时回复失败并调用异常处理方法
+	c, err = net.Dial("tcp", dynamic.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetDeadline(time.Now().Add(5 * time.Second))
+	c.Write([]byte{5, 1, 0, 5, 1, 0, 1, 127, 0, 0, 1, 0, 1})
+	reply := make([]byte, 12)
+	if n, _ := io.ReadFull(c, reply); n != 12 || reply[3] != 1 {
+		t.Fatalf("reply = %v", reply[:n])
+	}
+	c.Close()
+	for i := 0; i < 100 && handlerErr.Load() == nil; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err, _ := handlerErr.Load().(error); err == nil || !strings.Contains(err.Error(), "-D") {
+		t.Fatalf("handler = %v", err)
+	}
+
+	// 连接断开时停止转发
+	dropped := server.connect(t)
+	f, err := gossh.Forward(ctx, dropped, gossh.ForwardSpec{Kind: gossh.ForwardLocal, Listen: "127.0.0.1:0", Target: echo}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dropped.Close()
+	select {
+	case <-f.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("forwarder should stop when the connection is lost")
+	}
+	if _, err := net.DialTimeout("tcp", f.Addr().String(), time.Second); err == nil {
+		t.Fatal("forwarder still listening after the connection is lost")
+	}
+
+	// 停止后不再监听
+	cancel()
+	for _, f := range []*gossh.Forwarder{local, remote, dynamic} {
+		f.Close()
+		if _, err := net.DialTimeout("tcp", f.Addr().String(), time.Second); err == nil && f != remote {
+			t.Fatalf("%s still listening", f.Spec())
+		}
+	}
+}
@@ -0,0 +1,220 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 22:13:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-20 00:02:00
+ * @FilePath: inventory_test.go
+ * @Description: 主机清单相关单元测试
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package unit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/duanztop/gossh"
+)
+
+const testINIInventory = `
+bastion ansible_host=10.0.0.1
+db01:2222 ansible_host=10.0.1.1
+
+[web]
+web[01:03].dc1 ansible_user=deploy
+web04.dc1 ansible_host="10.0.2.4" ansible_ssh_private_key_file=~/.ssh/web_key
+
+[db]
+db01
+db-[a:b] ansible_port=3306
+
+[dc1:children]
+web
+db
+
+[dc1:vars]
+ansible_ssh_common_args='-o ProxyJump=jump@bastion.dc1:2200'
+region = "dc1"
+
+[web:vars]
+region=dc1-web
+
+[all:vars]
+ansible_user=admin
+region=global
+`
+
+const testYAMLInventory = `
+all:
+  vars:
+    ansible_user: admin
+  hosts:
+    bastion:
+      ansible_host: 10.0.0.1
+  children:
+    dc1:
+      vars:
+        ansible_ssh_common_args: -J jump@bastion.dc1:2200
+        region: dc1
+      children:
+        web:
+          vars:
+            region: dc1-web
+          hosts:
+            web[01:03].dc1:
+              ansible_user: deploy
+            web04.dc1:
+              ansible_host: 10.0.2.4
+              ansible_ssh_private_key_file: ~/.ssh/web_key
+        db:
+          hosts:
+            db01:
+              ansible_host: 10.0.1.1
+              ansible_port: 2222
+            db-[a:b]:
+              ansible_port: 3306
+              tags: [primary, ssd]
+`
+
+// TestInventory 测试主机清单（ini及yaml格式、主机范围、嵌套分组、变量优先级、匹配规则、跳板机）
+func TestInventory(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	for _, tc := range []struct {
+		format gossh.InventoryFormat
+		data   string
+	}{{gossh.InventoryINI, testINIInventory}, {gossh.InventoryYAML, testYAMLInventory}} {
+		inv, err := gossh.ParseInventory([]byte(tc.data), tc.format)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.format, err)
+		}
+		want := []string{"bastion", "db01", "web01.dc1", "web02.dc1", "web03.dc1", "web04.dc1", "db-a", "db-b"}
+		if tc.format == gossh.InventoryYAML {
+			want = []string{"bastion", "web01.dc1", "web02.dc1", "web03.dc1", "web04.dc1", "db01", "db-a", "db-b"}
+		}
+		if names := inv.HostNames(); !equalStrings(names, want) {
+			t.Fatalf("%s: hosts = %v", tc.format, names)
+		}
+
+		// 变量优先级及连接配置
+		hosts, err := inv.Hosts("web")
+		if err != nil || len(hosts) != 4 {
+			t.Fatalf("%s: web = %v, %v", tc.format, hosts, err)
+		}
+		web01, web04 := hosts[0], hosts[3]
+		if web01.Name != "web01.dc1" || web01.Addr != "web01.dc1" || web01.User != "deploy" || web01.Jump != "jump@bastion.dc1:2200" ||
+			web01.Vars["region"] != "dc1-web" || !equalStrings(web01.Groups, []string{"dc1", "web"}) {
+			t.Fatalf("%s: web01 = %+v", tc.format, web01)
+		}
+		if web04.Addr != "10.0.2.4" || web04.User != "admin" || web04.PrivateKey != filepath.Join(home, ".ssh/web_key") {
+			t.Fatalf("%s: web04 = %+v", tc.format, web04)
+		}
+		db01, err := inv.Host("db01")
+		if err != nil || db01.Addr != "10.0.1.1:2222" || db01.Vars["region"] != "dc1" {
+			t.Fatalf("%s: db01 = %+v, %v", tc.format, db01, err)
+		}
+		bastion, _ := inv.Host("bastion")
+		if bastion.Addr != "10.0.0.1" || bastion.Jump != "" || bastion.Vars["region"] == "dc1" || !equalStrings(bastion.Groups, nil) {
+			t.Fatalf("%s: bastion = %+v", tc.format, bastion)
+		}
+
+		// 匹配规则
+		for pattern, want := range map[string][]string{
+			"ungrouped":            {"bastion"},
+			"dc1:!web":             {"db01", "db-a", "db-b"},
+			"web*:&dc1:!web0[23]*": {"web01.dc1", "web04.dc1"},
+			"db-a,bastion":         {"bastion", "db-a"},
+			"~^web0[34]":           {"web03.dc1", "web04.dc1"},
+			"all:!dc1":             {"bastion"},
+		} {
+			names, err := inv.Match(pattern)
+			if err != nil {
+				t.Fatalf("%s: %s: %v", tc.format, pattern, err)
+			}
+			if !equalStrings(names, want) {
+				t.Fatalf("%s: %s = %v, want %v", tc.format, pattern, names, want)
+			}
+		}
+		if _, err := inv.Hosts("nosuchgroup"); err == nil {
+			t.Fatalf("%s: unknown pattern should fail", tc.format)
+		}
+	}
+
+	// 行内注释（引号内的#保留）
+	inv, err := gossh.ParseInventory([]byte("[web]\nweb1 ansible_host=10.0.0.1  # primary\nweb2 ansible_password=\"p#ss word\" region=a#b\n[dc:children]\nweb # all web hosts\n"), gossh.InventoryINI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	web1, _ := inv.Host("web1")
+	web2, _ := inv.Host("web2")
+	if web1.Addr != "10.0.0.1" || web2.Password != "p#ss word" || web2.Vars["region"] != "a#b" || !equalStrings(web2.Groups, []string{"dc", "web"}) {
+		t.Fatalf("inline comment = %+v, %+v", web1, web2)
+	}
+
+	// 格式错误
+	for _, data := range []string{"[web\nhost1", "[web]\nweb[01:x]", "[web:hosts]\nhost1", "[web]\nhost1 user"} {
+		if _, err := gossh.ParseInventory([]byte(data), gossh.InventoryINI); err == nil {
+			t.Fatalf("%q should fail", data)
+		}
+	}
+	if _, err := gossh.ParseInventory([]byte("all:\n  hosts: [a, b]"), gossh.InventoryYAML); err == nil {
+		t.Fatal("yaml hosts list should fail")
+	}
+}
+
+// TestInventoryDial 测试从主机清单文件生成的连接配置经过跳板机建立连接
+func TestInventoryDial(t *testing.T) {
+	jump := startTestServer(t)
+	target := startTestServer(t)
+	name := filepath.Join(t.TempDir(), "hosts.yml")
+	data := "all:\n  vars:\n    ansible_user: " + testUsername + "\n    ansible_password: " + testPassword + "\n" +
+		"  hosts:\n    app01:\n      ansible_host: 127.0.0.1\n      ansible_port: " + portOf(target) + "\n" +
+		"      ansible_ssh_extra_args: -o 'ProxyJump " + jump.listener.Addr().String() + "'\n"
+	if err := os.WriteFile(name, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	inv, err := gossh.LoadInventory(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts, err := inv.Hosts("all")
+	if err != nil || len(hosts) != 1 || hosts[0].Jump != jump.listener.Addr().String() {
+		t.Fatalf("hosts = %+v, %v", hosts, err)
+	}
+	conn, err := gossh.Dial(context.Background(), hosts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	result, err := conn.Run(context.Background(), "echo hello")
+	if err != nil || result.Stdout != "hello\n" || atomic.LoadInt32(&jump.forwards) != 1 {
+		t.Fatalf("result = %+v, %v, forwards = %d", result, err, jump.forwards)
+	}
+
+	// 经过跳板机连接目标主机较慢时，ctx超时即返回
+	jump.forwardDelay = 2 * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := gossh.Dial(ctx, hosts[0]); !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > time.Second {
+		t.Fatalf("dial through slow jump = %v after %s", err, time.Since(start))
+	}
+
+	// 跳板机不可用
+	hosts[0].Jump = "127.0.0.1:1"
+	if _, err := gossh.Dial(context.Background(), hosts[0]); err == nil {
+		t.Fatal("dial through unavailable jump host should fail")
+	}
+}
+
+// portOf 获取进程内ssh服务的端口
+func portOf(s *testServer) string {
+	addr := s.listener.Addr().String()
+	return addr[strings.LastIndex(addr, ":")+1:]
+}
@@ -0,0 +1,87 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 10:56:20
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 10:56:20
+ * @FilePath: iofs_test.go
+ * @Description: io/fs.FS适配相关单元测试
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package unit
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/duanztop/gossh"
+	"github.com/duanztop/gossh/internal"
+)
+
+// testDirFS 使用fstest.TestFS校验连接适配后的fs.FS
+//
+//	@author duanzt
+//	@date 2026-10-19 10:57:02
+//	@param t *testing.T
+//	@param conn internal.IConnection 连接
+func testDirFS(t *testing.T, conn internal.IConnection) {
+	root := t.TempDir()
+	files := map[string]string{
+		"index.html":           "<h1>gossh</h1>",
+		"conf/app.yaml":        "name: gossh",
+		"conf/nginx/site.conf": "server {}",
+	}
+	for name, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fsys := gossh.DirFS(conn, root)
+	if err := fstest.TestFS(fsys, "index.html", "conf/app.yaml", "conf/nginx/site.conf"); err != nil {
+		t.Fatal(err)
+	}
+	matches, err := fs.Glob(fsys, "conf/*.yaml")
+	if err != nil || len(matches) != 1 || matches[0] != "conf/app.yaml" {
+		t.Fatalf("Glob = %v, %v", matches, err)
+	}
+	if _, err := fs.ReadFile(fsys, "../etc/passwd"); !errors.Is(err, fs.ErrInvalid) {
+		t.Fatalf("ReadFile invalid path = %v, want fs.ErrInvalid", err)
+	}
+	if _, err := fs.Stat(fsys, "missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Stat missing = %v, want fs.ErrNotExist", err)
+	}
+	sub, err := fs.Sub(fsys, "conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := fs.ReadFile(sub, "nginx/site.conf"); err != nil || string(data) != "server {}" {
+		t.Fatalf("Sub ReadFile = %q, %v", data, err)
+	}
+}
+
+// TestLocalDirFS 测试本地连接适配的fs.FS
+//
+//	@author duanzt
+//	@date 2026-10-19 10:58:15
+//	@param t *testing.T
+func TestLocalDirFS(t *testing.T) {
+	testDirFS(t, gossh.Local())
+}
+
+// TestRemoteDirFS 测试远程连接（sftp）适配的fs.FS
+//
+//	@author duanzt
+//	@date 2026-10-19 10:58:40
+//	@param t *testing.T
+func TestRemoteDirFS(t *testing.T) {
+	testDirFS(t, startTestServer(t).connect(t))
+}
@@ -0,0 +1,139 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 16:24:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-20 00:01:00
+ * @FilePath: limit_test.go
+ * @Description: 传输限速相关单元测试
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package unit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/duanztop/gossh"
+	"github.com/duanztop/gossh/internal"
+)
+
+// TestRateLimiter 测试令牌桶限速（突发、不限速及运行时调整速度）
+func TestRateLimiter(t *testing.T) {
+	l := gossh.NewRateLimiter(1<<20, 64<<10)
+	start := time.Now()
+	l.WaitN(context.Background(), 64<<10) // 初始令牌桶已满，不等待
+	l.WaitN(context.Background(), 256<<10)
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond || elapsed > time.Second {
+		t.Fatalf("unexpected elapsed: %v", elapsed)
+	}
+
+	// 调整速度后等待中的传输立即按新速度执行
+	l.SetRate(1<<10, 1<<10)
+	done := make(chan struct{})
+	go func() {
+		l.WaitN(context.Background(), 1<<20)
+		close(done)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	l.SetRate(0, 0)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("SetRate did not take effect")
+	}
+	if l.Rate() != 0 {
+		t.Fatalf("unexpected rate: %d", l.Rate())
+	}
+
+	// ctx取消时立即停止等待
+	l.SetRate(1<<10, 1<<10)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	if err := l.WaitN(ctx, 1<<20); !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > time.Second {
+		t.Fatalf("WaitN = %v after %v", err, time.Since(start))
+	}
+}
+
+// TestLimitCopy 测试单次拷贝及连接级别（多连接共享）限速
+func TestLimitCopy(t *testing.T) {
+	server := startTestServer(t)
+	remoteCon := server.connect(t)
+
+	data := testData(300 << 10)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.bin")
+	if err := os.WriteFile(src, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	for name, con := range map[string]internal.IConnection{"local": gossh.Local(), "remote": remoteCon} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			start := time.Now()
+			up := filepath.Join(dir, "up.bin")
+			if err := con.CopyFileLTR(src, up, "", gossh.WithLimiter(gossh.NewRateLimiter(1<<20, 32<<10))); err != nil {
+				t.Fatal(err)
+			}
+			down := filepath.Join(dir, "down.bin")
+			if err := con.CopyFileRTL(up, down, "", gossh.WithParallel(2, 64<<10), gossh.WithLimiter(gossh.NewRateLimiter(1<<20, 32<<10))); err != nil {
+				t.Fatal(err)
+			}
+			if elapsed := time.Since(start); elapsed < 450*time.Millisecond {
+				t.Fatalf("transfer not limited: %v", elapsed)
+			}
+			assertContent(t, down, data)
+		})
+	}
+
+	// 两个连接共享同一限速，总速度受限
+	shared := gossh.NewRateLimiter(2<<20, 32<<10)
+	cons := []internal.IConnection{gossh.Local(), remoteCon}
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i, con := range cons {
+		con.SetRateLimiter(shared)
+		wg.Add(1)
+		go func(i int, con internal.IConnection) {
+			defer wg.Done()
+			if err := con.CopyFileITR(bytes.NewReader(data), filepath.Join(dir, "shared", string(rune('a'+i))), ""); err != nil {
+				t.Error(err)
+			}
+		}(i, con)
+	}
+	wg.Wait()
+	if elapsed := time.Since(start); elapsed < 250*time.Millisecond {
+		t.Fatalf("shared limiter not applied: %v", elapsed)
+	}
+
+	// 传输的ctx超时时停止等待限速
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	for _, con := range cons {
+		err := con.CopyFileITR(bytes.NewReader(data), filepath.Join(dir, "shared", "slow"), "",
+			gossh.WithLimiter(gossh.NewRateLimiter(1<<10, 1<<10)), gossh.WithCopyContext(ctx))
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("copy with canceled context = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("copy not canceled: %v", elapsed)
+	}
+
+	// 取消连接限速
+	remoteCon.SetRateLimiter(nil)
+	start = time.Now()
+	if err := remoteCon.CopyFileITR(bytes.NewReader(data), filepath.Join(dir, "shared", "c"), ""); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Fatalf("limiter not removed: %v", elapsed)
+	}
+}
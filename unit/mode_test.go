@@ -0,0 +1,121 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 12:44:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 12:44:10
+ * @FilePath: mode_test.go
+ * @Description: 文件拷贝权限处理相关单元测试
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package unit
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/duanztop/gossh"
+	"github.com/duanztop/gossh/internal"
+	"github.com/duanztop/gossh/internal/tools"
+)
+
+// TestModeParse 测试八进制及符号格式权限的解析
+func TestModeParse(t *testing.T) {
+	cases := []struct {
+		mode string
+		base fs.FileMode
+		want fs.FileMode
+	}{
+		{"0600", 0777, 0600},
+		{"755", 0, 0755},
+		{"4755", 0, 0755 | fs.ModeSetuid},
+		{"u+x", 0644, 0744},
+		{"go-w", 0666, 0644},
+		{"a=r", 0755, 0444},
+		{"u=rwx,g=rx,o=", 0644, 0750},
+		{"+x", 0600, 0711},
+		{"g=u", 0640, 0660},
+		{"a+X", 0644, 0644},
+		{"a+X", 0744, 0755},
+		{"", 0640, 0640},
+	}
+	for _, c := range cases {
+		got, err := tools.ModeTools.Parse(c.mode, c.base)
+		if err != nil || got != c.want {
+			t.Errorf("Parse(%q, %o) = %o, %v; want %o", c.mode, c.base, got, err, c.want)
+		}
+	}
+	for _, mode := range []string{"u+y", "z+x", "u", "99999"} {
+		if _, err := tools.ModeTools.Parse(mode, 0644); err == nil {
+			t.Errorf("Parse(%q) should fail", mode)
+		}
+	}
+}
+
+// TestCopyFileMode 测试本地及远程拷贝时权限的设置（不受umask影响）
+func TestCopyFileMode(t *testing.T) {
+	old := syscall.Umask(077)
+	defer syscall.Umask(old)
+
+	server := startTestServer(t)
+	remoteCon := server.connect(t)
+	defer remoteCon.Close()
+
+	for name, con := range map[string]internal.IConnection{"local": gossh.Local(), "remote": remoteCon} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			src := filepath.Join(dir, "src.sh")
+			if err := os.WriteFile(src, []byte("echo hi"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chmod(src, 0750); err != nil {
+				t.Fatal(err)
+			}
+
+			check := func(dest string, want fs.FileMode) {
+				t.Helper()
+				info, err := os.Stat(dest)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if info.Mode().Perm() != want {
+					t.Fatalf("%s mode = %o, want %o", filepath.Base(dest), info.Mode().Perm(), want)
+				}
+			}
+
+			up := filepath.Join(dir, "up", "a.sh")
+			if err := con.CopyFileLTR(src, up, "0664"); err != nil {
+				t.Fatal(err)
+			}
+			check(up, 0664)
+			// 符号格式基于已有文件权限计算
+			if err := con.CopyFileLTR(src, up, "u+x,o-r"); err != nil {
+				t.Fatal(err)
+			}
+			check(up, 0760)
+
+			fresh := filepath.Join(dir, "up", "b.sh")
+			if err := con.CopyFileITR(strings.NewReader("echo"), fresh, ""); err != nil {
+				t.Fatal(err)
+			}
+			check(fresh, 0644)
+
+			preserved := filepath.Join(dir, "down", "c.sh")
+			if err := con.CopyFileRTL(src, preserved, "g-x", gossh.WithPreserveMode()); err != nil {
+				t.Fatal(err)
+			}
+			check(preserved, 0740)
+
+			if err := con.CopyFileRTL(src, preserved, "u+w", gossh.WithOwner("gossh-no-such-user", "")); err == nil {
+				t.Fatal("invalid owner should fail")
+			}
+			if err := con.CopyFileLTR(src, preserved, "u+y"); err == nil {
+				t.Fatal("invalid mode should fail")
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 15:52:30
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-20 00:08:40
+ * @FilePath: parallel_test.go
+ * @Description: 并行分块传输相关单元测试及性能测试
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package unit
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/duanztop/gossh"
+	"github.com/duanztop/gossh/internal"
+)
+
+// failReaderAt 读取指定位置之后的内容时返回errCut
+type failReaderAt struct {
+	*bytes.Reader
+	failAt int64
+}
+
+// ReadAt 实现io.ReaderAt
+//
+//	@author duanzt
+//	@date 2026-10-19 15:52:50
+//	@receiver f *failReaderAt
+//	@param p []byte 读取缓冲
+//	@param off int64 读取位置
+//	@return int 读取的字节数
+//	@return error 读取范围超过failAt时返回errCut
+func (f *failReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off+int64(len(p)) > f.failAt {
+		return 0, errCut
+	}
+	return f.Reader.ReadAt(p, off)
+}
+
+// TestParallelCopy 测试并行分块上传、下载（分块不整除、校验、进度及异常）
+//
+//	@author duanzt
+//	@date 2026-10-19 15:53:10
+//	@param t *testing.T
+func TestParallelCopy(t *testing.T) {
+	server := startTestServer(t)
+	remoteCon := server.connect(t)
+
+	data := testData(1<<20 + 12345)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.bin")
+	if err := os.WriteFile(src, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	for name, con := range map[string]internal.IConnection{"local": gossh.Local(), "remote": remoteCon} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			var last gossh.ProgressInfo
+			progress := gossh.ProgressFunc(func(info gossh.ProgressInfo) {
+				last = info
+			})
+			up := filepath.Join(dir, "up.bin")
+			if err := con.CopyFileLTR(src, up, "0600", gossh.WithParallel(4, 64<<10), gossh.WithVerify(gossh.HashSHA256), gossh.WithProgress(progress)); err != nil {
+				t.Fatal(err)
+			}
+			assertContent(t, up, data)
+			if !last.Finished || last.Done != int64(len(data)) {
+				t.Fatalf("unexpected final report: %+v", last)
+			}
+			if info, err := os.Stat(up); err != nil || info.Mode().Perm() != 0600 {
+				t.Fatalf("unexpected mode: %v, %v", info, err)
+			}
+
+			down := filepath.Join(dir, "down.bin")
+			if err := con.CopyFileRTL(up, down, "0644", gossh.WithParallel(3, 100000), gossh.WithAtomic()); err != nil {
+				t.Fatal(err)
+			}
+			assertContent(t, down, data)
+
+			// 覆盖更大的已有文件时不残留多余内容
+			if err := con.CopyFileITR(bytes.NewReader(data[:200000]), down, "", gossh.WithParallel(0, 30000)); err != nil {
+				t.Fatal(err)
+			}
+			assertContent(t, down, data[:200000])
+
+			// 任一分块失败时返回异常
+			bad := &failReaderAt{Reader: bytes.NewReader(data), failAt: 500000}
+			if err := con.CopyFileITR(bad, filepath.Join(dir, "bad.bin"), "", gossh.WithParallel(4, 64<<10)); !errors.Is(err, errCut) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// benchmarkUpload 上传测试文件到进程内sftp服务
+//
+//	@author duanzt
+//	@date 2026-10-19 15:54:20
+//	@param b *testing.B
+//	@param opts ...gossh.CopyOption 拷贝配置
+func benchmarkUpload(b *testing.B, opts ...gossh.CopyOption) {
+	server := startTestServer(b)
+	con := server.connect(b)
+	dir := b.TempDir()
+	src := filepath.Join(dir, "src.bin")
+	data := testData(32 << 20)
+	if err := os.WriteFile(src, data, 0644); err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := con.CopyFileLTR(src, filepath.Join(dir, "dest.bin"), "0644", opts...); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkCopyFileLTR 顺序上传
+//
+//	@author duanzt
+//	@date 2026-10-19 15:54:50
+//	@param b *testing.B
+func BenchmarkCopyFileLTR(b *testing.B) {
+	benchmarkUpload(b)
+}
+
+// BenchmarkCopyFileLTRParallel 并行分块上传
+//
+//	@author duanzt
+//	@date 2026-10-19 15:55:10
+//	@param b *testing.B
+func BenchmarkCopyFileLTRParallel(b *testing.B) {
+	benchmarkUpload(b, gossh.WithParallel(gossh.DefaultParallel, gossh.DefaultChunkSize))
+}
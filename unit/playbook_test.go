@@ -0,0 +1,256 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 22:43:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:59:30
+ * @FilePath: playbook_test.go
+ * @Description: 任务文件相关单元测试
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package unit
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/duanztop/gossh"
+	"github.com/duanztop/gossh/internal/remote"
+)
+
+const testPlaybook = `
+name: deploy app
+vars:
+  version: "1.2.0"
+  packages: [nginx, redis]
+tasks:
+  - name: upload package
+    copy:
+      src: files/app.tar.gz
+      dest: "{{ .root }}/app.tar.gz"
+      mode: "0600"
+    notify: restart app
+  - name: render config
+    template:
+      src: files/app.conf.tmpl
+      dest: "{{ .root }}/app.conf"
+    notify: [restart app, reload proxy]
+  - name: check version
+    shell: cat {{ .root }}/version 2>/dev/null || echo none
+    register: current
+    changed_when: "false"
+  - name: upgrade
+    shell: echo {{ .version }} > {{ .root }}/version
+    when: ne .current.stdout .version
+  - name: install packages
+    shell: echo {{ .item }} >> {{ .root }}/packages
+    loop: "{{ .packages }}"
+    when: ne .current.stdout .version
+    register: installed
+  - name: missing command
+    shell: exit 3
+    ignore_errors: true
+  - name: grep
+    shell: grep -c redis {{ .root }}/packages
+    register: grep
+    failed_when: "gt .grep.rc 1"
+    changed_when: "false"
+  - name: fetch log
+    fetch:
+      src: "{{ .root }}/app.conf"
+      dest: "fetched/{{ .inventory_hostname }}/app.conf"
+handlers:
+  - name: restart app
+    shell: echo restarted >> {{ .root }}/restarts
+  - name: reload proxy
+    shell: echo reloaded
+`
+
+// TestPlaybook 测试任务文件（shell、copy、template、fetch，条件、注册、循环、handler、幂等及执行汇总）
+func TestPlaybook(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"files/app.tar.gz":    "package",
+		"files/app.conf.tmpl": "version={{ .version }}\nhost={{ .inventory_hostname }}\n",
+		"playbook.yml":        testPlaybook,
+	})
+	pb, err := gossh.LoadPlaybook(filepath.Join(dir, "playbook.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pb.Tasks) != 8 || len(pb.Handlers) != 2 || !equalStrings(pb.Tasks[1].Notify, []string{"restart app", "reload proxy"}) {
+		t.Fatalf("playbook = %+v", pb)
+	}
+
+	// 本地连接：首次执行全部变更，再次执行幂等
+	root := filepath.Join(dir, "local")
+	os.MkdirAll(root, 0o755)
+	var results []*gossh.TaskResult
+	report, err := gossh.RunPlaybook(context.Background(), gossh.Local(), pb, gossh.WithExtraVars(map[string]any{"root": root}),
+		gossh.WithTaskHandler(func(result *gossh.TaskResult) { results = append(results, result) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recap := report.Recaps[0]
+	if recap.Changed != 7 || recap.Ok != 2 || recap.Ignored != 1 || recap.Skipped != 0 || recap.Failed != 0 || len(results) != 11 {
+		t.Fatalf("recap = %+v, results = %d", recap, len(results))
+	}
+	assertContent(t, filepath.Join(root, "app.tar.gz"), []byte("package"))
+	assertContent(t, filepath.Join(root, "packages"), []byte("nginx\nredis\n"))
+	assertContent(t, filepath.Join(root, "restarts"), []byte("restarted\n"))
+	assertContent(t, filepath.Join(dir, "fetched", recap.Host.Name, "app.conf"), []byte("version=1.2.0\nhost="+recap.Host.Name+"\n"))
+	if info, _ := os.Stat(filepath.Join(root, "app.tar.gz")); info.Mode().Perm() != 0o600 {
+		t.Fatalf("mode = %v", info.Mode())
+	}
+	if grep := results[7]; grep.Task.Name != "grep" || grep.Stdout != "1\n" || grep.Status != gossh.TaskOk {
+		t.Fatalf("grep = %+v", grep)
+	}
+
+	report, err = gossh.RunPlaybook(context.Background(), gossh.Local(), pb, gossh.WithExtraVars(map[string]any{"root": root}))
+	if recap := report.Recaps[0]; err != nil || recap.Changed != 0 || recap.Ok != 5 || recap.Skipped != 2 || recap.Ignored != 1 {
+		t.Fatalf("recap = %+v, %v", recap, err)
+	}
+	assertContent(t, filepath.Join(root, "restarts"), []byte("restarted\n"))
+
+	// 远程连接：多台主机并发执行，主机变量可在任务中引用
+	server := startTestServer(t)
+	hosts := testHosts(server, 2, 0)
+	for i := range hosts {
+		hosts[i].Vars = map[string]string{"root": filepath.Join(dir, hosts[i].Name)}
+		os.MkdirAll(hosts[i].Vars["root"], 0o755)
+	}
+	report, err = gossh.RunPlaybookHosts(context.Background(), hosts, pb, gossh.WithPlaybookFanout(gossh.WithDialer(remote.Dial)))
+	if err != nil || len(report.Recaps) != 2 || report.Recaps[1].Changed != 7 || report.Recaps[1].Host.Name != "web02" {
+		t.Fatalf("report = %+v, %v", report, err)
+	}
+	assertContent(t, filepath.Join(dir, "web02", "app.conf"), []byte("version=1.2.0\nhost=web02\n"))
+	assertContent(t, filepath.Join(dir, "fetched", "web01", "app.conf"), []byte("version=1.2.0\nhost=web01\n"))
+	var out bytes.Buffer
+	report.WriteTo(&out)
+	if !strings.Contains(out.String(), "web01 : ok=2    changed=7    unreachable=0    failed=0    skipped=0    ignored=1") {
+		t.Fatalf("recap = %s", out.String())
+	}
+}
+
+// TestPlaybookMode 测试copy、template内容一致但权限不同时修正权限并视为有变更（目录拷贝时作用于其中的文件）
+func TestPlaybookMode(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"files/conf/a.conf": "a",
+		"files/app.tmpl":    "app",
+		"playbook.yml": `
+tasks:
+  - name: content
+    copy: {content: "x", dest: "{{ .root }}/x.txt", mode: "0640"}
+  - name: dir
+    copy: {src: files/conf, dest: "{{ .root }}/conf", mode: "0600"}
+  - name: template
+    template: {src: files/app.tmpl, dest: "{{ .root }}/app.sh", mode: "u+x"}
+`,
+	})
+	pb, err := gossh.LoadPlaybook(filepath.Join(dir, "playbook.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := startTestServer(t)
+	for name, conn := range map[string]gossh.IConnection{"local": gossh.Local(), "remote": server.connect(t)} {
+		root := filepath.Join(dir, name)
+		os.MkdirAll(root, 0o755)
+		run := func(changed int) {
+			t.Helper()
+			report, err := gossh.RunPlaybook(context.Background(), conn, pb, gossh.WithExtraVars(map[string]any{"root": root}))
+			if recap := report.Recaps[0]; err != nil || recap.Changed != changed {
+				t.Fatalf("%s: recap = %+v, %v", name, recap, err)
+			}
+		}
+		run(3)
+		files := map[string]os.FileMode{"x.txt": 0o640, "conf/a.conf": 0o600}
+		for file := range files {
+			os.Chmod(filepath.Join(root, file), 0o644)
+		}
+		os.Chmod(filepath.Join(root, "app.sh"), 0o644)
+		run(3)
+		files["app.sh"] = 0o744
+		for file, want := range files {
+			if info, err := os.Stat(filepath.Join(root, file)); err != nil || info.Mode().Perm() != want {
+				t.Fatalf("%s: %s mode = %v, %v, want %v", name, file, info.Mode().Perm(), err, want)
+			}
+		}
+		run(0)
+	}
+}
+
+// TestPlaybookFailure 测试任务失败时停止、不执行handler，以及解析校验
+func TestPlaybookFailure(t *testing.T) {
+	root := t.TempDir()
+	pb, err := gossh.ParsePlaybook([]byte(`
+- name: write
+  copy:
+    content: "{{ .greeting }}"
+    dest: `+root+`/greeting
+  notify: done
+- name: fail
+  shell: echo broken >&2; exit 2
+  register: broken
+- name: never
+  shell: touch `+root+`/never
+`), root)
+	if err == nil {
+		t.Fatal("unknown handler should fail")
+	}
+	pb, err = gossh.ParsePlaybook([]byte(`
+tasks:
+  - name: write
+    copy:
+      content: "{{ .greeting }}"
+      dest: `+root+`/greeting
+    notify: done
+  - name: fail
+    shell: echo broken >&2; exit 2
+  - name: never
+    shell: touch `+root+`/never
+handlers:
+  - name: done
+    shell: touch `+root+`/done
+`), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := gossh.RunPlaybook(context.Background(), gossh.Local(), pb, gossh.WithExtraVars(map[string]any{"greeting": "hello"}))
+	recap := report.Recaps[0]
+	if err == nil || recap.Changed != 1 || recap.Failed != 1 || !strings.Contains(err.Error(), "fail") {
+		t.Fatalf("recap = %+v, %v", recap, err)
+	}
+	if last := recap.Results[len(recap.Results)-1]; last.Stderr != "broken\n" || last.ExitCode != 2 || last.Status != gossh.TaskFailed {
+		t.Fatalf("result = %+v", last)
+	}
+	assertContent(t, filepath.Join(root, "greeting"), []byte("hello"))
+	for _, name := range []string{"never", "done"} {
+		if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+			t.Fatalf("%s should not exist", name)
+		}
+	}
+
+	// 引用不存在的变量
+	report, err = gossh.RunPlaybook(context.Background(), gossh.Local(), pb)
+	if err == nil || !strings.Contains(err.Error(), "greeting") {
+		t.Fatalf("missing var: %v", err)
+	}
+
+	for _, data := range []string{
+		"- name: none\n",
+		"- shell: a\n  copy: {src: a, dest: b}\n",
+		"- copy: {dest: b}\n",
+		"- shell: a\n  loop: {a: 1}\n",
+		"- shell: a\n  unknown: 1\n",
+		"tasks: []\nhandlers:\n  - shell: a\n",
+	} {
+		if _, err := gossh.ParsePlaybook([]byte(data), root); err == nil {
+			t.Fatalf("%q should fail", data)
+		}
+	}
+}
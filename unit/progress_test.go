@@ -0,0 +1,100 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 15:02:40
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 15:02:40
+ * @FilePath: progress_test.go
+ * @Description: 传输进度报告相关单元测试
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package unit
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/duanztop/gossh"
+	"github.com/duanztop/gossh/internal"
+)
+
+// TestProgress 测试进度报告（开始、递增、结束及异常）
+func TestProgress(t *testing.T) {
+	server := startTestServer(t)
+	remoteCon := server.connect(t)
+
+	data := testData(200000)
+	for name, con := range map[string]internal.IConnection{"local": gossh.Local(), "remote": remoteCon} {
+		t.Run(name, func(t *testing.T) {
+			dest := filepath.Join(t.TempDir(), "app.bin")
+			var infos []gossh.ProgressInfo
+			progress := gossh.ProgressFunc(func(info gossh.ProgressInfo) {
+				infos = append(infos, info)
+			})
+			if err := con.CopyFileITR(bytes.NewReader(data), dest, "", gossh.WithProgress(progress)); err != nil {
+				t.Fatal(err)
+			}
+			if len(infos) < 2 || infos[0].Done != 0 || infos[0].Total != int64(len(data)) || infos[0].Finished {
+				t.Fatalf("unexpected start report: %+v", infos)
+			}
+			last := infos[len(infos)-1]
+			if !last.Finished || last.Err != nil || last.Done != int64(len(data)) || last.Percent() != 100 || last.ETA != 0 {
+				t.Fatalf("unexpected final report: %+v", last)
+			}
+			for i := 1; i < len(infos); i++ {
+				if infos[i].Done < infos[i-1].Done || infos[i].Name != dest {
+					t.Fatalf("unexpected report: %+v", infos[i])
+				}
+			}
+
+			// 中断时结束报告携带异常，断点续传时已有部分计入已完成
+			infos = nil
+			err := con.CopyFileITR(&cutReader{r: bytes.NewReader(data), n: 5000}, dest, "", gossh.WithProgress(progress))
+			if last := infos[len(infos)-1]; !errors.Is(err, errCut) || !last.Finished || !errors.Is(last.Err, errCut) || last.Total != -1 {
+				t.Fatalf("unexpected final report: %+v, %v", last, err)
+			}
+			infos = nil
+			if err := con.CopyFileITR(bytes.NewReader(data), dest, "", gossh.WithResume(0), gossh.WithProgress(progress)); err != nil {
+				t.Fatal(err)
+			}
+			if infos[0].Done != 5000 || infos[len(infos)-1].Done != int64(len(data)) {
+				t.Fatalf("unexpected resume reports: %+v", infos)
+			}
+		})
+	}
+}
+
+// TestMonAdapter 测试*Mon方法的chan适配（结束或失败时均关闭chan）
+func TestMonAdapter(t *testing.T) {
+	server := startTestServer(t)
+	con := server.connect(t)
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.bin")
+	data := testData(100000)
+	if err := os.WriteFile(src, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sizes := make(chan int64, 1000)
+	if err := con.CopyFileLTRMon(src, filepath.Join(dir, "up.bin"), "0644", sizes); err != nil {
+		t.Fatal(err)
+	}
+	var last int64
+	for size := range sizes {
+		last = size
+	}
+	if last != int64(len(data)) {
+		t.Fatalf("last size %d, want %d", last, len(data))
+	}
+
+	sizes = make(chan int64, 1000)
+	if err := con.CopyFileRTLMon(filepath.Join(dir, "missing"), filepath.Join(dir, "down.bin"), "0644", sizes); err == nil {
+		t.Fatal("missing source should fail")
+	}
+	for range sizes {
+	}
+}
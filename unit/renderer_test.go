@@ -0,0 +1,97 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 15:26:40
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-20 00:03:20
+ * @FilePath: renderer_test.go
+ * @Description: 多进度条渲染相关单元测试
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package unit
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/duanztop/gossh"
+	"github.com/duanztop/gossh/internal/tools"
+)
+
+// TestRendererLog 测试非终端输出（日志行、多个传输、失败状态）
+func TestRendererLog(t *testing.T) {
+	var out bytes.Buffer
+	r := gossh.NewProgressRenderer(&out).SetLogInterval(time.Hour)
+	host1, host2 := r.WithLabel("host1"), r.WithLabel("host2")
+
+	host1.Report(gossh.ProgressInfo{Name: "/opt/app.tar", Done: 0, Total: 2048, ETA: -1})
+	host2.Report(gossh.ProgressInfo{Name: "/opt/app.tar", Done: 0, Total: -1, ETA: -1})
+	host1.Report(gossh.ProgressInfo{Name: "/opt/app.tar", Done: 1024, Total: 2048, Rate: 1024, ETA: time.Second})
+	host1.Report(gossh.ProgressInfo{Name: "/opt/app.tar", Done: 2048, Total: 2048, Rate: 1024, Finished: true})
+	host2.Report(gossh.ProgressInfo{Name: "/opt/app.tar", Done: 10, Total: -1, Finished: true, Err: errors.New("断开")})
+	r.Stop()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	// 间隔内的中间进度不输出
+	if len(lines) != 4 {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+	if !strings.HasPrefix(lines[0], "host1: /opt/app.tar   0.0% 0B/2.0KiB") || strings.ContainsAny(out.String(), "\r\x1b") {
+		t.Fatalf("unexpected start line: %q", lines[0])
+	}
+	if !strings.Contains(lines[2], "完成 2.0KiB 1.0KiB/s") || !strings.Contains(lines[3], "host2: /opt/app.tar 失败") {
+		t.Fatalf("unexpected end lines:\n%s", out.String())
+	}
+}
+
+// TestRendererCopy 测试渲染接入拷贝方法
+func TestRendererCopy(t *testing.T) {
+	var out bytes.Buffer
+	r := gossh.NewProgressRenderer(&out)
+	dir := t.TempDir()
+	if _, err := gossh.Local().CopyDirLTR(writeTempTree(t, dir), filepath.Join(dir, "dest"), gossh.WithProgress(r)); err != nil {
+		t.Fatal(err)
+	}
+	r.Stop()
+	if strings.Count(out.String(), "完成") != 2 {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+}
+
+// TestFormat 测试字节数及时长格式化，以及旧进度条对0大小的处理
+func TestFormat(t *testing.T) {
+	cases := map[int64]string{0: "0B", 1023: "1023B", 1024: "1.0KiB", 1536: "1.5KiB", 5 << 20: "5.0MiB", 3 << 40: "3.0TiB"}
+	for n, want := range cases {
+		if got := tools.FormatBytes(n); got != want {
+			t.Errorf("FormatBytes(%d) = %s, want %s", n, got, want)
+		}
+	}
+	if got := tools.FormatDuration(65 * time.Second); got != "01:05" {
+		t.Errorf("FormatDuration = %s", got)
+	}
+	if got := tools.FormatDuration(3725 * time.Second); got != "01:02:05" {
+		t.Errorf("FormatDuration = %s", got)
+	}
+	tools.PorcessOnTools.Print(0, 0)
+	tools.PorcessOnTools.PrintEnd()
+}
+
+// writeTempTree 创建包含两个文件的测试目录
+func writeTempTree(t *testing.T, dir string) string {
+	src := filepath.Join(dir, "src")
+	writeTree(t, src, map[string]string{"a.txt": "a", "sub/b.txt": "bb"})
+	return src
+}
+
+// TestDisplayWidth 测试字符串在终端中占的列数（中文等宽字符占2列）
+func TestDisplayWidth(t *testing.T) {
+	for s, want := range map[string]int{"": 0, "app.tar": 7, "部署包.tar": 10, "ｆｕｌｌ": 8, "é": 1, "한글🎉": 6} {
+		if got := tools.DisplayWidth(s); got != want {
+			t.Errorf("DisplayWidth(%q) = %d, want %d", s, got, want)
+		}
+	}
+}
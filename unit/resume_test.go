@@ -0,0 +1,188 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 13:20:05
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 13:20:05
+ * @FilePath: resume_test.go
+ * @Description: 断点续传相关单元测试
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package unit
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/duanztop/gossh"
+	"github.com/duanztop/gossh/internal"
+)
+
+// errCut 模拟传输中断
+var errCut = errors.New("连接中断")
+
+// cutReader 读取n个字节后返回errCut
+type cutReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *cutReader) Read(p []byte) (int, error) {
+	if c.n <= 0 {
+		return 0, errCut
+	}
+	if len(p) > c.n {
+		p = p[:c.n]
+	}
+	n, err := c.r.Read(p)
+	c.n -= n
+	return n, err
+}
+
+// countingReader 统计通过Read读取的字节数（支持Seek及ReadAt）
+type countingReader struct {
+	r     *bytes.Reader
+	count int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.count += n
+	return n, err
+}
+
+func (c *countingReader) Seek(offset int64, whence int) (int64, error) {
+	return c.r.Seek(offset, whence)
+}
+
+func (c *countingReader) ReadAt(p []byte, off int64) (int, error) {
+	return c.r.ReadAt(p, off)
+}
+
+// testData 生成测试内容
+func testData(size int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i*7 + i/251)
+	}
+	return data
+}
+
+// TestResumeUpload 测试上传中断后续传
+func TestResumeUpload(t *testing.T) {
+	server := startTestServer(t)
+	remoteCon := server.connect(t)
+	defer remoteCon.Close()
+
+	const cut = 100000
+	data := testData(300000)
+	for name, con := range map[string]internal.IConnection{"local": gossh.Local(), "remote": remoteCon} {
+		t.Run(name, func(t *testing.T) {
+			dest := filepath.Join(t.TempDir(), "app.tar")
+			err := con.CopyFileITR(&cutReader{r: bytes.NewReader(data), n: cut}, dest, "0644")
+			if !errors.Is(err, errCut) {
+				t.Fatalf("expected interrupted copy, got %v", err)
+			}
+			if info, err := os.Stat(dest); err != nil || info.Size() != cut {
+				t.Fatalf("partial file: %v, %v", info, err)
+			}
+
+			// 已有内容校验通过，只传输剩余部分
+			src := &countingReader{r: bytes.NewReader(data)}
+			if err := con.CopyFileITR(src, dest, "0644", gossh.WithResume(4096)); err != nil {
+				t.Fatal(err)
+			}
+			if src.count != len(data)-cut {
+				t.Fatalf("read %d bytes, want %d", src.count, len(data)-cut)
+			}
+			assertContent(t, dest, data)
+
+			// 已有内容被修改时从头传输
+			if err := os.Truncate(dest, cut); err != nil {
+				t.Fatal(err)
+			}
+			corrupt(t, dest, cut-1)
+			src = &countingReader{r: bytes.NewReader(data)}
+			if err := con.CopyFileITR(src, dest, "0644", gossh.WithResume(4096)); err != nil {
+				t.Fatal(err)
+			}
+			if src.count != len(data) {
+				t.Fatalf("read %d bytes, want %d", src.count, len(data))
+			}
+			assertContent(t, dest, data)
+
+			// 目标文件比源文件大时从头传输
+			if err := con.CopyFileITR(bytes.NewReader(data[:cut]), dest, "0644", gossh.WithResume(0)); err != nil {
+				t.Fatal(err)
+			}
+			assertContent(t, dest, data[:cut])
+		})
+	}
+}
+
+// TestResumeDownload 测试下载中断后续传
+func TestResumeDownload(t *testing.T) {
+	server := startTestServer(t)
+	con := server.connect(t)
+	defer con.Close()
+
+	const cut = 70000
+	data := testData(200000)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "remote.bin")
+	if err := os.WriteFile(src, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := filepath.Join(dir, "local.bin")
+	if err := os.WriteFile(dest, data[:cut], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := con.CopyFileRTL(src, dest, "", gossh.WithResume(1024)); err != nil {
+		t.Fatal(err)
+	}
+	assertContent(t, dest, data)
+
+	if err := os.Truncate(dest, cut); err != nil {
+		t.Fatal(err)
+	}
+	corrupt(t, dest, cut-10)
+	if err := con.CopyFileRTL(src, dest, "", gossh.WithResume(1024)); err != nil {
+		t.Fatal(err)
+	}
+	assertContent(t, dest, data)
+}
+
+// assertContent 校验文件内容
+func assertContent(t *testing.T, name string, want []byte) {
+	t.Helper()
+	got, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("%s: content mismatch (%d bytes, want %d)", filepath.Base(name), len(got), len(want))
+	}
+}
+
+// corrupt 修改文件指定位置的一个字节
+func corrupt(t *testing.T, name string, off int64) {
+	t.Helper()
+	f, err := os.OpenFile(name, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	b := make([]byte, 1)
+	if _, err := f.ReadAt(b, off); err != nil {
+		t.Fatal(err)
+	}
+	b[0] ^= 0xff
+	if _, err := f.WriteAt(b, off); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,137 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 21:38:10
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-20 00:02:40
+ * @FilePath: rollout_test.go
+ * @Description: 分批滚动执行相关单元测试
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package unit
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/duanztop/gossh"
+	"github.com/duanztop/gossh/internal"
+	"github.com/duanztop/gossh/internal/remote"
+)
+
+// TestRollout 测试分批滚动执行（按百分比分批、前置/后置任务、健康检查、错误预算、状态记录恢复、批次间隔）
+func TestRollout(t *testing.T) {
+	server := startTestServer(t)
+	ctx := context.Background()
+	dial := gossh.WithRolloutFanout(gossh.WithDialer(remote.Dial))
+	hosts := testHosts(server, 10, 0)
+
+	var lock sync.Mutex
+	var steps []string
+	unhealthy := map[string]bool{"web05": true}
+	step := func(name string, fail func(host gossh.Host) bool) gossh.HostTask {
+		return func(ctx context.Context, host gossh.Host, conn internal.IConnection) (*gossh.CommandResult, error) {
+			lock.Lock()
+			steps = append(steps, name+" "+host.Name)
+			failed := fail != nil && fail(host)
+			lock.Unlock()
+			if failed {
+				return conn.Run(ctx, "echo "+name+" failed >&2; exit 1")
+			}
+			return conn.Run(ctx, "echo "+name)
+		}
+	}
+	opts := []gossh.RolloutOption{
+		dial,
+		gossh.WithBatchPercent(30),
+		gossh.WithPreHook(step("pre", nil)),
+		gossh.WithPostHook(step("post", nil)),
+		gossh.WithHealthCheck(step("health", func(host gossh.Host) bool { return unhealthy[host.Name] })),
+		gossh.WithStateFile(filepath.Join(t.TempDir(), "state.json")),
+	}
+
+	// web05健康检查失败，第2批完成后停止
+	report, err := gossh.Rollout(ctx, hosts, step("deploy", nil), opts...)
+	if !errors.Is(err, gossh.ErrRolloutHalted) || !report.Halted || len(report.Batches) != 2 ||
+		report.Succeeded != 5 || report.Failed != 1 || report.Remaining != 4 {
+		t.Fatalf("report = %+v, %v", report, err)
+	}
+	if result := report.Batches[1].Results[0]; result.Stdout != "pre\ndeploy\npost\n" || result.ExitCode != 0 {
+		t.Fatalf("web04 = %+v", result)
+	}
+	if state := report.State; state.Batches != 2 || len(state.Done) != 5 || !equalStrings(state.Failed, []string{"web05"}) || !state.Halted {
+		t.Fatalf("state = %+v", state)
+	}
+	if len(steps) != 6*4 {
+		t.Fatalf("steps = %v", steps)
+	}
+
+	// 修复后再次执行：跳过已成功的主机，批次序号延续
+	delete(unhealthy, "web05")
+	steps = nil
+	report, err = gossh.Rollout(ctx, hosts, step("deploy", nil), opts...)
+	if err != nil || report.Resumed != 5 || report.Succeeded != 5 || len(report.Batches) != 2 || report.Batches[0].Index != 3 {
+		t.Fatalf("report = %+v, %v", report, err)
+	}
+	if hosts := report.Batches[0].Results; hosts[0].Host.Name != "web05" || hosts[1].Host.Name != "web07" {
+		t.Fatalf("batch 3 = %v, %v", hosts[0].Host.Name, hosts[1].Host.Name)
+	}
+	if state := report.State; len(state.Done) != 10 || len(state.Failed) != 0 || state.Halted {
+		t.Fatalf("state = %+v", state)
+	}
+	report, err = gossh.Rollout(ctx, hosts, step("deploy", nil), opts...)
+	if err != nil || report.Resumed != 10 || len(report.Batches) != 0 {
+		t.Fatalf("report = %+v, %v", report, err)
+	}
+
+	// 错误预算：允许1台失败，前置任务失败时不执行任务
+	steps = nil
+	failing := map[string]bool{"web01": true, "web03": true}
+	report, err = gossh.Rollout(ctx, hosts, step("deploy", nil), dial, gossh.WithBatchSize(2), gossh.WithErrorBudget(1),
+		gossh.WithPreHook(step("pre", func(host gossh.Host) bool { return failing[host.Name] })))
+	if !errors.Is(err, gossh.ErrRolloutHalted) || len(report.Batches) != 2 || report.Failed != 2 || report.Succeeded != 2 || report.Remaining != 6 {
+		t.Fatalf("report = %+v, %v", report, err)
+	}
+	if result := report.Batches[0].Results[0]; result.Err == nil || result.Stderr != "pre failed\n" || result.ExitCode != 1 {
+		t.Fatalf("web01 = %+v", result)
+	}
+	for _, s := range steps {
+		if s == "deploy web01" || s == "deploy web03" {
+			t.Fatalf("steps = %v", steps)
+		}
+	}
+
+	// 恢复执行时此前失败的主机计入错误预算
+	steps = nil
+	stateFile := filepath.Join(t.TempDir(), "budget.json")
+	failing = map[string]bool{"web06": true}
+	failHook := gossh.WithPreHook(step("pre", func(host gossh.Host) bool { return failing[host.Name] }))
+	report, err = gossh.Rollout(ctx, hosts[3:6], step("deploy", nil), dial, gossh.WithErrorBudget(1), gossh.WithStateFile(stateFile), failHook)
+	if err == nil || report.Halted || report.Failed != 1 {
+		t.Fatalf("report = %+v, %v", report, err)
+	}
+	failing["web02"] = true
+	report, err = gossh.Rollout(ctx, hosts[:6], step("deploy", nil), dial, gossh.WithBatchSize(2), gossh.WithErrorBudget(1),
+		gossh.WithStateFile(stateFile), failHook)
+	if !errors.Is(err, gossh.ErrRolloutHalted) || report.Resumed != 2 || len(report.Batches) != 1 || report.Failed != 1 || report.Remaining != 2 ||
+		!equalStrings(report.State.Failed, []string{"web02", "web06"}) {
+		t.Fatalf("report = %+v, %v", report, err)
+	}
+
+	// 批次间隔，间隔期间取消时停止
+	start := time.Now()
+	report, err = gossh.Rollout(ctx, hosts[:3], step("deploy", nil), dial, gossh.WithBatchPause(150*time.Millisecond))
+	if err != nil || len(report.Batches) != 3 || time.Since(start) < 300*time.Millisecond {
+		t.Fatalf("report = %+v, %v", report, err)
+	}
+	cancelCtx, cancel := context.WithCancel(ctx)
+	report, err = gossh.Rollout(cancelCtx, hosts[:3], step("deploy", nil), dial, gossh.WithBatchPause(time.Minute),
+		gossh.WithBatchHandler(func(*gossh.BatchResult) { cancel() }))
+	if !errors.Is(err, gossh.ErrRolloutHalted) || len(report.Batches) != 1 || report.Remaining != 2 {
+		t.Fatalf("report = %+v, %v", report, err)
+	}
+}
@@ -0,0 +1,199 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 17:26:40
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:53:30
+ * @FilePath: scp_test.go
+ * @Description: scp协议传输相关单元测试
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package unit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/duanztop/gossh"
+)
+
+// TestScpFallbackCached 测试服务端拒绝sftp子系统的结果记录在连接上，后续拷贝直接使用scp
+func TestScpFallbackCached(t *testing.T) {
+	server := startTestServer(t)
+	server.noSftp = true
+	con := server.connect(t)
+	dir := t.TempDir()
+	for i := 0; i < 3; i++ {
+		dest := filepath.Join(dir, fmt.Sprintf("%d.txt", i))
+		if err := con.CopyFileITR(strings.NewReader("content"), dest, "0644"); err != nil {
+			t.Fatal(err)
+		}
+		assertContent(t, dest, []byte("content"))
+	}
+	if n := atomic.LoadInt32(&server.sftpRequests); n != 1 {
+		t.Fatalf("sftp subsystem requests = %d, want 1", n)
+	}
+}
+
+// TestScpCopyFile 测试远端未开启sftp时使用scp上传及下载文件
+func TestScpCopyFile(t *testing.T) {
+	server := startTestServer(t)
+	server.noSftp = true
+	con := server.connect(t)
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.bin")
+	data := testData(300000)
+	if err := os.WriteFile(src, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local)
+	if err := os.Chtimes(src, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	// 上传：权限、修改时间、进度
+	var last gossh.ProgressInfo
+	up := filepath.Join(dir, "remote", "up.bin")
+	if err := con.CopyFileLTR(src, up, "0640", gossh.WithPreserveTimes(), gossh.WithProgress(gossh.ProgressFunc(func(info gossh.ProgressInfo) {
+		last = info
+	}))); err != nil {
+		t.Fatal(err)
+	}
+	assertContent(t, up, data)
+	if info, err := os.Stat(up); err != nil || info.Mode().Perm() != 0640 || !info.ModTime().Equal(modTime) {
+		t.Fatalf("unexpected file info: %v, %v", info, err)
+	}
+	if !last.Finished || last.Err != nil || last.Done != int64(len(data)) {
+		t.Fatalf("unexpected final report: %+v", last)
+	}
+
+	// 大小未知的流；mode为空时已有文件保持原权限
+	if err := os.Chmod(up, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := con.CopyFileITR(struct{ io.Reader }{bytes.NewReader(data[:1000])}, up, ""); err != nil {
+		t.Fatal(err)
+	}
+	assertContent(t, up, data[:1000])
+	if info, err := os.Stat(up); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("unexpected file info: %v, %v", info, err)
+	}
+
+	// 原子写入、备份及校验
+	if err := con.CopyFileLTR(src, up, "0644", gossh.WithBackup(".bak"), gossh.WithVerify(gossh.HashSHA256)); err != nil {
+		t.Fatal(err)
+	}
+	assertContent(t, up, data)
+	assertContent(t, up+".bak", data[:1000])
+	if names := listDir(t, filepath.Dir(up)); !equalStrings(names, []string{"up.bin", "up.bin.bak"}) {
+		t.Fatalf("unexpected files: %v", names)
+	}
+
+	// 下载
+	down := filepath.Join(dir, "local", "down.bin")
+	if err := con.CopyFileRTL(up, down, "", gossh.WithPreserveTimes()); err != nil {
+		t.Fatal(err)
+	}
+	assertContent(t, down, data)
+	if info, err := os.Stat(down); err != nil || info.Mode().Perm() != 0644 {
+		t.Fatalf("unexpected file info: %v, %v", info, err)
+	}
+	if err := con.CopyFileRTL(filepath.Join(dir, "missing"), down, ""); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 指定sftp时不回退
+	if err := con.CopyFileLTR(src, up, "", gossh.WithProtocol(gossh.ProtocolSFTP)); err == nil {
+		t.Fatal("sftp should fail")
+	}
+}
+
+// TestScpCopyDir 测试使用scp递归上传及下载目录（过滤、保留权限及修改时间）
+func TestScpCopyDir(t *testing.T) {
+	server := startTestServer(t)
+	con := server.connect(t)
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	writeTree(t, src, map[string]string{
+		"a.txt":          "a",
+		"app.log":        "log",
+		"conf/b.txt":     "bb",
+		"conf/c.yaml":    "c: 1",
+		"logs/today.log": "log",
+		"empty/x.log":    "log",
+	})
+	if err := os.Chmod(filepath.Join(src, "conf"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("a.txt", filepath.Join(src, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Date(2021, 6, 7, 8, 9, 10, 0, time.Local)
+	if err := os.Chtimes(filepath.Join(src, "conf", "b.txt"), modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	// 显式指定scp（服务端支持sftp）
+	dest := filepath.Join(dir, "remote", "app")
+	summary, err := con.CopyDirLTR(src, dest, gossh.WithProtocol(gossh.ProtocolSCP), gossh.WithExclude("*.log"),
+		gossh.WithPreserveMode(), gossh.WithPreserveTimes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"a.txt", "conf/b.txt", "conf/c.yaml", "link.txt"}
+	if got := listTree(t, dest); !equalStrings(got, want) {
+		t.Fatalf("unexpected tree: %v", got)
+	}
+	if summary.Files != 4 || summary.Skipped != 3 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if info, err := os.Stat(filepath.Join(dest, "conf")); err != nil || info.Mode().Perm() != 0700 {
+		t.Fatalf("unexpected dir info: %v, %v", info, err)
+	}
+	if info, err := os.Stat(filepath.Join(dest, "conf", "b.txt")); err != nil || !info.ModTime().Equal(modTime) {
+		t.Fatalf("unexpected file info: %v, %v", info, err)
+	}
+
+	// 下载（远端未开启sftp时自动使用scp），include时不创建空目录
+	server.noSftp = true
+	noSftpCon := server.connect(t)
+	back := filepath.Join(dir, "back")
+	summary, err = noSftpCon.CopyDirRTL(src, back, gossh.WithInclude("*.txt"), gossh.WithPreserveTimes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := listTree(t, back); !equalStrings(got, []string{"a.txt", "conf/b.txt", "link.txt"}) {
+		t.Fatalf("unexpected tree: %v", got)
+	}
+	if _, err := os.Stat(filepath.Join(back, "empty")); !os.IsNotExist(err) {
+		t.Fatalf("empty dir should not be created: %v", err)
+	}
+	if info, err := os.Stat(filepath.Join(back, "conf", "b.txt")); err != nil || !info.ModTime().Equal(modTime) {
+		t.Fatalf("unexpected file info: %v, %v", info, err)
+	}
+	if summary.Files != 3 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+
+	// 源为文件时仅拷贝该文件
+	if _, err := noSftpCon.CopyDirRTL(filepath.Join(src, "a.txt"), filepath.Join(dir, "single.txt")); err != nil {
+		t.Fatal(err)
+	}
+	assertContent(t, filepath.Join(dir, "single.txt"), []byte("a"))
+
+	// 上传目录时不支持校验
+	if _, err := noSftpCon.CopyDirLTR(src, dest, gossh.WithVerify(gossh.HashSHA256)); err == nil {
+		t.Fatal("verify should not be supported")
+	}
+}
@@ -0,0 +1,81 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 23:48:50
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:48:50
+ * @FilePath: sshconfig_test.go
+ * @Description: openssh客户端配置解析单元测试
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/duanztop/gossh"
+)
+
+// TestSSHConfig 测试解析openssh客户端配置（通配、排除、首个值生效、Include及跳板机别名）
+func TestSSHConfig(t *testing.T) {
+	dir := t.TempDir()
+	include := filepath.Join(dir, "extra.conf")
+	if err := os.WriteFile(include, []byte("Host db\n  HostName 10.0.0.9\n  User dba\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := gossh.ParseSSHConfig([]byte(`
+# 注释
+Host bastion
+  HostName=1.2.3.4
+  Port 2222
+  User jump
+
+Host web?? !web99
+  HostName %h.example.com
+  User deploy
+  IdentityFile ~/.ssh/web_key ~/.ssh/other
+  ProxyJump bastion
+
+Include ` + include + `
+
+Host *
+  User root
+  Port 22
+  User ignored
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aliases := config.Aliases(); !equalStrings(aliases, []string{"bastion", "db"}) {
+		t.Fatalf("aliases = %v", aliases)
+	}
+	home, _ := os.UserHomeDir()
+	host := config.Host("web01")
+	if host.Name != "web01" || host.Addr != "web01.example.com:22" || host.User != "deploy" ||
+		host.PrivateKey != filepath.Join(home, ".ssh/web_key") || host.Jump != "jump@1.2.3.4:2222" {
+		t.Fatalf("web01 = %+v", host)
+	}
+	if host := config.Host("web99"); host.Addr != "web99:22" || host.User != "root" || host.Jump != "" {
+		t.Fatalf("web99 = %+v", host)
+	}
+	if host := config.Host("db"); host.Addr != "10.0.0.9:22" || host.User != "dba" {
+		t.Fatalf("db = %+v", host)
+	}
+	if host := config.Host("bastion"); host.Addr != "1.2.3.4:2222" || host.User != "jump" {
+		t.Fatalf("bastion = %+v", host)
+	}
+	if v := config.Get("WEB01", "identityfile"); v != "~/.ssh/web_key ~/.ssh/other" {
+		t.Fatalf("get = %q", v)
+	}
+
+	// 文件不存在时为空配置；配置格式不正确时返回异常
+	empty, err := gossh.LoadSSHConfig(filepath.Join(dir, "missing"))
+	if err != nil || len(empty.Aliases()) != 0 || empty.Host("x").Addr != "x:22" {
+		t.Fatalf("missing = %v", err)
+	}
+	if _, err := gossh.ParseSSHConfig([]byte("Host \"unterminated\n")); err == nil {
+		t.Fatal("unterminated quote should fail")
+	}
+}
@@ -0,0 +1,528 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 10:18:44
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-20 00:05:40
+ * @FilePath: sshserver_test.go
+ * @Description: 单元测试使用的进程内ssh服务（支持exec、shell、sftp子系统、direct-tcpip及tcpip-forward转发）
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package unit
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/duanztop/gossh/internal"
+	"github.com/duanztop/gossh/internal/remote"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	testUsername = "gossh"
+	testPassword = "gossh"
+)
+
+// testServer 进程内ssh服务
+type testServer struct {
+	listener net.Listener
+	config   *ssh.ServerConfig
+	noSftp   bool // 为true时拒绝sftp子系统（模拟未开启sftp的主机）
+	noExec   bool // 为true时拒绝执行命令（模拟仅开启sftp的主机）
+	wg       sync.WaitGroup
+
+	checkFile      bool  // 为true时sftp子系统支持check-file扩展
+	badCheckFile   bool  // 为true时check-file返回错误的摘要（模拟传输损坏）
+	checkFileCalls int32 // check-file扩展被调用的次数
+	forwards       int32 // direct-tcpip转发（跳板机、本地端口转发）的次数
+	ptyRequests    int32 // pty-req请求的次数
+	windowChanges  int32 // window-change请求的次数
+	sftpRequests   int32 // sftp子系统请求的次数（含被拒绝的请求）
+	noPosixRename  bool  // 为true时sftp子系统不声明posix-rename扩展（模拟仅支持sftp v3 rename的服务端）
+	sftpLock       sync.Mutex
+	sftpChannels   []ssh.Channel // 进行中的sftp子系统（用于模拟sftp子系统断开）
+
+	authorizedKey ssh.PublicKey // 允许登录的公钥（为nil时仅支持密码验证）
+	forwardDelay  time.Duration // direct-tcpip连接目标地址前的等待时间（模拟无法及时连接的目标主机）
+}
+
+// startTestServer 启动进程内ssh服务，测试结束时自动关闭
+//
+//	@author duanzt
+//	@date 2026-10-19 10:19:30
+//	@param tb testing.TB
+//	@return *testServer ssh服务
+func startTestServer(tb testing.TB) *testServer {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() == testUsername && string(password) == testPassword {
+				return nil, nil
+			}
+			return nil, io.EOF
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	s := &testServer{listener: listener, config: config}
+	config.PublicKeyCallback = func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+		if conn.User() == testUsername && s.authorizedKey != nil && bytes.Equal(key.Marshal(), s.authorizedKey.Marshal()) {
+			return nil, nil
+		}
+		return nil, io.EOF
+	}
+	s.wg.Add(1)
+	go s.serve()
+	tb.Cleanup(func() {
+		listener.Close()
+		s.wg.Wait()
+	})
+	return s
+}
+
+// connect 使用remote连接到进程内ssh服务
+//
+//	@author duanzt
+//	@date 2026-10-19 10:20:12
+//	@receiver s *testServer
+//	@param tb testing.TB
+//	@return internal.IConnection ssh连接
+func (s *testServer) connect(tb testing.TB) internal.IConnection {
+	conn, err := remote.NewConnection1(testUsername, testPassword, s.listener.Addr().String())
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func (s *testServer) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handleConn(conn)
+		}()
+	}
+}
+
+func (s *testServer) handleConn(conn net.Conn) {
+	defer conn.Close()
+	serverConn, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		return
+	}
+	defer serverConn.Close()
+	go s.handleGlobalRequests(serverConn, reqs)
+	var wg sync.WaitGroup
+	for newChannel := range chans {
+		if newChannel.ChannelType() == "direct-tcpip" {
+			wg.Add(1)
+			go func(newChannel ssh.NewChannel) {
+				defer wg.Done()
+				s.handleDirectTCPIP(newChannel)
+			}(newChannel)
+			continue
+		}
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.handleSession(channel, requests)
+		}()
+	}
+	wg.Wait()
+}
+
+// handleDirectTCPIP 处理direct-tcpip通道：连接目标地址并双向转发
+//
+//	@author duanzt
+//	@date 2026-10-19 22:12:30
+//	@receiver s *testServer
+//	@param newChannel ssh.NewChannel 新通道
+func (s *testServer) handleDirectTCPIP(newChannel ssh.NewChannel) {
+	var payload struct {
+		Host       string
+		Port       uint32
+		OriginHost string
+		OriginPort uint32
+	}
+	if err := ssh.Unmarshal(newChannel.ExtraData(), &payload); err != nil {
+		newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	time.Sleep(s.forwardDelay)
+	target, err := net.Dial("tcp", net.JoinHostPort(payload.Host, strconv.Itoa(int(payload.Port))))
+	if err != nil {
+		newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	defer target.Close()
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		return
+	}
+	defer channel.Close()
+	go ssh.DiscardRequests(requests)
+	atomic.AddInt32(&s.forwards, 1)
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(target, channel)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(channel, target)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+// handleGlobalRequests 处理全局请求：tcpip-forward在服务端监听，并将连接通过forwarded-tcpip通道转发给客户端
+//
+//	@author duanzt
+//	@date 2026-10-19 23:19:10
+//	@receiver s *testServer
+//	@param serverConn *ssh.ServerConn ssh连接
+//	@param reqs <-chan *ssh.Request 全局请求
+func (s *testServer) handleGlobalRequests(serverConn *ssh.ServerConn, reqs <-chan *ssh.Request) {
+	listeners := map[string]net.Listener{}
+	defer func() {
+		for _, l := range listeners {
+			l.Close()
+		}
+	}()
+	for req := range reqs {
+		var payload struct {
+			Addr string
+			Port uint32
+		}
+		if req.Type != "tcpip-forward" && req.Type != "cancel-tcpip-forward" || ssh.Unmarshal(req.Payload, &payload) != nil {
+			if req.WantReply {
+				req.Reply(false, nil)
+			}
+			continue
+		}
+		key := net.JoinHostPort(payload.Addr, strconv.Itoa(int(payload.Port)))
+		if req.Type == "cancel-tcpip-forward" {
+			if l, ok := listeners[key]; ok {
+				l.Close()
+				delete(listeners, key)
+			}
+			req.Reply(true, nil)
+			continue
+		}
+		l, err := net.Listen("tcp", key)
+		if err != nil {
+			req.Reply(false, nil)
+			continue
+		}
+		port := uint32(l.Addr().(*net.TCPAddr).Port)
+		listeners[net.JoinHostPort(payload.Addr, strconv.Itoa(int(port)))] = l
+		req.Reply(true, ssh.Marshal(struct{ Port uint32 }{port}))
+		go func() {
+			for {
+				conn, err := l.Accept()
+				if err != nil {
+					return
+				}
+				go func() {
+					defer conn.Close()
+					origin := conn.RemoteAddr().(*net.TCPAddr)
+					channel, requests, err := serverConn.OpenChannel("forwarded-tcpip", ssh.Marshal(struct {
+						Addr       string
+						Port       uint32
+						OriginAddr string
+						OriginPort uint32
+					}{payload.Addr, port, origin.IP.String(), uint32(origin.Port)}))
+					if err != nil {
+						return
+					}
+					defer channel.Close()
+					go ssh.DiscardRequests(requests)
+					done := make(chan struct{}, 2)
+					go func() {
+						io.Copy(conn, channel)
+						done <- struct{}{}
+					}()
+					go func() {
+						io.Copy(channel, conn)
+						channel.CloseWrite()
+						done <- struct{}{}
+					}()
+					<-done
+					<-done
+				}()
+			}
+		}()
+	}
+}
+
+// dropSftp 关闭进行中的sftp子系统（模拟sftp子系统断开）
+//
+//	@author duanzt
+//	@date 2026-10-20 00:04:00
+//	@receiver s *testServer
+func (s *testServer) dropSftp() {
+	s.sftpLock.Lock()
+	defer s.sftpLock.Unlock()
+	for _, channel := range s.sftpChannels {
+		channel.Close()
+	}
+	s.sftpChannels = nil
+}
+
+func (s *testServer) handleSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+	for req := range requests {
+		switch req.Type {
+		case "subsystem":
+			if string(req.Payload[4:]) == "sftp" {
+				atomic.AddInt32(&s.sftpRequests, 1)
+			}
+			if s.noSftp || string(req.Payload[4:]) != "sftp" {
+				req.Reply(false, nil)
+				continue
+			}
+			req.Reply(true, nil)
+			s.sftpLock.Lock()
+			s.sftpChannels = append(s.sftpChannels, channel)
+			s.sftpLock.Unlock()
+			var rwc io.ReadWriteCloser = channel
+			if s.checkFile || s.noPosixRename {
+				rwc = &checkFileProxy{channel: channel, server: s}
+			}
+			server, err := sftp.NewServer(rwc)
+			if err != nil {
+				return
+			}
+			_ = server.Serve()
+			server.Close()
+			return
+		case "pty-req":
+			atomic.AddInt32(&s.ptyRequests, 1)
+			req.Reply(true, nil)
+		case "exec", "shell":
+			if s.noExec {
+				req.Reply(false, nil)
+				continue
+			}
+			req.Reply(true, nil)
+			cmd := exec.Command("sh")
+			if req.Type == "exec" {
+				cmd = exec.Command("sh", "-c", string(req.Payload[4:]))
+			}
+			// 通过管道转发输入，命令退出后不等待客户端关闭输入（与sshd一致，例如scp -f发送完毕后退出）
+			stdin, err := cmd.StdinPipe()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, _ = io.Copy(stdin, channel)
+				stdin.Close()
+			}()
+			cmd.Stdout = channel
+			cmd.Stderr = channel.Stderr()
+			if err := cmd.Start(); err != nil {
+				return
+			}
+			// 客户端关闭会话时结束命令（例如tail -F），与sshd一致
+			exited := make(chan struct{})
+			go func() {
+				for req := range requests {
+					if req.Type == "window-change" {
+						atomic.AddInt32(&s.windowChanges, 1)
+					}
+					if req.WantReply {
+						req.Reply(false, nil)
+					}
+				}
+				select {
+				case <-exited:
+				default:
+					_ = cmd.Process.Kill()
+				}
+			}()
+			status := 0
+			if err := cmd.Wait(); err != nil {
+				status = 255
+				if exitErr, ok := err.(*exec.ExitError); ok {
+					if waitStatus, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+						status = waitStatus.ExitStatus()
+					}
+				}
+			}
+			close(exited)
+			payload := make([]byte, 4)
+			binary.BigEndian.PutUint32(payload, uint32(status))
+			channel.SendRequest("exit-status", false, payload)
+			return
+		default:
+			if req.WantReply {
+				req.Reply(false, nil)
+			}
+		}
+	}
+}
+
+// checkFileProxy 在sftp服务前处理check-file-name扩展报文及修改声明的扩展，其余报文原样转发
+type checkFileProxy struct {
+	channel ssh.Channel
+	server  *testServer
+	mu      sync.Mutex // 保证写入channel的报文完整
+	rbuf    []byte     // 待交给sftp服务的客户端报文
+	wbuf    []byte     // sftp服务写出的不完整报文
+}
+
+func (p *checkFileProxy) Read(b []byte) (int, error) {
+	for len(p.rbuf) == 0 {
+		var header [4]byte
+		if _, err := io.ReadFull(p.channel, header[:]); err != nil {
+			return 0, err
+		}
+		packet := make([]byte, 4+binary.BigEndian.Uint32(header[:]))
+		copy(packet, header[:])
+		if _, err := io.ReadFull(p.channel, packet[4:]); err != nil {
+			return 0, err
+		}
+		if fields := parseFields(packet[9:], 2); packet[4] == 200 && len(fields) == 2 && fields[0] == "check-file-name" {
+			p.replyCheckFile(packet[5:9], packet[9:])
+			continue
+		}
+		p.rbuf = packet
+	}
+	n := copy(b, p.rbuf)
+	p.rbuf = p.rbuf[n:]
+	return n, nil
+}
+
+func (p *checkFileProxy) Write(b []byte) (int, error) {
+	p.wbuf = append(p.wbuf, b...)
+	for len(p.wbuf) >= 4 {
+		length := 4 + int(binary.BigEndian.Uint32(p.wbuf))
+		if len(p.wbuf) < length {
+			break
+		}
+		packet := append([]byte(nil), p.wbuf[:length]...)
+		p.wbuf = p.wbuf[length:]
+		if packet[4] == 2 {
+			packet = p.versionPacket(packet)
+		}
+		if err := p.send(packet); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// versionPacket 修改SSH_FXP_VERSION中声明的扩展（声明check-file扩展、去除posix-rename扩展）
+func (p *checkFileProxy) versionPacket(packet []byte) []byte {
+	result := append([]byte(nil), packet[:9]...)
+	for data := packet[9:]; ; {
+		fields := parseFields(data, 2)
+		if len(fields) < 2 {
+			break
+		}
+		data = data[8+len(fields[0])+len(fields[1]):]
+		if p.server.noPosixRename && fields[0] == "posix-rename@openssh.com" {
+			continue
+		}
+		result = appendSftpString(appendSftpString(result, fields[0]), fields[1])
+	}
+	if p.server.checkFile {
+		result = appendSftpString(appendSftpString(result, "check-file"), "sha256,sha512,md5")
+	}
+	binary.BigEndian.PutUint32(result, uint32(len(result)-4))
+	return result
+}
+
+func (p *checkFileProxy) Close() error {
+	return p.channel.Close()
+}
+
+func (p *checkFileProxy) send(packet []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := p.channel.Write(packet)
+	return err
+}
+
+// replyCheckFile 计算文件摘要并返回SSH_FXP_EXTENDED_REPLY
+func (p *checkFileProxy) replyCheckFile(id, data []byte) {
+	atomic.AddInt32(&p.server.checkFileCalls, 1)
+	fields := parseFields(data, 3)
+	algo := internal.HashAlgorithm(strings.Split(fields[2], ",")[0])
+	sum := []byte{}
+	if h, err := algo.New(); err == nil {
+		if content, err := os.ReadFile(fields[1]); err == nil {
+			h.Write(content)
+			sum = h.Sum(nil)
+		}
+	}
+	if p.server.badCheckFile && len(sum) > 0 {
+		sum[0] ^= 0xff
+	}
+	payload := append([]byte{0, 0, 0, 0, 201}, id...)
+	payload = appendSftpString(payload, "check-file")
+	payload = appendSftpString(payload, string(algo))
+	payload = append(payload, sum...)
+	binary.BigEndian.PutUint32(payload, uint32(len(payload)-4))
+	_ = p.send(payload)
+}
+
+// parseFields 解析n个sftp字符串字段
+func parseFields(b []byte, n int) []string {
+	var fields []string
+	for i := 0; i < n && len(b) >= 4; i++ {
+		l := binary.BigEndian.Uint32(b)
+		if uint32(len(b)-4) < l {
+			break
+		}
+		fields = append(fields, string(b[4:4+l]))
+		b = b[4+l:]
+	}
+	return fields
+}
+
+func appendSftpString(b []byte, s string) []byte {
+	var l [4]byte
+	binary.BigEndian.PutUint32(l[:], uint32(len(s)))
+	return append(append(b, l[:]...), s...)
+}
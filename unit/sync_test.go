@@ -0,0 +1,166 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 18:30:12
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-19 23:58:30
+ * @FilePath: sync_test.go
+ * @Description: 目录同步相关单元测试
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/duanztop/gossh"
+)
+
+// changeList 将同步操作转换为"操作 路径"列表
+//
+//	@author duanzt
+//	@date 2026-10-19 18:30:40
+//	@param report *gossh.SyncReport 同步结果
+//	@return []string 操作列表
+func changeList(report *gossh.SyncReport) []string {
+	changes := make([]string, 0, len(report.Changes))
+	for _, change := range report.Changes {
+		changes = append(changes, string(change.Action)+" "+change.Path+" "+change.Reason)
+	}
+	return changes
+}
+
+// TestSyncDirResume 测试同步时不对已确认不一致的文件断点续传（目标端较短的旧内容不是源文件的前缀）
+func TestSyncDirResume(t *testing.T) {
+	server := startTestServer(t)
+	for name, conn := range map[string]gossh.IConnection{"local": gossh.Local(), "remote": server.connect(t)} {
+		src, dest := t.TempDir(), t.TempDir()
+		writeTree(t, src, map[string]string{"a.txt": "hello world"})
+		writeTree(t, dest, map[string]string{"a.txt": "stale"})
+		report, err := conn.SyncDirLTR(src, dest, gossh.WithResume(0))
+		if err != nil || report.Files != 1 {
+			t.Fatalf("%s: report = %+v, %v", name, report, err)
+		}
+		assertContent(t, filepath.Join(dest, "a.txt"), []byte("hello world"))
+	}
+}
+
+// TestSyncDir 测试目录同步（新建、按大小及修改时间更新、删除多余文件、演练、排除文件不删除）
+func TestSyncDir(t *testing.T) {
+	server := startTestServer(t)
+	con := server.connect(t)
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	dest := filepath.Join(dir, "remote", "app")
+	writeTree(t, src, map[string]string{
+		"a.txt":      "a",
+		"conf/b.txt": "bb",
+		"conf/c.txt": "cc",
+	})
+
+	// 首次同步全部新建
+	report, err := con.SyncDirLTR(src, dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"create . missing", "create a.txt missing", "create conf missing", "create conf/b.txt missing", "create conf/c.txt missing"}
+	if got := changeList(report); !equalStrings(got, want) {
+		t.Fatalf("unexpected changes: %v", got)
+	}
+	if report.Files != 3 || report.Bytes != 5 || report.Dirs != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	// 再次同步无变化
+	if report, err = con.SyncDirLTR(src, dest); err != nil || len(report.Changes) != 0 || report.Unchanged != 3 {
+		t.Fatalf("unexpected report: %+v, %v", report, err)
+	}
+
+	// 修改源文件、目标端增加多余文件
+	writeTree(t, src, map[string]string{"a.txt": "A", "conf/b.txt": "bbb"})
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(src, "a.txt"), future, future); err != nil {
+		t.Fatal(err)
+	}
+	writeTree(t, dest, map[string]string{"old/x.txt": "x", "keep.log": "log"})
+
+	// 演练不修改目标端
+	report, err = con.SyncDirLTR(src, dest, gossh.WithDelete(), gossh.WithExclude("*.log"), gossh.WithDryRun())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"delete old extraneous", "update a.txt mtime", "update conf/b.txt size"}
+	if got := changeList(report); !equalStrings(got, want) || !report.DryRun || report.Files != 0 {
+		t.Fatalf("unexpected changes: %v, %+v", got, report)
+	}
+	assertContent(t, filepath.Join(dest, "a.txt"), []byte("a"))
+	if _, err := os.Stat(filepath.Join(dest, "old")); err != nil {
+		t.Fatalf("dry run should not delete: %v", err)
+	}
+
+	// 执行同步，排除的文件不删除，更新的文件未开启WithPreserveMode时保持原权限
+	if err := os.Chmod(filepath.Join(dest, "a.txt"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	report, err = con.SyncDirLTR(src, dest, gossh.WithDelete(), gossh.WithExclude("*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := changeList(report); !equalStrings(got, want) || report.Files != 2 || report.Count(gossh.SyncDelete) != 1 {
+		t.Fatalf("unexpected changes: %v, %+v", got, report)
+	}
+	if got := listTree(t, dest); !equalStrings(got, []string{"a.txt", "conf/b.txt", "conf/c.txt", "keep.log"}) {
+		t.Fatalf("unexpected tree: %v", got)
+	}
+	assertContent(t, filepath.Join(dest, "a.txt"), []byte("A"))
+	if info, err := os.Stat(filepath.Join(dest, "a.txt")); err != nil || info.ModTime().Unix() != future.Unix() || info.Mode().Perm() != 0600 {
+		t.Fatalf("mtime should be synced and mode kept: %v, %v", info, err)
+	}
+
+	// 大小及修改时间相同但内容不同，仅按摘要比较时发现
+	modTime := time.Date(2022, 1, 1, 0, 0, 0, 0, time.Local)
+	writeTree(t, dest, map[string]string{"conf/c.txt": "CC"})
+	for _, name := range []string{filepath.Join(src, "conf", "c.txt"), filepath.Join(dest, "conf", "c.txt")} {
+		if err := os.Chtimes(name, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if report, err = con.SyncDirLTR(src, dest); err != nil || len(report.Changes) != 0 {
+		t.Fatalf("unexpected report: %+v, %v", report, err)
+	}
+	report, err = con.SyncDirLTR(src, dest, gossh.WithChecksum(gossh.HashSHA256))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := changeList(report); !equalStrings(got, []string{"update conf/c.txt checksum"}) {
+		t.Fatalf("unexpected changes: %v", got)
+	}
+	assertContent(t, filepath.Join(dest, "conf", "c.txt"), []byte("cc"))
+
+	// 下载同步，类型不一致时替换
+	back := filepath.Join(dir, "back")
+	writeTree(t, back, map[string]string{"conf": "file"})
+	report, err = con.SyncDirRTL(dest, back, gossh.WithInclude("*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := listTree(t, back); !equalStrings(got, []string{"a.txt", "conf/b.txt", "conf/c.txt"}) {
+		t.Fatalf("unexpected tree: %v", got)
+	}
+	if report.Count(gossh.SyncUpdate) != 1 || report.Skipped != 1 {
+		t.Fatalf("unexpected report: %v, %+v", changeList(report), report)
+	}
+
+	// 本地连接
+	localCopy := filepath.Join(dir, "local")
+	if _, err := gossh.Local().SyncDirLTR(src, localCopy); err != nil {
+		t.Fatal(err)
+	}
+	if report, err = gossh.Local().SyncDirLTR(src, localCopy, gossh.WithChecksum(gossh.HashMD5)); err != nil || report.Unchanged != 3 {
+		t.Fatalf("unexpected report: %+v, %v", report, err)
+	}
+}
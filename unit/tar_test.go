@@ -0,0 +1,207 @@
+/*
+ * @Author: duanzt
+ * @Date: 2026-10-19 18:03:40
+ * @LastEditors: duanzt
+ * @LastEditTime: 2026-10-20 00:05:00
+ * @FilePath: tar_test.go
+ * @Description: tar流传输相关单元测试
+ *
+ * Copyright (c) 2026 by duanzt, All Rights Reserved.
+ */
+package unit
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/duanztop/gossh"
+	"github.com/duanztop/gossh/internal"
+	"github.com/duanztop/gossh/internal/transfer"
+)
+
+// TestTarCopyDir 测试以tar流上传及下载目录（压缩、过滤、符号链接、保留权限及修改时间、进度）
+func TestTarCopyDir(t *testing.T) {
+	server := startTestServer(t)
+	server.noSftp = true
+	con := server.connect(t)
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	writeTree(t, src, map[string]string{
+		"a.txt":          "a",
+		"app.log":        "log",
+		"conf/b.txt":     "bb",
+		"conf/c.yaml":    "c: 1",
+		"logs/today.log": "log",
+	})
+	if err := os.MkdirAll(filepath.Join(src, "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(src, "conf"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(src, "a.txt"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("a.txt", filepath.Join(src, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Date(2021, 6, 7, 8, 9, 10, 0, time.Local)
+	if err := os.Chtimes(filepath.Join(src, "conf", "b.txt"), modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, compression := range []gossh.Compression{gossh.CompressionNone, gossh.CompressionGzip, gossh.CompressionZstd} {
+		// 上传：排除、保留符号链接、权限及修改时间，进度按文件内容报告一次
+		var reports int
+		var last gossh.ProgressInfo
+		dest := filepath.Join(dir, "remote-"+string(compression), "app")
+		summary, err := con.CopyDirLTR(src, dest, gossh.WithTar(compression), gossh.WithExclude("*.log", "logs"),
+			gossh.WithSymlinkPolicy(gossh.SymlinkPreserve), gossh.WithPreserveMode(), gossh.WithPreserveTimes(),
+			gossh.WithProgress(gossh.ProgressFunc(func(info gossh.ProgressInfo) {
+				reports++
+				last = info
+			})))
+		if err != nil {
+			t.Fatalf("%s: %v", compression, err)
+		}
+		if got := listTree(t, dest); !equalStrings(got, []string{"a.txt", "conf/b.txt", "conf/c.yaml", "link.txt"}) {
+			t.Fatalf("%s: unexpected tree: %v", compression, got)
+		}
+		if summary.Files != 3 || summary.Symlinks != 1 || summary.Skipped != 2 || summary.Bytes != 7 {
+			t.Fatalf("%s: unexpected summary: %+v", compression, summary)
+		}
+		if !last.Finished || last.Err != nil || last.Done != 7 || last.Total != 7 || reports < 2 {
+			t.Fatalf("%s: unexpected final report: %+v", compression, last)
+		}
+		if target, err := os.Readlink(filepath.Join(dest, "link.txt")); err != nil || target != "a.txt" {
+			t.Fatalf("%s: unexpected link: %q, %v", compression, target, err)
+		}
+		if info, err := os.Stat(filepath.Join(dest, "conf")); err != nil || info.Mode().Perm() != 0700 {
+			t.Fatalf("%s: unexpected dir info: %v, %v", compression, info, err)
+		}
+		if info, err := os.Stat(filepath.Join(dest, "a.txt")); err != nil || info.Mode().Perm() != 0600 {
+			t.Fatalf("%s: unexpected file info: %v, %v", compression, info, err)
+		}
+		if info, err := os.Stat(filepath.Join(dest, "conf", "b.txt")); err != nil || !info.ModTime().Equal(modTime) {
+			t.Fatalf("%s: unexpected file info: %v, %v", compression, info, err)
+		}
+		if info, err := os.Stat(filepath.Join(dest, "empty")); err != nil || !info.IsDir() {
+			t.Fatalf("%s: empty dir should be created: %v", compression, err)
+		}
+
+		// 下载：include、跟随符号链接，include时不创建空目录
+		back := filepath.Join(dir, "back-"+string(compression))
+		summary, err = con.CopyDirRTL(src, back, gossh.WithTar(compression), gossh.WithInclude("*.txt"), gossh.WithPreserveTimes())
+		if err != nil {
+			t.Fatalf("%s: %v", compression, err)
+		}
+		if got := listTree(t, back); !equalStrings(got, []string{"a.txt", "conf/b.txt", "link.txt"}) {
+			t.Fatalf("%s: unexpected tree: %v", compression, got)
+		}
+		if _, err := os.Stat(filepath.Join(back, "empty")); !os.IsNotExist(err) {
+			t.Fatalf("%s: empty dir should not be created: %v", compression, err)
+		}
+		if info, err := os.Lstat(filepath.Join(back, "link.txt")); err != nil || !info.Mode().IsRegular() {
+			t.Fatalf("%s: symlink should be followed: %v, %v", compression, info, err)
+		}
+		if info, err := os.Stat(filepath.Join(back, "conf", "b.txt")); err != nil || !info.ModTime().Equal(modTime) {
+			t.Fatalf("%s: unexpected file info: %v, %v", compression, info, err)
+		}
+		if summary.Files != 3 {
+			t.Fatalf("%s: unexpected summary: %+v", compression, summary)
+		}
+	}
+
+	// 远端目录不存在
+	if _, err := con.CopyDirRTL(filepath.Join(dir, "missing"), filepath.Join(dir, "x"), gossh.WithTar(gossh.CompressionGzip)); err == nil {
+		t.Fatal("missing dir should fail")
+	}
+	// 不支持校验
+	if _, err := con.CopyDirLTR(src, filepath.Join(dir, "y"), gossh.WithTar(gossh.CompressionNone), gossh.WithVerify(gossh.HashSHA256)); !errors.Is(err, transfer.ErrTarUnsupported) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// TestExtractTarUnsafe 测试解包时拒绝写入目标目录之外的条目
+func TestExtractTarUnsafe(t *testing.T) {
+	dir := t.TempDir()
+	outside := filepath.Join(dir, "outside")
+	if err := os.MkdirAll(outside, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := tarStream(t, []*tar.Header{
+		{Name: "../escape.txt", Typeflag: tar.TypeReg, Mode: 0644, Size: 1},
+		{Name: "/abs.txt", Typeflag: tar.TypeReg, Mode: 0644, Size: 1},
+		{Name: "link", Typeflag: tar.TypeSymlink, Linkname: outside, Mode: 0777},
+		{Name: "link/through.txt", Typeflag: tar.TypeReg, Mode: 0644, Size: 1},
+		{Name: "./ok/file.txt", Typeflag: tar.TypeReg, Mode: 0640, Size: 1},
+	})
+
+	dest := filepath.Join(dir, "dest")
+	o := internal.NewCopyOptions(internal.WithSymlinkPolicy(internal.SymlinkPreserve))
+	summary, err := transfer.ExtractTar(buf, dest, o, nil)
+	if err == nil || len(summary.Errors) != 3 {
+		t.Fatalf("unexpected result: %+v, %v", summary, err)
+	}
+	if names := listDir(t, outside); len(names) != 0 {
+		t.Fatalf("files written outside: %v", names)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "escape.txt")); !os.IsNotExist(err) {
+		t.Fatalf("file written outside: %v", err)
+	}
+	assertContent(t, filepath.Join(dest, "ok", "file.txt"), []byte("x"))
+	if info, err := os.Stat(filepath.Join(dest, "ok", "file.txt")); err != nil || info.Mode().Perm() != 0640 {
+		t.Fatalf("unexpected file info: %v, %v", info, err)
+	}
+
+	// 先解压指向目标目录之外的符号链接，再通过其创建硬链接，最后写入同名文件
+	victim := filepath.Join(outside, "victim.txt")
+	if err := os.WriteFile(victim, []byte("original"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	buf = tarStream(t, []*tar.Header{
+		{Name: "l", Typeflag: tar.TypeSymlink, Linkname: outside, Mode: 0777},
+		{Name: "h", Typeflag: tar.TypeLink, Linkname: "l/victim.txt", Mode: 0644},
+		{Name: "h", Typeflag: tar.TypeReg, Mode: 0644, Size: 1},
+	})
+	dest = filepath.Join(dir, "dest2")
+	summary, err = transfer.ExtractTar(buf, dest, internal.NewCopyOptions(), nil)
+	if err == nil || len(summary.Errors) != 1 {
+		t.Fatalf("unexpected result: %+v, %v", summary, err)
+	}
+	assertContent(t, victim, []byte("original"))
+	assertContent(t, filepath.Join(dest, "h"), []byte("x"))
+}
+
+// tarStream 生成tar流（普通文件的内容为Size个x）
+//
+//	@author duanzt
+//	@date 2026-10-20 00:05:00
+//	@param t *testing.T
+//	@param entries []*tar.Header 条目
+//	@return *bytes.Buffer tar流
+func tarStream(t *testing.T, entries []*tar.Header) *bytes.Buffer {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, header := range entries {
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Size > 0 {
+			if _, err := tw.Write(bytes.Repeat([]byte("x"), int(header.Size))); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}